@@ -100,7 +100,7 @@ func testBenchmarkLakeFS() error {
 	failed = doInParallel(ctx, repoName, parallelism, filesAmount, "", reader)
 	logger.WithField("failedCount", failed).Info("Finished reading files")
 
-	commitResp, err := client.CommitWithResponse(ctx, repo.Id, branchName, api.CommitJSONRequestBody{
+	commitResp, err := client.CommitWithResponse(ctx, repo.Id, branchName, &api.CommitParams{}, api.CommitJSONRequestBody{
 		Message: "commit before merge",
 	})
 	if err != nil {
@@ -197,7 +197,7 @@ func uploader(ctx context.Context, ch chan string, repoName, contentPrefix strin
 
 func merge(ctx context.Context) {
 	err := retry.Do(func() error {
-		resp, err := client.MergeIntoBranchWithResponse(ctx, repoName, branchName, "main", api.MergeIntoBranchJSONRequestBody{
+		resp, err := client.MergeIntoBranchWithResponse(ctx, repoName, branchName, "main", &api.MergeIntoBranchParams{}, api.MergeIntoBranchJSONRequestBody{
 			Message: api.StringPtr("merging all objects to main"),
 		})
 		if err != nil {
@@ -8,7 +8,12 @@ import (
 	"github.com/treeverse/lakefs/pkg/uri"
 )
 
-const branchRevertCmdArgs = 2
+const (
+	branchRevertCmdArgs     = 2
+	branchCherryPickCmdArgs = 2
+	branchRebaseCmdArgs     = 2
+	branchProtectCmdArgs    = 2
+)
 
 const (
 	ParentNumberFlagName = "parent-number"
@@ -29,22 +34,34 @@ var branchListCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		amount := MustInt(cmd.Flags().GetInt("amount"))
 		after := MustString(cmd.Flags().GetString("after"))
+		compareTo := MustString(cmd.Flags().GetString("compare-to"))
 		u := MustParseRepoURI("repository", args[0])
 		client := getClient()
-		resp, err := client.ListBranchesWithResponse(cmd.Context(), u.Repository, &api.ListBranchesParams{
+		params := &api.ListBranchesParams{
 			After:  api.PaginationAfterPtr(after),
 			Amount: api.PaginationAmountPtr(amount),
-		})
+		}
+		if compareTo != "" {
+			params.CompareTo = (*api.CompareTo)(&compareTo)
+		}
+		resp, err := client.ListBranchesWithResponse(cmd.Context(), u.Repository, params)
 		DieOnResponseError(resp, err)
 
 		refs := resp.JSON200.Results
 		rows := make([][]interface{}, len(refs))
 		for i, row := range refs {
 			rows[i] = []interface{}{row.Id, row.CommitId}
+			if compareTo != "" {
+				rows[i] = append(rows[i], api.IntValue(row.Ahead), api.IntValue(row.Behind))
+			}
 		}
 
+		headers := []interface{}{"Branch", "Commit ID"}
+		if compareTo != "" {
+			headers = append(headers, "Ahead", "Behind")
+		}
 		pagination := resp.JSON200.Pagination
-		PrintTable(rows, []interface{}{"Branch", "Commit ID"}, &pagination, amount)
+		PrintTable(rows, headers, &pagination, amount)
 	},
 }
 
@@ -118,6 +135,69 @@ var branchRevertCmd = &cobra.Command{
 	},
 }
 
+// lakectl branch cherry-pick lakefs://myrepo/main commitId
+var branchCherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick <branch uri> <commit ref to cherry-pick>",
+	Short: "given a commit, record a new commit on the branch applying the changes introduced by this commit",
+	Args:  cobra.ExactArgs(branchCherryPickCmdArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseRefURI("branch", args[0])
+		Fmt("Branch: %s\n", u.String())
+		commitRef := args[1]
+		clt := getClient()
+		hasParentNumber := cmd.Flags().Changed(ParentNumberFlagName)
+		parentNumber, _ := cmd.Flags().GetInt(ParentNumberFlagName)
+		if hasParentNumber && parentNumber <= 0 {
+			Die("parent number must be a positive number, if specified", 1)
+		}
+		message, _ := cmd.Flags().GetString("message")
+		body := api.CherryPickJSONRequestBody{
+			Ref: commitRef,
+		}
+		if hasParentNumber {
+			body.ParentNumber = &parentNumber
+		}
+		if message != "" {
+			body.Message = &message
+		}
+		resp, err := clt.CherryPickWithResponse(cmd.Context(), u.Repository, u.Ref, body)
+		DieOnResponseError(resp, err)
+		Write(commitCreateTemplate, struct {
+			Branch *uri.URI
+			Commit *api.Commit
+		}{Branch: u, Commit: resp.JSON201})
+	},
+}
+
+var branchRebaseTemplate = `Rebase of branch "{{.Branch.Ref}}" completed.
+
+Head: {{.Commit.Id|yellow}}
+Message: {{.Commit.Message}}
+Parents: {{.Commit.Parents|join ", "}}
+
+`
+
+var branchRebaseCmd = &cobra.Command{
+	Use:   "rebase <branch uri> <onto ref>",
+	Short: "replay the commits of a branch since it diverged from the given ref on top of that ref",
+	Long:  "replay each non-merge commit of the branch since its merge base with the given ref on top of the ref, dropping commits whose changes are already there and stopping at the first conflicting commit",
+	Args:  cobra.ExactArgs(branchRebaseCmdArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseRefURI("branch", args[0])
+		Fmt("Branch: %s\n", u.String())
+		onto := args[1]
+		clt := getClient()
+		resp, err := clt.RebaseBranchWithResponse(cmd.Context(), u.Repository, u.Ref, api.RebaseBranchJSONRequestBody{
+			Onto: onto,
+		})
+		DieOnResponseError(resp, err)
+		Write(branchRebaseTemplate, struct {
+			Branch *uri.URI
+			Commit *api.Commit
+		}{Branch: u, Commit: resp.JSON200})
+	},
+}
+
 // lakectl branch reset lakefs://myrepo/main --commit commitId --prefix path --object path
 var branchResetCmd = &cobra.Command{
 	Use:   "reset <branch uri> [flags]",
@@ -177,13 +257,79 @@ var branchShowCmd = &cobra.Command{
 	Short: "show branch latest commit reference",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		compareTo := MustString(cmd.Flags().GetString("compare-to"))
 		client := getClient()
 		u := MustParseRefURI("branch", args[0])
 		Fmt("Branch: %s\n", u.String())
-		resp, err := client.GetBranchWithResponse(cmd.Context(), u.Repository, u.Ref)
+		params := &api.GetBranchParams{}
+		if compareTo != "" {
+			params.CompareTo = (*api.CompareTo)(&compareTo)
+		}
+		resp, err := client.GetBranchWithResponse(cmd.Context(), u.Repository, u.Ref, params)
 		DieOnResponseError(resp, err)
 		branch := resp.JSON200
-		Fmt("%s\n", branch)
+		Fmt("Commit ID: %s\n", branch.CommitId)
+		if compareTo != "" {
+			Fmt("Ahead of %s: %d\nBehind %s: %d\n", compareTo, api.IntValue(branch.Ahead), compareTo, api.IntValue(branch.Behind))
+		}
+	},
+}
+
+var branchProtectCmd = &cobra.Command{
+	Use:   "protect",
+	Short: "manage branch protection rules",
+	Long: `Protected branches reject direct writes, commits and deletion, they change only by merging into them.
+Rules match branch names by pattern, a rule may also require the latest action run of the merge source to pass.`,
+}
+
+var branchProtectListCmd = &cobra.Command{
+	Use:   "list <repository uri>",
+	Short: "list branch protection rules",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		u := MustParseRepoURI("repository", args[0])
+		resp, err := client.ListBranchProtectionRulesWithResponse(cmd.Context(), u.Repository)
+		DieOnResponseError(resp, err)
+		rules := *resp.JSON200
+		rows := make([][]interface{}, len(rules))
+		for i, rule := range rules {
+			rows[i] = []interface{}{rule.Pattern, api.BoolValue(rule.RequirePassingRun)}
+		}
+		PrintTable(rows, []interface{}{"Branch Pattern", "Require Passing Run"}, &api.Pagination{}, len(rules))
+	},
+}
+
+var branchProtectAddCmd = &cobra.Command{
+	Use:     "add <repository uri> <branch pattern>",
+	Short:   "protect branches matching a pattern",
+	Example: "lakectl branch protect add lakefs://example-repo 'release/*' --require-passing-run",
+	Args:    cobra.ExactArgs(branchProtectCmdArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		u := MustParseRepoURI("repository", args[0])
+		requirePassingRun := MustBool(cmd.Flags().GetBool("require-passing-run"))
+		resp, err := client.CreateBranchProtectionRuleWithResponse(cmd.Context(), u.Repository, api.CreateBranchProtectionRuleJSONRequestBody{
+			Pattern:           args[1],
+			RequirePassingRun: &requirePassingRun,
+		})
+		DieOnResponseError(resp, err)
+		Fmt("Branch pattern '%s' protected\n", args[1])
+	},
+}
+
+var branchProtectDeleteCmd = &cobra.Command{
+	Use:   "delete <repository uri> <branch pattern>",
+	Short: "delete a branch protection rule",
+	Args:  cobra.ExactArgs(branchProtectCmdArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		u := MustParseRepoURI("repository", args[0])
+		resp, err := client.DeleteBranchProtectionRuleWithResponse(cmd.Context(), u.Repository, &api.DeleteBranchProtectionRuleParams{
+			Pattern: args[1],
+		})
+		DieOnResponseError(resp, err)
+		Fmt("Branch pattern '%s' no longer protected\n", args[1])
 	},
 }
 
@@ -196,9 +342,18 @@ func init() {
 	branchCmd.AddCommand(branchShowCmd)
 	branchCmd.AddCommand(branchResetCmd)
 	branchCmd.AddCommand(branchRevertCmd)
+	branchCmd.AddCommand(branchCherryPickCmd)
+	branchCmd.AddCommand(branchRebaseCmd)
+	branchCmd.AddCommand(branchProtectCmd)
+	branchProtectCmd.AddCommand(branchProtectListCmd)
+	branchProtectCmd.AddCommand(branchProtectAddCmd)
+	branchProtectCmd.AddCommand(branchProtectDeleteCmd)
 
 	branchListCmd.Flags().Int("amount", defaultAmountArgumentValue, "number of results to return")
 	branchListCmd.Flags().String("after", "", "show results after this value (used for pagination)")
+	branchListCmd.Flags().String("compare-to", "", "show how many commits each branch is ahead of and behind this reference")
+
+	branchShowCmd.Flags().String("compare-to", "", "show how many commits the branch is ahead of and behind this reference")
 
 	branchCreateCmd.Flags().StringP("source", "s", "", "source branch uri")
 	_ = branchCreateCmd.MarkFlagRequired("source")
@@ -208,6 +363,11 @@ func init() {
 
 	branchRevertCmd.Flags().IntP(ParentNumberFlagName, "m", 0, "the parent number (starting from 1) of the mainline. The revert will reverse the change relative to the specified parent.")
 
+	branchCherryPickCmd.Flags().IntP(ParentNumberFlagName, "m", 0, "the parent number (starting from 1) of the mainline. The changes are taken relative to the specified parent.")
+	branchCherryPickCmd.Flags().String("message", "", "commit message, defaults to a message referring to the cherry-picked commit")
+
+	branchProtectAddCmd.Flags().Bool("require-passing-run", false, "allow merges only from branches whose latest action run passed")
+
 	AssignAutoConfirmFlag(branchResetCmd.Flags())
 	AssignAutoConfirmFlag(branchRevertCmd.Flags())
 	AssignAutoConfirmFlag(branchDeleteCmd.Flags())
@@ -34,6 +34,7 @@ var commitCmd = &cobra.Command{
 		if err != nil {
 			DieErr(err)
 		}
+		expectHead := MustString(cmd.Flags().GetString("expect-head"))
 		branchURI := MustParseRefURI("branch", args[0])
 		Fmt("Branch: %s\n", branchURI.String())
 
@@ -41,8 +42,12 @@ var commitCmd = &cobra.Command{
 		metadata := api.CommitCreation_Metadata{
 			AdditionalProperties: kvPairs,
 		}
+		params := &api.CommitParams{}
+		if expectHead != "" {
+			params.IfMatch = &expectHead
+		}
 		client := getClient()
-		resp, err := client.CommitWithResponse(cmd.Context(), branchURI.Repository, branchURI.Ref, api.CommitJSONRequestBody{
+		resp, err := client.CommitWithResponse(cmd.Context(), branchURI.Repository, branchURI.Ref, params, api.CommitJSONRequestBody{
 			Message:  message,
 			Metadata: &metadata,
 		})
@@ -81,4 +86,5 @@ func init() {
 	_ = commitCmd.MarkFlagRequired("message")
 
 	commitCmd.Flags().StringSlice("meta", []string{}, "key value pair in the form of key=value")
+	commitCmd.Flags().String("expect-head", "", "commit only if the branch head is this commit ID, fails if the branch moved")
 }
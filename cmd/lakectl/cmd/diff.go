@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/jedib0t/go-pretty/text"
@@ -17,12 +18,23 @@ const (
 	maxDiffPageSize = 100000
 )
 
+const diffStatTemplate = `{{ "Added:" | ljust 9 }}{{ .Added | green }} ({{ .AddedBytes }} bytes)
+{{ "Removed:" | ljust 9 }}{{ .Removed | red }} ({{ .RemovedBytes }} bytes)
+{{ "Changed:" | ljust 9 }}{{ .Changed | yellow }} ({{ .ChangedBytes }} bytes)
+`
+
 var diffCmd = &cobra.Command{
 	Use:   "diff <ref uri> [other ref uri]",
 	Short: "diff between commits/hashes",
 	Long:  "see the list of paths added/changed/removed in a branch or between two references (could be either commit hash or branch name)",
 	Args:  cobra.RangeArgs(diffCmdMinArgs, diffCmdMaxArgs),
 	Run: func(cmd *cobra.Command, args []string) {
+		prefix := MustString(cmd.Flags().GetString("prefix"))
+		delimiter := MustString(cmd.Flags().GetString("delimiter"))
+		stat := MustBool(cmd.Flags().GetBool("stat"))
+		if stat && len(args) != diffCmdMaxArgs {
+			DieFmt("--stat requires two references")
+		}
 		client := getClient()
 		if len(args) == diffCmdMaxArgs {
 			leftRefURI := MustParseRefURI("left ref", args[0])
@@ -32,11 +44,19 @@ var diffCmd = &cobra.Command{
 			if leftRefURI.Repository != rightRefURI.Repository {
 				Die("both references must belong to the same repository", 1)
 			}
-			printDiffRefs(cmd.Context(), client, leftRefURI.Repository, leftRefURI.Ref, rightRefURI.Ref)
+			if stat {
+				resp, err := client.DiffRefsSummaryWithResponse(cmd.Context(), leftRefURI.Repository, leftRefURI.Ref, rightRefURI.Ref, &api.DiffRefsSummaryParams{
+					Prefix: api.PaginationPrefixPtr(prefix),
+				})
+				DieOnResponseError(resp, err)
+				Write(diffStatTemplate, resp.JSON200)
+				return
+			}
+			printDiffRefs(cmd.Context(), client, leftRefURI.Repository, leftRefURI.Ref, rightRefURI.Ref, prefix, delimiter)
 		} else {
 			branchURI := MustParseRefURI("ref", args[0])
 			Fmt("Ref: %s\n", branchURI.String())
-			printDiffBranch(cmd.Context(), client, branchURI.Repository, branchURI.Ref)
+			printDiffBranch(cmd.Context(), client, branchURI.Repository, branchURI.Ref, prefix, delimiter)
 		}
 	},
 }
@@ -53,13 +73,15 @@ func (p *pageSize) Next() int {
 	return p.Value()
 }
 
-func printDiffBranch(ctx context.Context, client api.ClientWithResponsesInterface, repository string, branch string) {
+func printDiffBranch(ctx context.Context, client api.ClientWithResponsesInterface, repository string, branch string, prefix string, delimiter string) {
 	var after string
 	pageSize := pageSize(minDiffPageSize)
 	for {
 		resp, err := client.DiffBranchWithResponse(ctx, repository, branch, &api.DiffBranchParams{
-			After:  api.PaginationAfterPtr(after),
-			Amount: api.PaginationAmountPtr(int(pageSize)),
+			After:     api.PaginationAfterPtr(after),
+			Amount:    api.PaginationAmountPtr(int(pageSize)),
+			Prefix:    api.PaginationPrefixPtr(prefix),
+			Delimiter: api.StringPtr(delimiter),
 		})
 		DieOnResponseError(resp, err)
 
@@ -75,14 +97,16 @@ func printDiffBranch(ctx context.Context, client api.ClientWithResponsesInterfac
 	}
 }
 
-func printDiffRefs(ctx context.Context, client api.ClientWithResponsesInterface, repository string, leftRef string, rightRef string) {
+func printDiffRefs(ctx context.Context, client api.ClientWithResponsesInterface, repository string, leftRef string, rightRef string, prefix string, delimiter string) {
 	var after string
 	pageSize := pageSize(minDiffPageSize)
 	for {
 		amount := int(pageSize)
 		resp, err := client.DiffRefsWithResponse(ctx, repository, leftRef, rightRef, &api.DiffRefsParams{
-			After:  api.PaginationAfterPtr(after),
-			Amount: api.PaginationAmountPtr(amount),
+			After:     api.PaginationAfterPtr(after),
+			Amount:    api.PaginationAmountPtr(amount),
+			Prefix:    api.PaginationPrefixPtr(prefix),
+			Delimiter: api.StringPtr(delimiter),
 		})
 		DieOnResponseError(resp, err)
 
@@ -118,19 +142,27 @@ func FmtDiff(diff api.Diff, withDirection bool) {
 	default:
 	}
 
+	path := diff.Path
+	if diff.Count != nil {
+		path = fmt.Sprintf("%s (+%d -%d ~%d *%d)", path, diff.Count.Added, diff.Count.Removed, diff.Count.Changed, diff.Count.Conflict)
+	}
+
 	if !withDirection {
 		_, _ = os.Stdout.WriteString(
-			color.Sprintf("%s %s\n", action, diff.Path),
+			color.Sprintf("%s %s\n", action, path),
 		)
 		return
 	}
 
 	_, _ = os.Stdout.WriteString(
-		color.Sprintf("%s %s\n", action, diff.Path),
+		color.Sprintf("%s %s\n", action, path),
 	)
 }
 
 //nolint:gochecknoinits
 func init() {
+	diffCmd.Flags().String("prefix", "", "show only changes to paths starting with this prefix")
+	diffCmd.Flags().String("delimiter", "", "group changes to paths sharing the part up to the next delimiter after prefix, showing their counts")
+	diffCmd.Flags().Bool("stat", false, "show only the number and total size of changes between two references")
 	rootCmd.AddCommand(diffCmd)
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api"
+)
+
+const (
+	exportCmdArgs        = 2
+	exportStatusInterval = 2 * time.Second
+)
+
+var exportStartedTemplate = `Export {{.ExportId|yellow}} of commit {{.CommitId|green}} to "{{.Destination}}" started, deadline {{.Deadline}}.
+`
+
+var exportTemplate = `Export {{.ExportId|yellow}} of commit {{.CommitId|green}} to "{{.Destination}}": {{.Status}}
+{{if .Error}}Error: {{.Error}}
+{{end}}{{if .PreviousCommitId}}Changes since commit: {{.PreviousCommitId}}
+{{end}}
+Copied: {{.Copied}}
+Removed: {{.Removed}}
+
+`
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <ref uri> <destination>",
+	Short: "copy the objects of a ref to an object store prefix using their logical paths",
+	Long: `copy every object of the ref commit to a destination prefix (e.g. "s3://bucket/path/to/export") by its path in the repository,
+then write a manifest and a _SUCCESS marker. Objects of a previous export to the destination that the commit does not hold are removed.
+Use --incremental to copy only the changes from the commit of a previous export to the same destination.
+The export runs on the server, the command waits for it to end unless --no-wait is set.`,
+	Example: "lakectl export lakefs://example-repo/main s3://example-bucket/exports/main --incremental",
+	Args:    cobra.ExactArgs(exportCmdArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		u := MustParseRefURI("ref", args[0])
+		destination := args[1]
+		body := api.ExportRefJSONRequestBody{
+			Destination: destination,
+		}
+		if MustBool(cmd.Flags().GetBool("incremental")) {
+			body.Incremental = api.BoolPtr(true)
+		}
+		if parallelism := MustInt(cmd.Flags().GetInt("parallelism")); parallelism > 0 {
+			body.Parallelism = &parallelism
+		}
+		resp, err := client.ExportRefWithResponse(cmd.Context(), u.Repository, u.Ref, body)
+		DieOnResponseError(resp, err)
+		status := resp.JSON202
+		Write(exportStartedTemplate, status)
+		if MustBool(cmd.Flags().GetBool("no-wait")) {
+			return
+		}
+
+		for status.Status == "running" {
+			time.Sleep(exportStatusInterval)
+			statusResp, err := client.GetExportWithResponse(cmd.Context(), u.Repository, status.ExportId)
+			DieOnResponseError(statusResp, err)
+			status = statusResp.JSON200
+		}
+		Write(exportTemplate, status)
+		if status.Status != "completed" {
+			DieFmt("export %s %s", status.ExportId, status.Status)
+		}
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().Bool("incremental", false, "copy only the changes from the commit of the previous export to destination, removing deleted objects")
+	exportCmd.Flags().Int("parallelism", 0, "number of objects copied concurrently (default set by the server)")
+	exportCmd.Flags().Bool("no-wait", false, "return once the export started, without waiting for it to end")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -105,6 +106,36 @@ var fsListCmd = &cobra.Command{
 	},
 }
 
+const fsBlameTemplate = `{{ range $val := . -}}
+{{ $val.CommitId|yellow }}    {{ $val.CreationDate|date|ljust 29 }}    {{ $val.Committer|ljust 20 }}    {{ $val.Path }}
+{{ end -}}
+`
+
+var fsBlameCmd = &cobra.Command{
+	Use:   "blame <path uri>",
+	Short: "show the commit that last changed each object under a given prefix",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		pathURI := MustParsePathURI("path", args[0])
+		var from string
+		for {
+			resp, err := client.BlameObjectsWithResponse(cmd.Context(), pathURI.Repository, pathURI.Ref, &api.BlameObjectsParams{
+				Prefix: pathURI.Path,
+				After:  api.PaginationAfterPtr(from),
+			})
+			DieOnResponseError(resp, err)
+
+			Write(fsBlameTemplate, resp.JSON200.Results)
+			pagination := resp.JSON200.Pagination
+			if !pagination.HasMore {
+				break
+			}
+			from = pagination.NextOffset
+		}
+	},
+}
+
 var fsCatCmd = &cobra.Command{
 	Use:   "cat <path uri>",
 	Short: "dump content of object to stdout",
@@ -270,6 +301,35 @@ var fsStageCmd = &cobra.Command{
 	},
 }
 
+var fsTransactionCmd = &cobra.Command{
+	Use:   "transaction <branch uri>",
+	Short: "atomically stage a batch of put, link and delete operations on a branch",
+	Long: `Read a JSON document of operations and stage all of them on the branch, or none of them
+if any fails. For example:
+  {"operations": [
+    {"op": "put", "path": "a/1", "staging": {"physical_address": "s3://bucket/a/1", "checksum": "...", "size_bytes": 10}},
+    {"op": "link", "path": "a/2", "source_ref": "main", "source_path": "b/2"},
+    {"op": "delete", "path": "a/3"}
+  ]}`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		branchURI := MustParseRefURI("branch", args[0])
+		operations := MustString(cmd.Flags().GetString("operations"))
+		fp := OpenByPath(operations)
+		defer func() {
+			_ = fp.Close()
+		}()
+		var transaction api.StagingTransaction
+		if err := json.NewDecoder(fp).Decode(&transaction); err != nil {
+			DieFmt("could not parse operations JSON document: %v", err)
+		}
+		client := getClient()
+		resp, err := client.ApplyStagingTransactionWithResponse(cmd.Context(), branchURI.Repository, branchURI.Ref, api.ApplyStagingTransactionJSONRequestBody(transaction))
+		DieOnResponseError(resp, err)
+		Fmt("Staged %d operations on branch: %s\n", len(transaction.Operations), branchURI.String())
+	},
+}
+
 var fsRmCmd = &cobra.Command{
 	Use:   "rm <path uri>",
 	Short: "delete object",
@@ -296,10 +356,12 @@ func init() {
 	rootCmd.AddCommand(fsCmd)
 	fsCmd.AddCommand(fsStatCmd)
 	fsCmd.AddCommand(fsListCmd)
+	fsCmd.AddCommand(fsBlameCmd)
 	fsCmd.AddCommand(fsCatCmd)
 	fsCmd.AddCommand(fsUploadCmd)
 	fsCmd.AddCommand(fsStageCmd)
 	fsCmd.AddCommand(fsRmCmd)
+	fsCmd.AddCommand(fsTransactionCmd)
 
 	fsCatCmd.Flags().BoolP("direct", "d", false, "read directly from backing store (faster but requires more credentials)")
 
@@ -317,5 +379,8 @@ func init() {
 	_ = fsStageCmd.MarkFlagRequired("size")
 	_ = fsStageCmd.MarkFlagRequired("checksum")
 
+	fsTransactionCmd.Flags().String("operations", "", "JSON document of operations to stage, or \"-\" for stdin")
+	_ = fsTransactionCmd.MarkFlagRequired("operations")
+
 	fsListCmd.Flags().Bool("recursive", false, "list all objects under the specified prefix")
 }
@@ -126,7 +126,7 @@ var ingestCmd = &cobra.Command{
 
 //nolint:gochecknoinits
 func init() {
-	ingestCmd.Flags().String("from", "", "prefix to read from (e.g. \"s3://bucket/sub/path/\")")
+	ingestCmd.Flags().String("from", "", "prefix to read from (e.g. \"s3://bucket/sub/path/\", \"gs://bucket/sub/path/\", \"local://bucket/sub/path/\")")
 	_ = ingestCmd.MarkFlagRequired("from")
 	ingestCmd.Flags().String("to", "", "lakeFS path to load objects into (e.g. \"lakefs://repo/branch/sub/path/\")")
 	_ = ingestCmd.MarkFlagRequired("to")
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/api"
 )
@@ -22,6 +24,24 @@ Merge:         {{ $val.Parents|join ", "|bold }}
 {{ end }}{{ if .Pagination  }}
 {{.Pagination | paginate }}{{ end }}`
 
+// logTimeLayouts are the layouts accepted by the --since and --until flags
+var logTimeLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// mustParseLogTime parses the time given to flag name, returns nil if the flag was not set
+func mustParseLogTime(cmd *cobra.Command, name string) *time.Time {
+	value := MustString(cmd.Flags().GetString(name))
+	if value == "" {
+		return nil
+	}
+	for _, layout := range logTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	DieFmt("invalid %s time '%s', expected RFC3339 or YYYY-MM-DD", name, value)
+	return nil
+}
+
 // logCmd represents the log command
 var logCmd = &cobra.Command{
 	Use:   "log <branch uri>",
@@ -32,6 +52,16 @@ var logCmd = &cobra.Command{
 		after := MustString(cmd.Flags().GetString("after"))
 		pagination := api.Pagination{HasMore: true}
 		showMetaRangeID, _ := cmd.Flags().GetBool("show-meta-range-id")
+		prefix := MustString(cmd.Flags().GetString("prefix"))
+		firstParent := MustBool(cmd.Flags().GetBool("first-parent"))
+		committer := MustString(cmd.Flags().GetString("committer"))
+		since := mustParseLogTime(cmd, "since")
+		until := mustParseLogTime(cmd, "until")
+		metadata := MustStringSlice(cmd.Flags().GetStringSlice("metadata"))
+		var metadataParam *[]string
+		if len(metadata) > 0 {
+			metadataParam = &metadata
+		}
 		client := getClient()
 		branchURI := MustParseRefURI("branch", args[0])
 		amountForPagination := amount
@@ -40,8 +70,14 @@ var logCmd = &cobra.Command{
 		}
 		for pagination.HasMore {
 			res, err := client.LogCommitsWithResponse(cmd.Context(), branchURI.Repository, branchURI.Ref, &api.LogCommitsParams{
-				After:  api.PaginationAfterPtr(after),
-				Amount: api.PaginationAmountPtr(amountForPagination),
+				After:       api.PaginationAfterPtr(after),
+				Amount:      api.PaginationAmountPtr(amountForPagination),
+				Prefix:      api.StringPtr(prefix),
+				FirstParent: api.BoolPtr(firstParent),
+				Committer:   api.StringPtr(committer),
+				Since:       since,
+				Until:       until,
+				Metadata:    metadataParam,
 			})
 			DieOnResponseError(res, err)
 			pagination = res.JSON200.Pagination
@@ -74,4 +110,10 @@ func init() {
 	logCmd.Flags().Int("amount", 0, "number of results to return. By default, all results are returned.")
 	logCmd.Flags().String("after", "", "show results after this value (used for pagination)")
 	logCmd.Flags().Bool("show-meta-range-id", false, "also show meta range ID")
+	logCmd.Flags().String("prefix", "", "show only commits that change a path starting with this prefix")
+	logCmd.Flags().Bool("first-parent", false, "follow only the first parent of merge commits")
+	logCmd.Flags().String("committer", "", "show only commits by this committer")
+	logCmd.Flags().String("since", "", "show only commits created at or after this time (RFC3339 or YYYY-MM-DD)")
+	logCmd.Flags().String("until", "", "show only commits created at or before this time (RFC3339 or YYYY-MM-DD)")
+	logCmd.Flags().StringSlice("metadata", []string{}, "show only commits with this metadata, in the form of key=value")
 }
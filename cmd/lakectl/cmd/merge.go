@@ -1,8 +1,10 @@
 package cmd
 
 import (
-	"fmt"
+	"context"
+	"os"
 
+	"github.com/jedib0t/go-pretty/text"
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/api"
 )
@@ -39,9 +41,27 @@ var mergeCmd = &cobra.Command{
 			Die("both references must belong to the same repository", 1)
 		}
 
-		resp, err := client.MergeIntoBranchWithResponse(cmd.Context(), destinationRef.Repository, sourceRef.Ref, destinationRef.Ref, api.MergeIntoBranchJSONRequestBody{})
+		strategy := MustString(cmd.Flags().GetString("strategy"))
+		if strategy != "" && strategy != "dest-wins" && strategy != "source-wins" {
+			DieFmt("Invalid strategy value %s. Expected \"dest-wins\" or \"source-wins\"", strategy)
+		}
+		squash := MustBool(cmd.Flags().GetBool("squash"))
+		body := api.MergeIntoBranchJSONRequestBody{}
+		if strategy != "" {
+			body.Strategy = &strategy
+		}
+		if squash {
+			body.Squash = api.BoolPtr(squash)
+		}
+		if MustBool(cmd.Flags().GetBool("ff-only")) {
+			if squash {
+				DieFmt("--ff-only cannot be used with --squash")
+			}
+			body.Mode = api.StringPtr("ff-only")
+		}
+		resp, err := client.MergeIntoBranchWithResponse(cmd.Context(), destinationRef.Repository, sourceRef.Ref, destinationRef.Ref, &api.MergeIntoBranchParams{}, body)
 		if resp != nil && resp.JSON409 != nil {
-			_, _ = fmt.Printf("Conflicts: %d\n", resp.JSON409.Summary.Conflict)
+			printMergeConflicts(cmd.Context(), client, sourceRef.Repository, sourceRef.Ref, destinationRef.Ref, resp.JSON409.Conflicts)
 			return
 		}
 		DieOnResponseError(resp, err)
@@ -56,7 +76,45 @@ var mergeCmd = &cobra.Command{
 	},
 }
 
+// printMergeConflicts prints the conflicts reported by a failed merge, fetching the rest of the pages if needed
+func printMergeConflicts(ctx context.Context, client api.ClientWithResponsesInterface, repository, sourceRef, destinationBranch string, conflicts *api.MergeConflictList) {
+	if conflicts == nil {
+		Fmt("Conflicts found\n")
+		return
+	}
+	count := 0
+	pageSize := pageSize(minDiffPageSize)
+	for {
+		for _, conflict := range conflicts.Results {
+			FmtMergeConflict(conflict)
+			count++
+		}
+		pagination := conflicts.Pagination
+		if !pagination.HasMore {
+			break
+		}
+		resp, err := client.ListMergeConflictsWithResponse(ctx, repository, sourceRef, destinationBranch, &api.ListMergeConflictsParams{
+			After:  api.PaginationAfterPtr(pagination.NextOffset),
+			Amount: api.PaginationAmountPtr(int(pageSize)),
+		})
+		DieOnResponseError(resp, err)
+		conflicts = resp.JSON200
+		pageSize.Next()
+	}
+	Fmt("Conflicts: %d\n", count)
+}
+
+func FmtMergeConflict(conflict api.MergeConflict) {
+	_, _ = os.Stdout.WriteString(
+		text.FgHiYellow.Sprintf("* conflict %s (source: %s, destination: %s)\n", conflict.Path, conflict.SourceType, conflict.DestinationType),
+	)
+}
+
 //nolint:gochecknoinits
 func init() {
 	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().String("strategy", "", "in case of a merge conflict, this option will force the merge process to automatically favor changes from the dest branch (\"dest-wins\") or from the source branch(\"source-wins\"). In case no selection is made, the merge process will fail in case of a conflict")
+	mergeCmd.Flags().Bool("ff-only", false, "move the destination branch to the source commit without creating a merge commit, fail if the destination is not an ancestor of the source")
+	mergeCmd.Flags().Bool("squash", false, "record the merge as a single commit on the destination branch, with a message summarizing the squashed commits")
 }
@@ -22,7 +22,11 @@ var refsRestoreCmd = &cobra.Command{
 	Long: `restores refs (branches, commits, tags) from the underlying object store to a bare repository.
 
 This command is expected to run on a bare repository (i.e. one created with 'lakectl repo create-bare').
-Since a bare repo is expected, in case of transient failure, delete the repository and recreate it as bare and retry.`,
+Since a bare repo is expected, in case of transient failure, delete the repository and recreate it as bare and retry.
+
+With --incremental, merges the refs of an incremental dump (created with 'lakectl refs-dump --since') into a
+repository holding the refs of the previous dumps.  Branches and tags missing from the dump are deleted, except
+for the default branch.  Restoring an incremental dump again is safe.`,
 	Example: "aws s3 cp s3://bucket/_lakefs/refs_manifest.json - | lakectl refs-load lakefs://my-bare-repository --manifest -",
 	Hidden:  true,
 	Args:    cobra.ExactArgs(1),
@@ -46,23 +50,34 @@ Since a bare repo is expected, in case of transient failure, delete the reposito
 			DieErr(err)
 		}
 		// execute the restore operation
+		incremental := MustBool(cmd.Flags().GetBool("incremental"))
 		client := getClient()
-		resp, err := client.RestoreRefsWithResponse(cmd.Context(), repoURI.Repository, api.RestoreRefsJSONRequestBody(manifest))
+		resp, err := client.RestoreRefsWithResponse(cmd.Context(), repoURI.Repository, &api.RestoreRefsParams{
+			Incremental: &incremental,
+		}, api.RestoreRefsJSONRequestBody(manifest))
 		DieOnResponseError(resp, err)
 		Write(refsRestoreSuccess, nil)
 	},
 }
 
 var refsDumpCmd = &cobra.Command{
-	Use:    "refs-dump <repository uri>",
-	Short:  "dumps refs (branches, commits, tags) to the underlying object store",
+	Use:   "refs-dump <repository uri>",
+	Short: "dumps refs (branches, commits, tags) to the underlying object store",
+	Long: `dumps refs (branches, commits, tags) to the underlying object store.
+
+With --since, dumps only the commits added since the watermark of a previous dump, along with all branches and tags.`,
 	Hidden: true,
 	Args:   cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		repoURI := MustParseRepoURI("repository", args[0])
 		Fmt("Repository: %s\n", repoURI.String())
+		params := &api.DumpRefsParams{}
+		if cmd.Flags().Changed("since") {
+			since := MustInt64(cmd.Flags().GetInt64("since"))
+			params.Since = &since
+		}
 		client := getClient()
-		resp, err := client.DumpRefsWithResponse(cmd.Context(), repoURI.Repository)
+		resp, err := client.DumpRefsWithResponse(cmd.Context(), repoURI.Repository, params)
 		DieOnResponseError(resp, err)
 
 		Write(metadataDumpTemplate, struct {
@@ -78,4 +93,7 @@ func init() {
 
 	refsRestoreCmd.Flags().String("manifest", "", "path to a refs manifest json file (as generated by `refs-dump`). Alternatively, use \"-\" to read from stdin")
 	_ = refsRestoreCmd.MarkFlagRequired("manifest")
+	refsRestoreCmd.Flags().Bool("incremental", false, "merge an incremental dump into a repository restored from previous dumps")
+
+	refsDumpCmd.Flags().Int64("since", 0, "dump only commits added since this watermark of a previous dump")
 }
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -8,8 +10,10 @@ import (
 )
 
 const (
-	DefaultBranch     = "main"
-	repoCreateCmdArgs = 2
+	DefaultBranch      = "main"
+	repoCreateCmdArgs  = 2
+	repoForkCmdArgs    = 3
+	retentionRuleParts = 2
 )
 
 // repoCmd represents the repo command
@@ -108,6 +112,71 @@ var repoCreateBareCmd = &cobra.Command{
 	},
 }
 
+// repoForkCmd represents the fork repo command
+// lakectl fork lakefs://newrepo lakefs://myrepo/main s3://my-bucket/
+var repoForkCmd = &cobra.Command{
+	Use:   "fork <repository uri> <source ref uri> <storage namespace>",
+	Short: "create a new repository starting at a reference of another repository, sharing its objects without copying them",
+	Args:  cobra.ExactArgs(repoForkCmdArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		clt := getClient()
+		u := MustParseRepoURI("repository", args[0])
+		sourceRef := MustParseRefURI("source ref", args[1])
+		Fmt("Repository: %s\n", u.String())
+		defaultBranch, err := cmd.Flags().GetString("default-branch")
+		if err != nil {
+			DieErr(err)
+		}
+		respForkRepo, err := clt.ForkRepositoryWithResponse(cmd.Context(), sourceRef.Repository,
+			api.ForkRepositoryJSONRequestBody{
+				Name:             u.Repository,
+				StorageNamespace: args[2],
+				DefaultBranch:    &defaultBranch,
+				Ref:              &sourceRef.Ref,
+			})
+		DieOnResponseError(respForkRepo, err)
+
+		repo := respForkRepo.JSON201
+		Fmt("Repository '%s' forked from %s:\nstorage namespace: %s\ndefault branch: %s\ntimestamp: %d\n",
+			repo.Id, sourceRef.String(), repo.StorageNamespace, repo.DefaultBranch, repo.CreationDate)
+	},
+}
+
+// repoUpdateCmd represents the update repo command
+// lakectl update lakefs://myrepo --default-branch main
+var repoUpdateCmd = &cobra.Command{
+	Use:   "update <repository uri>",
+	Short: "update the name, default branch, description or metadata of a repository",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clt := getClient()
+		u := MustParseRepoURI("repository", args[0])
+		body := api.UpdateRepositoryJSONRequestBody{}
+		if cmd.Flags().Changed("name") {
+			body.Name = api.StringPtr(MustString(cmd.Flags().GetString("name")))
+		}
+		if cmd.Flags().Changed("default-branch") {
+			body.DefaultBranch = api.StringPtr(MustString(cmd.Flags().GetString("default-branch")))
+		}
+		if cmd.Flags().Changed("description") {
+			body.Description = api.StringPtr(MustString(cmd.Flags().GetString("description")))
+		}
+		if cmd.Flags().Changed("meta") {
+			kvPairs, err := getKV(cmd, "meta")
+			if err != nil {
+				DieErr(err)
+			}
+			body.Metadata = &api.RepositoryUpdate_Metadata{AdditionalProperties: kvPairs}
+		}
+		resp, err := clt.UpdateRepositoryWithResponse(cmd.Context(), u.Repository, body)
+		DieOnResponseError(resp, err)
+
+		repo := resp.JSON200
+		Fmt("Repository '%s' updated:\nstorage namespace: %s\ndefault branch: %s\ndescription: %s\n",
+			repo.Id, repo.StorageNamespace, repo.DefaultBranch, api.StringValue(repo.Description))
+	},
+}
+
 // repoDeleteCmd represents the delete repo command
 // lakectl delete lakefs://myrepo
 var repoDeleteCmd = &cobra.Command{
@@ -128,13 +197,72 @@ var repoDeleteCmd = &cobra.Command{
 	},
 }
 
+var repoRetentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "manage repository retention rules",
+	Long: `Retention rules expire objects referenced only by commits older than the branch retention.
+Rules are matched against branch names in order, branches matching no rule are retained forever.`,
+}
+
+var repoRetentionGetCmd = &cobra.Command{
+	Use:   "get <repository uri>",
+	Short: "show repository retention rules",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clt := getClient()
+		u := MustParseRepoURI("repository", args[0])
+		resp, err := clt.GetRetentionRulesWithResponse(cmd.Context(), u.Repository)
+		DieOnResponseError(resp, err)
+		rules := resp.JSON200.Rules
+		rows := make([][]interface{}, len(rules))
+		for i, rule := range rules {
+			rows[i] = []interface{}{rule.BranchPattern, rule.RetentionDays}
+		}
+		PrintTable(rows, []interface{}{"Branch Pattern", "Retention Days"}, &api.Pagination{}, len(rules))
+	},
+}
+
+var repoRetentionSetCmd = &cobra.Command{
+	Use:     "set <repository uri> [--rule <branch pattern>=<days>]...",
+	Short:   "replace repository retention rules",
+	Example: "lakectl repo retention set lakefs://example-repo --rule 'scratch/*=30' --rule 'main=365'",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clt := getClient()
+		u := MustParseRepoURI("repository", args[0])
+		values := MustStringSlice(cmd.Flags().GetStringSlice("rule"))
+		rules := make([]api.RetentionRule, 0, len(values))
+		for _, v := range values {
+			parts := strings.SplitN(v, "=", retentionRuleParts)
+			if len(parts) != retentionRuleParts {
+				DieFmt("Invalid rule '%s': expected <branch pattern>=<days>", v)
+			}
+			days, err := strconv.Atoi(parts[1])
+			if err != nil {
+				DieFmt("Invalid rule '%s': %s", v, err)
+			}
+			rules = append(rules, api.RetentionRule{BranchPattern: parts[0], RetentionDays: days})
+		}
+		resp, err := clt.SetRetentionRulesWithResponse(cmd.Context(), u.Repository, api.SetRetentionRulesJSONRequestBody{
+			Rules: rules,
+		})
+		DieOnResponseError(resp, err)
+		Fmt("Repository '%s' retention rules set\n", u.Repository)
+	},
+}
+
 //nolint:gochecknoinits
 func init() {
 	rootCmd.AddCommand(repoCmd)
 	repoCmd.AddCommand(repoListCmd)
 	repoCmd.AddCommand(repoCreateCmd)
 	repoCmd.AddCommand(repoCreateBareCmd)
+	repoCmd.AddCommand(repoForkCmd)
+	repoCmd.AddCommand(repoUpdateCmd)
 	repoCmd.AddCommand(repoDeleteCmd)
+	repoCmd.AddCommand(repoRetentionCmd)
+	repoRetentionCmd.AddCommand(repoRetentionGetCmd)
+	repoRetentionCmd.AddCommand(repoRetentionSetCmd)
 
 	repoListCmd.Flags().Int("amount", defaultAmountArgumentValue, "number of results to return")
 	repoListCmd.Flags().String("after", "", "show results after this value (used for pagination)")
@@ -143,5 +271,14 @@ func init() {
 
 	repoCreateBareCmd.Flags().StringP("default-branch", "d", DefaultBranch, "the default branch name of this repository (will not be created)")
 
+	repoForkCmd.Flags().StringP("default-branch", "d", DefaultBranch, "the default branch of the new repository")
+
+	repoUpdateCmd.Flags().String("name", "", "rename the repository")
+	repoUpdateCmd.Flags().StringP("default-branch", "d", "", "an existing branch to make the default branch")
+	repoUpdateCmd.Flags().String("description", "", "the description of the repository")
+	repoUpdateCmd.Flags().StringSlice("meta", []string{}, "replace the repository metadata with key value pairs in the form of key=value")
+
 	AssignAutoConfirmFlag(repoDeleteCmd.Flags())
+
+	repoRetentionSetCmd.Flags().StringSlice("rule", nil, "retention rule as <branch pattern>=<days>, may be repeated, the first matching rule applies")
 }
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/local"
+)
+
+const DefaultLocalPath = "~/data/lakefs/block"
+
+// GetLocalAdapter returns a local block adapter on the path used by the lakeFS local blockstore.
+// The path is taken from the LAKEFS_BLOCKSTORE_LOCAL_PATH environment variable, as configured for lakeFS.
+func GetLocalAdapter() (*local.Adapter, error) {
+	localPath := os.Getenv("LAKEFS_BLOCKSTORE_LOCAL_PATH")
+	if localPath == "" {
+		localPath = DefaultLocalPath
+	}
+	p, err := homedir.Expand(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("parse local blockstore path %s: %w", localPath, err)
+	}
+	return local.NewAdapter(p)
+}
+
+// BlockAdapterWalker walks a storage URI using a lakeFS block adapter
+type BlockAdapterWalker struct {
+	adapter block.Adapter
+}
+
+func (w *BlockAdapterWalker) Walk(ctx context.Context, storageURI *url.URL, walkFn func(e ObjectStoreEntry) error) error {
+	return w.adapter.Walk(ctx, block.WalkOpts{StorageNamespace: storageURI.String()}, walkFn)
+}
@@ -7,6 +7,18 @@ import (
 
 const tagCreateRequiredArgs = 2
 
+const tagShowTemplate = `{{ .ID|yellow }} {{ .CommitID }}
+{{ if .Annotated -}}
+Tagger: {{ .Creator }}
+Date:   {{ .CreationDate|date }}
+
+	{{ .Message }}
+	{{ range $key, $value := .Metadata }}
+		{{ $key }} = {{ $value }}
+	{{ end }}
+{{ end -}}
+`
+
 // tagCmd represents the tag command
 var tagCmd = &cobra.Command{
 	Use:   "tag",
@@ -83,14 +95,24 @@ var tagCreateCmd = &cobra.Command{
 			}
 		}
 
-		resp, err := client.CreateTagWithResponse(ctx, tagURI.Repository, api.CreateTagJSONRequestBody{
+		body := api.CreateTagJSONRequestBody{
 			Id:  tagURI.Ref,
 			Ref: commitRef,
-		})
+		}
+		if cmd.Flags().Changed("message") {
+			body.Message = api.StringPtr(MustString(cmd.Flags().GetString("message")))
+		}
+		if cmd.Flags().Changed("meta") {
+			kvPairs, err := getKV(cmd, "meta")
+			if err != nil {
+				DieErr(err)
+			}
+			body.Metadata = &api.TagCreation_Metadata{AdditionalProperties: kvPairs}
+		}
+		resp, err := client.CreateTagWithResponse(ctx, tagURI.Repository, body)
 		DieOnResponseError(resp, err)
 
-		commitID := *resp.JSON201
-		Fmt("Created tag '%s' (%s)\n", tagURI.Ref, commitID)
+		Fmt("Created tag '%s' (%s)\n", tagURI.Ref, resp.JSON201.CommitId)
 	},
 }
 
@@ -115,7 +137,7 @@ var tagDeleteCmd = &cobra.Command{
 
 var tagShowCmd = &cobra.Command{
 	Use:   "show <tag uri>",
-	Short: "show tag's commit reference",
+	Short: "show tag's commit reference and annotation",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		client := getClient()
@@ -125,13 +147,38 @@ var tagShowCmd = &cobra.Command{
 		ctx := cmd.Context()
 		resp, err := client.GetTagWithResponse(ctx, u.Repository, u.Ref)
 		DieOnResponseError(resp, err)
-		Fmt("%s %s", resp.JSON200.Id, resp.JSON200.CommitId)
+
+		tag := resp.JSON200
+		tmplArgs := struct {
+			ID           string
+			CommitID     string
+			Annotated    bool
+			Creator      string
+			CreationDate int64
+			Message      string
+			Metadata     map[string]string
+		}{
+			ID:        tag.Id,
+			CommitID:  tag.CommitId,
+			Annotated: api.BoolValue(tag.Annotated),
+			Creator:   api.StringValue(tag.Creator),
+			Message:   api.StringValue(tag.Message),
+		}
+		if tag.CreationDate != nil {
+			tmplArgs.CreationDate = *tag.CreationDate
+		}
+		if tag.Metadata != nil {
+			tmplArgs.Metadata = tag.Metadata.AdditionalProperties
+		}
+		Write(tagShowTemplate, tmplArgs)
 	},
 }
 
 //nolint:gochecknoinits
 func init() {
 	tagCreateCmd.Flags().BoolP("force", "f", false, "override the tag if it exists")
+	tagCreateCmd.Flags().StringP("message", "m", "", "create an annotated tag with this message")
+	tagCreateCmd.Flags().StringSlice("meta", []string{}, "create an annotated tag with key value pair in the form of key=value")
 
 	rootCmd.AddCommand(tagCmd)
 	tagCmd.AddCommand(tagCreateCmd, tagDeleteCmd, tagListCmd, tagShowCmd)
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/db"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+const (
+	DeleteFlagName               = "delete"
+	ReportFlagName               = "report"
+	DefaultRetentionDaysFlagName = "default-retention-days"
+	BranchRetentionFlagName      = "branch-retention"
+	GCCmdNumArgs                 = 1
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc <repository uri>",
+	Short: "Remove objects no longer referenced by retained commits",
+	Long: `Walk all commits and staging areas of a repository and remove the objects referenced only by commits
+older than their branch retention. Branch heads, tagged commits and staged objects are always retained.
+Objects imported by full address are never removed.
+
+Retention follows the rules stored for the repository. Rules passed by --branch-retention are matched
+before the stored rules, and --default-retention-days applies to branches no rule matches and to commits
+no branch reaches. Without it those commits are retained.
+
+Only reports the expired objects unless --delete is passed. gc does not lock the repository: stop writes
+to it while deleting, as a branch created or an object staged from an expired commit during the run
+loses its objects.`,
+	Example: "lakefs gc lakefs://example-repo --default-retention-days 30 --branch-retention 'feature/*=7' --report gc.csv --delete",
+	Args:    cobra.ExactArgs(GCCmdNumArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		rc := runGC(cmd, args)
+		os.Exit(rc)
+	},
+}
+
+func runGC(cmd *cobra.Command, args []string) (statusCode int) {
+	flags := cmd.Flags()
+	del, _ := flags.GetBool(DeleteFlagName)
+	dryRun := !del
+	reportPath, _ := flags.GetString(ReportFlagName)
+	branchRetention, _ := flags.GetStringSlice(BranchRetentionFlagName)
+
+	u := uri.Must(uri.Parse(args[0]))
+	if !u.IsRepository() {
+		fmt.Printf("Invalid 'repository': %s\n", uri.ErrInvalidRefURI)
+		return 1
+	}
+	rules, err := parseRetentionRules(branchRetention)
+	if err != nil {
+		fmt.Printf("Invalid branch retention: %s\n", err)
+		return 1
+	}
+
+	ctx := cmd.Context()
+	err = db.ValidateSchemaUpToDate(ctx, cfg.GetDatabaseParams())
+	if errors.Is(err, db.ErrSchemaNotCompatible) {
+		fmt.Println("Migration version mismatch, for more information see https://docs.lakefs.io/deploying-aws/upgrade.html")
+		return 1
+	}
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	dbPool := db.BuildDatabaseConnection(ctx, cfg.GetDatabaseParams())
+	defer dbPool.Close()
+
+	c, err := catalog.New(ctx, catalog.Config{
+		Config: cfg,
+		DB:     dbPool,
+	})
+	if err != nil {
+		fmt.Printf("Failed to create catalog: %s\n", err)
+		return 1
+	}
+	defer func() { _ = c.Close() }()
+
+	repo, err := c.GetRepository(ctx, u.Repository)
+	if err != nil {
+		fmt.Printf("Error getting repository: %s\n", err)
+		return 1
+	}
+
+	storedRules, err := c.GetRetentionRules(ctx, repo.Name)
+	if err != nil {
+		fmt.Printf("Failed to get retention rules: %s\n", err)
+		return 1
+	}
+	params := catalog.ExpiryParams{
+		DefaultRetentionDays: catalog.RetainForever,
+		Rules:                append(rules, storedRules...),
+	}
+	if flags.Changed(DefaultRetentionDaysFlagName) {
+		params.DefaultRetentionDays, _ = flags.GetInt(DefaultRetentionDaysFlagName)
+	}
+
+	var report io.Writer = os.Stdout
+	if reportPath != "" {
+		f, err := os.Create(reportPath)
+		if err != nil {
+			fmt.Printf("Failed to create report: %s\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		report = f
+	}
+
+	if dryRun {
+		fmt.Fprint(os.Stderr, "Starting gc dry run. Will not remove any objects, pass --delete to remove them.\n\n")
+	}
+	rows, err := c.ListExpired(ctx, repo.Name, params)
+	if err != nil {
+		fmt.Printf("Failed to list expired objects: %s\n", err)
+		return 1
+	}
+	defer rows.Close()
+
+	logger := logging.FromContext(ctx)
+	w := csv.NewWriter(report)
+	_ = w.Write([]string{"repository", "branch", "physical_address", "internal_reference", "removed"})
+	var expired, removed int
+	for rows.Next() {
+		res, err := rows.Read()
+		if err != nil {
+			fmt.Printf("Failed to read expired object: %s\n", err)
+			return 1
+		}
+		expired++
+		isRemoved := false
+		if !dryRun {
+			if err := c.RemoveExpired(ctx, repo.StorageNamespace, res); err != nil {
+				logger.WithError(err).WithField("physical_address", res.PhysicalAddress).Error("Failed to remove expired object")
+			} else {
+				isRemoved = true
+				removed++
+			}
+		}
+		_ = w.Write([]string{res.Repository, res.Branch, res.PhysicalAddress, res.InternalReference, strconv.FormatBool(isRemoved)})
+	}
+	w.Flush()
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Failed to list expired objects: %s\n", err)
+		return 1
+	}
+	if err := w.Error(); err != nil {
+		fmt.Printf("Failed to write report: %s\n", err)
+		return 1
+	}
+
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run successful. Found %d expired objects, none were removed.\n", expired)
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "Removed %d of %d expired objects.\n", removed, expired)
+	if removed < expired {
+		return 1
+	}
+	return 0
+}
+
+// parseRetentionRules parses "pattern=days" values, keeping their order
+func parseRetentionRules(values []string) ([]catalog.RetentionRule, error) {
+	rules := make([]catalog.RetentionRule, 0, len(values))
+	for _, v := range values {
+		const retentionRuleParts = 2
+		parts := strings.SplitN(v, "=", retentionRuleParts)
+		if len(parts) != retentionRuleParts {
+			return nil, fmt.Errorf("%w: expected <branch pattern>=<days>, got %s", catalog.ErrInvalidValue, v)
+		}
+		days, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: retention days %s", catalog.ErrInvalidValue, parts[1])
+		}
+		rules = append(rules, catalog.RetentionRule{BranchPattern: parts[0], RetentionDays: days})
+	}
+	return rules, nil
+}
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().Bool(DeleteFlagName, false, "Remove the expired objects, only report them otherwise. Stop writes to the repository first")
+	gcCmd.Flags().String(ReportFlagName, "", "Write the CSV report of expired objects to this file instead of stdout")
+	gcCmd.Flags().Int(DefaultRetentionDaysFlagName, catalog.RetainForever, "Days to retain commits no retention rule matches, -1 to retain them forever")
+	gcCmd.Flags().StringSlice(BranchRetentionFlagName, nil, "Retention rule as <branch pattern>=<days>, may be repeated, overrides the stored rules, the first matching rule applies")
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/jedib0t/go-pretty/text"
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/block"
 	"github.com/treeverse/lakefs/pkg/block/factory"
 	"github.com/treeverse/lakefs/pkg/catalog"
 	"github.com/treeverse/lakefs/pkg/cmdutils"
@@ -28,6 +29,8 @@ const (
 	WithMergeFlagName    = "with-merge"
 	HideProgressFlagName = "hide-progress"
 	ManifestURLFlagName  = "manifest"
+	SourceURLFlagName    = "source"
+	ParallelismFlagName  = "parallelism"
 	PrefixesFileFlagName = "prefix-file"
 	BaseCommitFlagName   = "commit"
 	ManifestURLFormat    = "s3://example-bucket/inventory/YYYY-MM-DDT00-00Z/manifest.json"
@@ -36,9 +39,9 @@ const (
 )
 
 var importCmd = &cobra.Command{
-	Use:   "import <repository uri> --manifest <s3 uri to manifest.json>",
-	Short: "Import data from S3 to a lakeFS repository",
-	Long:  fmt.Sprintf("Import from an S3 inventory to lakeFS without copying the data. It will be added as a new commit in branch %s", onboard.DefaultImportBranchName),
+	Use:   "import <repository uri> {--manifest <s3 uri to manifest.json> | --source <storage uri>}",
+	Short: "Import data from the object store to a lakeFS repository",
+	Long:  fmt.Sprintf("Import from an S3 inventory, or by listing a storage uri of the configured blockstore, to lakeFS without copying the data. It will be added as a new commit in branch %s", onboard.DefaultImportBranchName),
 	Args:  cobra.ExactArgs(ImportCmdNumArgs),
 	Run: func(cmd *cobra.Command, args []string) {
 		rc := runImport(cmd, args)
@@ -47,8 +50,8 @@ var importCmd = &cobra.Command{
 }
 
 var importBaseCmd = &cobra.Command{
-	Use:    "import-base <repository uri> --manifest <s3 uri to manifest.json> --commit <base commit>",
-	Short:  "Import data from S3 to a lakeFS repository on top of existing commit",
+	Use:    "import-base <repository uri> {--manifest <s3 uri to manifest.json> | --source <storage uri>} --commit <base commit>",
+	Short:  "Import data from the object store to a lakeFS repository on top of existing commit",
 	Long:   "Creates a new commit with the imported data, on top of the given commit. Does not affect any branch",
 	Hidden: true,
 	Args:   cobra.ExactArgs(ImportCmdNumArgs),
@@ -62,11 +65,18 @@ func runImport(cmd *cobra.Command, args []string) (statusCode int) {
 	flags := cmd.Flags()
 	dryRun, _ := flags.GetBool(DryRunFlagName)
 	manifestURL, _ := flags.GetString(ManifestURLFlagName)
+	sourceURL, _ := flags.GetString(SourceURLFlagName)
+	parallelism, _ := flags.GetInt(ParallelismFlagName)
 	withMerge, _ := flags.GetBool(WithMergeFlagName)
 	hideProgress, _ := flags.GetBool(HideProgressFlagName)
 	prefixFile, _ := flags.GetString(PrefixesFileFlagName)
 	baseCommit, _ := flags.GetString(BaseCommitFlagName)
 
+	if (manifestURL == "") == (sourceURL == "") {
+		fmt.Printf("Exactly one of --%s or --%s is required\n", ManifestURLFlagName, SourceURLFlagName)
+		return 1
+	}
+
 	ctx := cmd.Context()
 	conf, err := config.NewConfig()
 	if err != nil {
@@ -104,6 +114,7 @@ func runImport(cmd *cobra.Command, args []string) (statusCode int) {
 		catalog.NewActionsOutputWriter(c.BlockAdapter),
 	)
 	c.SetHooksHandler(actionsService)
+	c.SetBranchRunChecker(actionsService)
 
 	u := uri.Must(uri.Parse(args[0]))
 	if !u.IsRepository() {
@@ -116,16 +127,28 @@ func runImport(cmd *cobra.Command, args []string) (statusCode int) {
 		fmt.Printf("Failed to create block adapter: %s\n", err)
 		return 1
 	}
-	if blockStore.BlockstoreType() != "s3" {
-		fmt.Printf("Configuration uses unsupported block adapter: %s. Only s3 is supported.\n", blockStore.BlockstoreType())
-		return 1
+	// import from an S3 inventory manifest, or by walking the source with any block adapter
+	var inventoryGenerator block.InventoryGenerator = blockStore
+	inventoryURL := manifestURL
+	if sourceURL != "" {
+		if _, err := block.ResolveNamespacePrefix(sourceURL, ""); err != nil {
+			fmt.Printf("Invalid source url %s: %s\n", sourceURL, err)
+			return 1
+		}
+		inventoryGenerator = block.NewWalkInventoryGenerator(blockStore, parallelism)
+		inventoryURL = sourceURL
+	} else {
+		if blockStore.BlockstoreType() != "s3" {
+			fmt.Printf("Configuration uses unsupported block adapter: %s. Only s3 supports import from manifest, use --%s instead.\n", blockStore.BlockstoreType(), SourceURLFlagName)
+			return 1
+		}
+		parsedURL, err := url.Parse(manifestURL)
+		if err != nil || parsedURL.Scheme != "s3" || !strings.HasSuffix(parsedURL.Path, "/manifest.json") {
+			fmt.Printf("Invalid manifest url. expected format: %s\n", ManifestURLFormat)
+			return 1
+		}
 	}
 	repoName := u.Repository
-	parsedURL, err := url.Parse(manifestURL)
-	if err != nil || parsedURL.Scheme != "s3" || !strings.HasSuffix(parsedURL.Path, "/manifest.json") {
-		fmt.Printf("Invalid manifest url. expected format: %s\n", ManifestURLFormat)
-		return 1
-	}
 
 	repo, err := getRepository(ctx, c, repoName)
 	if err != nil {
@@ -162,10 +185,10 @@ func runImport(cmd *cobra.Command, args []string) (statusCode int) {
 
 	importConfig := &onboard.Config{
 		CommitUsername:     CommitterName,
-		InventoryURL:       manifestURL,
+		InventoryURL:       inventoryURL,
 		RepositoryID:       graveler.RepositoryID(repoName),
 		DefaultBranchID:    graveler.BranchID(repo.DefaultBranch),
-		InventoryGenerator: blockStore,
+		InventoryGenerator: inventoryGenerator,
 		Store:              c.Store,
 		KeyPrefixes:        prefixes,
 		BaseCommit:         graveler.CommitID(baseCommit),
@@ -211,7 +234,10 @@ func runImport(cmd *cobra.Command, args []string) (statusCode int) {
 	if withMerge {
 		fmt.Printf("Merging import changes into lakefs://%s@%s/\n", repoName, repo.DefaultBranch)
 		msg := fmt.Sprintf(onboard.CommitMsgTemplate, stats.CommitRef)
-		commitLog, err := c.Merge(ctx, repoName, onboard.DefaultImportBranchName, repo.DefaultBranch, CommitterName, msg, nil)
+		commitLog, err := c.Merge(ctx, repoName, onboard.DefaultImportBranchName, repo.DefaultBranch, catalog.MergeParams{
+			Committer: CommitterName,
+			Message:   msg,
+		})
 		if err != nil {
 			fmt.Printf("Merge failed: %s\n", err)
 			return 1
@@ -240,21 +266,25 @@ func getRepository(ctx context.Context, c catalog.Interface, repoName string) (*
 func init() {
 	manifestFlagMsg := fmt.Sprintf("S3 uri to the manifest.json to use for the import. Format: %s", ManifestURLFormat)
 	const (
-		hideMsg     = "Suppress progress bar"
-		prefixesMsg = "File with a list of key prefixes. Imported object keys will be filtered according to these prefixes"
+		hideMsg        = "Suppress progress bar"
+		prefixesMsg    = "File with a list of key prefixes. Imported object keys will be filtered according to these prefixes"
+		sourceMsg      = "Storage uri of the configured blockstore to list and import (e.g. gs://bucket/path). Object keys are relative to it, and key prefixes are listed in parallel"
+		parallelismMsg = "Number of key prefixes listed concurrently when importing from --source"
 	)
 
 	rootCmd.AddCommand(importCmd)
 	importCmd.Flags().Bool(DryRunFlagName, false, "Only read inventory, print stats and write metarange. Commits nothing")
 	importCmd.Flags().StringP(ManifestURLFlagName, "m", "", manifestFlagMsg)
-	_ = importCmd.MarkFlagRequired(ManifestURLFlagName)
+	importCmd.Flags().StringP(SourceURLFlagName, "s", "", sourceMsg)
+	importCmd.Flags().Int(ParallelismFlagName, block.DefaultWalkParallelism, parallelismMsg)
 	importCmd.Flags().Bool(WithMergeFlagName, false, "Merge imported data to the repository's main branch")
 	importCmd.Flags().Bool(HideProgressFlagName, false, hideMsg)
 	importCmd.Flags().StringP(PrefixesFileFlagName, "p", "", prefixesMsg)
 
 	rootCmd.AddCommand(importBaseCmd)
 	importBaseCmd.Flags().StringP(ManifestURLFlagName, "m", "", manifestFlagMsg)
-	_ = importBaseCmd.MarkFlagRequired(ManifestURLFlagName)
+	importBaseCmd.Flags().StringP(SourceURLFlagName, "s", "", sourceMsg)
+	importBaseCmd.Flags().Int(ParallelismFlagName, block.DefaultWalkParallelism, parallelismMsg)
 	importBaseCmd.Flags().Bool(HideProgressFlagName, false, hideMsg)
 	importBaseCmd.Flags().StringP(PrefixesFileFlagName, "p", "", prefixesMsg)
 	importBaseCmd.Flags().StringP(BaseCommitFlagName, "b", "", "Commit to apply to apply the import on top of")
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/db"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+var enforceRetentionCmd = &cobra.Command{
+	Use:   "enforce-retention [repository uri]...",
+	Short: "Mark commits expired by repository retention rules",
+	Long: `Apply the retention rules of each repository (all repositories if none are given), marking the commits
+older than their branch retention as expired. Reading objects owned by lakeFS from an expired commit fails.
+Run 'lakefs gc' to remove the objects of the expired commits from the underlying storage.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rc := runEnforceRetention(cmd, args)
+		os.Exit(rc)
+	},
+}
+
+func runEnforceRetention(cmd *cobra.Command, args []string) (statusCode int) {
+	repositories := make([]string, 0, len(args))
+	for _, arg := range args {
+		u := uri.Must(uri.Parse(arg))
+		if !u.IsRepository() {
+			fmt.Printf("Invalid 'repository': %s\n", uri.ErrInvalidRefURI)
+			return 1
+		}
+		repositories = append(repositories, u.Repository)
+	}
+
+	ctx := cmd.Context()
+	err := db.ValidateSchemaUpToDate(ctx, cfg.GetDatabaseParams())
+	if errors.Is(err, db.ErrSchemaNotCompatible) {
+		fmt.Println("Migration version mismatch, for more information see https://docs.lakefs.io/deploying-aws/upgrade.html")
+		return 1
+	}
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	dbPool := db.BuildDatabaseConnection(ctx, cfg.GetDatabaseParams())
+	defer dbPool.Close()
+	c, err := catalog.New(ctx, catalog.Config{
+		Config: cfg,
+		DB:     dbPool,
+	})
+	if err != nil {
+		fmt.Printf("Failed to create catalog: %s\n", err)
+		return 1
+	}
+	defer func() { _ = c.Close() }()
+
+	if len(repositories) == 0 {
+		after := ""
+		for {
+			repos, hasMore, err := c.ListRepositories(ctx, -1, "", after)
+			if err != nil {
+				fmt.Printf("Failed to list repositories: %s\n", err)
+				return 1
+			}
+			for _, repo := range repos {
+				repositories = append(repositories, repo.Name)
+				after = repo.Name
+			}
+			if !hasMore {
+				break
+			}
+		}
+	}
+
+	for _, repository := range repositories {
+		expired, err := c.EnforceRetention(ctx, repository)
+		if err != nil {
+			fmt.Printf("Failed to enforce retention on %s: %s\n", repository, err)
+			statusCode = 1
+			continue
+		}
+		fmt.Printf("Repository %s: %d expired commits\n", repository, expired)
+	}
+	return statusCode
+}
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(enforceRetentionCmd)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/block/factory"
 	"github.com/treeverse/lakefs/pkg/catalog"
 	"github.com/treeverse/lakefs/pkg/db"
+	"github.com/treeverse/lakefs/pkg/export"
 	"github.com/treeverse/lakefs/pkg/gateway"
 	"github.com/treeverse/lakefs/pkg/gateway/multiparts"
 	"github.com/treeverse/lakefs/pkg/gateway/simulator"
@@ -87,7 +88,13 @@ var runCmd = &cobra.Command{
 			catalog.NewActionsSource(c),
 			catalog.NewActionsOutputWriter(c.BlockAdapter),
 		)
+		exportJobs := export.NewJobs(dbPool, export.NewExporter(c, c.BlockAdapter))
+		if err := exportJobs.FailRunning(ctx); err != nil {
+			logger.WithError(err).Warn("Failed to record stopped export jobs")
+		}
+		actionsService.SetExporter(exportJobs)
 		c.SetHooksHandler(actionsService)
+		c.SetBranchRunChecker(actionsService)
 
 		multipartsTracker := multiparts.NewTracker(dbPool)
 
@@ -102,6 +109,7 @@ var runCmd = &cobra.Command{
 			dbPool,
 			crypt.NewSecretStore(cfg.GetAuthEncryptionSecret()),
 			cfg.GetAuthCacheConfig())
+		actionsService.SetAuthorizer(authService)
 		authMetadataManager := auth.NewDBMetadataManager(version.Version, cfg.GetFixedInstallationID(), dbPool)
 		cloudMetadataProvider := stats.BuildMetadataProvider(logger, cfg)
 		metadata := stats.NewMetadata(ctx, logger, cfg.GetBlockstoreType(), authMetadataManager, cloudMetadataProvider)
@@ -127,6 +135,7 @@ var runCmd = &cobra.Command{
 			bufferedCollector,
 			cloudMetadataProvider,
 			actionsService,
+			exportJobs,
 			logger.WithField("service", "api_gateway"),
 			cfg.GetS3GatewayDomainNames(),
 		)
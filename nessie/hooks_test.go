@@ -85,7 +85,7 @@ func TestHooksSuccess(t *testing.T) {
 	require.Equal(t, http.StatusCreated, uploadResp.StatusCode())
 	logger.WithField("branch", branch).Info("Commit initial content")
 
-	commitResp, err := client.CommitWithResponse(ctx, repo, branch, api.CommitJSONRequestBody{
+	commitResp, err := client.CommitWithResponse(ctx, repo, branch, &api.CommitParams{}, api.CommitJSONRequestBody{
 		Message: "Initial content",
 	})
 	require.NoError(t, err, "failed to commit initial content")
@@ -110,7 +110,7 @@ func TestHooksSuccess(t *testing.T) {
 	require.Equal(t, branch, commitEvent.SourceRef)
 	require.Equal(t, commitRecord.Metadata.AdditionalProperties, commitEvent.Metadata)
 
-	mergeResp, err := client.MergeIntoBranchWithResponse(ctx, repo, branch, mainBranch, api.MergeIntoBranchJSONRequestBody{})
+	mergeResp, err := client.MergeIntoBranchWithResponse(ctx, repo, branch, mainBranch, &api.MergeIntoBranchParams{}, api.MergeIntoBranchJSONRequestBody{})
 
 	webhookData, err = responseWithTimeout(server, 1*time.Minute)
 	require.NoError(t, err)
@@ -27,7 +27,7 @@ func TestIdentity(t *testing.T) {
 
 			checksum, objContent, err := uploadFileRandomDataAndReport(ctx, repo, branch1, objPath, direct)
 			require.NoError(t, err, "failed uploading file")
-			commitResp, err := client.CommitWithResponse(ctx, repo, branch1, api.CommitJSONRequestBody{
+			commitResp, err := client.CommitWithResponse(ctx, repo, branch1, &api.CommitParams{}, api.CommitJSONRequestBody{
 				Message: "commit on branch1",
 			})
 			require.NoError(t, err, "failed to commit changes")
@@ -42,7 +42,7 @@ func TestIdentity(t *testing.T) {
 			require.NoError(t, err, "failed creating branch2")
 			checksumNew, err := uploadFileAndReport(ctx, repo, branch2, objPath, objContent, direct)
 			require.Equal(t, checksum, checksumNew, "Same file uploaded to committed branch, expected no checksum difference")
-			commitResp, err = client.CommitWithResponse(ctx, repo, branch2, api.CommitJSONRequestBody{
+			commitResp, err = client.CommitWithResponse(ctx, repo, branch2, &api.CommitParams{}, api.CommitJSONRequestBody{
 				Message: "commit on branch2",
 			})
 			require.NoError(t, err, "failed to commit changes")
@@ -51,7 +51,7 @@ func TestIdentity(t *testing.T) {
 			require.NoError(t, err, "Diff refs failed")
 			require.Empty(t, diff.JSON200.Results, "Expected no diff files")
 
-			resp, err := client.MergeIntoBranchWithResponse(ctx, repo, branch1, branch2, api.MergeIntoBranchJSONRequestBody{})
+			resp, err := client.MergeIntoBranchWithResponse(ctx, repo, branch1, branch2, &api.MergeIntoBranchParams{}, api.MergeIntoBranchJSONRequestBody{})
 			require.NoError(t, err, "error during merge")
 			require.NotNil(t, resp.JSON400, "merge should fail since there are no changes between the branches")
 		})
@@ -22,6 +22,7 @@ type Action struct {
 type OnEvents struct {
 	PreMerge  *ActionOn `yaml:"pre-merge"`
 	PreCommit *ActionOn `yaml:"pre-commit"`
+	PostMerge *ActionOn `yaml:"post-merge"`
 }
 
 type ActionOn struct {
@@ -55,7 +56,7 @@ func (a *Action) Validate() error {
 	if !reName.MatchString(a.Name) {
 		return fmt.Errorf("'name' is invalid: %w", ErrInvalidAction)
 	}
-	if a.On.PreMerge == nil && a.On.PreCommit == nil {
+	if a.On.PreMerge == nil && a.On.PreCommit == nil && a.On.PostMerge == nil {
 		return fmt.Errorf("'on' is required: %w", ErrInvalidAction)
 	}
 	ids := make(map[string]struct{})
@@ -82,6 +83,8 @@ func (a *Action) Match(spec MatchSpec) (bool, error) {
 		actionOn = a.On.PreCommit
 	case graveler.EventTypePreMerge:
 		actionOn = a.On.PreMerge
+	case graveler.EventTypePostMerge:
+		actionOn = a.On.PostMerge
 	default:
 		return false, ErrInvalidEventType
 	}
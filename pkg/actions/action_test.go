@@ -27,6 +27,7 @@ func TestAction_ReadAction(t *testing.T) {
 		{name: "invalid id", filename: "action_invalid_id.yaml", wantErr: true},
 		{name: "invalid hook type", filename: "action_invalid_type.yaml", wantErr: true},
 		{name: "invalid yaml", filename: "action_invalid_yaml.yaml", wantErr: true},
+		{name: "export", filename: "action_export.yaml", wantErr: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -109,6 +110,20 @@ func TestAction_Match(t *testing.T) {
 			want:    true,
 			wantErr: false,
 		},
+		{
+			name:    "post-merge - on post-merge without branch",
+			on:      actions.OnEvents{PostMerge: &actions.ActionOn{}},
+			spec:    actions.MatchSpec{EventType: graveler.EventTypePostMerge},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name:    "pre-merge - on post-merge without branch",
+			on:      actions.OnEvents{PreMerge: &actions.ActionOn{}},
+			spec:    actions.MatchSpec{EventType: graveler.EventTypePostMerge},
+			want:    false,
+			wantErr: false,
+		},
 		{
 			name:    "pre-commit main - on pre-commit main",
 			on:      actions.OnEvents{PreCommit: &actions.ActionOn{Branches: []string{"main"}}},
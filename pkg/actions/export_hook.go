@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/export"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/permissions"
+)
+
+// Exporter starts exporting the objects of a repository reference to a destination in the background
+type Exporter interface {
+	Start(ctx context.Context, repository, reference, destination string, params export.Params) (*export.Job, error)
+}
+
+// Authorizer authorizes the user that triggered the event to write to the export destination
+type Authorizer interface {
+	Authorize(ctx context.Context, req *auth.AuthorizationRequest) (*auth.AuthorizationResponse, error)
+}
+
+// ExportHook starts an export of the commit created by the event to a destination.  The hook does not wait for
+// the export, its output holds the export ID to get the export status with.
+type ExportHook struct {
+	ID          string
+	ActionName  string
+	Destination string
+	Incremental bool
+	Parallelism int
+	Timeout     time.Duration
+	Exporter    Exporter
+	Authorizer  Authorizer
+}
+
+const (
+	exportDestinationPropertyKey = "destination"
+	exportIncrementalPropertyKey = "incremental"
+	exportParallelismPropertyKey = "parallelism"
+	exportTimeoutPropertyKey     = "timeout"
+)
+
+var (
+	ErrExportWrongFormat   = errors.New("export wrong format")
+	ErrExportNotConfigured = errors.New("export is not configured")
+	ErrExportNoCommit      = errors.New("export requires a commit, use it on a post event")
+	ErrExportNotAllowed    = errors.New("export to destination not allowed")
+)
+
+func NewExportHook(h ActionHook, action *Action, deps Deps) (Hook, error) {
+	dest, ok := h.Properties[exportDestinationPropertyKey]
+	if !ok {
+		return nil, fmt.Errorf("missing destination: %w", ErrExportWrongFormat)
+	}
+	destination, ok := dest.(string)
+	if !ok || destination == "" {
+		return nil, fmt.Errorf("export destination must be a non empty string: %w", ErrExportWrongFormat)
+	}
+
+	var incremental bool
+	if v, ok := h.Properties[exportIncrementalPropertyKey]; ok {
+		incremental, ok = v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("export incremental must be boolean: %w", ErrExportWrongFormat)
+		}
+	}
+
+	var parallelism int
+	if v, ok := h.Properties[exportParallelismPropertyKey]; ok {
+		parallelism, ok = v.(int)
+		if !ok || parallelism < 0 {
+			return nil, fmt.Errorf("export parallelism must be a positive integer: %w", ErrExportWrongFormat)
+		}
+	}
+
+	var timeout time.Duration
+	if v, ok := h.Properties[exportTimeoutPropertyKey]; ok {
+		if s, ok := v.(string); ok && len(s) > 0 {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, fmt.Errorf("export timeout: %w", err)
+			}
+			timeout = d
+		}
+	}
+
+	return &ExportHook{
+		ID:          h.ID,
+		ActionName:  action.Name,
+		Destination: destination,
+		Incremental: incremental,
+		Parallelism: parallelism,
+		Timeout:     timeout,
+		Exporter:    deps.Exporter,
+		Authorizer:  deps.Authorizer,
+	}, nil
+}
+
+func (e *ExportHook) Run(ctx context.Context, record graveler.HookRecord, writer *HookOutputWriter) error {
+	if e.Exporter == nil || e.Authorizer == nil {
+		return ErrExportNotConfigured
+	}
+	if record.CommitID == "" {
+		return fmt.Errorf("%w (event type: %s)", ErrExportNoCommit, record.EventType)
+	}
+	if err := e.authorize(ctx, record.Commit.Committer); err != nil {
+		return err
+	}
+	job, err := e.Exporter.Start(ctx, record.RepositoryID.String(), record.CommitID.String(), e.Destination, export.Params{
+		Incremental: e.Incremental,
+		Parallelism: e.Parallelism,
+		Timeout:     e.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBufferString(job.ID + "\n")
+	return writer.OutputWrite(ctx, buf, int64(buf.Len()))
+}
+
+// authorize checks that the user who created the commit may export to the destination, the action file alone
+// does not grant it
+func (e *ExportHook) authorize(ctx context.Context, username string) error {
+	resp, err := e.Authorizer.Authorize(ctx, &auth.AuthorizationRequest{
+		Username: username,
+		RequiredPermissions: []permissions.Permission{
+			{
+				Action:   permissions.WriteExportDestinationAction,
+				Resource: permissions.ExportDestinationArn(e.Destination),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%w: %s", ErrExportNotAllowed, resp.Error)
+	}
+	if !resp.Allowed {
+		return fmt.Errorf("%w: user %s to %s", ErrExportNotAllowed, username, e.Destination)
+	}
+	return nil
+}
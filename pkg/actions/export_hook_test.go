@@ -0,0 +1,157 @@
+package actions_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/treeverse/lakefs/pkg/actions"
+	"github.com/treeverse/lakefs/pkg/actions/mock"
+	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/export"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/permissions"
+)
+
+type fakeExporter struct {
+	repository  string
+	reference   string
+	destination string
+	params      export.Params
+}
+
+func (f *fakeExporter) Start(_ context.Context, repository, reference, destination string, params export.Params) (*export.Job, error) {
+	f.repository = repository
+	f.reference = reference
+	f.destination = destination
+	f.params = params
+	return &export.Job{ID: "export-id", Repository: repository, CommitID: reference, Destination: destination, Status: export.JobStatusRunning}, nil
+}
+
+// fakeAuthorizer allows the export to destinations under prefix
+type fakeAuthorizer struct {
+	prefix   string
+	username string
+}
+
+func (f *fakeAuthorizer) Authorize(_ context.Context, req *auth.AuthorizationRequest) (*auth.AuthorizationResponse, error) {
+	f.username = req.Username
+	for _, perm := range req.RequiredPermissions {
+		if perm.Action != permissions.WriteExportDestinationAction || !strings.HasPrefix(perm.Resource, permissions.ExportDestinationArn(f.prefix)) {
+			return &auth.AuthorizationResponse{Allowed: false}, nil
+		}
+	}
+	return &auth.AuthorizationResponse{Allowed: true}, nil
+}
+
+func TestNewExportHook(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties map[string]interface{}
+		wantErr    bool
+	}{
+		{name: "destination", properties: map[string]interface{}{"destination": "s3://bucket/prefix"}},
+		{name: "all", properties: map[string]interface{}{"destination": "s3://bucket/prefix", "incremental": true, "parallelism": 4, "timeout": "10m"}},
+		{name: "missing destination", properties: map[string]interface{}{"incremental": true}, wantErr: true},
+		{name: "empty destination", properties: map[string]interface{}{"destination": ""}, wantErr: true},
+		{name: "invalid incremental", properties: map[string]interface{}{"destination": "s3://bucket/prefix", "incremental": "yes"}, wantErr: true},
+		{name: "invalid parallelism", properties: map[string]interface{}{"destination": "s3://bucket/prefix", "parallelism": -1}, wantErr: true},
+		{name: "invalid timeout", properties: map[string]interface{}{"destination": "s3://bucket/prefix", "timeout": "soon"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := actions.ActionHook{ID: "export", Type: actions.HookTypeExport, Properties: tt.properties}
+			_, err := actions.NewExportHook(h, &actions.Action{Name: "action"}, actions.Deps{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewExportHook() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExportHook_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	record := graveler.HookRecord{
+		RunID:            graveler.NewRunID(),
+		EventType:        graveler.EventTypePostMerge,
+		StorageNamespace: "storageNamespace",
+		RepositoryID:     "repoID",
+		BranchID:         "main",
+		Commit:           graveler.Commit{Committer: "committer"},
+		CommitID:         "commitID",
+	}
+	hookRunID := actions.NewHookRunID(0, 0)
+	var output []byte
+	writer := mock.NewMockOutputWriter(ctrl)
+	writer.EXPECT().
+		OutputWrite(ctx, record.StorageNamespace.String(), actions.FormatHookOutputPath(record.RunID, hookRunID), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, storageNamespace, name string, reader io.Reader, size int64) error {
+			var err error
+			output, err = ioutil.ReadAll(reader)
+			return err
+		})
+	hookWriter := &actions.HookOutputWriter{
+		StorageNamespace: record.StorageNamespace.String(),
+		RunID:            record.RunID,
+		HookRunID:        hookRunID,
+		ActionName:       "action",
+		HookID:           "export",
+		Writer:           writer,
+	}
+
+	exporter := &fakeExporter{}
+	authorizer := &fakeAuthorizer{prefix: "s3://bucket/"}
+	deps := actions.Deps{Exporter: exporter, Authorizer: authorizer}
+	h, err := actions.NewExportHook(actions.ActionHook{
+		ID:         "export",
+		Type:       actions.HookTypeExport,
+		Properties: map[string]interface{}{"destination": "s3://bucket/prefix", "incremental": true, "timeout": "10m"},
+	}, &actions.Action{Name: "action"}, deps)
+	if err != nil {
+		t.Fatalf("NewExportHook() failed with err=%s", err)
+	}
+	if err := h.Run(ctx, record, hookWriter); err != nil {
+		t.Fatalf("Run() failed with err=%s", err)
+	}
+	if exporter.repository != "repoID" || exporter.reference != "commitID" || exporter.destination != "s3://bucket/prefix" ||
+		!exporter.params.Incremental || exporter.params.Timeout != 10*time.Minute {
+		t.Errorf("Run() started export %+v, expected incremental export of repoID commitID to s3://bucket/prefix within 10m", exporter)
+	}
+	if string(output) != "export-id\n" {
+		t.Errorf("Run() output '%s', expected the export ID", output)
+	}
+	if authorizer.username != "committer" {
+		t.Errorf("Run() authorized user '%s', expected the committer", authorizer.username)
+	}
+
+	// the committer may not export to other destinations
+	other, err := actions.NewExportHook(actions.ActionHook{
+		ID:         "export",
+		Type:       actions.HookTypeExport,
+		Properties: map[string]interface{}{"destination": "s3://other-bucket/prefix"},
+	}, &actions.Action{Name: "action"}, deps)
+	if err != nil {
+		t.Fatalf("NewExportHook() failed with err=%s", err)
+	}
+	exporter.destination = ""
+	if err := other.Run(ctx, record, hookWriter); !errors.Is(err, actions.ErrExportNotAllowed) {
+		t.Errorf("Run() to unauthorized destination err=%v, expected %s", err, actions.ErrExportNotAllowed)
+	}
+	if exporter.destination != "" {
+		t.Errorf("Run() exported to unauthorized destination %s", exporter.destination)
+	}
+
+	// export requires the commit of a post event
+	record.CommitID = ""
+	if err := h.Run(ctx, record, hookWriter); !errors.Is(err, actions.ErrExportNoCommit) {
+		t.Errorf("Run() without commit err=%v, expected %s", err, actions.ErrExportNoCommit)
+	}
+}
@@ -12,6 +12,7 @@ type HookType string
 
 const (
 	HookTypeWebhook HookType = "webhook"
+	HookTypeExport  HookType = "export"
 )
 
 // Hook is the abstraction of the basic user-configured runnable building-stone
@@ -19,18 +20,25 @@ type Hook interface {
 	Run(ctx context.Context, record graveler.HookRecord, writer *HookOutputWriter) error
 }
 
-type NewHookFunc func(ActionHook, *Action) (Hook, error)
+// Deps holds the services a hook may use while running
+type Deps struct {
+	Exporter   Exporter
+	Authorizer Authorizer
+}
+
+type NewHookFunc func(ActionHook, *Action, Deps) (Hook, error)
 
 var hooks = map[HookType]NewHookFunc{
 	HookTypeWebhook: NewWebhook,
+	HookTypeExport:  NewExportHook,
 }
 
 var ErrUnknownHookType = errors.New("unknown hook type")
 
-func NewHook(h ActionHook, a *Action) (Hook, error) {
+func NewHook(h ActionHook, a *Action, deps Deps) (Hook, error) {
 	f := hooks[h.Type]
 	if f == nil {
 		return nil, fmt.Errorf("%w (%s)", ErrUnknownHookType, h.Type)
 	}
-	return f(h, a)
+	return f(h, a, deps)
 }
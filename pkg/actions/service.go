@@ -17,9 +17,11 @@ import (
 )
 
 type Service struct {
-	DB     db.Database
-	Source Source
-	Writer OutputWriter
+	DB         db.Database
+	Source     Source
+	Writer     OutputWriter
+	Exporter   Exporter
+	Authorizer Authorizer
 }
 
 type Task struct {
@@ -89,6 +91,16 @@ func NewService(db db.Database, source Source, writer OutputWriter) *Service {
 	}
 }
 
+// SetExporter sets the exporter used by export hooks
+func (s *Service) SetExporter(exporter Exporter) {
+	s.Exporter = exporter
+}
+
+// SetAuthorizer sets the authorizer of the users that trigger export hooks
+func (s *Service) SetAuthorizer(authorizer Authorizer) {
+	s.Authorizer = authorizer
+}
+
 // Run load and run actions based on the event information
 func (s *Service) Run(ctx context.Context, record graveler.HookRecord) error {
 	// load relevant actions
@@ -129,10 +141,11 @@ func (s *Service) loadMatchedActions(ctx context.Context, record graveler.HookRe
 
 func (s *Service) allocateTasks(runID string, actions []*Action) ([][]*Task, error) {
 	var tasks [][]*Task
+	deps := Deps{Exporter: s.Exporter, Authorizer: s.Authorizer}
 	for actionIdx, action := range actions {
 		var actionTasks []*Task
 		for hookIdx, hook := range action.Hooks {
-			h, err := NewHook(hook, action)
+			h, err := NewHook(hook, action, deps)
 			if err != nil {
 				return nil, err
 			}
@@ -355,6 +368,22 @@ func (s *Service) getRunResultTx(tx db.Tx, repositoryID string, runID string) (*
 	return result, nil
 }
 
+// LatestRunPassed returns true if the latest run on branchID passed, false if it failed or no run exists
+func (s *Service) LatestRunPassed(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID) (bool, error) {
+	var passed bool
+	err := s.DB.GetPrimitive(ctx, &passed, `SELECT passed FROM actions_runs
+			WHERE repository_id=$1 AND branch_id=$2
+			ORDER BY run_id DESC LIMIT 1`,
+		repositoryID, branchID)
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return passed, nil
+}
+
 func (s *Service) GetTaskResult(ctx context.Context, repositoryID string, runID string, hookRunID string) (*TaskResult, error) {
 	res, err := s.DB.Transact(ctx, func(tx db.Tx) (interface{}, error) {
 		result := &TaskResult{
@@ -411,8 +440,7 @@ func (s *Service) PostMergeHook(ctx context.Context, record graveler.HookRecord)
 	if err != nil {
 		return err
 	}
-	// post merge actions will be enabled here
-	return nil
+	return s.Run(ctx, record)
 }
 
 func NewHookRunID(actionIdx, hookIdx int) string {
@@ -46,7 +46,7 @@ var (
 	ErrWebhookWrongFormat   = errors.New("webhook wrong format")
 )
 
-func NewWebhook(h ActionHook, action *Action) (Hook, error) {
+func NewWebhook(h ActionHook, action *Action, _ Deps) (Hook, error) {
 	url, ok := h.Properties[webhookURLPropertyKey]
 	if !ok {
 		return nil, fmt.Errorf("missing url: %w", ErrWebhookWrongFormat)
@@ -24,6 +24,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/catalog"
 	"github.com/treeverse/lakefs/pkg/cloud"
 	"github.com/treeverse/lakefs/pkg/db"
+	"github.com/treeverse/lakefs/pkg/export"
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/httputil"
 	"github.com/treeverse/lakefs/pkg/logging"
@@ -40,6 +41,10 @@ const (
 	lakeFSPrefix                 = "symlinks"
 	UserContextKey    contextKey = "user"
 
+	// MaxPerPageCompareTo is the maximum amount of branches listed when counting their commits ahead of and
+	// behind a reference, as each branch walks its history
+	MaxPerPageCompareTo = 100
+
 	actionStatusCompleted = "completed"
 	actionStatusFailed    = "failed"
 )
@@ -51,6 +56,11 @@ type actionsHandler interface {
 	ListRunTaskResults(ctx context.Context, repositoryID string, runID string, after string) (actions.TaskResultIterator, error)
 }
 
+type exportsHandler interface {
+	Start(ctx context.Context, repository, reference, destination string, params export.Params) (*export.Job, error)
+	GetJob(ctx context.Context, repository, exportID string) (*export.Job, error)
+}
+
 type Controller struct {
 	Catalog               catalog.Interface
 	Auth                  auth.Service
@@ -60,6 +70,7 @@ type Controller struct {
 	Collector             stats.Collector
 	CloudMetadataProvider cloud.MetadataProvider
 	Actions               actionsHandler
+	Exports               exportsHandler
 	Logger                logging.Logger
 }
 
@@ -1041,14 +1052,7 @@ func (c *Controller) ListRepositories(w http.ResponseWriter, r *http.Request, pa
 	}
 	results := make([]Repository, 0, len(repos))
 	for _, repo := range repos {
-		creationDate := repo.CreationDate.Unix()
-		r := Repository{
-			Id:               repo.Name,
-			StorageNamespace: repo.StorageNamespace,
-			CreationDate:     creationDate,
-			DefaultBranch:    repo.DefaultBranch,
-		}
-		results = append(results, r)
+		results = append(results, newRepository(repo))
 	}
 	repositoryList := RepositoryList{
 		Pagination: paginationFor(hasMore, results, "Id"),
@@ -1119,6 +1123,69 @@ func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, bo
 	writeResponse(w, http.StatusCreated, response)
 }
 
+func (c *Controller) ForkRepository(w http.ResponseWriter, r *http.Request, body ForkRepositoryJSONRequestBody, repository string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.CreateRepositoryAction,
+			Resource: permissions.RepoArn(body.Name),
+		},
+		{
+			Action:   permissions.ReadRepositoryAction,
+			Resource: permissions.RepoArn(repository),
+		},
+		{
+			Action:   permissions.ListObjectsAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "fork_repo")
+	user, ok := ctx.Value(UserContextKey).(*model.User)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "missing user")
+		return
+	}
+
+	source, err := c.Catalog.GetRepository(ctx, repository)
+	if handleAPIError(w, err) {
+		return
+	}
+	defaultBranch := StringValue(body.DefaultBranch)
+	if defaultBranch == "" {
+		defaultBranch = "main"
+	}
+	ref := StringValue(body.Ref)
+	if ref == "" {
+		ref = source.DefaultBranch
+	}
+
+	err = ensureStorageNamespaceRW(ctx, c.BlockAdapter, body.StorageNamespace)
+	if err != nil {
+		c.Logger.
+			WithError(err).
+			WithField("storage_namespace", body.StorageNamespace).
+			Warn("Could not access storage namespace")
+		writeError(w, http.StatusBadRequest, "error forking repository: could not access storage namespace")
+		return
+	}
+
+	newRepo, err := c.Catalog.CreateRepositoryFrom(ctx, body.Name, body.StorageNamespace, defaultBranch, repository, ref, user.Username)
+	if err != nil {
+		handleAPIError(w, fmt.Errorf("error forking repository: %w", err))
+		return
+	}
+
+	response := Repository{
+		CreationDate:     newRepo.CreationDate.Unix(),
+		DefaultBranch:    newRepo.DefaultBranch,
+		Id:               newRepo.Name,
+		StorageNamespace: newRepo.StorageNamespace,
+	}
+	writeResponse(w, http.StatusCreated, response)
+}
+
 func ensureStorageNamespaceRW(ctx context.Context, adapter block.Adapter, storageNamespace string) error {
 	const (
 		dummyKey  = "dummy"
@@ -1179,16 +1246,175 @@ func (c *Controller) GetRepository(w http.ResponseWriter, r *http.Request, repos
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("error fetching repository: %s", err))
 		return
 	}
+	writeResponse(w, http.StatusOK, newRepository(repo))
+}
 
-	response := Repository{
-		CreationDate:     repo.CreationDate.Unix(),
-		DefaultBranch:    repo.DefaultBranch,
-		Id:               repo.Name,
-		StorageNamespace: repo.StorageNamespace,
+func (c *Controller) UpdateRepository(w http.ResponseWriter, r *http.Request, body UpdateRepositoryJSONRequestBody, repository string) {
+	permissionsToCheck := []permissions.Permission{
+		{
+			Action:   permissions.UpdateRepositoryAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}
+	if body.Name != nil {
+		// renaming creates the repository under its new name
+		permissionsToCheck = append(permissionsToCheck, permissions.Permission{
+			Action:   permissions.CreateRepositoryAction,
+			Resource: permissions.RepoArn(*body.Name),
+		})
+	}
+	if !c.authorize(w, r, permissionsToCheck) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "update_repo")
+	params := catalog.UpdateRepositoryParams{
+		Name:          body.Name,
+		DefaultBranch: body.DefaultBranch,
+		Description:   body.Description,
+	}
+	if body.Metadata != nil {
+		params.Metadata = body.Metadata.AdditionalProperties
+		if params.Metadata == nil {
+			params.Metadata = catalog.Metadata{}
+		}
+	}
+	repo, err := c.Catalog.UpdateRepository(ctx, repository, params)
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusOK, newRepository(repo))
+}
+
+func (c *Controller) GetRetentionRules(w http.ResponseWriter, r *http.Request, repository string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ReadRetentionAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_retention_rules")
+	rules, err := c.Catalog.GetRetentionRules(ctx, repository)
+	if handleAPIError(w, err) {
+		return
+	}
+	response := RetentionRules{
+		Rules: make([]RetentionRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, RetentionRule{
+			BranchPattern: rule.BranchPattern,
+			RetentionDays: rule.RetentionDays,
+		})
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
+func (c *Controller) SetRetentionRules(w http.ResponseWriter, r *http.Request, body SetRetentionRulesJSONRequestBody, repository string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.UpdateRetentionAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "set_retention_rules")
+	rules := make([]catalog.RetentionRule, 0, len(body.Rules))
+	for _, rule := range body.Rules {
+		if rule.RetentionDays < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid retention days %d for branch pattern %s", rule.RetentionDays, rule.BranchPattern))
+			return
+		}
+		rules = append(rules, catalog.RetentionRule{
+			BranchPattern: rule.BranchPattern,
+			RetentionDays: rule.RetentionDays,
+		})
+	}
+	err := c.Catalog.SetRetentionRules(ctx, repository, rules)
+	if errors.Is(err, catalog.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusNoContent, nil)
+}
+
+func (c *Controller) ListBranchProtectionRules(w http.ResponseWriter, r *http.Request, repository string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ReadBranchProtectionAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "list_branch_protection_rules")
+	rules, err := c.Catalog.ListBranchProtectionRules(ctx, repository)
+	if handleAPIError(w, err) {
+		return
+	}
+	response := make([]BranchProtectionRule, 0, len(rules))
+	for _, rule := range rules {
+		requirePassingRun := rule.RequirePassingRun
+		response = append(response, BranchProtectionRule{
+			Pattern:           rule.Pattern,
+			RequirePassingRun: &requirePassingRun,
+		})
 	}
 	writeResponse(w, http.StatusOK, response)
 }
 
+func (c *Controller) CreateBranchProtectionRule(w http.ResponseWriter, r *http.Request, body CreateBranchProtectionRuleJSONRequestBody, repository string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.UpdateBranchProtectionAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "create_branch_protection_rule")
+	err := c.Catalog.CreateBranchProtectionRule(ctx, repository, catalog.BranchProtectionRule{
+		Pattern:           body.Pattern,
+		RequirePassingRun: BoolValue(body.RequirePassingRun),
+	})
+	if errors.Is(err, catalog.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusNoContent, nil)
+}
+
+func (c *Controller) DeleteBranchProtectionRule(w http.ResponseWriter, r *http.Request, repository string, params DeleteBranchProtectionRuleParams) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.UpdateBranchProtectionAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "delete_branch_protection_rule")
+	err := c.Catalog.DeleteBranchProtectionRule(ctx, repository, params.Pattern)
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusNoContent, nil)
+}
+
 func (c *Controller) ListRepositoryRuns(w http.ResponseWriter, r *http.Request, repository string, params ListRepositoryRunsParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
@@ -1411,22 +1637,38 @@ func (c *Controller) ListBranches(w http.ResponseWriter, r *http.Request, reposi
 	ctx := r.Context()
 	c.LogAction(ctx, "list_branches")
 
-	res, hasMore, err := c.Catalog.ListBranches(ctx, repository, paginationPrefix(params.Prefix), paginationAmount(params.Amount), paginationAfter(params.After))
+	amount := paginationAmount(params.Amount)
+	if params.CompareTo != nil && amount > MaxPerPageCompareTo {
+		amount = MaxPerPageCompareTo
+	}
+	res, hasMore, err := c.Catalog.ListBranches(ctx, repository, paginationPrefix(params.Prefix), amount, paginationAfter(params.After))
 	if handleAPIError(w, err) {
 		return
 	}
 
 	refs := make([]Ref, 0, len(res))
 	for _, branch := range res {
-		refs = append(refs, Ref{
+		ref := Ref{
 			CommitId: branch.Reference,
 			Id:       branch.Name,
-		})
+		}
+		if params.CompareTo != nil {
+			ahead, behind, err := c.Catalog.AheadBehind(ctx, repository, branch.Name, string(*params.CompareTo))
+			if handleAPIError(w, err) {
+				return
+			}
+			ref.Ahead = &ahead
+			ref.Behind = &behind
+		}
+		refs = append(refs, ref)
 	}
 	response := RefList{
 		Results:    refs,
 		Pagination: paginationFor(hasMore, refs, "Id"),
 	}
+	if params.CompareTo != nil {
+		response.Pagination.MaxPerPage = MaxPerPageCompareTo
+	}
 	writeResponse(w, http.StatusOK, response)
 }
 
@@ -1467,7 +1709,7 @@ func (c *Controller) DeleteBranch(w http.ResponseWriter, r *http.Request, reposi
 	writeResponse(w, http.StatusNoContent, nil)
 }
 
-func (c *Controller) GetBranch(w http.ResponseWriter, r *http.Request, repository string, branch string) {
+func (c *Controller) GetBranch(w http.ResponseWriter, r *http.Request, repository string, branch string, params GetBranchParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
 			Action:   permissions.ReadBranchAction,
@@ -1486,6 +1728,14 @@ func (c *Controller) GetBranch(w http.ResponseWriter, r *http.Request, repositor
 		CommitId: reference,
 		Id:       branch,
 	}
+	if params.CompareTo != nil {
+		ahead, behind, err := c.Catalog.AheadBehind(ctx, repository, branch, string(*params.CompareTo))
+		if handleAPIError(w, err) {
+			return
+		}
+		response.Ahead = &ahead
+		response.Behind = &behind
+	}
 	writeResponse(w, http.StatusOK, response)
 }
 
@@ -1493,7 +1743,8 @@ func handleAPIError(w http.ResponseWriter, err error) bool {
 	switch {
 	case errors.Is(err, catalog.ErrNotFound),
 		errors.Is(err, graveler.ErrNotFound),
-		errors.Is(err, actions.ErrNotFound):
+		errors.Is(err, actions.ErrNotFound),
+		errors.Is(err, export.ErrJobNotFound):
 		writeError(w, http.StatusNotFound, err)
 
 	case errors.Is(err, graveler.ErrDirtyBranch),
@@ -1501,12 +1752,21 @@ func handleAPIError(w http.ResponseWriter, err error) bool {
 		errors.Is(err, graveler.ErrNoChanges),
 		errors.Is(err, permissions.ErrInvalidServiceName),
 		errors.Is(err, permissions.ErrInvalidAction),
-		errors.Is(err, model.ErrValidationError):
+		errors.Is(err, model.ErrValidationError),
+		errors.Is(err, export.ErrInvalidDestination):
 		writeError(w, http.StatusBadRequest, err)
 
-	case errors.Is(err, graveler.ErrNotUnique):
+	case errors.Is(err, graveler.ErrNotUnique),
+		errors.Is(err, graveler.ErrRepositoryInPolicy),
+		errors.Is(err, export.ErrJobRunning):
 		writeError(w, http.StatusConflict, err)
 
+	case errors.Is(err, graveler.ErrPreconditionFailed):
+		writeError(w, http.StatusPreconditionFailed, err)
+
+	case errors.Is(err, graveler.ErrProtectedBranch):
+		writeError(w, http.StatusForbidden, err)
+
 	case errors.Is(err, catalog.ErrFeatureNotSupported):
 		writeError(w, http.StatusNotImplemented, err)
 
@@ -1551,7 +1811,7 @@ func (c *Controller) ResetBranch(w http.ResponseWriter, r *http.Request, body Re
 	writeResponse(w, http.StatusNoContent, nil)
 }
 
-func (c *Controller) Commit(w http.ResponseWriter, r *http.Request, body CommitJSONRequestBody, repository string, branch string) {
+func (c *Controller) Commit(w http.ResponseWriter, r *http.Request, body CommitJSONRequestBody, repository string, branch string, params CommitParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
 			Action:   permissions.CreateCommitAction,
@@ -1572,7 +1832,11 @@ func (c *Controller) Commit(w http.ResponseWriter, r *http.Request, body CommitJ
 		metadata = body.Metadata.AdditionalProperties
 	}
 	committer := user.Username
-	newCommit, err := c.Catalog.Commit(ctx, repository, branch, body.Message, committer, metadata)
+	var opts []catalog.CommitOption
+	if params.IfMatch != nil {
+		opts = append(opts, catalog.WithExpectedHead(*params.IfMatch))
+	}
+	newCommit, err := c.Catalog.Commit(ctx, repository, branch, body.Message, committer, metadata, opts...)
 	var hookAbortErr *graveler.HookAbortError
 	if errors.As(err, &hookAbortErr) {
 		c.Logger.
@@ -1611,19 +1875,17 @@ func (c *Controller) DiffBranch(w http.ResponseWriter, r *http.Request, reposito
 	}
 	ctx := r.Context()
 	c.LogAction(ctx, "diff_workspace")
-	diff, hasMore, err := c.Catalog.DiffUncommitted(ctx, repository, branch, paginationAmount(params.Amount), paginationAfter(params.After))
+	diff, hasMore, err := c.Catalog.DiffUncommitted(ctx, repository, branch, catalog.DiffParams{
+		Limit:     paginationAmount(params.Amount),
+		After:     paginationAfter(params.After),
+		Prefix:    paginationPrefix(params.Prefix),
+		Delimiter: StringValue(params.Delimiter),
+	})
 	if handleAPIError(w, err) {
 		return
 	}
 
-	results := make([]Diff, 0, len(diff))
-	for _, d := range diff {
-		results = append(results, Diff{
-			Path:     d.Path,
-			Type:     transformDifferenceTypeToString(d.Type),
-			PathType: "object",
-		})
-	}
+	results := newDiffResults(diff)
 	response := DiffList{
 		Pagination: paginationFor(hasMore, results, "Path"),
 		Results:    results,
@@ -1676,6 +1938,15 @@ func (c *Controller) UploadObject(w http.ResponseWriter, r *http.Request, reposi
 		writeError(w, http.StatusNotFound, fmt.Sprintf("branch '%s' not found", branch))
 		return
 	}
+	// same for protected branches, graveler will reject the write after the upload
+	protected, err := c.Catalog.IsBranchProtected(ctx, repository, branch)
+	if handleAPIError(w, err) {
+		return
+	}
+	if protected {
+		writeError(w, http.StatusForbidden, fmt.Errorf("branch %s: %w", branch, graveler.ErrWriteToProtectedBranch))
+		return
+	}
 
 	// before writing body, ensure preconditions - this means we essentially check for object existence twice:
 	// once before uploading the body to save resources and time,
@@ -1698,6 +1969,22 @@ func (c *Controller) UploadObject(w http.ResponseWriter, r *http.Request, reposi
 		}
 		allowOverwrite = false
 	}
+	writeConditions := []graveler.WriteConditionOption{graveler.IfAbsent(!allowOverwrite)}
+	if params.IfMatch != nil {
+		if params.IfNoneMatch != nil {
+			writeError(w, http.StatusBadRequest, "If-Match and If-None-Match cannot be used together")
+			return
+		}
+		cond, err := c.Catalog.IfMatchChecksum(ctx, repo.Name, branch, params.Path, checksumFromETag(*params.IfMatch))
+		if errors.Is(err, graveler.ErrPreconditionFailed) {
+			writeError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		if handleAPIError(w, err) {
+			return
+		}
+		writeConditions = append(writeConditions, cond)
+	}
 
 	// write the content
 	file, handler, err := r.FormFile("content")
@@ -1728,8 +2015,12 @@ func (c *Controller) UploadObject(w http.ResponseWriter, r *http.Request, reposi
 		Checksum:        blob.Checksum,
 	}
 
-	err = c.Catalog.CreateEntry(ctx, repo.Name, branch, entry, graveler.IfAbsent(!allowOverwrite))
+	err = c.Catalog.CreateEntry(ctx, repo.Name, branch, entry, writeConditions...)
 	if errors.Is(err, graveler.ErrPreconditionFailed) {
+		if params.IfMatch != nil {
+			writeError(w, http.StatusPreconditionFailed, "object changed")
+			return
+		}
 		writeError(w, http.StatusPreconditionFailed, "path already exists")
 		return
 	}
@@ -1759,6 +2050,11 @@ func (c *Controller) UploadObject(w http.ResponseWriter, r *http.Request, reposi
 	writeResponse(w, http.StatusCreated, response)
 }
 
+// checksumFromETag returns the checksum of an ETag header value, which may be quoted
+func checksumFromETag(etag string) string {
+	return strings.Trim(etag, "\"")
+}
+
 func (c *Controller) StageObject(w http.ResponseWriter, r *http.Request, body StageObjectJSONRequestBody, repository string, branch string, params StageObjectParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
@@ -1810,7 +2106,15 @@ func (c *Controller) StageObject(w http.ResponseWriter, r *http.Request, body St
 		entry.Metadata = body.Metadata.AdditionalProperties
 	}
 
-	err = c.Catalog.CreateEntry(ctx, repo.Name, branch, entry)
+	var writeConditions []graveler.WriteConditionOption
+	if params.IfMatch != nil {
+		cond, err := c.Catalog.IfMatchChecksum(ctx, repo.Name, branch, params.Path, checksumFromETag(*params.IfMatch))
+		if handleAPIError(w, err) {
+			return
+		}
+		writeConditions = append(writeConditions, cond)
+	}
+	err = c.Catalog.CreateEntry(ctx, repo.Name, branch, entry, writeConditions...)
 	if handleAPIError(w, err) {
 		return
 	}
@@ -1825,6 +2129,81 @@ func (c *Controller) StageObject(w http.ResponseWriter, r *http.Request, body St
 	writeResponse(w, http.StatusCreated, response)
 }
 
+func (c *Controller) ApplyStagingTransaction(w http.ResponseWriter, r *http.Request, body ApplyStagingTransactionJSONRequestBody, repository string, branch string) {
+	perms := make([]permissions.Permission, 0, len(body.Operations))
+	for _, op := range body.Operations {
+		if op.Op == string(catalog.TransactionOperationDelete) {
+			perms = append(perms, permissions.Permission{
+				Action:   permissions.DeleteObjectAction,
+				Resource: permissions.ObjectArn(repository, op.Path),
+			})
+			continue
+		}
+		if op.Op == string(catalog.TransactionOperationLink) {
+			perms = append(perms, permissions.Permission{
+				Action:   permissions.ReadObjectAction,
+				Resource: permissions.ObjectArn(repository, StringValue(op.SourcePath)),
+			})
+		}
+		perms = append(perms, permissions.Permission{
+			Action:   permissions.WriteObjectAction,
+			Resource: permissions.ObjectArn(repository, op.Path),
+		})
+	}
+	if !c.authorize(w, r, perms) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "apply_staging_transaction")
+
+	uriRegex := c.BlockAdapter.GetStorageNamespaceInfo().ValidityRegex
+	ops := make([]catalog.TransactionOperation, 0, len(body.Operations))
+	for i, op := range body.Operations {
+		txOp := catalog.TransactionOperation{
+			Type:       catalog.TransactionOperationType(op.Op),
+			Path:       op.Path,
+			SourceRef:  StringValue(op.SourceRef),
+			SourcePath: StringValue(op.SourcePath),
+		}
+		if txOp.Type == catalog.TransactionOperationPut {
+			if op.Staging == nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("operation %d: put requires staging", i))
+				return
+			}
+			if match, err := regexp.MatchString(uriRegex, op.Staging.PhysicalAddress); err != nil || !match {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("operation %d: physical address is not valid for block adapter: %s",
+					i, c.BlockAdapter.BlockstoreType()))
+				return
+			}
+			writeTime := time.Now()
+			if op.Staging.Mtime != nil {
+				writeTime = time.Unix(*op.Staging.Mtime, 0)
+			}
+			txOp.Entry = catalog.DBEntry{
+				PhysicalAddress: op.Staging.PhysicalAddress,
+				AddressType:     catalog.AddressTypeFull,
+				CreationDate:    writeTime,
+				Size:            op.Staging.SizeBytes,
+				Checksum:        op.Staging.Checksum,
+			}
+			if op.Staging.Metadata != nil {
+				txOp.Entry.Metadata = op.Staging.Metadata.AdditionalProperties
+			}
+		}
+		ops = append(ops, txOp)
+	}
+
+	err := c.Catalog.ApplyTransaction(ctx, repository, branch, ops)
+	if errors.Is(err, catalog.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusNoContent, nil)
+}
+
 func (c *Controller) RevertBranch(w http.ResponseWriter, r *http.Request, body RevertBranchJSONRequestBody, repository string, branch string) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
@@ -1853,28 +2232,122 @@ func (c *Controller) RevertBranch(w http.ResponseWriter, r *http.Request, body R
 	writeResponse(w, http.StatusNoContent, nil)
 }
 
-func (c *Controller) GetCommit(w http.ResponseWriter, r *http.Request, repository string, commitID string) {
+func (c *Controller) CherryPick(w http.ResponseWriter, r *http.Request, body CherryPickJSONRequestBody, repository string, branch string) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
-			Action:   permissions.ReadCommitAction,
-			Resource: permissions.RepoArn(repository),
+			Action:   permissions.CreateCommitAction,
+			Resource: permissions.BranchArn(repository, branch),
 		},
 	}) {
 		return
 	}
 	ctx := r.Context()
-	c.LogAction(ctx, "get_commit")
-	commit, err := c.Catalog.GetCommit(ctx, repository, commitID)
-	if errors.Is(err, catalog.ErrNotFound) {
-		writeError(w, http.StatusNotFound, "commit not found")
-		return
-	}
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+	c.LogAction(ctx, "cherry_pick")
+	user, ok := ctx.Value(UserContextKey).(*model.User)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "user not found")
 		return
 	}
-	metadata := Commit_Metadata{
-		AdditionalProperties: map[string]string(commit.Metadata),
+	committer := user.Username
+	var parentNumber int
+	if body.ParentNumber != nil {
+		parentNumber = *body.ParentNumber
+	}
+	newCommit, err := c.Catalog.CherryPick(ctx, repository, branch, catalog.CherryPickParams{
+		Reference:    body.Ref,
+		ParentNumber: parentNumber,
+		Committer:    committer,
+		Message:      StringValue(body.Message),
+	})
+	switch {
+	case errors.Is(err, graveler.ErrConflictFound):
+		writeError(w, http.StatusConflict, err)
+		return
+	case errors.Is(err, catalog.ErrInvalid),
+		errors.Is(err, graveler.ErrCherryPickMergeNoParent),
+		errors.Is(err, graveler.ErrCherryPickParentOutOfRange):
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if handleAPIError(w, err) {
+		return
+	}
+	newMetadata := Commit_Metadata{
+		AdditionalProperties: map[string]string(newCommit.Metadata),
+	}
+	response := Commit{
+		Committer:    newCommit.Committer,
+		CreationDate: newCommit.CreationDate.Unix(),
+		Id:           newCommit.Reference,
+		Message:      newCommit.Message,
+		MetaRangeId:  newCommit.MetaRangeID,
+		Metadata:     &newMetadata,
+		Parents:      newCommit.Parents,
+	}
+	writeResponse(w, http.StatusCreated, response)
+}
+
+func (c *Controller) RebaseBranch(w http.ResponseWriter, r *http.Request, body RebaseBranchJSONRequestBody, repository string, branch string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.CreateCommitAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "rebase_branch")
+	newCommit, err := c.Catalog.Rebase(ctx, repository, branch, body.Onto)
+	switch {
+	case errors.Is(err, graveler.ErrConflictFound):
+		writeError(w, http.StatusConflict, err)
+		return
+	case errors.Is(err, catalog.ErrInvalid),
+		errors.Is(err, graveler.ErrNoMergeBase):
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if handleAPIError(w, err) {
+		return
+	}
+	newMetadata := Commit_Metadata{
+		AdditionalProperties: map[string]string(newCommit.Metadata),
+	}
+	response := Commit{
+		Committer:    newCommit.Committer,
+		CreationDate: newCommit.CreationDate.Unix(),
+		Id:           newCommit.Reference,
+		Message:      newCommit.Message,
+		MetaRangeId:  newCommit.MetaRangeID,
+		Metadata:     &newMetadata,
+		Parents:      newCommit.Parents,
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
+func (c *Controller) GetCommit(w http.ResponseWriter, r *http.Request, repository string, commitID string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ReadCommitAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_commit")
+	commit, err := c.Catalog.GetCommit(ctx, repository, commitID)
+	if errors.Is(err, catalog.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "commit not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	metadata := Commit_Metadata{
+		AdditionalProperties: map[string]string(commit.Metadata),
 	}
 	response := Commit{
 		Committer:    commit.Committer,
@@ -1943,7 +2416,7 @@ func (c *Controller) GetRange(w http.ResponseWriter, r *http.Request, repository
 	writeResponse(w, http.StatusOK, response)
 }
 
-func (c *Controller) DumpRefs(w http.ResponseWriter, r *http.Request, repository string) {
+func (c *Controller) DumpRefs(w http.ResponseWriter, r *http.Request, repository string, params DumpRefsParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
 			Action:   permissions.ListTagsAction,
@@ -1968,6 +2441,9 @@ func (c *Controller) DumpRefs(w http.ResponseWriter, r *http.Request, repository
 		return
 	}
 
+	// commits added from now on are left for the next incremental dump
+	watermark := time.Now().Unix()
+
 	// dump all types:
 	tagsID, err := c.Catalog.DumpTags(ctx, repository)
 	if handleAPIError(w, err) {
@@ -1978,7 +2454,12 @@ func (c *Controller) DumpRefs(w http.ResponseWriter, r *http.Request, repository
 	if handleAPIError(w, err) {
 		return
 	}
-	commitsID, err := c.Catalog.DumpCommits(ctx, repository)
+	var commitsID string
+	if params.Since != nil {
+		commitsID, err = c.Catalog.DumpCommitsSince(ctx, repository, time.Unix(*params.Since, 0))
+	} else {
+		commitsID, err = c.Catalog.DumpCommits(ctx, repository)
+	}
 	if handleAPIError(w, err) {
 		return
 	}
@@ -1987,6 +2468,13 @@ func (c *Controller) DumpRefs(w http.ResponseWriter, r *http.Request, repository
 		BranchesMetaRangeId: branchesID,
 		CommitsMetaRangeId:  commitsID,
 		TagsMetaRangeId:     tagsID,
+		Watermark:           &watermark,
+		Since:               params.Since,
+	}
+	// an incremental dump keeps the manifest of the full dump it continues
+	manifestIdentifier := "_lakefs/refs_manifest.json"
+	if params.Since != nil {
+		manifestIdentifier = fmt.Sprintf("_lakefs/refs_manifest_%d.json", watermark)
 	}
 
 	// write this to the block store
@@ -1997,7 +2485,7 @@ func (c *Controller) DumpRefs(w http.ResponseWriter, r *http.Request, repository
 	}
 	err = c.BlockAdapter.Put(ctx, block.ObjectPointer{
 		StorageNamespace: repo.StorageNamespace,
-		Identifier:       "_lakefs/refs_manifest.json",
+		Identifier:       manifestIdentifier,
 	}, int64(len(manifestBytes)), bytes.NewReader(manifestBytes), block.PutOpts{})
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
@@ -2006,7 +2494,7 @@ func (c *Controller) DumpRefs(w http.ResponseWriter, r *http.Request, repository
 	writeResponse(w, http.StatusCreated, response)
 }
 
-func (c *Controller) RestoreRefs(w http.ResponseWriter, r *http.Request, body RestoreRefsJSONRequestBody, repository string) {
+func (c *Controller) RestoreRefs(w http.ResponseWriter, r *http.Request, body RestoreRefsJSONRequestBody, repository string, params RestoreRefsParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
 			Action:   permissions.CreateTagAction,
@@ -2031,11 +2519,14 @@ func (c *Controller) RestoreRefs(w http.ResponseWriter, r *http.Request, body Re
 		return
 	}
 
-	// ensure no refs currently found
-	_, _, err = c.Catalog.ListCommits(ctx, repo.Name, repo.DefaultBranch, "", 1)
-	if !errors.Is(err, graveler.ErrNotFound) {
-		writeError(w, http.StatusBadRequest, "can only restore into a bare repository")
-		return
+	// ensure no refs currently found, unless merging an incremental dump into the refs of previous dumps
+	incremental := params.Incremental != nil && *params.Incremental
+	if !incremental {
+		_, _, err = c.Catalog.ListCommits(ctx, repo.Name, repo.DefaultBranch, "", 1)
+		if !errors.Is(err, graveler.ErrNotFound) {
+			writeError(w, http.StatusBadRequest, "can only restore into a bare repository")
+			return
+		}
 	}
 
 	// load commits
@@ -2159,20 +2650,15 @@ func (c *Controller) DiffRefs(w http.ResponseWriter, r *http.Request, repository
 		diffFunc = c.Catalog.Diff
 	}
 	diff, hasMore, err := diffFunc(ctx, repository, leftRef, rightRef, catalog.DiffParams{
-		Limit: paginationAmount(params.Amount),
-		After: paginationAfter(params.After),
+		Limit:     paginationAmount(params.Amount),
+		After:     paginationAfter(params.After),
+		Prefix:    paginationPrefix(params.Prefix),
+		Delimiter: StringValue(params.Delimiter),
 	})
 	if handleAPIError(w, err) {
 		return
 	}
-	results := make([]Diff, 0, len(diff))
-	for _, d := range diff {
-		results = append(results, Diff{
-			Path:     d.Path,
-			Type:     transformDifferenceTypeToString(d.Type),
-			PathType: "object",
-		})
-	}
+	results := newDiffResults(diff)
 	response := DiffList{
 		Pagination: paginationFor(hasMore, results, "Path"),
 		Results:    results,
@@ -2180,16 +2666,129 @@ func (c *Controller) DiffRefs(w http.ResponseWriter, r *http.Request, repository
 	writeResponse(w, http.StatusOK, response)
 }
 
+func (c *Controller) AheadBehindRefs(w http.ResponseWriter, r *http.Request, repository string, leftRef string, rightRef string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ReadCommitAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "ahead_behind_refs")
+	ahead, behind, err := c.Catalog.AheadBehind(ctx, repository, leftRef, rightRef)
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusOK, AheadBehind{
+		Ahead:  ahead,
+		Behind: behind,
+	})
+}
+
+func (c *Controller) DiffRefsSummary(w http.ResponseWriter, r *http.Request, repository string, leftRef string, rightRef string, params DiffRefsSummaryParams) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ListObjectsAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "diff_refs_summary")
+	summary, err := c.Catalog.DiffSummary(ctx, repository, leftRef, rightRef, paginationPrefix(params.Prefix))
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusOK, DiffSummary{
+		Added:        int64(summary.Count[catalog.DifferenceTypeAdded]),
+		Removed:      int64(summary.Count[catalog.DifferenceTypeRemoved]),
+		Changed:      int64(summary.Count[catalog.DifferenceTypeChanged]),
+		AddedBytes:   summary.Bytes[catalog.DifferenceTypeAdded],
+		RemovedBytes: summary.Bytes[catalog.DifferenceTypeRemoved],
+		ChangedBytes: summary.Bytes[catalog.DifferenceTypeChanged],
+	})
+}
+
+func (c *Controller) ExportRef(w http.ResponseWriter, r *http.Request, body ExportRefJSONRequestBody, repository string, ref string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ExportRepositoryAction,
+			Resource: permissions.RepoArn(repository),
+		},
+		{
+			Action:   permissions.ListObjectsAction,
+			Resource: permissions.RepoArn(repository),
+		},
+		{
+			Action:   permissions.WriteExportDestinationAction,
+			Resource: permissions.ExportDestinationArn(strings.TrimSuffix(body.Destination, "/")),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "export_ref")
+	job, err := c.Exports.Start(ctx, repository, ref, body.Destination, export.Params{
+		Incremental: BoolValue(body.Incremental),
+		Parallelism: IntValue(body.Parallelism),
+	})
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusAccepted, exportStatus(job))
+}
+
+func (c *Controller) GetExport(w http.ResponseWriter, r *http.Request, repository string, exportID string) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ReadRepositoryAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_export")
+	job, err := c.Exports.GetJob(ctx, repository, exportID)
+	if handleAPIError(w, err) {
+		return
+	}
+	writeResponse(w, http.StatusOK, exportStatus(job))
+}
+
+func exportStatus(job *export.Job) ExportStatus {
+	status := ExportStatus{
+		ExportId:    job.ID,
+		CommitId:    job.CommitID,
+		Destination: job.Destination,
+		Status:      string(job.Status),
+		StartTime:   job.StartTime,
+		Deadline:    job.Deadline,
+		EndTime:     job.EndTime,
+		Copied:      job.Copied,
+		Removed:     job.Removed,
+	}
+	if job.Error != "" {
+		status.Error = StringPtr(job.Error)
+	}
+	if job.PreviousCommitID != "" {
+		status.PreviousCommitId = StringPtr(job.PreviousCommitID)
+	}
+	return status
+}
+
 // LogBranchCommits deprecated replaced by LogCommits
 func (c *Controller) LogBranchCommits(w http.ResponseWriter, r *http.Request, repository string, branch string, params LogBranchCommitsParams) {
-	c.logCommitsHelper(w, r, repository, branch, params.After, params.Amount)
+	c.LogCommits(w, r, repository, branch, LogCommitsParams{
+		After:  params.After,
+		Amount: params.Amount,
+	})
 }
 
 func (c *Controller) LogCommits(w http.ResponseWriter, r *http.Request, repository string, ref string, params LogCommitsParams) {
-	c.logCommitsHelper(w, r, repository, ref, params.After, params.Amount)
-}
-
-func (c *Controller) logCommitsHelper(w http.ResponseWriter, r *http.Request, repository string, ref string, after *PaginationAfter, amount *PaginationAmount) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
 			Action:   permissions.ReadBranchAction,
@@ -2201,8 +2800,38 @@ func (c *Controller) logCommitsHelper(w http.ResponseWriter, r *http.Request, re
 	ctx := r.Context()
 	c.LogAction(ctx, "get_branch_commit_log")
 
+	var opts []catalog.LogOption
+	if params.Prefix != nil && *params.Prefix != "" {
+		opts = append(opts, catalog.WithPathPrefix(*params.Prefix))
+	}
+	if BoolValue(params.FirstParent) {
+		opts = append(opts, catalog.WithFirstParent())
+	}
+	if params.Committer != nil && *params.Committer != "" {
+		opts = append(opts, catalog.WithCommitter(*params.Committer))
+	}
+	if params.Since != nil {
+		opts = append(opts, catalog.WithSince(*params.Since))
+	}
+	if params.Until != nil {
+		opts = append(opts, catalog.WithUntil(*params.Until))
+	}
+	if params.Metadata != nil && len(*params.Metadata) > 0 {
+		const keyValueParts = 2
+		metadata := make(catalog.Metadata, len(*params.Metadata))
+		for _, pair := range *params.Metadata {
+			parts := strings.SplitN(pair, "=", keyValueParts)
+			if len(parts) != keyValueParts {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid metadata filter '%s', expected key=value", pair))
+				return
+			}
+			metadata[parts[0]] = parts[1]
+		}
+		opts = append(opts, catalog.WithMetadata(metadata))
+	}
+
 	// get commit log
-	commitLog, hasMore, err := c.Catalog.ListCommits(ctx, repository, ref, paginationAfter(after), paginationAmount(amount))
+	commitLog, hasMore, err := c.Catalog.ListCommits(ctx, repository, ref, paginationAfter(params.After), paginationAmount(params.Amount), opts...)
 	if handleAPIError(w, err) {
 		return
 	}
@@ -2370,6 +2999,44 @@ func (c *Controller) ListObjects(w http.ResponseWriter, r *http.Request, reposit
 	writeResponse(w, http.StatusOK, response)
 }
 
+func (c *Controller) BlameObjects(w http.ResponseWriter, r *http.Request, repository string, ref string, params BlameObjectsParams) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ListObjectsAction,
+			Resource: permissions.RepoArn(repository),
+		},
+		{
+			Action:   permissions.ReadCommitAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "blame_objects")
+
+	res, hasMore, err := c.Catalog.Blame(ctx, repository, ref, StringValue(params.Prefix), paginationAfter(params.After), paginationAmount(params.Amount))
+	if handleAPIError(w, err) {
+		return
+	}
+
+	results := make([]ObjectBlame, 0, len(res))
+	for _, entry := range res {
+		blame := ObjectBlame{Path: entry.Path}
+		if entry.Commit != nil {
+			blame.CommitId = entry.Commit.Reference
+			blame.Committer = entry.Commit.Committer
+			blame.CreationDate = entry.Commit.CreationDate.Unix()
+		}
+		results = append(results, blame)
+	}
+	response := ObjectBlameList{
+		Pagination: paginationFor(hasMore, results, "Path"),
+		Results:    results,
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
 func (c *Controller) StatObject(w http.ResponseWriter, r *http.Request, repository string, ref string, params StatObjectParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
@@ -2448,7 +3115,7 @@ func (c *Controller) GetUnderlyingProperties(w http.ResponseWriter, r *http.Requ
 	writeResponse(w, http.StatusOK, response)
 }
 
-func (c *Controller) MergeIntoBranch(w http.ResponseWriter, r *http.Request, body MergeIntoBranchJSONRequestBody, repository string, sourceRef string, destinationBranch string) {
+func (c *Controller) MergeIntoBranch(w http.ResponseWriter, r *http.Request, body MergeIntoBranchJSONRequestBody, repository string, sourceRef string, destinationBranch string, params MergeIntoBranchParams) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
 			Action:   permissions.CreateCommitAction,
@@ -2468,11 +3135,15 @@ func (c *Controller) MergeIntoBranch(w http.ResponseWriter, r *http.Request, bod
 	if body.Metadata != nil {
 		metadata = body.Metadata.AdditionalProperties
 	}
-	res, err := c.Catalog.Merge(ctx,
-		repository, destinationBranch, sourceRef,
-		user.Username,
-		StringValue(body.Message),
-		metadata)
+	res, err := c.Catalog.Merge(ctx, repository, destinationBranch, sourceRef, catalog.MergeParams{
+		Committer:    user.Username,
+		Message:      StringValue(body.Message),
+		Metadata:     metadata,
+		Strategy:     StringValue(body.Strategy),
+		Squash:       BoolValue(body.Squash),
+		Mode:         StringValue(body.Mode),
+		ExpectedHead: StringValue(params.IfMatch),
+	})
 
 	var hookAbortErr *graveler.HookAbortError
 	switch {
@@ -2481,24 +3152,125 @@ func (c *Controller) MergeIntoBranch(w http.ResponseWriter, r *http.Request, bod
 		writeError(w, http.StatusPreconditionFailed, err)
 		return
 	case errors.Is(err, catalog.ErrConflictFound) || errors.Is(err, graveler.ErrConflictFound):
-		writeError(w, http.StatusConflict, newMergeResultFromCatalog(res))
+		repo, err := c.Catalog.GetRepository(ctx, repository)
+		if handleAPIError(w, err) {
+			return
+		}
+		response, err := newMergeResultFromCatalog(repo.StorageNamespace, res)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeResponse(w, http.StatusConflict, response)
+		return
+	case errors.Is(err, graveler.ErrNotFastForward):
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	case errors.Is(err, catalog.ErrInvalid):
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	if handleAPIError(w, err) {
 		return
 	}
 
-	response := newMergeResultFromCatalog(res)
+	response, err := newMergeResultFromCatalog("", res)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
+func (c *Controller) ListMergeConflicts(w http.ResponseWriter, r *http.Request, repository string, sourceRef string, destinationBranch string, params ListMergeConflictsParams) {
+	if !c.authorize(w, r, []permissions.Permission{
+		{
+			Action:   permissions.ListObjectsAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "list_merge_conflicts")
+	repo, err := c.Catalog.GetRepository(ctx, repository)
+	if handleAPIError(w, err) {
+		return
+	}
+	conflicts, hasMore, err := c.Catalog.ListMergeConflicts(ctx, repository, destinationBranch, sourceRef, paginationAmount(params.Amount), paginationAfter(params.After))
+	if handleAPIError(w, err) {
+		return
+	}
+	response, err := newMergeConflictListFromCatalog(repo.StorageNamespace, conflicts, hasMore)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
 	writeResponse(w, http.StatusOK, response)
 }
 
-func newMergeResultFromCatalog(res *catalog.MergeResult) MergeResult {
+func newMergeConflictListFromCatalog(storageNamespace string, conflicts []*catalog.MergeConflict, hasMore bool) (MergeConflictList, error) {
+	results := make([]MergeConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		result := MergeConflict{
+			Path:            conflict.Path,
+			SourceType:      transformDifferenceTypeToString(conflict.SourceType),
+			DestinationType: transformDifferenceTypeToString(conflict.DestinationType),
+		}
+		var err error
+		if result.Source, err = newObjectStatsFromCatalogEntry(storageNamespace, conflict.Source); err != nil {
+			return MergeConflictList{}, err
+		}
+		if result.Destination, err = newObjectStatsFromCatalogEntry(storageNamespace, conflict.Destination); err != nil {
+			return MergeConflictList{}, err
+		}
+		if result.Base, err = newObjectStatsFromCatalogEntry(storageNamespace, conflict.Base); err != nil {
+			return MergeConflictList{}, err
+		}
+		results = append(results, result)
+	}
+	return MergeConflictList{
+		Pagination: paginationFor(hasMore, results, "Path"),
+		Results:    results,
+	}, nil
+}
+
+func newObjectStatsFromCatalogEntry(storageNamespace string, entry *catalog.DBEntry) (*ObjectStats, error) {
+	if entry == nil {
+		return nil, nil
+	}
+	qk, err := block.ResolveNamespace(storageNamespace, entry.PhysicalAddress, entry.AddressType.ToIdentifierType())
+	if err != nil {
+		return nil, err
+	}
+	var mtime int64
+	if !entry.CreationDate.IsZero() {
+		mtime = entry.CreationDate.Unix()
+	}
+	return &ObjectStats{
+		Checksum:        entry.Checksum,
+		Mtime:           mtime,
+		Path:            entry.Path,
+		PhysicalAddress: qk.Format(),
+		PathType:        "object",
+		SizeBytes:       Int64Ptr(entry.Size),
+	}, nil
+}
+
+func newMergeResultFromCatalog(storageNamespace string, res *catalog.MergeResult) (MergeResult, error) {
 	if res == nil {
-		return MergeResult{}
+		return MergeResult{}, nil
 	}
 	result := MergeResult{
 		Reference: res.Reference,
 	}
+	if res.Conflicts != nil {
+		conflicts, err := newMergeConflictListFromCatalog(storageNamespace, res.Conflicts, res.HasMoreConflicts)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		result.Conflicts = &conflicts
+	}
 	for k, v := range res.Summary {
 		switch k {
 		case catalog.DifferenceTypeAdded:
@@ -2511,7 +3283,7 @@ func newMergeResultFromCatalog(res *catalog.MergeResult) MergeResult {
 			result.Summary.Conflict = v
 		}
 	}
-	return result
+	return result, nil
 }
 
 func (c *Controller) ListTags(w http.ResponseWriter, r *http.Request, repository string, params ListTagsParams) {
@@ -2531,20 +3303,32 @@ func (c *Controller) ListTags(w http.ResponseWriter, r *http.Request, repository
 		return
 	}
 
-	results := make([]Ref, 0, len(res))
+	results := make([]Tag, 0, len(res))
 	for _, tag := range res {
-		results = append(results, Ref{
-			CommitId: tag.CommitID,
-			Id:       tag.ID,
-		})
+		results = append(results, newTagFromCatalog(tag))
 	}
-	response := RefList{
+	response := TagList{
 		Results:    results,
 		Pagination: paginationFor(hasMore, results, "Id"),
 	}
 	writeResponse(w, http.StatusOK, response)
 }
 
+func newTagFromCatalog(tag *catalog.Tag) Tag {
+	response := Tag{
+		CommitId:  tag.CommitID,
+		Id:        tag.ID,
+		Annotated: BoolPtr(tag.Annotation != nil),
+	}
+	if tag.Annotation != nil {
+		response.Message = StringPtr(tag.Annotation.Message)
+		response.Creator = StringPtr(tag.Annotation.Creator)
+		response.CreationDate = Int64Ptr(tag.Annotation.CreationDate.Unix())
+		response.Metadata = &Tag_Metadata{AdditionalProperties: tag.Annotation.Metadata}
+	}
+	return response
+}
+
 func (c *Controller) CreateTag(w http.ResponseWriter, r *http.Request, body CreateTagJSONRequestBody, repository string) {
 	if !c.authorize(w, r, []permissions.Permission{
 		{
@@ -2557,14 +3341,31 @@ func (c *Controller) CreateTag(w http.ResponseWriter, r *http.Request, body Crea
 	ctx := r.Context()
 	c.LogAction(ctx, "create_tag")
 
-	commitID, err := c.Catalog.CreateTag(ctx, repository, body.Id, body.Ref)
+	var annotation *catalog.TagAnnotation
+	if body.Message != nil || body.Metadata != nil {
+		user, ok := ctx.Value(UserContextKey).(*model.User)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "user not found")
+			return
+		}
+		annotation = &catalog.TagAnnotation{
+			Message:      StringValue(body.Message),
+			Creator:      user.Username,
+			CreationDate: time.Now(),
+		}
+		if body.Metadata != nil {
+			annotation.Metadata = body.Metadata.AdditionalProperties
+		}
+	}
+	commitID, err := c.Catalog.CreateTag(ctx, repository, body.Id, body.Ref, annotation)
 	if handleAPIError(w, err) {
 		return
 	}
-	response := Ref{
-		CommitId: commitID,
-		Id:       body.Id,
-	}
+	response := newTagFromCatalog(&catalog.Tag{
+		ID:         body.Id,
+		CommitID:   commitID,
+		Annotation: annotation,
+	})
 	writeResponse(w, http.StatusCreated, response)
 }
 
@@ -2597,15 +3398,11 @@ func (c *Controller) GetTag(w http.ResponseWriter, r *http.Request, repository s
 	}
 	ctx := r.Context()
 	c.LogAction(ctx, "get_tag")
-	reference, err := c.Catalog.GetTag(ctx, repository, tag)
+	res, err := c.Catalog.GetTag(ctx, repository, tag)
 	if handleAPIError(w, err) {
 		return
 	}
-	response := Ref{
-		CommitId: reference,
-		Id:       tag,
-	}
-	writeResponse(w, http.StatusOK, response)
+	writeResponse(w, http.StatusOK, newTagFromCatalog(res))
 }
 
 func (c *Controller) Setup(w http.ResponseWriter, r *http.Request, body SetupJSONRequestBody) {
@@ -2687,10 +3484,28 @@ func Int64Value(p *int64) int64 {
 	return *p
 }
 
+func IntValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
 func Int64Ptr(n int64) *int64 {
 	return &n
 }
 
+func BoolPtr(b bool) *bool {
+	return &b
+}
+
+func BoolValue(p *bool) bool {
+	if p == nil {
+		return false
+	}
+	return *p
+}
+
 func PaginationAmountPtr(a int) *PaginationAmount {
 	amount := PaginationAmount(a)
 	return &amount
@@ -2701,6 +3516,11 @@ func PaginationAfterPtr(a string) *PaginationAfter {
 	return &after
 }
 
+func PaginationPrefixPtr(p string) *PaginationPrefix {
+	prefix := PaginationPrefix(p)
+	return &prefix
+}
+
 func writeError(w http.ResponseWriter, code int, v interface{}) {
 	apiErr := Error{
 		Message: fmt.Sprint(v),
@@ -2758,6 +3578,7 @@ func NewController(
 	collector stats.Collector,
 	cloudMetadataProvider cloud.MetadataProvider,
 	actions actionsHandler,
+	exports exportsHandler,
 	logger logging.Logger,
 ) *Controller {
 	return &Controller{
@@ -2769,6 +3590,7 @@ func NewController(
 		Collector:             collector,
 		CloudMetadataProvider: cloudMetadataProvider,
 		Actions:               actions,
+		Exports:               exports,
 		Logger:                logger,
 	}
 }
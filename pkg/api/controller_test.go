@@ -21,6 +21,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/api"
 	"github.com/treeverse/lakefs/pkg/block"
 	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/export"
 	"github.com/treeverse/lakefs/pkg/httputil"
 	"github.com/treeverse/lakefs/pkg/stats"
 	"github.com/treeverse/lakefs/pkg/testutil"
@@ -212,6 +213,66 @@ func TestController_CommitsGetBranchCommitLogHandler(t *testing.T) {
 			t.Fatalf("Log %d commits, expected %d", len(commitsLog), expectedCommits)
 		}
 	})
+
+	t.Run("get branch log by prefix", func(t *testing.T) {
+		_, err := deps.catalog.CreateRepository(ctx, "repo3", onBlock(deps, "ns3"), "main")
+		testutil.Must(t, err)
+
+		var expected []string
+		for i, p := range []string{"tables/events/part1", "ingest/batch1", "tables/events/part2", "ingest/batch2"} {
+			n := strconv.Itoa(i + 1)
+			err := deps.catalog.CreateEntry(ctx, "repo3", "main", catalog.DBEntry{Path: p, PhysicalAddress: onBlock(deps, "addr"+n), CreationDate: time.Now(), Size: 1, Checksum: "cksum" + n})
+			testutil.MustDo(t, "create entry "+p, err)
+			commit, err := deps.catalog.Commit(ctx, "repo3", "main", "commit"+n, "some_user", nil)
+			testutil.MustDo(t, "commit "+p, err)
+			if strings.HasPrefix(p, "tables/events/") {
+				expected = append([]string{commit.Reference}, expected...)
+			}
+		}
+		resp, err := clt.LogCommitsWithResponse(ctx, "repo3", "main", &api.LogCommitsParams{Prefix: api.StringPtr("tables/events/")})
+		verifyResponseOK(t, resp, err)
+
+		var got []string
+		for _, commit := range resp.JSON200.Results {
+			got = append(got, commit.Id)
+		}
+		if diff := deep.Equal(got, expected); diff != nil {
+			t.Fatalf("Log by prefix unexpected commits: %s", diff)
+		}
+	})
+
+	t.Run("get branch log by filters", func(t *testing.T) {
+		_, err := deps.catalog.CreateRepository(ctx, "repo4", onBlock(deps, "ns4"), "main")
+		testutil.Must(t, err)
+
+		var commitIDs []string
+		for i := 0; i < 3; i++ {
+			n := strconv.Itoa(i + 1)
+			err := deps.catalog.CreateEntry(ctx, "repo4", "main", catalog.DBEntry{Path: "foo/bar" + n, PhysicalAddress: onBlock(deps, "addr"+n), CreationDate: time.Now(), Size: 1, Checksum: "cksum" + n})
+			testutil.MustDo(t, "create entry "+n, err)
+			commit, err := deps.catalog.Commit(ctx, "repo4", "main", "commit"+n, "user"+n, catalog.Metadata{"job_id": n})
+			testutil.MustDo(t, "commit "+n, err)
+			commitIDs = append(commitIDs, commit.Reference)
+		}
+
+		resp, err := clt.LogCommitsWithResponse(ctx, "repo4", "main", &api.LogCommitsParams{Metadata: &[]string{"job_id=2"}})
+		verifyResponseOK(t, resp, err)
+		if len(resp.JSON200.Results) != 1 || resp.JSON200.Results[0].Id != commitIDs[1] {
+			t.Fatalf("Log by metadata got %+v, expected commit %s", resp.JSON200.Results, commitIDs[1])
+		}
+
+		resp, err = clt.LogCommitsWithResponse(ctx, "repo4", "main", &api.LogCommitsParams{Committer: api.StringPtr("user3")})
+		verifyResponseOK(t, resp, err)
+		if len(resp.JSON200.Results) != 1 || resp.JSON200.Results[0].Id != commitIDs[2] {
+			t.Fatalf("Log by committer got %+v, expected commit %s", resp.JSON200.Results, commitIDs[2])
+		}
+
+		resp, err = clt.LogCommitsWithResponse(ctx, "repo4", "main", &api.LogCommitsParams{Metadata: &[]string{"job_id"}})
+		testutil.Must(t, err)
+		if resp.JSON400 == nil {
+			t.Fatalf("Log by invalid metadata expected 400, got %d", resp.StatusCode())
+		}
+	})
 }
 
 func TestController_GetCommitHandler(t *testing.T) {
@@ -259,7 +320,7 @@ func TestController_CommitHandler(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("commit non-existent commit", func(t *testing.T) {
-		resp, err := clt.CommitWithResponse(ctx, "foo1", "main", api.CommitJSONRequestBody{
+		resp, err := clt.CommitWithResponse(ctx, "foo1", "main", &api.CommitParams{}, api.CommitJSONRequestBody{
 			Message: "some message",
 		})
 		testutil.Must(t, err)
@@ -276,11 +337,32 @@ func TestController_CommitHandler(t *testing.T) {
 		_, err := deps.catalog.CreateRepository(ctx, "foo1", onBlock(deps, "foo1"), "main")
 		testutil.MustDo(t, "create repo foo1", err)
 		testutil.MustDo(t, "commit bar on foo1", deps.catalog.CreateEntry(ctx, "foo1", "main", catalog.DBEntry{Path: "foo/bar", PhysicalAddress: "pa", CreationDate: time.Now(), Size: 666, Checksum: "cs", Metadata: nil}))
-		resp, err := clt.CommitWithResponse(ctx, "foo1", "main", api.CommitJSONRequestBody{
+		resp, err := clt.CommitWithResponse(ctx, "foo1", "main", &api.CommitParams{}, api.CommitJSONRequestBody{
 			Message: "some message",
 		})
 		verifyResponseOK(t, resp, err)
 	})
+
+	t.Run("commit expected head", func(t *testing.T) {
+		branchResp, err := clt.GetBranchWithResponse(ctx, "foo1", "main", &api.GetBranchParams{})
+		verifyResponseOK(t, branchResp, err)
+		head := branchResp.JSON200.CommitId
+		testutil.MustDo(t, "create entry bar2 on foo1", deps.catalog.CreateEntry(ctx, "foo1", "main", catalog.DBEntry{Path: "foo/bar2", PhysicalAddress: "pa2", CreationDate: time.Now(), Size: 666, Checksum: "cs2", Metadata: nil}))
+		resp, err := clt.CommitWithResponse(ctx, "foo1", "main", &api.CommitParams{IfMatch: api.StringPtr(head)}, api.CommitJSONRequestBody{
+			Message: "expected head",
+		})
+		verifyResponseOK(t, resp, err)
+
+		// head moved by the previous commit
+		testutil.MustDo(t, "create entry bar3 on foo1", deps.catalog.CreateEntry(ctx, "foo1", "main", catalog.DBEntry{Path: "foo/bar3", PhysicalAddress: "pa3", CreationDate: time.Now(), Size: 666, Checksum: "cs3", Metadata: nil}))
+		resp, err = clt.CommitWithResponse(ctx, "foo1", "main", &api.CommitParams{IfMatch: api.StringPtr(head)}, api.CommitJSONRequestBody{
+			Message: "stale head",
+		})
+		testutil.Must(t, err)
+		if resp.StatusCode() != http.StatusPreconditionFailed {
+			t.Fatalf("Commit with stale head expected status %d, got %d", http.StatusPreconditionFailed, resp.StatusCode())
+		}
+	})
 }
 
 func TestController_CreateRepositoryHandler(t *testing.T) {
@@ -320,6 +402,263 @@ func TestController_CreateRepositoryHandler(t *testing.T) {
 	})
 }
 
+func TestController_ForkRepositoryHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+	sourceNamespace := onBlock(deps, "source-bucket")
+	_, err := deps.catalog.CreateRepository(ctx, "source-repo", sourceNamespace, "main")
+	testutil.Must(t, err)
+	err = deps.catalog.CreateEntry(ctx, "source-repo", "main", catalog.DBEntry{Path: "foo/bar", PhysicalAddress: "data/bar", AddressType: catalog.AddressTypeRelative, CreationDate: time.Now(), Size: 1, Checksum: "cksum"})
+	testutil.MustDo(t, "create entry", err)
+	_, err = deps.catalog.Commit(ctx, "source-repo", "main", "commit", "some_user", nil)
+	testutil.MustDo(t, "commit", err)
+	// uncommitted changes are not part of the fork
+	err = deps.catalog.CreateEntry(ctx, "source-repo", "main", catalog.DBEntry{Path: "foo/uncommitted", PhysicalAddress: "data/uncommitted", AddressType: catalog.AddressTypeRelative, CreationDate: time.Now(), Size: 1, Checksum: "cksum2"})
+	testutil.MustDo(t, "create uncommitted entry", err)
+
+	t.Run("fork repo success", func(t *testing.T) {
+		resp, err := clt.ForkRepositoryWithResponse(ctx, "source-repo", api.ForkRepositoryJSONRequestBody{
+			DefaultBranch:    api.StringPtr("fork"),
+			Name:             "forked-repo",
+			StorageNamespace: onBlock(deps, "fork-bucket"),
+		})
+		verifyResponseOK(t, resp, err)
+		if resp.JSON201.Id != "forked-repo" || resp.JSON201.DefaultBranch != "fork" {
+			t.Fatalf("got unexpected repo when forking: %+v", resp.JSON201)
+		}
+
+		entry, err := deps.catalog.GetEntry(ctx, "forked-repo", "fork", "foo/bar", catalog.GetEntryParams{})
+		testutil.MustDo(t, "get forked entry", err)
+		qk, err := block.ResolveNamespace(sourceNamespace, "data/bar", block.IdentifierTypeRelative)
+		testutil.Must(t, err)
+		if entry.AddressType != catalog.AddressTypeFull || entry.PhysicalAddress != qk.Format() {
+			t.Errorf("got forked entry address %s (type %d), expected full address %s", entry.PhysicalAddress, entry.AddressType, qk.Format())
+		}
+		_, err = deps.catalog.GetEntry(ctx, "forked-repo", "fork", "foo/uncommitted", catalog.GetEntryParams{})
+		if !errors.Is(err, catalog.ErrNotFound) {
+			t.Errorf("expected uncommitted entry not to be forked, got error %v", err)
+		}
+	})
+
+	t.Run("fork repo missing ref", func(t *testing.T) {
+		resp, err := clt.ForkRepositoryWithResponse(ctx, "source-repo", api.ForkRepositoryJSONRequestBody{
+			Name:             "forked-repo2",
+			StorageNamespace: onBlock(deps, "fork-bucket2"),
+			Ref:              api.StringPtr("no-such-ref"),
+		})
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("expected not found forking a missing ref, got status %d", resp.StatusCode())
+		}
+	})
+}
+
+func TestController_ExportRefHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+	namespace := onBlock(deps, "export-source")
+	_, err := deps.catalog.CreateRepository(ctx, "export-repo", namespace, "main")
+	testutil.Must(t, err)
+	const data = "bar"
+	err = deps.blocks.Put(ctx, block.ObjectPointer{StorageNamespace: namespace, Identifier: "data/bar", IdentifierType: block.IdentifierTypeRelative}, int64(len(data)), strings.NewReader(data), block.PutOpts{})
+	testutil.MustDo(t, "put object", err)
+	err = deps.catalog.CreateEntry(ctx, "export-repo", "main", catalog.DBEntry{Path: "foo/bar", PhysicalAddress: "data/bar", AddressType: catalog.AddressTypeRelative, CreationDate: time.Now(), Size: int64(len(data)), Checksum: "cksum"})
+	testutil.MustDo(t, "create entry", err)
+	_, err = deps.catalog.Commit(ctx, "export-repo", "main", "commit", "some_user", nil)
+	testutil.MustDo(t, "commit", err)
+	destination := onBlock(deps, "export-destination")
+	exported := func(path string) bool {
+		exists, err := deps.blocks.Exists(ctx, block.ObjectPointer{StorageNamespace: destination, Identifier: path, IdentifierType: block.IdentifierTypeRelative})
+		testutil.MustDo(t, "exists "+path, err)
+		return exists
+	}
+	waitExport := func(t *testing.T, resp *api.ExportRefResponse, err error) *api.ExportStatus {
+		t.Helper()
+		testutil.Must(t, err)
+		if resp.JSON202 == nil {
+			t.Fatalf("expected export to start, got status %d", resp.StatusCode())
+		}
+		status := resp.JSON202
+		for deadline := time.Now().Add(10 * time.Second); status.Status == "running"; {
+			if time.Now().After(deadline) {
+				t.Fatalf("export %s still running", status.ExportId)
+			}
+			time.Sleep(50 * time.Millisecond)
+			statusResp, err := clt.GetExportWithResponse(ctx, "export-repo", status.ExportId)
+			verifyResponseOK(t, statusResp, err)
+			status = statusResp.JSON200
+		}
+		if status.Status != "completed" {
+			t.Fatalf("export %s %s: %s", status.ExportId, status.Status, api.StringValue(status.Error))
+		}
+		return status
+	}
+
+	t.Run("export ref", func(t *testing.T) {
+		resp, err := clt.ExportRefWithResponse(ctx, "export-repo", "main", api.ExportRefJSONRequestBody{
+			Destination: destination,
+		})
+		status := waitExport(t, resp, err)
+		if status.Copied != 1 || status.PreviousCommitId != nil {
+			t.Fatalf("got unexpected export status: %+v", status)
+		}
+		if !exported("foo/bar") || !exported(export.SuccessMarker) {
+			t.Fatal("expected exported object and success marker")
+		}
+	})
+
+	t.Run("incremental export ref", func(t *testing.T) {
+		err := deps.catalog.DeleteEntry(ctx, "export-repo", "main", "foo/bar")
+		testutil.MustDo(t, "delete entry", err)
+		_, err = deps.catalog.Commit(ctx, "export-repo", "main", "delete", "some_user", nil)
+		testutil.MustDo(t, "commit delete", err)
+		resp, err := clt.ExportRefWithResponse(ctx, "export-repo", "main", api.ExportRefJSONRequestBody{
+			Destination: destination,
+			Incremental: api.BoolPtr(true),
+		})
+		status := waitExport(t, resp, err)
+		if status.Copied != 0 || status.Removed != 1 || status.PreviousCommitId == nil {
+			t.Fatalf("got unexpected incremental export status: %+v", status)
+		}
+		if exported("foo/bar") {
+			t.Fatal("expected removed object to be removed from the export")
+		}
+	})
+
+	t.Run("export to invalid destination", func(t *testing.T) {
+		resp, err := clt.ExportRefWithResponse(ctx, "export-repo", "main", api.ExportRefJSONRequestBody{
+			Destination: "no-scheme/prefix",
+		})
+		testutil.Must(t, err)
+		if resp.JSON400 == nil {
+			t.Fatalf("expected bad request exporting to an invalid destination, got status %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("export into repository namespace", func(t *testing.T) {
+		resp, err := clt.ExportRefWithResponse(ctx, "export-repo", "main", api.ExportRefJSONRequestBody{
+			Destination: namespace + "/exports",
+		})
+		testutil.Must(t, err)
+		if resp.JSON400 == nil {
+			t.Fatalf("expected bad request exporting into a repository namespace, got status %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("get missing export", func(t *testing.T) {
+		resp, err := clt.GetExportWithResponse(ctx, "export-repo", "no-such-export")
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("expected not found getting a missing export, got status %d", resp.StatusCode())
+		}
+	})
+}
+
+func TestController_RestoreRefsIncremental(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+	_, err := deps.catalog.CreateRepository(ctx, "restore-repo", onBlock(deps, "restore-bucket"), "main")
+	testutil.Must(t, err)
+	_, err = deps.catalog.CreateBranch(ctx, "restore-repo", "feature", "main")
+	testutil.MustDo(t, "create branch", err)
+	_, err = deps.catalog.CreateTag(ctx, "restore-repo", "v1", "main", nil)
+	testutil.MustDo(t, "create tag", err)
+	dump := func() api.RefsDump {
+		resp, err := clt.DumpRefsWithResponse(ctx, "restore-repo", &api.DumpRefsParams{})
+		testutil.Must(t, err)
+		if resp.JSON201 == nil {
+			t.Fatalf("dump refs, got status %d", resp.StatusCode())
+		}
+		return *resp.JSON201
+	}
+	restore := func(refs api.RefsDump) {
+		resp, err := clt.RestoreRefsWithResponse(ctx, "restore-repo", &api.RestoreRefsParams{Incremental: api.BoolPtr(true)}, api.RestoreRefsJSONRequestBody(refs))
+		testutil.Must(t, err)
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("restore refs, got status %d", resp.StatusCode())
+		}
+	}
+
+	// the branch and tag are deleted between the two dumps
+	firstDump := dump()
+	testutil.MustDo(t, "delete branch", deps.catalog.DeleteBranch(ctx, "restore-repo", "feature"))
+	testutil.MustDo(t, "delete tag", deps.catalog.DeleteTag(ctx, "restore-repo", "v1"))
+	secondDump := dump()
+
+	restore(firstDump)
+	exists, err := deps.catalog.BranchExists(ctx, "restore-repo", "feature")
+	testutil.MustDo(t, "branch exists after first restore", err)
+	if !exists {
+		t.Fatal("expected branch restored from the first dump")
+	}
+
+	restore(secondDump)
+	exists, err = deps.catalog.BranchExists(ctx, "restore-repo", "feature")
+	testutil.MustDo(t, "branch exists after second restore", err)
+	if exists {
+		t.Error("expected branch missing from the second dump to be deleted")
+	}
+	tagResp, err := clt.GetTagWithResponse(ctx, "restore-repo", "v1")
+	testutil.Must(t, err)
+	if tagResp.JSON404 == nil {
+		t.Errorf("expected tag missing from the second dump to be deleted, got status %d", tagResp.StatusCode())
+	}
+	exists, err = deps.catalog.BranchExists(ctx, "restore-repo", "main")
+	testutil.MustDo(t, "default branch exists", err)
+	if !exists {
+		t.Error("expected the default branch to be kept")
+	}
+}
+
+func TestController_UpdateRepositoryHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+	_, err := deps.catalog.CreateRepository(ctx, "my-repo", onBlock(deps, "foo1"), "master")
+	testutil.Must(t, err)
+	_, err = deps.catalog.CreateBranch(ctx, "my-repo", "main", "master")
+	testutil.Must(t, err)
+
+	t.Run("update repo settings", func(t *testing.T) {
+		resp, err := clt.UpdateRepositoryWithResponse(ctx, "my-repo", api.UpdateRepositoryJSONRequestBody{
+			DefaultBranch: api.StringPtr("main"),
+			Description:   api.StringPtr("curated datasets"),
+			Metadata:      &api.RepositoryUpdate_Metadata{AdditionalProperties: map[string]string{"team": "data"}},
+		})
+		verifyResponseOK(t, resp, err)
+		repo := resp.JSON200
+		if repo.DefaultBranch != "main" || api.StringValue(repo.Description) != "curated datasets" {
+			t.Fatalf("got unexpected repository settings: %+v", repo)
+		}
+		if repo.Metadata == nil || repo.Metadata.AdditionalProperties["team"] != "data" {
+			t.Fatalf("got unexpected repository metadata: %+v", repo.Metadata)
+		}
+	})
+
+	t.Run("rename repo", func(t *testing.T) {
+		resp, err := clt.UpdateRepositoryWithResponse(ctx, "my-repo", api.UpdateRepositoryJSONRequestBody{
+			Name: api.StringPtr("my-renamed-repo"),
+		})
+		verifyResponseOK(t, resp, err)
+		if resp.JSON200.Id != "my-renamed-repo" || resp.JSON200.DefaultBranch != "main" {
+			t.Fatalf("got unexpected renamed repository: %+v", resp.JSON200)
+		}
+		_, err = deps.catalog.GetRepository(ctx, "my-repo")
+		if !errors.Is(err, catalog.ErrNotFound) {
+			t.Fatalf("expected old repository name to be gone, got error: %v", err)
+		}
+	})
+
+	t.Run("missing default branch", func(t *testing.T) {
+		resp, err := clt.UpdateRepositoryWithResponse(ctx, "my-renamed-repo", api.UpdateRepositoryJSONRequestBody{
+			DefaultBranch: api.StringPtr("no-such-branch"),
+		})
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("expected not found setting a missing default branch, got status %d", resp.StatusCode())
+		}
+	})
+}
+
 func TestController_DeleteRepositoryHandler(t *testing.T) {
 	clt, deps := setupClientWithAdmin(t, "")
 	ctx := context.Background()
@@ -451,7 +790,7 @@ func TestController_ListTagsHandler(t *testing.T) {
 	commitLog, err := deps.catalog.Commit(ctx, "repo1", "main", "first commit", "test", nil)
 	testutil.Must(t, err)
 	const createTagLen = 7
-	var createdTags []api.Ref
+	var createdTags []api.Tag
 	for i := 0; i < createTagLen; i++ {
 		tagID := "tag" + strconv.Itoa(i)
 		commitID := commitLog.Reference
@@ -460,9 +799,10 @@ func TestController_ListTagsHandler(t *testing.T) {
 			Ref: commitID,
 		})
 		testutil.Must(t, err)
-		createdTags = append(createdTags, api.Ref{
-			Id:       tagID,
-			CommitId: commitID,
+		createdTags = append(createdTags, api.Tag{
+			Id:        tagID,
+			CommitId:  commitID,
+			Annotated: api.BoolPtr(false),
 		})
 	}
 
@@ -483,7 +823,7 @@ func TestController_ListTagsHandler(t *testing.T) {
 
 	t.Run("pagination", func(t *testing.T) {
 		const pageSize = 2
-		var results []api.Ref
+		var results []api.Tag
 		var after string
 		var calls int
 		for {
@@ -509,6 +849,25 @@ func TestController_ListTagsHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("annotated", func(t *testing.T) {
+		createResp, err := clt.CreateTagWithResponse(ctx, "repo1", api.CreateTagJSONRequestBody{
+			Id:       "annotated",
+			Ref:      commitLog.Reference,
+			Message:  api.StringPtr("release"),
+			Metadata: &api.TagCreation_Metadata{AdditionalProperties: map[string]string{"dataset": "v1"}},
+		})
+		verifyResponseOK(t, createResp, err)
+		resp, err := clt.GetTagWithResponse(ctx, "repo1", "annotated")
+		verifyResponseOK(t, resp, err)
+		tag := resp.JSON200
+		if !api.BoolValue(tag.Annotated) || api.StringValue(tag.Message) != "release" || api.StringValue(tag.Creator) == "" || tag.CreationDate == nil {
+			t.Fatalf("GetTag unexpected annotation: %+v", tag)
+		}
+		if diff := deep.Equal(tag.Metadata.AdditionalProperties, map[string]string{"dataset": "v1"}); diff != nil {
+			t.Fatal("GetTag metadata diff:", diff)
+		}
+	})
+
 	t.Run("no repository", func(t *testing.T) {
 		resp, err := clt.ListTagsWithResponse(ctx, "repo666", &api.ListTagsParams{})
 		testutil.Must(t, err)
@@ -531,7 +890,7 @@ func TestController_GetBranchHandler(t *testing.T) {
 		_, err = deps.catalog.Commit(ctx, "repo1", testBranch, "first commit", "test", nil)
 		testutil.Must(t, err)
 
-		resp, err := clt.GetBranchWithResponse(ctx, "repo1", testBranch)
+		resp, err := clt.GetBranchWithResponse(ctx, "repo1", testBranch, &api.GetBranchParams{})
 		verifyResponseOK(t, resp, err)
 		reference := resp.JSON200
 		if reference == nil || reference.CommitId == "" {
@@ -539,8 +898,42 @@ func TestController_GetBranchHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("get branch compared to another", func(t *testing.T) {
+		_, err := deps.catalog.CreateBranch(ctx, "repo1", "feature", "main")
+		testutil.Must(t, err)
+		for _, path := range []string{"feature/1", "feature/2"} {
+			testutil.Must(t, deps.catalog.CreateEntry(ctx, "repo1", "feature", catalog.DBEntry{Path: path}))
+			_, err = deps.catalog.Commit(ctx, "repo1", "feature", "commit "+path, "test", nil)
+			testutil.Must(t, err)
+		}
+		testutil.Must(t, deps.catalog.CreateEntry(ctx, "repo1", "main", catalog.DBEntry{Path: "main/1"}))
+		_, err = deps.catalog.Commit(ctx, "repo1", "main", "commit on main", "test", nil)
+		testutil.Must(t, err)
+
+		compareTo := api.CompareTo("main")
+		resp, err := clt.GetBranchWithResponse(ctx, "repo1", "feature", &api.GetBranchParams{CompareTo: &compareTo})
+		verifyResponseOK(t, resp, err)
+		if api.IntValue(resp.JSON200.Ahead) != 2 || api.IntValue(resp.JSON200.Behind) != 1 {
+			t.Errorf("GetBranch ahead=%d behind=%d, expected 2 and 1", api.IntValue(resp.JSON200.Ahead), api.IntValue(resp.JSON200.Behind))
+		}
+
+		listResp, err := clt.ListBranchesWithResponse(ctx, "repo1", &api.ListBranchesParams{CompareTo: &compareTo})
+		verifyResponseOK(t, listResp, err)
+		for _, ref := range listResp.JSON200.Results {
+			if ref.Ahead == nil || ref.Behind == nil {
+				t.Errorf("ListBranches missing ahead/behind for %s", ref.Id)
+			}
+		}
+
+		aheadBehindResp, err := clt.AheadBehindRefsWithResponse(ctx, "repo1", "main", "feature")
+		verifyResponseOK(t, aheadBehindResp, err)
+		if aheadBehindResp.JSON200.Ahead != 1 || aheadBehindResp.JSON200.Behind != 2 {
+			t.Errorf("AheadBehindRefs=%+v, expected ahead 1 and behind 2", aheadBehindResp.JSON200)
+		}
+	})
+
 	t.Run("get missing branch", func(t *testing.T) {
-		resp, err := clt.GetBranchWithResponse(ctx, "repo1", "main333")
+		resp, err := clt.GetBranchWithResponse(ctx, "repo1", "main333", &api.GetBranchParams{})
 		if err != nil {
 			t.Fatal("GetBranch error", err)
 		}
@@ -550,7 +943,7 @@ func TestController_GetBranchHandler(t *testing.T) {
 	})
 
 	t.Run("get branch for missing repo", func(t *testing.T) {
-		resp, err := clt.GetBranchWithResponse(ctx, "repo3", "main")
+		resp, err := clt.GetBranchWithResponse(ctx, "repo3", "main", &api.GetBranchParams{})
 		if err != nil {
 			t.Fatal("GetBranch error", err)
 		}
@@ -593,6 +986,30 @@ func TestController_BranchesDiffBranchHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("diff branch with prefix and delimiter", func(t *testing.T) {
+		testutil.Must(t, deps.catalog.CreateEntry(ctx, "repo1", testBranch, catalog.DBEntry{Path: "a/c/d"}))
+		testutil.Must(t, deps.catalog.CreateEntry(ctx, "repo1", testBranch, catalog.DBEntry{Path: "a/c/e"}))
+		testutil.Must(t, deps.catalog.CreateEntry(ctx, "repo1", testBranch, catalog.DBEntry{Path: "b/f"}))
+		resp, err := clt.DiffBranchWithResponse(ctx, "repo1", testBranch, &api.DiffBranchParams{
+			Prefix:    api.PaginationPrefixPtr("a/"),
+			Delimiter: api.StringPtr("/"),
+		})
+		verifyResponseOK(t, resp, err)
+		results := resp.JSON200.Results
+		if len(results) != 2 {
+			t.Fatalf("expected 2 diff results, got %d", len(results))
+		}
+		if results[0].Path != "a/b" || results[0].PathType != "object" {
+			t.Errorf("got wrong diff object, expected object a/b, got %s %s", results[0].PathType, results[0].Path)
+		}
+		if results[1].Path != "a/c/" || results[1].PathType != "common_prefix" {
+			t.Errorf("got wrong diff object, expected common_prefix a/c/, got %s %s", results[1].PathType, results[1].Path)
+		}
+		if results[1].Count == nil || results[1].Count.Added != 2 {
+			t.Errorf("expected 2 added under a/c/, got %+v", results[1].Count)
+		}
+	})
+
 	t.Run("diff branch that doesn't exist", func(t *testing.T) {
 		resp, err := clt.DiffBranchWithResponse(ctx, "repo1", "some-other-missing-branch", &api.DiffBranchParams{})
 		if err != nil {
@@ -815,6 +1232,41 @@ func TestController_UploadObject(t *testing.T) {
 			t.Fatalf("expected 412 for UploadObject, got %d", b.StatusCode())
 		}
 	})
+
+	t.Run("overwrite with if-match", func(t *testing.T) {
+		contentType, buf := writeMultipart("content", "baz4", "hello world!")
+		b, err := clt.UploadObjectWithBodyWithResponse(ctx, "my-new-repo", "main", &api.UploadObjectParams{
+			Path: "foo/baz4",
+		}, contentType, buf)
+		testutil.Must(t, err)
+		if b.StatusCode() != 201 {
+			t.Fatalf("expected 201 for UploadObject, got %d", b.StatusCode())
+		}
+		current := "\"" + b.JSON201.Checksum + "\""
+
+		// overwrite with a stale checksum
+		stale := "\"not-the-checksum\""
+		contentType, buf = writeMultipart("content", "baz4", "something else!")
+		b, err = clt.UploadObjectWithBodyWithResponse(ctx, "my-new-repo", "main", &api.UploadObjectParams{
+			Path:    "foo/baz4",
+			IfMatch: &stale,
+		}, contentType, buf)
+		testutil.Must(t, err)
+		if b.StatusCode() != 412 {
+			t.Fatalf("expected 412 for UploadObject with stale If-Match, got %d", b.StatusCode())
+		}
+
+		// overwrite with the current checksum
+		contentType, buf = writeMultipart("content", "baz4", "something else!")
+		b, err = clt.UploadObjectWithBodyWithResponse(ctx, "my-new-repo", "main", &api.UploadObjectParams{
+			Path:    "foo/baz4",
+			IfMatch: &current,
+		}, contentType, buf)
+		testutil.Must(t, err)
+		if b.StatusCode() != 201 {
+			t.Fatalf("expected 201 for UploadObject with matching If-Match, got %d", b.StatusCode())
+		}
+	})
 }
 
 func TestController_DeleteBranchHandler(t *testing.T) {
@@ -964,6 +1416,54 @@ func TestController_ObjectsListObjectsHandler(t *testing.T) {
 	})
 }
 
+func TestController_ObjectsBlameObjectsHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+
+	_, err := deps.catalog.CreateRepository(ctx, "repo1", onBlock(deps, "bucket/prefix"), "main")
+	testutil.Must(t, err)
+	commitEntry := func(path, checksum, committer string) string {
+		t.Helper()
+		testutil.MustDo(t, "create entry "+path, deps.catalog.CreateEntry(ctx, "repo1", "main", catalog.DBEntry{Path: path, PhysicalAddress: "address_" + checksum, CreationDate: time.Now(), Size: 1, Checksum: checksum}))
+		commit, err := deps.catalog.Commit(ctx, "repo1", "main", "change "+path, committer, nil)
+		testutil.MustDo(t, "commit "+path, err)
+		return commit.Reference
+	}
+	commitBar := commitEntry("foo/bar", "bar1", "user1")
+	commitEntry("foo/baz", "baz1", "user1")
+	commitBaz := commitEntry("foo/baz", "baz2", "user2")
+	commitEntry("other/qux", "qux1", "user1")
+
+	t.Run("blame prefix", func(t *testing.T) {
+		resp, err := clt.BlameObjectsWithResponse(ctx, "repo1", "main", &api.BlameObjectsParams{
+			Prefix: api.StringPtr("foo/"),
+		})
+		verifyResponseOK(t, resp, err)
+		expected := []api.ObjectBlame{
+			{Path: "foo/bar", CommitId: commitBar, Committer: "user1"},
+			{Path: "foo/baz", CommitId: commitBaz, Committer: "user2"},
+		}
+		results := resp.JSON200.Results
+		for i := range results {
+			results[i].CreationDate = 0
+		}
+		if diff := deep.Equal(results, expected); diff != nil {
+			t.Fatalf("BlameObjects unexpected results: %s", diff)
+		}
+	})
+
+	t.Run("blame paginated", func(t *testing.T) {
+		resp, err := clt.BlameObjectsWithResponse(ctx, "repo1", "main", &api.BlameObjectsParams{
+			Prefix: api.StringPtr("foo/"),
+			Amount: api.PaginationAmountPtr(1),
+		})
+		verifyResponseOK(t, resp, err)
+		if len(resp.JSON200.Results) != 1 || !resp.JSON200.Pagination.HasMore || resp.JSON200.Pagination.NextOffset != "foo/bar" {
+			t.Fatalf("BlameObjects unexpected page: %+v", resp.JSON200)
+		}
+	})
+}
+
 func TestController_ObjectsGetObjectHandler(t *testing.T) {
 	clt, deps := setupClientWithAdmin(t, "")
 	ctx := context.Background()
@@ -1154,6 +1654,73 @@ func TestController_ObjectsStageObjectHandler(t *testing.T) {
 	})
 }
 
+func TestController_ApplyStagingTransaction(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t, "s3")
+	ctx := context.Background()
+
+	_, err := deps.catalog.CreateRepository(ctx, "repo1", onBlock(deps, "bucket/prefix"), "main")
+	testutil.Must(t, err)
+	stageResp, err := clt.StageObjectWithResponse(ctx, "repo1", "main", &api.StageObjectParams{Path: "foo/old"}, api.StageObjectJSONRequestBody{
+		Checksum:        "afb0689fe58b82c5f762991453edbbec",
+		PhysicalAddress: onBlock(deps, "another-bucket/old"),
+		SizeBytes:       38,
+	})
+	verifyResponseOK(t, stageResp, err)
+
+	t.Run("put link and delete", func(t *testing.T) {
+		resp, err := clt.ApplyStagingTransactionWithResponse(ctx, "repo1", "main", api.ApplyStagingTransactionJSONRequestBody{
+			Operations: []api.StagingTransactionOperation{
+				{Op: "put", Path: "foo/new", Staging: &api.ObjectStageCreation{
+					Checksum:        "b10a8db164e0754105b7a99be72e3fe5",
+					PhysicalAddress: onBlock(deps, "another-bucket/new"),
+					SizeBytes:       11,
+				}},
+				{Op: "link", Path: "foo/linked", SourcePath: api.StringPtr("foo/old")},
+				{Op: "delete", Path: "foo/old"},
+			},
+		})
+		verifyResponseOK(t, resp, err)
+
+		statResp, err := clt.StatObjectWithResponse(ctx, "repo1", "main", &api.StatObjectParams{Path: "foo/new"})
+		verifyResponseOK(t, statResp, err)
+		statResp, err = clt.StatObjectWithResponse(ctx, "repo1", "main", &api.StatObjectParams{Path: "foo/linked"})
+		verifyResponseOK(t, statResp, err)
+		if statResp.JSON200.Checksum != "afb0689fe58b82c5f762991453edbbec" {
+			t.Fatalf("linked object checksum %s, expected the source checksum", statResp.JSON200.Checksum)
+		}
+		statResp, err = clt.StatObjectWithResponse(ctx, "repo1", "main", &api.StatObjectParams{Path: "foo/old"})
+		testutil.Must(t, err)
+		if statResp.JSON404 == nil {
+			t.Fatalf("deleted object expected not found, got status %d", statResp.StatusCode())
+		}
+	})
+
+	t.Run("failed operation stages nothing", func(t *testing.T) {
+		resp, err := clt.ApplyStagingTransactionWithResponse(ctx, "repo1", "main", api.ApplyStagingTransactionJSONRequestBody{
+			Operations: []api.StagingTransactionOperation{
+				{Op: "delete", Path: "foo/new"},
+				{Op: "link", Path: "foo/linked2", SourcePath: api.StringPtr("foo/missing")},
+			},
+		})
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("missing link source expected not found, got status %d", resp.StatusCode())
+		}
+		statResp, err := clt.StatObjectWithResponse(ctx, "repo1", "main", &api.StatObjectParams{Path: "foo/new"})
+		verifyResponseOK(t, statResp, err)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		resp, err := clt.ApplyStagingTransactionWithResponse(ctx, "repo1", "main", api.ApplyStagingTransactionJSONRequestBody{
+			Operations: []api.StagingTransactionOperation{{Op: "copy", Path: "foo/new"}},
+		})
+		testutil.Must(t, err)
+		if resp.StatusCode() != http.StatusBadRequest {
+			t.Fatalf("unknown operation expected status %d, got %d", http.StatusBadRequest, resp.StatusCode())
+		}
+	})
+}
+
 func TestController_ObjectsDeleteObjectHandler(t *testing.T) {
 	clt, deps := setupClientWithAdmin(t, "")
 	ctx := context.Background()
@@ -1448,7 +2015,7 @@ func TestController_ListRepositoryRuns(t *testing.T) {
 	uploadResp, err := uploadObjectHelper(t, ctx, clt, "_lakefs_actions/pre_commit.yaml", strings.NewReader(actionContent), "repo9", "main")
 	verifyResponseOK(t, uploadResp, err)
 	// commit
-	respCommit, err := clt.CommitWithResponse(ctx, "repo9", "main", api.CommitJSONRequestBody{
+	respCommit, err := clt.CommitWithResponse(ctx, "repo9", "main", &api.CommitParams{}, api.CommitJSONRequestBody{
 		Message: "pre-commit action",
 	})
 	verifyResponseOK(t, respCommit, err)
@@ -1465,7 +2032,7 @@ func TestController_ListRepositoryRuns(t *testing.T) {
 		content := fmt.Sprintf("content-%d", i)
 		uploadResp, err := uploadObjectHelper(t, ctx, clt, content, strings.NewReader(content), "repo9", "work")
 		verifyResponseOK(t, uploadResp, err)
-		respCommit, err := clt.CommitWithResponse(ctx, "repo9", "work", api.CommitJSONRequestBody{Message: content})
+		respCommit, err := clt.CommitWithResponse(ctx, "repo9", "work", &api.CommitParams{}, api.CommitJSONRequestBody{Message: content})
 		verifyResponseOK(t, respCommit, err)
 		commitIDs = append(commitIDs, respCommit.JSON201.Id)
 	}
@@ -1529,10 +2096,10 @@ func TestController_MergeDiffWithParent(t *testing.T) {
 	resp, err := uploadObjectHelper(t, ctx, clt, "file1", strings.NewReader(content), repoName, "work")
 	verifyResponseOK(t, resp, err)
 
-	commitResp, err := clt.CommitWithResponse(ctx, repoName, "work", api.CommitJSONRequestBody{Message: "file 1 commit to work"})
+	commitResp, err := clt.CommitWithResponse(ctx, repoName, "work", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "file 1 commit to work"})
 	verifyResponseOK(t, commitResp, err)
 
-	mergeResp, err := clt.MergeIntoBranchWithResponse(ctx, repoName, "work", "main", api.MergeIntoBranchJSONRequestBody{
+	mergeResp, err := clt.MergeIntoBranchWithResponse(ctx, repoName, "work", "main", &api.MergeIntoBranchParams{}, api.MergeIntoBranchJSONRequestBody{
 		Message: api.StringPtr("merge work to main"),
 	})
 	verifyResponseOK(t, mergeResp, err)
@@ -1546,3 +2113,279 @@ func TestController_MergeDiffWithParent(t *testing.T) {
 		t.Fatal("Diff results not as expected:", diff)
 	}
 }
+
+func TestController_DiffRefsSummary(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+
+	const repoName = "repo-summary"
+	repoResp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repoName,
+		StorageNamespace: "mem://",
+	})
+	verifyResponseOK(t, repoResp, err)
+
+	branchResp, err := clt.CreateBranchWithResponse(ctx, repoName, api.CreateBranchJSONRequestBody{Name: "work", Source: "main"})
+	verifyResponseOK(t, branchResp, err)
+
+	for _, path := range []string{"a/file1", "a/file2", "b/file3"} {
+		resp, err := uploadObjectHelper(t, ctx, clt, path, strings.NewReader("content of "+path), repoName, "work")
+		verifyResponseOK(t, resp, err)
+	}
+	commitResp, err := clt.CommitWithResponse(ctx, repoName, "work", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "add files"})
+	verifyResponseOK(t, commitResp, err)
+
+	t.Run("all", func(t *testing.T) {
+		resp, err := clt.DiffRefsSummaryWithResponse(ctx, repoName, "main", "work", &api.DiffRefsSummaryParams{})
+		verifyResponseOK(t, resp, err)
+		if resp.JSON200.Added != 3 || resp.JSON200.Removed != 0 || resp.JSON200.Changed != 0 {
+			t.Errorf("unexpected summary %+v, expected 3 added", resp.JSON200)
+		}
+		if resp.JSON200.AddedBytes <= 0 {
+			t.Errorf("expected added bytes, got %d", resp.JSON200.AddedBytes)
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		resp, err := clt.DiffRefsSummaryWithResponse(ctx, repoName, "main", "work", &api.DiffRefsSummaryParams{
+			Prefix: api.PaginationPrefixPtr("a/"),
+		})
+		verifyResponseOK(t, resp, err)
+		if resp.JSON200.Added != 2 {
+			t.Errorf("unexpected summary %+v, expected 2 added", resp.JSON200)
+		}
+	})
+
+	t.Run("reversed", func(t *testing.T) {
+		resp, err := clt.DiffRefsSummaryWithResponse(ctx, repoName, "work", "main", &api.DiffRefsSummaryParams{})
+		verifyResponseOK(t, resp, err)
+		if resp.JSON200.Removed != 3 || resp.JSON200.Added != 0 {
+			t.Errorf("unexpected summary %+v, expected 3 removed", resp.JSON200)
+		}
+	})
+}
+
+func TestController_CherryPick(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+
+	const repoName = "repo8"
+	repoResp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repoName,
+		StorageNamespace: "mem://",
+	})
+	verifyResponseOK(t, repoResp, err)
+
+	branchResp, err := clt.CreateBranchWithResponse(ctx, repoName, api.CreateBranchJSONRequestBody{Name: "dev", Source: "main"})
+	verifyResponseOK(t, branchResp, err)
+
+	resp, err := uploadObjectHelper(t, ctx, clt, "file1", strings.NewReader("first"), repoName, "dev")
+	verifyResponseOK(t, resp, err)
+	commitResp, err := clt.CommitWithResponse(ctx, repoName, "dev", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "file 1 commit to dev"})
+	verifyResponseOK(t, commitResp, err)
+
+	resp, err = uploadObjectHelper(t, ctx, clt, "file2", strings.NewReader("fix"), repoName, "dev")
+	verifyResponseOK(t, resp, err)
+	commitResp, err = clt.CommitWithResponse(ctx, repoName, "dev", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "file 2 commit to dev"})
+	verifyResponseOK(t, commitResp, err)
+	fixCommitID := commitResp.JSON201.Id
+
+	t.Run("cherry-pick", func(t *testing.T) {
+		cherryPickResp, err := clt.CherryPickWithResponse(ctx, repoName, "main", api.CherryPickJSONRequestBody{Ref: fixCommitID})
+		verifyResponseOK(t, cherryPickResp, err)
+
+		diffResp, err := clt.DiffRefsWithResponse(ctx, repoName, "main~1", "main", &api.DiffRefsParams{})
+		verifyResponseOK(t, diffResp, err)
+		expectedResults := []api.Diff{
+			{Path: "file2", PathType: "object", Type: "added"},
+		}
+		if diff := deep.Equal(diffResp.JSON200.Results, expectedResults); diff != nil {
+			t.Fatal("Diff results not as expected:", diff)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		resp, err := uploadObjectHelper(t, ctx, clt, "file2", strings.NewReader("other fix"), repoName, "main")
+		verifyResponseOK(t, resp, err)
+		commitResp, err := clt.CommitWithResponse(ctx, repoName, "main", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "file 2 changed on main"})
+		verifyResponseOK(t, commitResp, err)
+
+		cherryPickResp, err := clt.CherryPickWithResponse(ctx, repoName, "main", api.CherryPickJSONRequestBody{Ref: fixCommitID})
+		testutil.Must(t, err)
+		if cherryPickResp.JSON409 == nil {
+			t.Fatalf("CherryPick expected conflict, got status %d", cherryPickResp.StatusCode())
+		}
+	})
+}
+
+func TestController_MergeConflicts(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+
+	const repoName = "repo9"
+	repoResp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repoName,
+		StorageNamespace: "mem://",
+	})
+	verifyResponseOK(t, repoResp, err)
+
+	branchResp, err := clt.CreateBranchWithResponse(ctx, repoName, api.CreateBranchJSONRequestBody{Name: "dev", Source: "main"})
+	verifyResponseOK(t, branchResp, err)
+
+	for _, branch := range []string{"main", "dev"} {
+		for _, path := range []string{"file1", "file2", "file3"} {
+			resp, err := uploadObjectHelper(t, ctx, clt, path, strings.NewReader(branch+" "+path), repoName, branch)
+			verifyResponseOK(t, resp, err)
+		}
+		commitResp, err := clt.CommitWithResponse(ctx, repoName, branch, &api.CommitParams{}, api.CommitJSONRequestBody{Message: "files on " + branch})
+		verifyResponseOK(t, commitResp, err)
+	}
+
+	mergeResp, err := clt.MergeIntoBranchWithResponse(ctx, repoName, "dev", "main", &api.MergeIntoBranchParams{}, api.MergeIntoBranchJSONRequestBody{})
+	testutil.Must(t, err)
+	if mergeResp.JSON409 == nil {
+		t.Fatalf("Merge expected conflict, got status %d", mergeResp.StatusCode())
+	}
+	conflicts := mergeResp.JSON409.Conflicts
+	if conflicts == nil || len(conflicts.Results) != 3 || conflicts.Pagination.HasMore {
+		t.Fatalf("Merge conflicts expected 3 conflicts, got %+v", conflicts)
+	}
+	for i, conflict := range conflicts.Results {
+		expectedPath := fmt.Sprintf("file%d", i+1)
+		if conflict.Path != expectedPath || conflict.SourceType != "added" || conflict.DestinationType != "added" {
+			t.Errorf("Conflict %d expected added on both sides of %s, got %+v", i, expectedPath, conflict)
+		}
+		if conflict.Source == nil || conflict.Destination == nil || conflict.Base != nil {
+			t.Errorf("Conflict %d expected source and destination values without base, got %+v", i, conflict)
+		}
+	}
+
+	listResp, err := clt.ListMergeConflictsWithResponse(ctx, repoName, "dev", "main", &api.ListMergeConflictsParams{
+		After:  api.PaginationAfterPtr("file1"),
+		Amount: api.PaginationAmountPtr(1),
+	})
+	verifyResponseOK(t, listResp, err)
+	if len(listResp.JSON200.Results) != 1 || listResp.JSON200.Results[0].Path != "file2" || !listResp.JSON200.Pagination.HasMore {
+		t.Fatalf("ListMergeConflicts expected file2 with more results, got %+v", listResp.JSON200)
+	}
+}
+
+func TestController_RetentionRules(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+
+	const repoName = "repo10"
+	repoResp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repoName,
+		StorageNamespace: "mem://",
+	})
+	verifyResponseOK(t, repoResp, err)
+
+	getResp, err := clt.GetRetentionRulesWithResponse(ctx, repoName)
+	verifyResponseOK(t, getResp, err)
+	if len(getResp.JSON200.Rules) != 0 {
+		t.Fatalf("GetRetentionRules expected no rules, got %+v", getResp.JSON200.Rules)
+	}
+
+	badResp, err := clt.SetRetentionRulesWithResponse(ctx, repoName, api.SetRetentionRulesJSONRequestBody{
+		Rules: []api.RetentionRule{{BranchPattern: "[", RetentionDays: 1}},
+	})
+	testutil.Must(t, err)
+	if badResp.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("SetRetentionRules with bad pattern expected status %d, got %d", http.StatusBadRequest, badResp.StatusCode())
+	}
+
+	rules := []api.RetentionRule{
+		{BranchPattern: "scratch/*", RetentionDays: 0},
+		{BranchPattern: "main", RetentionDays: 30},
+	}
+	setResp, err := clt.SetRetentionRulesWithResponse(ctx, repoName, api.SetRetentionRulesJSONRequestBody{Rules: rules})
+	verifyResponseOK(t, setResp, err)
+	getResp, err = clt.GetRetentionRulesWithResponse(ctx, repoName)
+	verifyResponseOK(t, getResp, err)
+	if diff := deep.Equal(getResp.JSON200.Rules, rules); diff != nil {
+		t.Fatalf("GetRetentionRules diff found %s", diff)
+	}
+
+	// an object overwritten on a scratch branch expires with the commit that referenced it
+	branchResp, err := clt.CreateBranchWithResponse(ctx, repoName, api.CreateBranchJSONRequestBody{Name: "scratch/x", Source: "main"})
+	verifyResponseOK(t, branchResp, err)
+	var commitIDs []string
+	for _, content := range []string{"first", "second"} {
+		uploadResp, err := uploadObjectHelper(t, ctx, clt, "file1", strings.NewReader(content), repoName, "scratch/x")
+		verifyResponseOK(t, uploadResp, err)
+		commitResp, err := clt.CommitWithResponse(ctx, repoName, "scratch/x", &api.CommitParams{}, api.CommitJSONRequestBody{Message: content})
+		verifyResponseOK(t, commitResp, err)
+		commitIDs = append(commitIDs, commitResp.JSON201.Id)
+	}
+	expired, err := deps.catalog.EnforceRetention(ctx, repoName)
+	testutil.Must(t, err)
+	if expired != 1 {
+		t.Fatalf("EnforceRetention expected 1 expired object, got %d", expired)
+	}
+
+	oldResp, err := clt.GetObjectWithResponse(ctx, repoName, commitIDs[0], &api.GetObjectParams{Path: "file1"})
+	testutil.Must(t, err)
+	if oldResp.StatusCode() != http.StatusGone {
+		t.Errorf("GetObject on expired commit expected status %d, got %d", http.StatusGone, oldResp.StatusCode())
+	}
+	headResp, err := clt.GetObjectWithResponse(ctx, repoName, "scratch/x", &api.GetObjectParams{Path: "file1"})
+	verifyResponseOK(t, headResp, err)
+}
+
+func TestController_BranchProtection(t *testing.T) {
+	clt, _ := setupClientWithAdmin(t, "")
+	ctx := context.Background()
+
+	const repoName = "repo11"
+	repoResp, err := clt.CreateRepositoryWithResponse(ctx, &api.CreateRepositoryParams{}, api.CreateRepositoryJSONRequestBody{
+		DefaultBranch:    api.StringPtr("main"),
+		Name:             repoName,
+		StorageNamespace: "mem://",
+	})
+	verifyResponseOK(t, repoResp, err)
+	branchResp, err := clt.CreateBranchWithResponse(ctx, repoName, api.CreateBranchJSONRequestBody{Name: "feature", Source: "main"})
+	verifyResponseOK(t, branchResp, err)
+
+	createResp, err := clt.CreateBranchProtectionRuleWithResponse(ctx, repoName, api.CreateBranchProtectionRuleJSONRequestBody{Pattern: "main"})
+	verifyResponseOK(t, createResp, err)
+	dupResp, err := clt.CreateBranchProtectionRuleWithResponse(ctx, repoName, api.CreateBranchProtectionRuleJSONRequestBody{Pattern: "main"})
+	testutil.Must(t, err)
+	if dupResp.StatusCode() != http.StatusConflict {
+		t.Fatalf("CreateBranchProtectionRule duplicate expected status %d, got %d", http.StatusConflict, dupResp.StatusCode())
+	}
+	listResp, err := clt.ListBranchProtectionRulesWithResponse(ctx, repoName)
+	verifyResponseOK(t, listResp, err)
+	expectedRules := []api.BranchProtectionRule{{Pattern: "main", RequirePassingRun: api.BoolPtr(false)}}
+	if diff := deep.Equal(*listResp.JSON200, expectedRules); diff != nil {
+		t.Fatalf("ListBranchProtectionRules diff found %s", diff)
+	}
+
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "file1", strings.NewReader("data"), repoName, "main")
+	testutil.Must(t, err)
+	if uploadResp.StatusCode() != http.StatusForbidden {
+		t.Fatalf("UploadObject to protected branch expected status %d, got %d", http.StatusForbidden, uploadResp.StatusCode())
+	}
+	deleteBranchResp, err := clt.DeleteBranchWithResponse(ctx, repoName, "main")
+	testutil.Must(t, err)
+	if deleteBranchResp.StatusCode() != http.StatusForbidden {
+		t.Fatalf("DeleteBranch of protected branch expected status %d, got %d", http.StatusForbidden, deleteBranchResp.StatusCode())
+	}
+
+	// protected branches change only by merge
+	uploadResp, err = uploadObjectHelper(t, ctx, clt, "file1", strings.NewReader("data"), repoName, "feature")
+	verifyResponseOK(t, uploadResp, err)
+	commitResp, err := clt.CommitWithResponse(ctx, repoName, "feature", &api.CommitParams{}, api.CommitJSONRequestBody{Message: "feature"})
+	verifyResponseOK(t, commitResp, err)
+	mergeResp, err := clt.MergeIntoBranchWithResponse(ctx, repoName, "feature", "main", &api.MergeIntoBranchParams{}, api.MergeIntoBranchJSONRequestBody{})
+	verifyResponseOK(t, mergeResp, err)
+
+	deleteResp, err := clt.DeleteBranchProtectionRuleWithResponse(ctx, repoName, &api.DeleteBranchProtectionRuleParams{Pattern: "main"})
+	verifyResponseOK(t, deleteResp, err)
+	uploadResp, err = uploadObjectHelper(t, ctx, clt, "file2", strings.NewReader("data"), repoName, "main")
+	verifyResponseOK(t, uploadResp, err)
+}
@@ -45,6 +45,7 @@ func Serve(
 	collector stats.Collector,
 	cloudMetadataProvider cloud.MetadataProvider,
 	actions actionsHandler,
+	exports exportsHandler,
 	logger logging.Logger,
 	gatewayDomains []string,
 ) http.Handler {
@@ -72,6 +73,7 @@ func Serve(
 		collector,
 		cloudMetadataProvider,
 		actions,
+		exports,
 		logger,
 	)
 	HandlerFromMuxWithBaseURL(controller, apiRouter, BaseURL)
@@ -18,3 +18,41 @@ func transformDifferenceTypeToString(d catalog.DifferenceType) string {
 		return ""
 	}
 }
+
+func newDiffResults(diff catalog.Differences) []Diff {
+	results := make([]Diff, 0, len(diff))
+	for _, d := range diff {
+		result := Diff{
+			Path:     d.Path,
+			Type:     transformDifferenceTypeToString(d.Type),
+			PathType: "object",
+		}
+		if d.CommonLevel {
+			result.PathType = "common_prefix"
+			result.Count = &DiffCount{
+				Added:    d.Count[catalog.DifferenceTypeAdded],
+				Removed:  d.Count[catalog.DifferenceTypeRemoved],
+				Changed:  d.Count[catalog.DifferenceTypeChanged],
+				Conflict: d.Count[catalog.DifferenceTypeConflict],
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func newRepository(repo *catalog.Repository) Repository {
+	r := Repository{
+		CreationDate:     repo.CreationDate.Unix(),
+		DefaultBranch:    repo.DefaultBranch,
+		Id:               repo.Name,
+		StorageNamespace: repo.StorageNamespace,
+	}
+	if repo.Description != "" {
+		r.Description = StringPtr(repo.Description)
+	}
+	if repo.Metadata != nil {
+		r.Metadata = &Repository_Metadata{AdditionalProperties: repo.Metadata}
+	}
+	return r
+}
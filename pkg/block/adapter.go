@@ -2,8 +2,10 @@ package block
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/s3"
 )
@@ -74,13 +76,34 @@ type Properties struct {
 	StorageClass *string
 }
 
+// ObjectStoreEntry describes an object visited by Walk
+type ObjectStoreEntry struct {
+	// FullKey represents the fully qualified path in the object store namespace for the given entry
+	FullKey string
+	// RelativeKey represents a path relative to the walked storage namespace. The walk prefix is part of it.
+	RelativeKey string
+	// Address is a full URI for the entry, including the storage namespace (i.e. s3://bucket/path/to/key)
+	Address string
+	// ETag represents a hash of the entry's content. Generally as hex encoded MD5,
+	// but depends on the underlying object store
+	ETag string
+	// Mtime is the last-modified datetime of the entry
+	Mtime time.Time
+	// Size in bytes
+	Size int64
+}
+
+func (e ObjectStoreEntry) String() string {
+	return fmt.Sprintf("ObjectStoreEntry: {Address:%s, RelativeKey:%s, ETag:%s, Size:%d, Mtime:%s}",
+		e.Address, e.RelativeKey, e.ETag, e.Size, e.Mtime)
+}
+
 // WalkFunc is called for each object visited by the Walk.
-// The id argument contains the argument to Walk as a prefix; that is, if Walk is called with "test/data/",
-// which is a prefix containing the object "test/data/a", the walk function will be called with argument "test/data/a".
-// If there was a problem walking to the object, the incoming error will describe the problem and the function can decide
-// how to handle that error.
-// If an error is returned, processing stops.
-type WalkFunc func(id string) error
+// The entry describes an object under the walked prefix; that is, if Walk is called with storage namespace
+// "s3://bucket/repo" and prefix "test/data/", which contains the object "test/data/a", the walk function will be
+// called with an entry with RelativeKey "test/data/a" and Address "s3://bucket/repo/test/data/a".
+// Objects are visited in lexicographic order of their keys.  If an error is returned, processing stops.
+type WalkFunc func(e ObjectStoreEntry) error
 
 type Adapter interface {
 	InventoryGenerator
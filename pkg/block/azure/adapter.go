@@ -209,18 +209,35 @@ func (a *Adapter) Walk(ctx context.Context, walkOpt block.WalkOpts, walkFn block
 	if err != nil {
 		return err
 	}
+	qualifiedNamespace, err := resolveNamespacePrefix(block.WalkOpts{StorageNamespace: walkOpt.StorageNamespace})
+	if err != nil {
+		return err
+	}
+	// a namespace without a path resolves to a prefix starting with a slash, blob names never do
+	prefix := strings.TrimPrefix(qualifiedPrefix.Prefix, "/")
+	namespacePrefix := strings.TrimPrefix(qualifiedNamespace.Prefix, "/")
 
 	containerURL := a.getContainerURL(qualifiedPrefix.ContainerURL)
 
 	for marker := (azblob.Marker{}); marker.NotDone(); {
-		listBlob, err := containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{Prefix: qualifiedPrefix.Prefix})
+		listBlob, err := containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{Prefix: prefix})
 		if err != nil {
 			return err
 		}
 
 		marker = listBlob.NextMarker
 		for _, blobInfo := range listBlob.Segment.BlobItems {
-			if err := walkFn(blobInfo.Name); err != nil {
+			e := block.ObjectStoreEntry{
+				FullKey:     blobInfo.Name,
+				RelativeKey: strings.TrimPrefix(strings.TrimPrefix(blobInfo.Name, namespacePrefix), "/"),
+				Address:     qualifiedPrefix.ContainerURL + "/" + blobInfo.Name,
+				ETag:        string(blobInfo.Properties.Etag),
+				Mtime:       blobInfo.Properties.LastModified,
+			}
+			if blobInfo.Properties.ContentLength != nil {
+				e.Size = *blobInfo.Properties.ContentLength
+			}
+			if err := walkFn(e); err != nil {
 				return err
 			}
 		}
@@ -2,6 +2,7 @@ package gs
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -124,6 +125,11 @@ func (a *Adapter) Walk(ctx context.Context, walkOpt block.WalkOpts, walkFn block
 		return err
 	}
 
+	qualifiedNamespace, err := resolveNamespacePrefix(block.WalkOpts{StorageNamespace: walkOpt.StorageNamespace})
+	if err != nil {
+		return err
+	}
+
 	iter := a.client.
 		Bucket(qualifiedPrefix.StorageNamespace).
 		Objects(ctx, &storage.Query{Prefix: qualifiedPrefix.Prefix})
@@ -138,13 +144,25 @@ func (a *Adapter) Walk(ctx context.Context, walkOpt block.WalkOpts, walkFn block
 			return fmt.Errorf("bucket(%s).Objects(): %w", qualifiedPrefix.StorageNamespace, err)
 		}
 
-		if err := walkFn(attrs.Name); err != nil {
+		e := block.NewObjectStoreEntry(qualifiedNamespace, attrs.Name)
+		e.ETag = objectETag(attrs)
+		e.Mtime = attrs.Updated
+		e.Size = attrs.Size
+		if err := walkFn(e); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// objectETag returns the hex encoded MD5 of the object, or its ETag for composite objects that have no MD5
+func objectETag(attrs *storage.ObjectAttrs) string {
+	if len(attrs.MD5) > 0 {
+		return hex.EncodeToString(attrs.MD5)
+	}
+	return attrs.Etag
+}
+
 func (a *Adapter) Exists(ctx context.Context, obj block.ObjectPointer) (bool, error) {
 	var err error
 	defer reportMetrics("Exists", time.Now(), nil, &err)
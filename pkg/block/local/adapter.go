@@ -229,14 +229,83 @@ func (l *Adapter) Get(_ context.Context, obj block.ObjectPointer, _ int64) (read
 }
 
 func (l *Adapter) Walk(_ context.Context, walkOpt block.WalkOpts, walkFn block.WalkFunc) error {
-	p := filepath.Clean(path.Join(l.path, walkOpt.StorageNamespace, walkOpt.Prefix))
-	return filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+	qualifiedPrefix, err := block.ResolveNamespacePrefix(walkOpt.StorageNamespace, walkOpt.Prefix)
+	if err != nil {
+		return err
+	}
+	if qualifiedPrefix.StorageType != block.StorageTypeLocal {
+		return block.ErrInvalidNamespace
+	}
+	qualifiedNamespace, err := block.ResolveNamespacePrefix(walkOpt.StorageNamespace, "")
+	if err != nil {
+		return err
+	}
+
+	// objects are files under the bucket directory, their key is the path relative to it
+	bucketPath := filepath.Join(l.path, qualifiedPrefix.StorageNamespace)
+	fullPrefix := filepath.Join(bucketPath, qualifiedPrefix.Prefix)
+	walkRoot := fullPrefix
+	if qualifiedPrefix.Prefix != "" && !strings.HasSuffix(qualifiedPrefix.Prefix, "/") {
+		// prefix may end in the middle of a file or directory name
+		walkRoot = filepath.Dir(fullPrefix)
+	}
+	err = walkSorted(walkRoot, func(p string, info os.FileInfo) error {
+		if !strings.HasPrefix(p, fullPrefix) {
+			return nil
+		}
+		key, err := filepath.Rel(bucketPath, p)
 		if err != nil {
 			return err
 		}
+		e := block.NewObjectStoreEntry(qualifiedNamespace, filepath.ToSlash(key))
+		e.ETag = fileETag(p, info)
+		e.Mtime = info.ModTime()
+		e.Size = info.Size()
+		return walkFn(e)
+	})
+	if os.IsNotExist(err) {
+		// nothing to walk under a missing directory
+		return nil
+	}
+	return err
+}
 
-		return walkFn(p)
+// walkSorted calls fn for each file under dir in lexicographic order of its slash separated path.  Unlike
+// filepath.Walk, a directory is ordered by its name followed by a separator, so "a-c" precedes "a/b".
+func walkSorted(dir string, fn func(p string, info os.FileInfo) error) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	sortName := func(info os.FileInfo) string {
+		if info.IsDir() {
+			return info.Name() + "/"
+		}
+		return info.Name()
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortName(entries[i]) < sortName(entries[j])
 	})
+	for _, info := range entries {
+		p := filepath.Join(dir, info.Name())
+		if info.IsDir() {
+			err = walkSorted(p, fn)
+		} else {
+			err = fn(p, info)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileETag returns an ETag of the file built from its path, size and modification time, so that walking
+// does not read file contents.  It changes whenever the file is rewritten.
+func fileETag(p string, info os.FileInfo) string {
+	h := md5.New() //nolint:gosec
+	_, _ = fmt.Fprintf(h, "%s:%d:%d", p, info.Size(), info.ModTime().UnixNano())
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 func (l *Adapter) Exists(_ context.Context, obj block.ObjectPointer) (bool, error) {
@@ -227,3 +227,51 @@ func TestAdapter_Remove(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalWalk(t *testing.T) {
+	ctx := context.Background()
+	a := makeAdapter(t)
+	const content = "content"
+	for _, p := range []string{"data/a", "data/b/c", "data-x/f", "database/d", "other/e"} {
+		testutil.MustDo(t, "Put", a.Put(ctx, makePointer(p), 0, strings.NewReader(content), block.PutOpts{}))
+	}
+	// a sibling namespace sharing the name prefix is not walked
+	testutil.MustDo(t, "Put", a.Put(ctx, block.ObjectPointer{StorageNamespace: testStorageNamespace + "2", Identifier: "data/x"}, 0, strings.NewReader(content), block.PutOpts{}))
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{name: "all", prefix: "", want: []string{"data-x/f", "data/a", "data/b/c", "database/d", "other/e"}},
+		{name: "directory", prefix: "data/", want: []string{"data/a", "data/b/c"}},
+		{name: "partial name", prefix: "data", want: []string{"data-x/f", "data/a", "data/b/c", "database/d"}},
+		{name: "missing", prefix: "missing/", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			etags := make(map[string]struct{})
+			err := a.Walk(ctx, block.WalkOpts{StorageNamespace: testStorageNamespace, Prefix: tt.prefix}, func(e block.ObjectStoreEntry) error {
+				if e.Size != int64(len(content)) || e.ETag == "" || e.Mtime.IsZero() {
+					t.Errorf("Walk() entry %s missing properties: %+v", e.RelativeKey, e)
+				}
+				if e.Address != testStorageNamespace+"/"+e.RelativeKey {
+					t.Errorf("Walk() entry %s address %s", e.RelativeKey, e.Address)
+				}
+				keys = append(keys, e.RelativeKey)
+				etags[e.ETag] = struct{}{}
+				return nil
+			})
+			testutil.MustDo(t, "Walk", err)
+			// files share their content, ETags come from their path and not from hashing the content
+			if len(etags) != len(keys) {
+				t.Errorf("Walk() got %d distinct ETags for %d keys", len(etags), len(keys))
+			}
+			// keys are walked in order, a directory after the names it prefixes
+			if diff := deep.Equal(keys, tt.want); diff != nil {
+				t.Errorf("Walk() keys diff = %s", diff)
+			}
+		})
+	}
+}
@@ -280,11 +280,26 @@ func (a *Adapter) Walk(_ context.Context, walkOpt block.WalkOpts, walkFn block.W
 	defer a.mutex.RUnlock()
 
 	fullPrefix := getPrefix(walkOpt)
+	namespacePrefix := getPrefix(block.WalkOpts{StorageNamespace: walkOpt.StorageNamespace})
+	keys := make([]string, 0)
 	for k := range a.data {
 		if strings.HasPrefix(k, fullPrefix) {
-			if err := walkFn(k); err != nil {
-				return err
-			}
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		data := a.data[k]
+		relativeKey := strings.TrimPrefix(k, namespacePrefix)
+		checksum := sha256.Sum256(data)
+		if err := walkFn(block.ObjectStoreEntry{
+			FullKey:     k,
+			RelativeKey: relativeKey,
+			Address:     strings.TrimSuffix(walkOpt.StorageNamespace, "/") + "/" + strings.TrimPrefix(relativeKey, "/"),
+			ETag:        hex.EncodeToString(checksum[:]),
+			Size:        int64(len(data)),
+		}); err != nil {
+			return err
 		}
 	}
 	return nil
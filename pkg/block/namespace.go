@@ -175,3 +175,17 @@ func DefaultStorageNamespaceInfo(scheme string) StorageNamespaceInfo {
 		Example:       DefaultExample(scheme),
 	}
 }
+
+// NewObjectStoreEntry returns the entry of the object at fullKey, found by walking the storage namespace
+// qualified by namespace.  The caller fills in the object properties.
+func NewObjectStoreEntry(namespace QualifiedPrefix, fullKey string) ObjectStoreEntry {
+	return ObjectStoreEntry{
+		FullKey:     fullKey,
+		RelativeKey: strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(fullKey, "/"), strings.TrimPrefix(namespace.Prefix, "/")), "/"),
+		Address: QualifiedKey{
+			StorageType:      namespace.StorageType,
+			StorageNamespace: namespace.StorageNamespace,
+			Key:              fullKey,
+		}.Format(),
+	}
+}
@@ -322,14 +322,18 @@ func (a *Adapter) Walk(ctx context.Context, walkOpt block.WalkOpts, walkFn block
 	if err != nil {
 		return err
 	}
+	qualifiedNamespace, err := resolveNamespacePrefix(block.WalkOpts{StorageNamespace: walkOpt.StorageNamespace})
+	if err != nil {
+		return err
+	}
 
-	listObjectInput := s3.ListObjectsInput{
+	listObjectInput := s3.ListObjectsV2Input{
 		Bucket: aws.String(qualifiedPrefix.StorageNamespace),
 		Prefix: aws.String(qualifiedPrefix.Prefix),
 	}
 
 	for {
-		listOutput, err := a.s3.ListObjectsWithContext(ctx, &listObjectInput)
+		listOutput, err := a.s3.ListObjectsV2WithContext(ctx, &listObjectInput)
 		if err != nil {
 			log.WithError(err).WithFields(logging.Fields{
 				"bucket": qualifiedPrefix.StorageNamespace,
@@ -339,17 +343,21 @@ func (a *Adapter) Walk(ctx context.Context, walkOpt block.WalkOpts, walkFn block
 		}
 
 		for _, obj := range listOutput.Contents {
-			if err := walkFn(*obj.Key); err != nil {
+			e := block.NewObjectStoreEntry(qualifiedNamespace, aws.StringValue(obj.Key))
+			e.ETag = strings.Trim(aws.StringValue(obj.ETag), "\"")
+			e.Mtime = aws.TimeValue(obj.LastModified)
+			e.Size = aws.Int64Value(obj.Size)
+			if err := walkFn(e); err != nil {
 				return err
 			}
 		}
 
-		if listOutput.IsTruncated == nil || !*listOutput.IsTruncated {
+		if !aws.BoolValue(listOutput.IsTruncated) {
 			break
 		}
 
-		// start with the next marker
-		listObjectInput.Marker = listOutput.NextMarker
+		// continue from where the last page ended
+		listObjectInput.ContinuationToken = listOutput.NextContinuationToken
 	}
 
 	return nil
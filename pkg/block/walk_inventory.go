@@ -0,0 +1,247 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/treeverse/lakefs/pkg/cmdutils"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+const (
+	DefaultWalkParallelism = 8
+	// walkBufferSize is the number of objects each prefix walk lists ahead of the iterator
+	walkBufferSize = 1000
+)
+
+var (
+	ErrInvalidWalkSource = errors.New("invalid walk source")
+	ErrWalkNotSorted     = errors.New("got unsorted walk")
+)
+
+// WalkInventoryGenerator generates an inventory by walking the objects of a storage namespace with the adapter.
+// It lets any configured block adapter serve as an import source, not only storage that provides an inventory.
+type WalkInventoryGenerator struct {
+	Adapter     Adapter
+	Parallelism int
+}
+
+func NewWalkInventoryGenerator(adapter Adapter, parallelism int) *WalkInventoryGenerator {
+	if parallelism <= 0 {
+		parallelism = DefaultWalkParallelism
+	}
+	return &WalkInventoryGenerator{
+		Adapter:     adapter,
+		Parallelism: parallelism,
+	}
+}
+
+// GenerateInventory lists the objects under sourceURL (e.g. "gs://bucket/path/to/data"). The prefixes, relative
+// to sourceURL, are walked in sorted order, up to Parallelism of them ahead of the iterator. Inventory object keys
+// are relative to sourceURL, and are sorted as the adapter walks each prefix in key order.
+func (g *WalkInventoryGenerator) GenerateInventory(ctx context.Context, logger logging.Logger, sourceURL string, shouldSort bool, prefixes []string) (Inventory, error) {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	sourceURL = strings.TrimSuffix(sourceURL, "/")
+	qualifiedPrefix, err := ResolveNamespacePrefix(sourceURL, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWalkSource, sourceURL)
+	}
+	return &walkInventory{
+		ctx:         ctx,
+		logger:      logger,
+		generator:   g,
+		sourceURL:   sourceURL,
+		bucket:      qualifiedPrefix.StorageNamespace,
+		prefixes:    sortPrefixes(prefixes),
+		shouldSort:  shouldSort,
+		parallelism: g.Parallelism,
+	}, nil
+}
+
+// sortPrefixes returns the prefixes sorted, without those another prefix covers.  The objects of sorted disjoint
+// prefixes are sorted when the prefixes are walked one after the other.
+func sortPrefixes(prefixes []string) []string {
+	if len(prefixes) == 0 {
+		return []string{""}
+	}
+	sorted := make([]string, len(prefixes))
+	copy(sorted, prefixes)
+	sort.Strings(sorted)
+	n := 0
+	for _, prefix := range sorted {
+		if n > 0 && strings.HasPrefix(prefix, sorted[n-1]) {
+			continue
+		}
+		sorted[n] = prefix
+		n++
+	}
+	return sorted[:n]
+}
+
+type walkInventory struct {
+	ctx         context.Context
+	logger      logging.Logger
+	generator   *WalkInventoryGenerator
+	sourceURL   string
+	bucket      string
+	prefixes    []string
+	shouldSort  bool
+	parallelism int
+}
+
+// Iterator starts walking the prefixes, the walks stop once the iterator is exhausted or fails
+func (inv *walkInventory) Iterator() InventoryIterator {
+	ctx, cancel := context.WithCancel(inv.ctx)
+	prefixProgress := cmdutils.NewActiveProgress("Prefixes walked", cmdutils.Bar)
+	prefixProgress.SetTotal(int64(len(inv.prefixes)))
+	it := &walkInventoryIterator{
+		walkInventory:  inv,
+		ctx:            ctx,
+		cancel:         cancel,
+		listings:       make(chan *walkListing, inv.parallelism),
+		sem:            make(chan struct{}, inv.parallelism),
+		prefixProgress: prefixProgress,
+		objectProgress: cmdutils.NewActiveProgress("Objects read from walk", cmdutils.Spinner),
+	}
+	go it.walkPrefixes()
+	return it
+}
+
+func (inv *walkInventory) SourceName() string {
+	return inv.sourceURL
+}
+
+func (inv *walkInventory) InventoryURL() string {
+	return inv.sourceURL
+}
+
+// walkListing holds the objects of a single prefix walk, err is set before objects is closed
+type walkListing struct {
+	prefix  string
+	objects chan InventoryObject
+	err     error
+}
+
+type walkInventoryIterator struct {
+	*walkInventory
+	ctx            context.Context
+	cancel         context.CancelFunc
+	listings       chan *walkListing
+	sem            chan struct{}
+	current        *walkListing
+	val            *InventoryObject
+	err            error
+	prefixProgress *cmdutils.Progress
+	objectProgress *cmdutils.Progress
+}
+
+// walkPrefixes starts the walk of each prefix in order, once fewer than parallelism listings are pending
+func (it *walkInventoryIterator) walkPrefixes() {
+	defer close(it.listings)
+	for _, prefix := range it.prefixes {
+		if it.ctx.Err() != nil {
+			return
+		}
+		select {
+		case it.sem <- struct{}{}:
+		case <-it.ctx.Done():
+			return
+		}
+		listing := &walkListing{
+			prefix:  prefix,
+			objects: make(chan InventoryObject, walkBufferSize),
+		}
+		it.listings <- listing
+		go it.walk(listing)
+	}
+}
+
+func (it *walkInventoryIterator) walk(listing *walkListing) {
+	defer close(listing.objects)
+	count := 0
+	err := it.generator.Adapter.Walk(it.ctx, WalkOpts{StorageNamespace: it.sourceURL, Prefix: listing.prefix}, func(e ObjectStoreEntry) error {
+		obj := InventoryObject{
+			Bucket:          it.bucket,
+			Key:             strings.TrimPrefix(e.RelativeKey, "/"),
+			Size:            e.Size,
+			Checksum:        e.ETag,
+			PhysicalAddress: e.Address,
+		}
+		if !e.Mtime.IsZero() {
+			mtime := e.Mtime
+			obj.LastModified = &mtime
+		}
+		select {
+		case listing.objects <- obj:
+			count++
+			return nil
+		case <-it.ctx.Done():
+			return it.ctx.Err()
+		}
+	})
+	if err != nil {
+		listing.err = fmt.Errorf("walk %s prefix '%s': %w", it.sourceURL, listing.prefix, err)
+		return
+	}
+	it.logger.WithFields(logging.Fields{"source": it.sourceURL, "prefix": listing.prefix, "objects": count}).Debug("Walk prefix done")
+}
+
+func (it *walkInventoryIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for {
+		if it.current == nil {
+			listing, ok := <-it.listings
+			if !ok {
+				// all prefixes walked, unless walking stopped early
+				it.err = it.ctx.Err()
+				it.cancel()
+				it.prefixProgress.SetCompleted(true)
+				it.objectProgress.SetCompleted(true)
+				return false
+			}
+			it.current = listing
+		}
+		obj, ok := <-it.current.objects
+		if ok {
+			// validate element order
+			if it.shouldSort && it.val != nil && obj.Key < it.val.Key {
+				return it.fail(fmt.Errorf("%w: %s after %s", ErrWalkNotSorted, obj.Key, it.val.Key))
+			}
+			it.val = &obj
+			it.objectProgress.Incr()
+			return true
+		}
+		if it.current.err != nil {
+			return it.fail(it.current.err)
+		}
+		it.current = nil
+		it.prefixProgress.Incr()
+		<-it.sem
+	}
+}
+
+// fail stops the walks, reporting err
+func (it *walkInventoryIterator) fail(err error) bool {
+	it.err = err
+	it.cancel()
+	return false
+}
+
+func (it *walkInventoryIterator) Err() error {
+	return it.err
+}
+
+func (it *walkInventoryIterator) Get() *InventoryObject {
+	return it.val
+}
+
+func (it *walkInventoryIterator) Progress() []*cmdutils.Progress {
+	return []*cmdutils.Progress{it.prefixProgress, it.objectProgress}
+}
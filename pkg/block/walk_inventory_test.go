@@ -0,0 +1,143 @@
+package block_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+)
+
+func TestWalkInventoryGenerator(t *testing.T) {
+	ctx := context.Background()
+	adapter := mem.New()
+	const source = "mem://bucket/data"
+	for _, key := range []string{"b/2", "a/1", "a/2", "c/1", "ab/1"} {
+		err := adapter.Put(ctx, block.ObjectPointer{
+			StorageNamespace: source,
+			Identifier:       key,
+			IdentifierType:   block.IdentifierTypeRelative,
+		}, int64(len(key)), strings.NewReader(key), block.PutOpts{})
+		if err != nil {
+			t.Fatalf("put %s: %s", key, err)
+		}
+	}
+
+	cases := []struct {
+		Name     string
+		Prefixes []string
+		Expected []string
+	}{
+		{Name: "all", Expected: []string{"a/1", "a/2", "ab/1", "b/2", "c/1"}},
+		{Name: "prefixes", Prefixes: []string{"c/", "a/"}, Expected: []string{"a/1", "a/2", "c/1"}},
+		{Name: "overlapping prefixes", Prefixes: []string{"a", "a/", "ab"}, Expected: []string{"a/1", "a/2", "ab/1"}},
+		{Name: "more prefixes than parallelism", Prefixes: []string{"c/", "b/", "ab/", "a/"}, Expected: []string{"a/1", "a/2", "ab/1", "b/2", "c/1"}},
+		{Name: "no match", Prefixes: []string{"d/"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			gen := block.NewWalkInventoryGenerator(adapter, 2)
+			inv, err := gen.GenerateInventory(ctx, nil, source+"/", true, tt.Prefixes)
+			if err != nil {
+				t.Fatalf("GenerateInventory() failed with err=%s", err)
+			}
+			if inv.SourceName() != source {
+				t.Errorf("SourceName()=%s, expected %s", inv.SourceName(), source)
+			}
+			var keys []string
+			it := inv.Iterator()
+			for it.Next() {
+				obj := it.Get()
+				keys = append(keys, obj.Key)
+				if obj.PhysicalAddress != source+"/"+obj.Key {
+					t.Errorf("object %s physical address %s, expected %s", obj.Key, obj.PhysicalAddress, source+"/"+obj.Key)
+				}
+				if obj.Size != int64(len(obj.Key)) {
+					t.Errorf("object %s size %d, expected %d", obj.Key, obj.Size, len(obj.Key))
+				}
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("iterator failed with err=%s", err)
+			}
+			if !reflect.DeepEqual(keys, tt.Expected) {
+				t.Errorf("keys=%v, expected %v", keys, tt.Expected)
+			}
+		})
+	}
+}
+
+func TestWalkInventoryGenerator_InvalidSource(t *testing.T) {
+	gen := block.NewWalkInventoryGenerator(mem.New(), 0)
+	_, err := gen.GenerateInventory(context.Background(), nil, "bucket/data", true, nil)
+	if !errors.Is(err, block.ErrInvalidWalkSource) {
+		t.Errorf("GenerateInventory() err=%v, expected %s", err, block.ErrInvalidWalkSource)
+	}
+}
+
+type failingWalkAdapter struct {
+	block.Adapter
+	failPrefix string
+}
+
+var errWalkFailed = errors.New("walk failed")
+
+func (a *failingWalkAdapter) Walk(ctx context.Context, walkOpt block.WalkOpts, walkFn block.WalkFunc) error {
+	if walkOpt.Prefix == a.failPrefix {
+		return errWalkFailed
+	}
+	return a.Adapter.Walk(ctx, walkOpt, walkFn)
+}
+
+func TestWalkInventoryGenerator_Errors(t *testing.T) {
+	adapter := mem.New()
+	const source = "mem://bucket/data"
+	for _, key := range []string{"a/1", "b/1", "c/1"} {
+		err := adapter.Put(context.Background(), block.ObjectPointer{
+			StorageNamespace: source,
+			Identifier:       key,
+			IdentifierType:   block.IdentifierTypeRelative,
+		}, int64(len(key)), strings.NewReader(key), block.PutOpts{})
+		if err != nil {
+			t.Fatalf("put %s: %s", key, err)
+		}
+	}
+
+	t.Run("walk failed", func(t *testing.T) {
+		gen := block.NewWalkInventoryGenerator(&failingWalkAdapter{Adapter: adapter, failPrefix: "b/"}, 1)
+		inv, err := gen.GenerateInventory(context.Background(), nil, source, true, []string{"a/", "b/", "c/"})
+		if err != nil {
+			t.Fatalf("GenerateInventory() failed with err=%s", err)
+		}
+		var keys []string
+		it := inv.Iterator()
+		for it.Next() {
+			keys = append(keys, it.Get().Key)
+		}
+		if !errors.Is(it.Err(), errWalkFailed) {
+			t.Errorf("iterator err=%v, expected %s", it.Err(), errWalkFailed)
+		}
+		if !reflect.DeepEqual(keys, []string{"a/1"}) {
+			t.Errorf("keys=%v, expected the keys before the failed prefix", keys)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		gen := block.NewWalkInventoryGenerator(adapter, 1)
+		inv, err := gen.GenerateInventory(ctx, nil, source, true, []string{"a/", "b/", "c/"})
+		if err != nil {
+			t.Fatalf("GenerateInventory() failed with err=%s", err)
+		}
+		it := inv.Iterator()
+		if it.Next() {
+			t.Errorf("Next() read %s after cancel", it.Get().Key)
+		}
+		if !errors.Is(it.Err(), context.Canceled) {
+			t.Errorf("iterator err=%v, expected %s", it.Err(), context.Canceled)
+		}
+	})
+}
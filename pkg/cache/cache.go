@@ -12,6 +12,7 @@ type SetFn func() (v interface{}, err error)
 
 type Cache interface {
 	GetOrSet(k interface{}, setFn SetFn) (v interface{}, err error)
+	Invalidate(k interface{})
 }
 
 type GetSetCache struct {
@@ -45,6 +46,11 @@ func (c *GetSetCache) GetOrSet(k interface{}, setFn SetFn) (v interface{}, err e
 	})
 }
 
+// Invalidate removes k, the next GetOrSet of k computes it again
+func (c *GetSetCache) Invalidate(k interface{}) {
+	c.lru.Remove(k)
+}
+
 func NewJitterFn(jitter time.Duration) JitterFn {
 	return func() time.Duration {
 		n := rand.Intn(int(jitter)) //nolint:gosec
@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func (c *Catalog) ListBranchProtectionRules(ctx context.Context, repository string) ([]*BranchProtectionRule, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := c.Store.GetRepository(ctx, repositoryID); err != nil {
+		return nil, err
+	}
+	rules, err := c.Store.GetBranchProtectionRules(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*BranchProtectionRule, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, &BranchProtectionRule{
+			Pattern:           rule.Pattern,
+			RequirePassingRun: rule.RequirePassingRun,
+		})
+	}
+	return res, nil
+}
+
+func (c *Catalog) CreateBranchProtectionRule(ctx context.Context, repository string, rule BranchProtectionRule) error {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"pattern", rule.Pattern, ValidateRequiredString},
+	}); err != nil {
+		return err
+	}
+	if _, err := c.Store.GetRepository(ctx, repositoryID); err != nil {
+		return err
+	}
+	err := c.Store.CreateBranchProtectionRule(ctx, repositoryID, graveler.BranchProtectionRule{
+		Pattern:           rule.Pattern,
+		RequirePassingRun: rule.RequirePassingRun,
+	})
+	if errors.Is(err, graveler.ErrInvalidValue) {
+		return fmt.Errorf("pattern %s: %w", rule.Pattern, ErrInvalidValue)
+	}
+	return err
+}
+
+func (c *Catalog) DeleteBranchProtectionRule(ctx context.Context, repository string, pattern string) error {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"pattern", pattern, ValidateRequiredString},
+	}); err != nil {
+		return err
+	}
+	return c.Store.DeleteBranchProtectionRule(ctx, repositoryID, pattern)
+}
+
+func (c *Catalog) IsBranchProtected(ctx context.Context, repository, branch string) (bool, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	branchID := graveler.BranchID(branch)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"branch", branchID, ValidateBranchID},
+	}); err != nil {
+		return false, err
+	}
+	rules, err := c.Store.GetBranchProtectionRules(ctx, repositoryID)
+	if err != nil {
+		return false, err
+	}
+	return graveler.MatchBranchProtection(rules, branchID) != nil, nil
+}
+
+func (c *Catalog) SetBranchRunChecker(checker graveler.BranchRunChecker) {
+	c.Store.SetBranchRunChecker(checker)
+}
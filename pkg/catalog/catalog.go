@@ -1,6 +1,7 @@
 package catalog
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	_ "crypto/sha256"
@@ -8,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/batch"
 
@@ -50,6 +52,8 @@ type EntryDiff struct {
 	Type  graveler.DiffType
 	Path  Path
 	Entry *Entry
+	// CommonPrefix is set when Path is a common prefix grouping the diffs under it
+	CommonPrefix *graveler.DiffSummary
 }
 
 type EntryIterator interface {
@@ -112,7 +116,11 @@ const (
 	ListBranchesLimitMax     = 1000
 	ListTagsLimitMax         = 1000
 	DiffLimitMax             = 1000
+	MergeConflictsLimitMax   = 1000
 	ListEntriesLimitMax      = 10000
+
+	// MergeConflictsLimitDefault is the number of conflicts reported on a failed merge
+	MergeConflictsLimitDefault = 100
 )
 
 var ErrUnknownDiffType = errors.New("unknown graveler difference type")
@@ -163,8 +171,10 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 
 	sstableManager := sstable.NewPebbleSSTableRangeManager(pebbleSSTableCache, rangeFS, hashAlg)
 	sstableMetaManager := sstable.NewPebbleSSTableRangeManager(pebbleSSTableCache, metaRangeFS, hashAlg)
+	committedParams := *cfg.Config.GetCommittedParams()
+	committedParams.ValueSize = ValueSize
 	sstableMetaRangeManager, err := committed.NewMetaRangeManager(
-		*cfg.Config.GetCommittedParams(),
+		committedParams,
 		// TODO(ariels): Use separate range managers for metaranges and ranges
 		sstableMetaManager,
 		sstableManager,
@@ -173,7 +183,7 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 		cancelFn()
 		return nil, fmt.Errorf("create SSTable-based metarange manager: %w", err)
 	}
-	committedManager := committed.NewCommittedManager(sstableMetaRangeManager)
+	committedManager := committed.NewCommittedManager(sstableMetaRangeManager, ValueSize)
 
 	stagingManager := staging.NewManager(cfg.DB)
 
@@ -244,6 +254,91 @@ func (c *Catalog) CreateBareRepository(ctx context.Context, repository string, s
 	return catalogRepo, nil
 }
 
+// CreateRepositoryFrom creates a new repository pointing to 'storageNamespace' whose default branch 'branch'
+// starts at the commit of 'sourceReference' in 'sourceRepository'.  Entries of the new repository point
+// at the objects of the source repository by their full address, so no data is copied.
+func (c *Catalog) CreateRepositoryFrom(ctx context.Context, repository string, storageNamespace string, branch string, sourceRepository string, sourceReference string, committer string) (*Repository, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	storageNS := graveler.StorageNamespace(storageNamespace)
+	branchID := graveler.BranchID(branch)
+	sourceRepositoryID := graveler.RepositoryID(sourceRepository)
+	sourceRef := graveler.Ref(sourceReference)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"storageNamespace", storageNS, ValidateStorageNamespace},
+		{"branch", branchID, ValidateBranchID},
+		{"sourceRepositoryID", sourceRepositoryID, ValidateRepositoryID},
+		{"sourceRef", sourceRef, ValidateRef},
+	}); err != nil {
+		return nil, err
+	}
+	source, err := c.Store.GetRepository(ctx, sourceRepositoryID)
+	if err != nil {
+		return nil, err
+	}
+	sourceCommitID, err := c.Store.Dereference(ctx, sourceRepositoryID, sourceRef)
+	if err != nil {
+		return nil, err
+	}
+	// the fork references objects on the source storage namespace, and on those the source references
+	references, err := c.Store.ListStorageNamespaceReferences(ctx, sourceRepositoryID)
+	if err != nil {
+		return nil, err
+	}
+	references = append(references, source.StorageNamespace)
+	repo, err := c.Store.CreateRepository(ctx, repositoryID, storageNS, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.forkCommit(ctx, repositoryID, branchID, sourceRepositoryID, source.StorageNamespace, sourceCommitID, references, committer); err != nil {
+		if deleteErr := c.Store.DeleteRepository(ctx, repositoryID); deleteErr != nil {
+			c.log.WithError(deleteErr).WithField("repository", repositoryID).Warn("Failed to delete repository after failed fork")
+		}
+		return nil, err
+	}
+	catalogRepo := &Repository{
+		Name:             repositoryID.String(),
+		StorageNamespace: storageNS.String(),
+		DefaultBranch:    branchID.String(),
+		CreationDate:     repo.CreationDate,
+	}
+	return catalogRepo, nil
+}
+
+// forkCommit commits on branchID of repositoryID the entries of sourceCommitID in sourceRepositoryID,
+// rewritten to the full addresses of their objects on sourceStorageNamespace.  It first records the
+// references of repositoryID to objects on the storage namespaces in references, so that retention of the
+// repositories owning them keeps these objects.
+func (c *Catalog) forkCommit(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID, sourceRepositoryID graveler.RepositoryID, sourceStorageNamespace graveler.StorageNamespace, sourceCommitID graveler.CommitID, references []graveler.StorageNamespace, committer string) error {
+	if err := c.Store.AddStorageNamespaceReferences(ctx, repositoryID, references); err != nil {
+		return fmt.Errorf("add storage namespace references: %w", err)
+	}
+	it, err := c.Store.List(ctx, sourceRepositoryID, graveler.Ref(sourceCommitID))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	metaRangeID, err := c.Store.WriteMetaRange(ctx, repositoryID, NewFullAddressIterator(it, sourceStorageNamespace.String()))
+	if err != nil {
+		return fmt.Errorf("write meta range: %w", err)
+	}
+	branch, err := c.Store.GetBranch(ctx, repositoryID, branchID)
+	if err != nil {
+		return err
+	}
+	commit := graveler.NewCommit()
+	commit.Committer = committer
+	commit.Message = fmt.Sprintf("Fork of %s@%s", sourceRepositoryID, sourceCommitID)
+	commit.MetaRangeID = *metaRangeID
+	commit.Parents = graveler.CommitParents{branch.CommitID}
+	commit.Metadata = graveler.Metadata{
+		"fork_repository": sourceRepositoryID.String(),
+		"fork_commit":     sourceCommitID.String(),
+	}
+	_, err = c.Store.AddCommitToBranchHead(ctx, repositoryID, branchID, commit)
+	return err
+}
+
 // GetRepository get repository information
 func (c *Catalog) GetRepository(ctx context.Context, repository string) (*Repository, error) {
 	repositoryID := graveler.RepositoryID(repository)
@@ -256,13 +351,51 @@ func (c *Catalog) GetRepository(ctx context.Context, repository string) (*Reposi
 	if err != nil {
 		return nil, err
 	}
-	catalogRepository := &Repository{
+	return newCatalogRepository(repositoryID, repo), nil
+}
+
+func newCatalogRepository(repositoryID graveler.RepositoryID, repo *graveler.Repository) *Repository {
+	return &Repository{
 		Name:             repositoryID.String(),
 		StorageNamespace: repo.StorageNamespace.String(),
 		DefaultBranch:    repo.DefaultBranchID.String(),
 		CreationDate:     repo.CreationDate,
+		Description:      repo.Description,
+		Metadata:         Metadata(repo.Metadata),
 	}
-	return catalogRepository, nil
+}
+
+// UpdateRepository changes the name, default branch, description or metadata of a repository, as set on params
+func (c *Catalog) UpdateRepository(ctx context.Context, repository string, params UpdateRepositoryParams) (*Repository, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	update := graveler.RepositoryUpdate{
+		Description: params.Description,
+		Metadata:    graveler.Metadata(params.Metadata),
+	}
+	validations := []ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+	}
+	if params.Name != nil {
+		newRepositoryID := graveler.RepositoryID(*params.Name)
+		update.RepositoryID = &newRepositoryID
+		validations = append(validations, ValidateArg{"name", newRepositoryID, ValidateRepositoryID})
+	}
+	if params.DefaultBranch != nil {
+		defaultBranchID := graveler.BranchID(*params.DefaultBranch)
+		update.DefaultBranchID = &defaultBranchID
+		validations = append(validations, ValidateArg{"defaultBranch", defaultBranchID, ValidateBranchID})
+	}
+	if err := Validate(validations); err != nil {
+		return nil, err
+	}
+	repo, err := c.Store.UpdateRepository(ctx, repositoryID, update)
+	if err != nil {
+		return nil, err
+	}
+	if update.RepositoryID != nil {
+		repositoryID = *update.RepositoryID
+	}
+	return newCatalogRepository(repositoryID, repo), nil
 }
 
 // DeleteRepository delete a repository
@@ -311,12 +444,7 @@ func (c *Catalog) ListRepositories(ctx context.Context, limit int, prefix, after
 		if record.RepositoryID == afterRepositoryID {
 			continue
 		}
-		repos = append(repos, &Repository{
-			Name:             record.RepositoryID.String(),
-			StorageNamespace: record.StorageNamespace.String(),
-			DefaultBranch:    record.DefaultBranchID.String(),
-			CreationDate:     record.CreationDate,
-		})
+		repos = append(repos, newCatalogRepository(record.RepositoryID, record.Repository))
 		// collect limit +1 to return limit and has more
 		if len(repos) >= limit+1 {
 			break
@@ -499,7 +627,7 @@ func (c *Catalog) ResetBranch(ctx context.Context, repository string, branch str
 	return c.Store.Reset(ctx, repositoryID, branchID)
 }
 
-func (c *Catalog) CreateTag(ctx context.Context, repository string, tagID string, ref string) (string, error) {
+func (c *Catalog) CreateTag(ctx context.Context, repository string, tagID string, ref string, annotation *TagAnnotation) (string, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	tag := graveler.TagID(tagID)
 	if err := Validate([]ValidateArg{
@@ -512,7 +640,19 @@ func (c *Catalog) CreateTag(ctx context.Context, repository string, tagID string
 	if err != nil {
 		return "", err
 	}
-	err = c.Store.CreateTag(ctx, repositoryID, tag, commitID)
+	var tagAnnotation *graveler.TagAnnotation
+	if annotation != nil {
+		tagAnnotation = &graveler.TagAnnotation{
+			Message:      annotation.Message,
+			Creator:      annotation.Creator,
+			CreationDate: annotation.CreationDate,
+			Metadata:     graveler.Metadata(annotation.Metadata),
+		}
+		if tagAnnotation.CreationDate.IsZero() {
+			tagAnnotation.CreationDate = time.Now()
+		}
+	}
+	err = c.Store.CreateTag(ctx, repositoryID, tag, commitID, tagAnnotation)
 	if err != nil {
 		return "", err
 	}
@@ -555,11 +695,7 @@ func (c *Catalog) ListTags(ctx context.Context, repository string, limit int, af
 		if v.TagID == afterTagID {
 			continue
 		}
-		branch := &Tag{
-			ID:       string(v.TagID),
-			CommitID: v.CommitID.String(),
-		}
-		tags = append(tags, branch)
+		tags = append(tags, newTagFromRecord(v))
 		if len(tags) >= limit+1 {
 			break
 		}
@@ -576,25 +712,41 @@ func (c *Catalog) ListTags(ctx context.Context, repository string, limit int, af
 	return tags, hasMore, nil
 }
 
-func (c *Catalog) GetTag(ctx context.Context, repository string, tagID string) (string, error) {
+func (c *Catalog) GetTag(ctx context.Context, repository string, tagID string) (*Tag, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	tag := graveler.TagID(tagID)
 	if err := Validate([]ValidateArg{
 		{"repositoryID", repositoryID, ValidateRepositoryID},
 		{"tagID", tag, ValidateTagID},
 	}); err != nil {
-		return "", err
+		return nil, err
 	}
-	commit, err := c.Store.GetTag(ctx, repositoryID, tag)
+	record, err := c.Store.GetTagRecord(ctx, repositoryID, tag)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return commit.String(), nil
+	return newTagFromRecord(record), nil
+}
+
+func newTagFromRecord(record *graveler.TagRecord) *Tag {
+	tag := &Tag{
+		ID:       string(record.TagID),
+		CommitID: record.CommitID.String(),
+	}
+	if record.Annotation != nil {
+		tag.Annotation = &TagAnnotation{
+			Message:      record.Annotation.Message,
+			Creator:      record.Annotation.Creator,
+			CreationDate: record.Annotation.CreationDate,
+			Metadata:     Metadata(record.Annotation.Metadata),
+		}
+	}
+	return tag
 }
 
 // GetEntry returns the current entry for path in repository branch reference.  Returns
 // the entry with ExpiredError if it has expired from underlying storage.
-func (c *Catalog) GetEntry(ctx context.Context, repository string, reference string, path string, _ GetEntryParams) (*DBEntry, error) {
+func (c *Catalog) GetEntry(ctx context.Context, repository string, reference string, path string, params GetEntryParams) (*DBEntry, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	ref := graveler.Ref(reference)
 	p := Path(path)
@@ -606,7 +758,8 @@ func (c *Catalog) GetEntry(ctx context.Context, repository string, reference str
 		return nil, err
 	}
 	val, err := c.Store.Get(ctx, repositoryID, ref, graveler.Key(p))
-	if err != nil {
+	commitExpired := errors.Is(err, graveler.ErrCommitExpired)
+	if err != nil && !commitExpired {
 		return nil, err
 	}
 	ent, err := ValueToEntry(val)
@@ -614,6 +767,13 @@ func (c *Catalog) GetEntry(ctx context.Context, repository string, reference str
 		return nil, err
 	}
 	catalogEntry := newCatalogEntryFromEntry(false, p.String(), ent)
+	// objects imported by full address are not removed with expired commits
+	if commitExpired && catalogEntry.AddressType == AddressTypeRelative {
+		catalogEntry.Expired = true
+		if !params.ReturnExpired {
+			return &catalogEntry, ErrExpired
+		}
+	}
 	return &catalogEntry, nil
 }
 
@@ -674,6 +834,35 @@ func (c *Catalog) CreateEntry(ctx context.Context, repository string, branch str
 	return c.Store.Set(ctx, repositoryID, branchID, key, *value, writeConditions...)
 }
 
+func (c *Catalog) IfMatchChecksum(ctx context.Context, repository, branch, path, checksum string) (graveler.WriteConditionOption, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	branchID := graveler.BranchID(branch)
+	p := Path(path)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"branchID", branchID, ValidateBranchID},
+		{"path", p, ValidatePath},
+	}); err != nil {
+		return nil, err
+	}
+	value, err := c.Store.Get(ctx, repositoryID, graveler.Ref(branchID), graveler.Key(p))
+	if errors.Is(err, graveler.ErrNotFound) {
+		return nil, fmt.Errorf("path %s: %w", path, graveler.ErrPreconditionFailed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ent, err := ValueToEntry(value)
+	if err != nil {
+		return nil, err
+	}
+	if ent.ETag != checksum {
+		return nil, fmt.Errorf("path %s checksum %s: %w", path, ent.ETag, graveler.ErrPreconditionFailed)
+	}
+	// the identity covers the checksum, graveler fails the write if the entry changes meanwhile
+	return graveler.IfMatch(value.Identity), nil
+}
+
 func (c *Catalog) CreateEntries(ctx context.Context, repository string, branch string, entries []DBEntry) error {
 	for _, entry := range entries {
 		if err := c.CreateEntry(ctx, repository, branch, entry); err != nil {
@@ -777,7 +966,7 @@ func (c *Catalog) ResetEntries(ctx context.Context, repository string, branch st
 	return c.Store.ResetPrefix(ctx, repositoryID, branchID, keyPrefix)
 }
 
-func (c *Catalog) Commit(ctx context.Context, repository string, branch string, message string, committer string, metadata Metadata) (*CommitLog, error) {
+func (c *Catalog) Commit(ctx context.Context, repository string, branch string, message string, committer string, metadata Metadata, opts ...CommitOption) (*CommitLog, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	branchID := graveler.BranchID(branch)
 	if err := Validate([]ValidateArg{
@@ -786,11 +975,15 @@ func (c *Catalog) Commit(ctx context.Context, repository string, branch string,
 	}); err != nil {
 		return nil, err
 	}
-	commitID, err := c.Store.Commit(ctx, repositoryID, branchID, graveler.CommitParams{
+	params := graveler.CommitParams{
 		Committer: committer,
 		Message:   message,
 		Metadata:  map[string]string(metadata),
-	})
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	commitID, err := c.Store.Commit(ctx, repositoryID, branchID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -843,7 +1036,24 @@ func (c *Catalog) GetCommit(ctx context.Context, repository string, reference st
 	return catalogCommitLog, nil
 }
 
-func (c *Catalog) ListCommits(ctx context.Context, repository string, branch string, fromReference string, limit int) ([]*CommitLog, bool, error) {
+// Dereference returns the ID of the commit that reference points to
+func (c *Catalog) Dereference(ctx context.Context, repository string, reference string) (string, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	ref := graveler.Ref(reference)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"ref", ref, ValidateRef},
+	}); err != nil {
+		return "", err
+	}
+	commitID, err := c.Store.Dereference(ctx, repositoryID, ref)
+	if err != nil {
+		return "", err
+	}
+	return commitID.String(), nil
+}
+
+func (c *Catalog) ListCommits(ctx context.Context, repository string, branch string, fromReference string, limit int, opts ...LogOption) ([]*CommitLog, bool, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	branchRef := graveler.BranchID(branch)
 	if err := Validate([]ValidateArg{
@@ -860,7 +1070,11 @@ func (c *Catalog) ListCommits(ctx context.Context, repository string, branch str
 		// return empty log if there is no commit on branch yet
 		return make([]*CommitLog, 0), false, nil
 	}
-	it, err := c.Store.Log(ctx, repositoryID, branchCommitID)
+	var params graveler.LogParams
+	for _, opt := range opts {
+		opt(&params)
+	}
+	it, err := c.Store.Log(ctx, repositoryID, branchCommitID, params)
 	if err != nil {
 		return nil, false, err
 	}
@@ -913,6 +1127,53 @@ func (c *Catalog) ListCommits(ctx context.Context, repository string, branch str
 	return commits, hasMore, nil
 }
 
+func (c *Catalog) Blame(ctx context.Context, repository string, reference string, prefix string, after string, limit int) ([]*BlameEntry, bool, error) {
+	// normalize limit
+	if limit < 0 || limit > ListEntriesLimitMax {
+		limit = ListEntriesLimitMax
+	}
+	prefixPath := Path(prefix)
+	repositoryID := graveler.RepositoryID(repository)
+	ref := graveler.Ref(reference)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"ref", ref, ValidateRef},
+		{"prefix", prefixPath, ValidatePathOptional},
+	}); err != nil {
+		return nil, false, err
+	}
+	// a single call walks the log once for all the keys of the page
+	records, err := c.Store.Blame(ctx, repositoryID, ref, graveler.Key(prefix), graveler.Key(after), limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	entries := make([]*BlameEntry, 0, len(records))
+	for _, record := range records {
+		entry := &BlameEntry{Path: string(record.Key)}
+		if record.Commit != nil {
+			entry.Commit = &CommitLog{
+				Reference:    record.Commit.CommitID.String(),
+				Committer:    record.Commit.Committer,
+				Message:      record.Commit.Message,
+				CreationDate: record.Commit.CreationDate,
+				Metadata:     Metadata(record.Commit.Metadata),
+				MetaRangeID:  string(record.Commit.MetaRangeID),
+				Parents:      make([]string, 0, len(record.Commit.Parents)),
+			}
+			for _, parent := range record.Commit.Parents {
+				entry.Commit.Parents = append(entry.Commit.Parents, parent.String())
+			}
+		}
+		entries = append(entries, entry)
+	}
+	hasMore := false
+	if len(entries) > limit {
+		hasMore = true
+		entries = entries[:limit]
+	}
+	return entries, hasMore, nil
+}
+
 func (c *Catalog) Revert(ctx context.Context, repository string, branch string, params RevertParams) error {
 	repositoryID := graveler.RepositoryID(repository)
 	branchID := graveler.BranchID(branch)
@@ -936,6 +1197,53 @@ func (c *Catalog) Revert(ctx context.Context, repository string, branch string,
 	return err
 }
 
+func (c *Catalog) CherryPick(ctx context.Context, repository string, branch string, params CherryPickParams) (*CommitLog, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	branchID := graveler.BranchID(branch)
+	ref := graveler.Ref(params.Reference)
+	commitParams := graveler.CommitParams{
+		Committer: params.Committer,
+		Message:   params.Message,
+	}
+	if commitParams.Message == "" {
+		commitParams.Message = fmt.Sprintf("Cherry-pick %s", params.Reference)
+	}
+	parentNumber := params.ParentNumber
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"branchID", branchID, ValidateBranchID},
+		{"ref", ref, ValidateRef},
+		{"committer", commitParams.Committer, ValidateRequiredString},
+		{"message", commitParams.Message, ValidateRequiredString},
+		{"parentNumber", parentNumber, ValidateNonNegativeInt},
+	}); err != nil {
+		return nil, err
+	}
+	commitID, _, err := c.Store.CherryPick(ctx, repositoryID, branchID, ref, parentNumber, commitParams)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetCommit(ctx, repository, commitID.String())
+}
+
+func (c *Catalog) Rebase(ctx context.Context, repository, branch, onto string) (*CommitLog, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	branchID := graveler.BranchID(branch)
+	ontoRef := graveler.Ref(onto)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"branchID", branchID, ValidateBranchID},
+		{"onto", ontoRef, ValidateRef},
+	}); err != nil {
+		return nil, err
+	}
+	commitID, err := c.Store.Rebase(ctx, repositoryID, branchID, ontoRef)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetCommit(ctx, repository, commitID.String())
+}
+
 func (c *Catalog) Diff(ctx context.Context, repository string, leftReference string, rightReference string, params DiffParams) (Differences, bool, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	left := graveler.Ref(leftReference)
@@ -947,7 +1255,7 @@ func (c *Catalog) Diff(ctx context.Context, repository string, leftReference str
 	}); err != nil {
 		return nil, false, err
 	}
-	iter, err := c.Store.Diff(ctx, repositoryID, left, right)
+	iter, err := c.Store.Diff(ctx, repositoryID, left, right, params.gravelerParams())
 	if err != nil {
 		return nil, false, err
 	}
@@ -967,7 +1275,7 @@ func (c *Catalog) Compare(ctx context.Context, repository, leftReference string,
 	}); err != nil {
 		return nil, false, err
 	}
-	iter, err := c.Store.Compare(ctx, repositoryID, from, to)
+	iter, err := c.Store.Compare(ctx, repositoryID, from, to, params.gravelerParams())
 	if err != nil {
 		return nil, false, err
 	}
@@ -976,7 +1284,7 @@ func (c *Catalog) Compare(ctx context.Context, repository, leftReference string,
 	return listDiffHelper(it, params.Limit, params.After)
 }
 
-func (c *Catalog) DiffUncommitted(ctx context.Context, repository string, branch string, limit int, after string) (Differences, bool, error) {
+func (c *Catalog) DiffUncommitted(ctx context.Context, repository string, branch string, params DiffParams) (Differences, bool, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	branchID := graveler.BranchID(branch)
 	if err := Validate([]ValidateArg{
@@ -989,9 +1297,53 @@ func (c *Catalog) DiffUncommitted(ctx context.Context, repository string, branch
 	if err != nil {
 		return nil, false, err
 	}
-	it := NewEntryDiffIterator(iter)
+	it := NewEntryDiffIterator(graveler.NewScopedDiffIterator(iter, params.gravelerParams()))
 	defer it.Close()
-	return listDiffHelper(it, limit, after)
+	return listDiffHelper(it, params.Limit, params.After)
+}
+
+func (c *Catalog) AheadBehind(ctx context.Context, repository, reference, otherReference string) (int, int, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	ref := graveler.Ref(reference)
+	other := graveler.Ref(otherReference)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"ref", ref, ValidateRef},
+		{"other", other, ValidateRef},
+	}); err != nil {
+		return 0, 0, err
+	}
+	return c.Store.AheadBehind(ctx, repositoryID, ref, other)
+}
+
+func (c *Catalog) DiffSummary(ctx context.Context, repository, leftReference, rightReference string, prefix string) (*DiffSummary, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	left := graveler.Ref(leftReference)
+	right := graveler.Ref(rightReference)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"left", left, ValidateRef},
+		{"right", right, ValidateRef},
+	}); err != nil {
+		return nil, err
+	}
+	summary, err := c.Store.DiffSummary(ctx, repositoryID, left, right, graveler.Key(prefix))
+	if err != nil {
+		return nil, err
+	}
+	res := &DiffSummary{
+		Count: make(map[DifferenceType]int, len(summary.Count)),
+		Bytes: make(map[DifferenceType]int64, len(summary.Bytes)),
+	}
+	for typ, count := range summary.Count {
+		catalogType, err := catalogDiffType(typ)
+		if err != nil {
+			return nil, err
+		}
+		res.Count[catalogType] = count
+		res.Bytes[catalogType] = summary.Bytes[typ]
+	}
+	return res, nil
 }
 
 func listDiffHelper(it EntryDiffIterator, limit int, after string) (Differences, bool, error) {
@@ -1028,14 +1380,14 @@ func listDiffHelper(it EntryDiffIterator, limit int, after string) (Differences,
 	return diffs, hasMore, nil
 }
 
-func (c *Catalog) Merge(ctx context.Context, repository string, destinationBranch string, sourceRef string, committer string, message string, metadata Metadata) (*MergeResult, error) {
+func (c *Catalog) Merge(ctx context.Context, repository string, destinationBranch string, sourceRef string, params MergeParams) (*MergeResult, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	destination := graveler.BranchID(destinationBranch)
 	source := graveler.Ref(sourceRef)
-	meta := graveler.Metadata(metadata)
+	meta := graveler.Metadata(params.Metadata)
 	commitParams := graveler.CommitParams{
-		Committer: committer,
-		Message:   message,
+		Committer: params.Committer,
+		Message:   params.Message,
 		Metadata:  meta,
 	}
 	if commitParams.Message == "" {
@@ -1050,11 +1402,35 @@ func (c *Catalog) Merge(ctx context.Context, repository string, destinationBranc
 	}); err != nil {
 		return nil, err
 	}
-	commitID, summary, err := c.Store.Merge(ctx, repositoryID, destination, source, commitParams)
-	if errors.Is(err, graveler.ErrConflictFound) {
-		// for compatibility with old Catalog
+	mergeStrategy, err := graveler.ParseMergeStrategy(params.Strategy)
+	if err != nil {
+		return nil, fmt.Errorf("argument strategy '%s': %w", params.Strategy, ErrInvalidValue)
+	}
+	mergeMode, err := graveler.ParseMergeMode(params.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("argument mode '%s': %w", params.Mode, ErrInvalidValue)
+	}
+	if mergeMode == graveler.MergeModeFFOnly && params.Squash {
+		return nil, fmt.Errorf("argument squash with mode '%s': %w", params.Mode, ErrInvalidValue)
+	}
+	commitID, summary, err := c.Store.Merge(ctx, repositoryID, destination, source, commitParams, graveler.MergeParams{
+		Strategy:     mergeStrategy,
+		Squash:       params.Squash,
+		Mode:         mergeMode,
+		ExpectedHead: graveler.CommitID(params.ExpectedHead),
+	})
+	var conflictErr *graveler.MergeConflictError
+	if errors.As(err, &conflictErr) {
+		// list the conflicts between the commits the merge used, branches may have moved since.  Conflicts
+		// are not counted, HasMoreConflicts tells whether there are more than those listed.
+		conflicts, hasMore, listErr := c.listMergeConflicts(ctx, repositoryID, conflictErr.Destination.Ref(), conflictErr.Source.Ref(), MergeConflictsLimitDefault, "")
+		if listErr != nil {
+			return nil, fmt.Errorf("list merge conflicts: %w", listErr)
+		}
 		return &MergeResult{
-			Summary: map[DifferenceType]int{DifferenceTypeConflict: 1},
+			Summary:          map[DifferenceType]int{},
+			Conflicts:        conflicts,
+			HasMoreConflicts: hasMore,
 		}, err
 	}
 	if err != nil {
@@ -1074,6 +1450,61 @@ func (c *Catalog) Merge(ctx context.Context, repository string, destinationBranc
 	}, nil
 }
 
+func (c *Catalog) ListMergeConflicts(ctx context.Context, repository, destinationBranch, sourceRef string, limit int, after string) ([]*MergeConflict, bool, error) {
+	if limit < 0 || limit > MergeConflictsLimitMax {
+		limit = MergeConflictsLimitMax
+	}
+	repositoryID := graveler.RepositoryID(repository)
+	destination := graveler.BranchID(destinationBranch)
+	source := graveler.Ref(sourceRef)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"destination", destination, ValidateBranchID},
+		{"source", source, ValidateRef},
+	}); err != nil {
+		return nil, false, err
+	}
+	return c.listMergeConflicts(ctx, repositoryID, destination.Ref(), source, limit, after)
+}
+
+func (c *Catalog) listMergeConflicts(ctx context.Context, repositoryID graveler.RepositoryID, destination, source graveler.Ref, limit int, after string) ([]*MergeConflict, bool, error) {
+	it, err := c.Store.MergeConflicts(ctx, repositoryID, destination, source)
+	if err != nil {
+		return nil, false, err
+	}
+	defer it.Close()
+	afterKey := graveler.Key(after)
+	if after != "" {
+		it.SeekGE(afterKey)
+	}
+
+	var conflicts []*MergeConflict
+	for it.Next() {
+		v := it.Value()
+		if after != "" && bytes.Equal(v.Key, afterKey) {
+			continue
+		}
+		conflict, err := newMergeConflict(v)
+		if err != nil {
+			return nil, false, err
+		}
+		conflicts = append(conflicts, conflict)
+		if len(conflicts) >= limit+1 {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, false, err
+	}
+	// return results (optional trimmed) and hasMore
+	hasMore := false
+	if len(conflicts) > limit {
+		hasMore = true
+		conflicts = conflicts[:limit]
+	}
+	return conflicts, hasMore, nil
+}
+
 func (c *Catalog) DumpCommits(ctx context.Context, repositoryID string) (string, error) {
 	metaRangeID, err := c.Store.DumpCommits(ctx, graveler.RepositoryID(repositoryID))
 	if err != nil {
@@ -1082,6 +1513,15 @@ func (c *Catalog) DumpCommits(ctx context.Context, repositoryID string) (string,
 	return string(*metaRangeID), nil
 }
 
+// DumpCommitsSince dumps the commits added to repositoryID since the start time of a previous dump
+func (c *Catalog) DumpCommitsSince(ctx context.Context, repositoryID string, since time.Time) (string, error) {
+	metaRangeID, err := c.Store.DumpCommitsSince(ctx, graveler.RepositoryID(repositoryID), since)
+	if err != nil {
+		return "", err
+	}
+	return string(*metaRangeID), nil
+}
+
 func (c *Catalog) DumpBranches(ctx context.Context, repositoryID string) (string, error) {
 	metaRangeID, err := c.Store.DumpBranches(ctx, graveler.RepositoryID(repositoryID))
 	if err != nil {
@@ -1162,12 +1602,71 @@ func catalogDiffType(typ graveler.DiffType) (DifferenceType, error) {
 	}
 }
 
+func newMergeConflict(v *graveler.MergeConflict) (*MergeConflict, error) {
+	path := string(v.Key)
+	source, err := newMergeConflictEntry(path, v.Source)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := newMergeConflictEntry(path, v.Destination)
+	if err != nil {
+		return nil, err
+	}
+	base, err := newMergeConflictEntry(path, v.Base)
+	if err != nil {
+		return nil, err
+	}
+	return &MergeConflict{
+		Path:            path,
+		SourceType:      mergeConflictSideType(v.Base, v.Source),
+		DestinationType: mergeConflictSideType(v.Base, v.Destination),
+		Source:          source,
+		Destination:     destination,
+		Base:            base,
+	}, nil
+}
+
+func newMergeConflictEntry(path string, value *graveler.Value) (*DBEntry, error) {
+	if value == nil {
+		return nil, nil
+	}
+	ent, err := ValueToEntry(value)
+	if err != nil {
+		return nil, err
+	}
+	catEnt := newCatalogEntryFromEntry(false, path, ent)
+	return &catEnt, nil
+}
+
+// mergeConflictSideType returns the change made on one side of a merge, relative to the merge base
+func mergeConflictSideType(base, side *graveler.Value) DifferenceType {
+	switch {
+	case base == nil:
+		return DifferenceTypeAdded
+	case side == nil:
+		return DifferenceTypeRemoved
+	default:
+		return DifferenceTypeChanged
+	}
+}
+
 func newDifferenceFromEntryDiff(v *EntryDiff) (Difference, error) {
 	var (
 		diff Difference
 		err  error
 	)
-	diff.DBEntry = newCatalogEntryFromEntry(false, v.Path.String(), v.Entry)
+	diff.DBEntry = newCatalogEntryFromEntry(v.CommonPrefix != nil, v.Path.String(), v.Entry)
 	diff.Type, err = catalogDiffType(v.Type)
-	return diff, err
+	if err != nil || v.CommonPrefix == nil {
+		return diff, err
+	}
+	diff.Count = make(map[DifferenceType]int, len(v.CommonPrefix.Count))
+	for typ, count := range v.CommonPrefix.Count {
+		catalogType, err := catalogDiffType(typ)
+		if err != nil {
+			return diff, err
+		}
+		diff.Count[catalogType] = count
+	}
+	return diff, nil
 }
@@ -2,6 +2,8 @@ package catalog
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -466,3 +468,193 @@ func TestCatalog_ListEntries(t *testing.T) {
 		})
 	}
 }
+
+func TestCatalog_IfMatchChecksum(t *testing.T) {
+	value := MustEntryToValue(&Entry{Address: "addr1", ETag: "checksum1", Size: 1})
+	gravelerMock := &FakeGraveler{
+		KeyValue: map[string]*graveler.Value{
+			fakeGravelerBuildKey("repo", "main", graveler.Key("path/file1")): value,
+		},
+	}
+	c := &Catalog{
+		Store: gravelerMock,
+	}
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		path        string
+		checksum    string
+		expectedErr error
+	}{
+		{name: "match", path: "path/file1", checksum: "checksum1"},
+		{name: "mismatch", path: "path/file1", checksum: "checksum2", expectedErr: graveler.ErrPreconditionFailed},
+		{name: "missing", path: "path/file2", checksum: "checksum1", expectedErr: graveler.ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, err := c.IfMatchChecksum(ctx, "repo", "main", tt.path, tt.checksum)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("IfMatchChecksum() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+			var writeCondition graveler.WriteCondition
+			cond(&writeCondition)
+			if diff := deep.Equal(writeCondition, graveler.WriteCondition{IfMatch: value.Identity}); diff != nil {
+				t.Errorf("IfMatchChecksum() condition diff %s", diff)
+			}
+		})
+	}
+}
+
+func TestCatalog_ApplyTransaction(t *testing.T) {
+	ctx := context.Background()
+	sourceValue := MustEntryToValue(&Entry{Address: "s3://bucket/source", AddressType: Entry_FULL, ETag: "checksum1", Size: 1})
+	newFake := func() *FakeGraveler {
+		return &FakeGraveler{
+			KeyValue: map[string]*graveler.Value{
+				fakeGravelerBuildKey("repo", "main", graveler.Key("source")):  sourceValue,
+				fakeGravelerBuildKey("repo", "main", graveler.Key("deleted")): sourceValue,
+			},
+		}
+	}
+
+	t.Run("put link and delete", func(t *testing.T) {
+		gravelerMock := newFake()
+		c := &Catalog{Store: gravelerMock}
+		err := c.ApplyTransaction(ctx, "repo", "main", []TransactionOperation{
+			{Type: TransactionOperationPut, Path: "put", Entry: DBEntry{PhysicalAddress: "s3://bucket/put", AddressType: AddressTypeFull, Checksum: "checksum2", Size: 2}},
+			{Type: TransactionOperationLink, Path: "link", SourcePath: "source"},
+			{Type: TransactionOperationDelete, Path: "deleted"},
+		})
+		if err != nil {
+			t.Fatalf("ApplyTransaction() unexpected error: %v", err)
+		}
+		expected := map[string]string{"put": "checksum2", "link": "checksum1", "source": "checksum1"}
+		if len(gravelerMock.KeyValue) != len(expected) {
+			t.Fatalf("ApplyTransaction() got %d keys, expected %d", len(gravelerMock.KeyValue), len(expected))
+		}
+		for path, checksum := range expected {
+			ent, err := ValueToEntry(gravelerMock.KeyValue[fakeGravelerBuildKey("repo", "main", graveler.Key(path))])
+			if err != nil {
+				t.Fatalf("path %s: %v", path, err)
+			}
+			if ent.ETag != checksum {
+				t.Errorf("path %s checksum %s, expected %s", path, ent.ETag, checksum)
+			}
+		}
+	})
+
+	tests := []struct {
+		name        string
+		ops         []TransactionOperation
+		expectedErr error
+	}{
+		{name: "no operations", expectedErr: ErrInvalid},
+		{name: "unknown type", ops: []TransactionOperation{{Type: "copy", Path: "a"}}, expectedErr: ErrInvalid},
+		{name: "missing path", ops: []TransactionOperation{{Type: TransactionOperationDelete}}, expectedErr: ErrInvalid},
+		{
+			name: "missing link source",
+			ops: []TransactionOperation{
+				{Type: TransactionOperationDelete, Path: "deleted"},
+				{Type: TransactionOperationLink, Path: "link", SourcePath: "missing"},
+			},
+			expectedErr: graveler.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gravelerMock := newFake()
+			c := &Catalog{Store: gravelerMock}
+			err := c.ApplyTransaction(ctx, "repo", "main", tt.ops)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("ApplyTransaction() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if len(gravelerMock.KeyValue) != 2 {
+				t.Errorf("ApplyTransaction() changed staging on failure, got %d keys", len(gravelerMock.KeyValue))
+			}
+		})
+	}
+}
+
+func TestCatalog_BlamePagination(t *testing.T) {
+	commit := &graveler.CommitRecord{CommitID: "c1", Commit: &graveler.Commit{}}
+	records := []graveler.BlameRecord{
+		{Key: graveler.Key("a"), Commit: commit},
+		{Key: graveler.Key("b")},
+		{Key: graveler.Key("c"), Commit: commit},
+		{Key: graveler.Key("d"), Commit: commit},
+		{Key: graveler.Key("e")},
+	}
+	ctx := context.Background()
+	tests := []struct {
+		name            string
+		after           string
+		limit           int
+		expectedPaths   []string
+		expectedHasMore bool
+	}{
+		{name: "first page", limit: 2, expectedPaths: []string{"a", "b"}, expectedHasMore: true},
+		{name: "middle page", after: "b", limit: 2, expectedPaths: []string{"c", "d"}, expectedHasMore: true},
+		{name: "last page", after: "c", limit: 2, expectedPaths: []string{"d", "e"}},
+		{name: "exact page", limit: 5, expectedPaths: []string{"a", "b", "c", "d", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gravelerMock := &FakeGraveler{BlameRecords: records}
+			c := &Catalog{
+				Store: gravelerMock,
+			}
+			entries, hasMore, err := c.Blame(ctx, "repo", "main", "", tt.after, tt.limit)
+			if err != nil {
+				t.Fatalf("Blame() error = %v", err)
+			}
+			paths := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				paths = append(paths, entry.Path)
+				// keys no commit adds are listed without a commit
+				if (entry.Commit == nil) != (entry.Path == "b" || entry.Path == "e") {
+					t.Errorf("Blame() entry %s commit = %v", entry.Path, entry.Commit)
+				}
+			}
+			if diff := deep.Equal(paths, tt.expectedPaths); diff != nil {
+				t.Error("Blame() paths diff found", diff)
+			}
+			if hasMore != tt.expectedHasMore {
+				t.Errorf("Blame() hasMore = %t, expected %t", hasMore, tt.expectedHasMore)
+			}
+			if gravelerMock.BlameCalls != 1 {
+				t.Errorf("Blame() called the store %d times, expected once", gravelerMock.BlameCalls)
+			}
+		})
+	}
+}
+
+func TestCatalog_MergeConflicts(t *testing.T) {
+	var conflicts []*graveler.MergeConflict
+	for i := 0; i < MergeConflictsLimitDefault+1; i++ {
+		conflicts = append(conflicts, &graveler.MergeConflict{Key: graveler.Key(fmt.Sprintf("file%04d", i))})
+	}
+	gravelerMock := &FakeGraveler{
+		MergeErr:        &graveler.MergeConflictError{Source: "s1", Destination: "d1", Err: graveler.ErrConflictFound},
+		ConflictRecords: conflicts,
+	}
+	c := &Catalog{
+		Store: gravelerMock,
+	}
+	res, err := c.Merge(context.Background(), "repo", "main", "feature", MergeParams{Committer: "committer"})
+	if !errors.Is(err, graveler.ErrConflictFound) {
+		t.Fatalf("Merge() error = %v, expected ErrConflictFound", err)
+	}
+	// conflicts are listed between the commits the merge used, not the refs that may have moved since
+	if diff := deep.Equal(gravelerMock.MergeConflictRefs, []graveler.Ref{"d1", "s1"}); diff != nil {
+		t.Error("Merge() listed conflicts between unexpected refs", diff)
+	}
+	if len(res.Conflicts) != MergeConflictsLimitDefault || !res.HasMoreConflicts {
+		t.Errorf("Merge() got %d conflicts, has more %t, expected the first %d with more", len(res.Conflicts), res.HasMoreConflicts, MergeConflictsLimitDefault)
+	}
+	if count, ok := res.Summary[DifferenceTypeConflict]; ok {
+		t.Errorf("Merge() summary counts %d conflicts of a single page", count)
+	}
+}
@@ -13,6 +13,8 @@ const (
 type Difference struct {
 	DBEntry                // Partially filled. Path is always set.
 	Type    DifferenceType `db:"diff_type"`
+	// Count holds, for a common prefix (CommonLevel), the number of differences under it by type
+	Count map[DifferenceType]int
 }
 
 type DiffResultRecord struct {
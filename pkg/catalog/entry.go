@@ -18,6 +18,15 @@ func ValueToEntry(value *graveler.Value) (*Entry, error) {
 	return &ent, nil
 }
 
+// ValueSize returns the size of the object that the entry value describes
+func ValueSize(value *graveler.Value) (int64, error) {
+	ent, err := ValueToEntry(value)
+	if err != nil {
+		return 0, err
+	}
+	return ent.GetSize(), nil
+}
+
 func EntryToValue(entry *Entry) (*graveler.Value, error) {
 	// marshal data using pb
 	data, err := proto.Marshal(entry)
@@ -36,9 +36,10 @@ func (e *entryDiffIterator) Next() bool {
 	}
 	// return entry diff
 	e.value = &EntryDiff{
-		Type:  v.Type,
-		Path:  Path(v.Key),
-		Entry: entry,
+		Type:         v.Type,
+		Path:         Path(v.Key),
+		Entry:        entry,
+		CommonPrefix: v.CommonPrefix,
 	}
 	return true
 }
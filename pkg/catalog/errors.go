@@ -18,4 +18,5 @@ var (
 	ErrInvalidValue             = fmt.Errorf("invalid value: %w", ErrInvalid)
 	ErrNoDifferenceWasFound     = errors.New("no difference was found")
 	ErrConflictFound            = errors.New("conflict found")
+	ErrNoExpiryRow              = errors.New("no current expiry row")
 )
@@ -0,0 +1,481 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+// retentionDays returns the number of days commits are retained on branchID
+func (p ExpiryParams) retentionDays(branchID graveler.BranchID) int {
+	for _, rule := range p.Rules {
+		if matched, _ := path.Match(rule.BranchPattern, branchID.String()); matched {
+			return rule.RetentionDays
+		}
+	}
+	return p.DefaultRetentionDays
+}
+
+func (p ExpiryParams) validate() error {
+	if p.DefaultRetentionDays < 0 && p.DefaultRetentionDays != RetainForever {
+		return fmt.Errorf("default retention days %d: %w", p.DefaultRetentionDays, ErrInvalidValue)
+	}
+	for _, rule := range p.Rules {
+		if _, err := path.Match(rule.BranchPattern, ""); err != nil {
+			return fmt.Errorf("branch pattern %s: %w", rule.BranchPattern, ErrInvalidValue)
+		}
+		if rule.RetentionDays < 0 && rule.RetentionDays != RetainForever {
+			return fmt.Errorf("branch pattern %s retention days %d: %w", rule.BranchPattern, rule.RetentionDays, ErrInvalidValue)
+		}
+	}
+	return nil
+}
+
+// retentionCutoff returns the creation time before which commits retained for days expire, zero to retain forever
+func retentionCutoff(now time.Time, days int) time.Time {
+	if days == RetainForever {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -days)
+}
+
+// expiredCommit is a commit that is not retained, with the branch through which it was first reached
+type expiredCommit struct {
+	commitID graveler.CommitID
+	branchID graveler.BranchID
+}
+
+// ListExpired reports the lakeFS-owned physical addresses referenced only by commits that are not retained.
+// A commit is retained if it is tagged, is the head of a branch, or is newer than its retention: that of the
+// first branch reaching it, or the default retention for commits no branch reaches.
+// Objects written by full address (e.g. imported) are never reported, as lakeFS does not own them.  Objects
+// referenced by all commits and staging areas of other repositories (e.g. forks) are never reported either.
+//
+// Each metarange and range is read at most once: the ranges of retained commits are read first to collect
+// the live addresses, then the ranges of expired commits not already read are streamed by the returned rows.
+// Memory is bounded by the number of commits and ranges, and by the addresses of the retained and referenced
+// ranges.
+func (c *Catalog) ListExpired(ctx context.Context, repository string, params ExpiryParams) (ExpiryRows, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+	}); err != nil {
+		return nil, err
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	retained, expired, err := c.expiredCommits(ctx, repositoryID, params, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	repo, err := c.Store.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	rows := &expiryRows{
+		ctx:           ctx,
+		catalog:       c,
+		repositoryID:  repositoryID,
+		expired:       expired,
+		live:          make(map[string]struct{}),
+		seen:          newRangeSet(),
+		currentExpiry: -1,
+	}
+	if err := rows.collectLive(retained); err != nil {
+		return nil, err
+	}
+	if err := rows.collectReferenced(repo.StorageNamespace); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// expiredCommits splits the commits of the repository into retained and expired, following ExpiryParams
+func (c *Catalog) expiredCommits(ctx context.Context, repositoryID graveler.RepositoryID, params ExpiryParams, now time.Time) ([]graveler.CommitID, []expiredCommit, error) {
+	retained := make(map[graveler.CommitID]struct{})
+	// branch through which each non-retained commit was first reached
+	reachedBranch := make(map[graveler.CommitID]graveler.BranchID)
+
+	branches, err := c.Store.ListBranches(ctx, repositoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer branches.Close()
+	for branches.Next() {
+		branch := branches.Value()
+		if branch.CommitID == "" {
+			continue
+		}
+		retained[branch.CommitID] = struct{}{}
+		cutoff := retentionCutoff(now, params.retentionDays(branch.BranchID))
+		if err := c.markRetainedCommits(ctx, repositoryID, branch.BranchID, branch.CommitID, cutoff, retained, reachedBranch); err != nil {
+			return nil, nil, fmt.Errorf("log branch %s: %w", branch.BranchID, err)
+		}
+	}
+	if err := branches.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	tags, err := c.Store.ListTags(ctx, repositoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tags.Close()
+	for tags.Next() {
+		retained[tags.Value().CommitID] = struct{}{}
+	}
+	if err := tags.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// commits no branch reaches may still be needed (e.g. by a pending merge), keep them for the default retention
+	unreachableCutoff := retentionCutoff(now, params.DefaultRetentionDays)
+	commits, err := c.Store.ListCommits(ctx, repositoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer commits.Close()
+	var (
+		retainedIDs []graveler.CommitID
+		expired     []expiredCommit
+	)
+	for commits.Next() {
+		commit := commits.Value()
+		if _, ok := retained[commit.CommitID]; ok {
+			retainedIDs = append(retainedIDs, commit.CommitID)
+			continue
+		}
+		branchID, reached := reachedBranch[commit.CommitID]
+		if !reached && commit.CreationDate.After(unreachableCutoff) {
+			retainedIDs = append(retainedIDs, commit.CommitID)
+			continue
+		}
+		expired = append(expired, expiredCommit{commitID: commit.CommitID, branchID: branchID})
+	}
+	if err := commits.Err(); err != nil {
+		return nil, nil, err
+	}
+	return retainedIDs, expired, nil
+}
+
+// markRetainedCommits walks the log of branchID from head marking the commits created after cutoff as retained.
+// Older commits not yet reached through another branch are recorded in reachedBranch.
+func (c *Catalog) markRetainedCommits(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID, head graveler.CommitID, cutoff time.Time, retained map[graveler.CommitID]struct{}, reachedBranch map[graveler.CommitID]graveler.BranchID) error {
+	it, err := c.Store.Log(ctx, repositoryID, head, graveler.LogParams{})
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		v := it.Value()
+		if v.CreationDate.After(cutoff) {
+			retained[v.CommitID] = struct{}{}
+		} else if _, ok := reachedBranch[v.CommitID]; !ok {
+			reachedBranch[v.CommitID] = branchID
+		}
+	}
+	return it.Err()
+}
+
+// relativeAddress returns the address of a lakeFS-owned object of the entry value, or false if it has none
+func relativeAddress(value *graveler.Value) (string, bool, error) {
+	if value == nil {
+		return "", false, nil
+	}
+	ent, err := ValueToEntry(value)
+	if err != nil {
+		return "", false, err
+	}
+	if AddressType(ent.AddressType) != AddressTypeRelative {
+		return "", false, nil
+	}
+	return ent.Address, true, nil
+}
+
+func (c *Catalog) RemoveExpired(ctx context.Context, storageNamespace string, expired *ExpireResult) error {
+	return c.BlockAdapter.Remove(ctx, block.ObjectPointer{
+		StorageNamespace: storageNamespace,
+		Identifier:       expired.PhysicalAddress,
+		IdentifierType:   block.IdentifierTypeRelative,
+	})
+}
+
+// expiryRows streams the expired addresses by reading the ranges of the expired commits not read before
+type expiryRows struct {
+	ctx          context.Context
+	catalog      *Catalog
+	repositoryID graveler.RepositoryID
+	expired      []expiredCommit
+	// live holds the addresses of retained ranges and staging areas, and the addresses already reported
+	live map[string]struct{}
+	seen *rangeSet
+
+	currentExpiry   int
+	currentMetaIter graveler.MetaRangeIterator
+	value           *ExpireResult
+	err             error
+}
+
+// rangeSet holds the metaranges and ranges already read
+type rangeSet struct {
+	metaRanges map[graveler.MetaRangeID]struct{}
+	ranges     map[graveler.RangeID]struct{}
+}
+
+func newRangeSet() *rangeSet {
+	return &rangeSet{
+		metaRanges: make(map[graveler.MetaRangeID]struct{}),
+		ranges:     make(map[graveler.RangeID]struct{}),
+	}
+}
+
+// visitMetaRange calls fn on the values of the ranges of metaRangeID in repositoryID not in seen, adding them to seen
+func (c *Catalog) visitMetaRange(ctx context.Context, seen *rangeSet, repositoryID graveler.RepositoryID, metaRangeID graveler.MetaRangeID, fn func(*graveler.ValueRecord) error) error {
+	if metaRangeID == "" {
+		return nil
+	}
+	if _, ok := seen.metaRanges[metaRangeID]; ok {
+		return nil
+	}
+	seen.metaRanges[metaRangeID] = struct{}{}
+	it, err := c.Store.ListMetaRange(ctx, repositoryID, metaRangeID)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for hasNext := it.Next(); hasNext; {
+		rangeID, record := it.Value()
+		if record == nil {
+			if _, ok := seen.ranges[rangeID]; ok {
+				hasNext = it.NextRange()
+				continue
+			}
+			seen.ranges[rangeID] = struct{}{}
+		} else if err := fn(record); err != nil {
+			return err
+		}
+		hasNext = it.Next()
+	}
+	return it.Err()
+}
+
+// collectLive reads the addresses of the retained commits and of the staging areas
+func (r *expiryRows) collectLive(retained []graveler.CommitID) error {
+	addLive := func(record *graveler.ValueRecord) error {
+		address, ok, err := relativeAddress(record.Value)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", record.Key, err)
+		}
+		if ok {
+			r.live[address] = struct{}{}
+		}
+		return nil
+	}
+	for _, commitID := range retained {
+		commit, err := r.catalog.Store.GetCommit(r.ctx, r.repositoryID, commitID)
+		if err != nil {
+			return fmt.Errorf("get commit %s: %w", commitID, err)
+		}
+		if err := r.catalog.visitMetaRange(r.ctx, r.seen, r.repositoryID, commit.MetaRangeID, addLive); err != nil {
+			return fmt.Errorf("list commit %s: %w", commitID, err)
+		}
+	}
+	return r.catalog.visitStaged(r.ctx, r.repositoryID, addLive)
+}
+
+// collectReferenced reads the addresses on storageNamespace referenced by the commits and staging areas of
+// other repositories
+func (r *expiryRows) collectReferenced(storageNamespace graveler.StorageNamespace) error {
+	repositories, err := r.catalog.Store.ListReferencingRepositories(r.ctx, storageNamespace)
+	if err != nil {
+		return fmt.Errorf("list referencing repositories: %w", err)
+	}
+	if len(repositories) == 0 {
+		return nil
+	}
+	qk, err := block.ResolveNamespace(storageNamespace.String(), "", block.IdentifierTypeRelative)
+	if err != nil {
+		return err
+	}
+	namespacePrefix := qk.Format()
+	addReferenced := func(record *graveler.ValueRecord) error {
+		if record.Value == nil {
+			return nil
+		}
+		ent, err := ValueToEntry(record.Value)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", record.Key, err)
+		}
+		if AddressType(ent.AddressType) == AddressTypeFull && strings.HasPrefix(ent.Address, namespacePrefix) {
+			r.live[strings.TrimPrefix(ent.Address, namespacePrefix)] = struct{}{}
+		}
+		return nil
+	}
+	for _, repositoryID := range repositories {
+		if repositoryID == r.repositoryID {
+			continue
+		}
+		if err := r.collectRepository(repositoryID, addReferenced); err != nil {
+			return fmt.Errorf("referencing repository %s: %w", repositoryID, err)
+		}
+	}
+	return nil
+}
+
+// collectRepository calls fn on the values of all commits and staging areas of repositoryID
+func (r *expiryRows) collectRepository(repositoryID graveler.RepositoryID, fn func(*graveler.ValueRecord) error) error {
+	seen := newRangeSet()
+	commits, err := r.catalog.Store.ListCommits(r.ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	defer commits.Close()
+	for commits.Next() {
+		commit := commits.Value()
+		if err := r.catalog.visitMetaRange(r.ctx, seen, repositoryID, commit.MetaRangeID, fn); err != nil {
+			return fmt.Errorf("list commit %s: %w", commit.CommitID, err)
+		}
+	}
+	if err := commits.Err(); err != nil {
+		return err
+	}
+	return r.catalog.visitStaged(r.ctx, repositoryID, fn)
+}
+
+// visitStaged calls fn on the values staged on all branches of repositoryID
+func (c *Catalog) visitStaged(ctx context.Context, repositoryID graveler.RepositoryID, fn func(*graveler.ValueRecord) error) error {
+	branches, err := c.Store.ListBranches(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	defer branches.Close()
+	for branches.Next() {
+		branchID := branches.Value().BranchID
+		it, err := c.Store.DiffUncommitted(ctx, repositoryID, branchID)
+		if err != nil {
+			return fmt.Errorf("list staging of branch %s: %w", branchID, err)
+		}
+		for it.Next() {
+			diff := it.Value()
+			if diff.Type == graveler.DiffTypeRemoved {
+				continue
+			}
+			if err := fn(&graveler.ValueRecord{Key: diff.Key, Value: diff.Value}); err != nil {
+				it.Close()
+				return err
+			}
+		}
+		err = it.Err()
+		it.Close()
+		if err != nil {
+			return fmt.Errorf("list staging of branch %s: %w", branchID, err)
+		}
+	}
+	return branches.Err()
+}
+
+func (r *expiryRows) Close() {
+	if r.currentMetaIter != nil {
+		r.currentMetaIter.Close()
+		r.currentMetaIter = nil
+	}
+}
+
+// nextMetaRange moves to the next expired commit with a metarange not read before
+func (r *expiryRows) nextMetaRange() bool {
+	r.Close()
+	for r.currentExpiry+1 < len(r.expired) {
+		r.currentExpiry++
+		commitID := r.expired[r.currentExpiry].commitID
+		commit, err := r.catalog.Store.GetCommit(r.ctx, r.repositoryID, commitID)
+		if err != nil {
+			r.err = fmt.Errorf("get commit %s: %w", commitID, err)
+			return false
+		}
+		if _, ok := r.seen.metaRanges[commit.MetaRangeID]; ok || commit.MetaRangeID == "" {
+			continue
+		}
+		r.seen.metaRanges[commit.MetaRangeID] = struct{}{}
+		r.currentMetaIter, err = r.catalog.Store.ListMetaRange(r.ctx, r.repositoryID, commit.MetaRangeID)
+		if err != nil {
+			r.err = fmt.Errorf("list commit %s: %w", commitID, err)
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func (r *expiryRows) Next() bool {
+	r.value = nil
+	if r.err != nil {
+		return false
+	}
+	for {
+		if r.currentMetaIter == nil {
+			if !r.nextMetaRange() {
+				return false
+			}
+		}
+		if !r.currentMetaIter.Next() {
+			if err := r.currentMetaIter.Err(); err != nil {
+				r.err = err
+				return false
+			}
+			r.Close()
+			continue
+		}
+		rangeID, record := r.currentMetaIter.Value()
+		for record == nil {
+			if _, ok := r.seen.ranges[rangeID]; !ok {
+				r.seen.ranges[rangeID] = struct{}{}
+				break
+			}
+			// range already read, as live or expired
+			if !r.currentMetaIter.NextRange() {
+				break
+			}
+			rangeID, record = r.currentMetaIter.Value()
+		}
+		if record == nil {
+			continue
+		}
+		address, ok, err := relativeAddress(record.Value)
+		if err != nil {
+			r.err = fmt.Errorf("key %s: %w", record.Key, err)
+			return false
+		}
+		if !ok {
+			continue
+		}
+		if _, ok := r.live[address]; ok {
+			continue
+		}
+		r.live[address] = struct{}{}
+		r.value = &ExpireResult{
+			Repository:        r.repositoryID.String(),
+			Branch:            r.expired[r.currentExpiry].branchID.String(),
+			PhysicalAddress:   address,
+			InternalReference: record.Key.String(),
+		}
+		return true
+	}
+}
+
+func (r *expiryRows) Err() error {
+	return r.err
+}
+
+func (r *expiryRows) Read() (*ExpireResult, error) {
+	if r.value == nil {
+		return nil, ErrNoExpiryRow
+	}
+	return r.value, nil
+}
@@ -0,0 +1,316 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/local"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func TestCatalog_ListExpired(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+	record := func(name string) *graveler.ValueRecord {
+		return &graveler.ValueRecord{
+			Key:   graveler.Key("path/" + name),
+			Value: MustEntryToValue(&Entry{Address: "addr-" + name, AddressType: Entry_RELATIVE}),
+		}
+	}
+	records := func(names ...string) []*graveler.ValueRecord {
+		var res []*graveler.ValueRecord
+		for _, name := range names {
+			res = append(res, record(name))
+		}
+		return res
+	}
+	imported := &graveler.ValueRecord{
+		Key:   graveler.Key("path/imported"),
+		Value: MustEntryToValue(&Entry{Address: "s3://bucket/imported", AddressType: Entry_FULL}),
+	}
+
+	gravelerMock := &FakeGraveler{
+		BranchIteratorFactory: NewFakeBranchIteratorFactory([]*graveler.BranchRecord{
+			{BranchID: "feature/x", Branch: &graveler.Branch{CommitID: "f2"}},
+			{BranchID: "main", Branch: &graveler.Branch{CommitID: "c3"}},
+		}),
+		TagIteratorFactory: NewFakeTagIteratorFactory([]*graveler.TagRecord{
+			{TagID: "v1", CommitID: "t1"},
+		}),
+		Commits: map[graveler.CommitID]*graveler.Commit{
+			"c1": {CreationDate: daysAgo(60), MetaRangeID: "mr-c1"},
+			"c2": {CreationDate: daysAgo(40), MetaRangeID: "mr-c2", Parents: graveler.CommitParents{"c1"}},
+			"c3": {CreationDate: daysAgo(1), MetaRangeID: "mr-c3", Parents: graveler.CommitParents{"c2"}},
+			"d1": {CreationDate: daysAgo(90), MetaRangeID: "mr-d1"},
+			"d2": {CreationDate: daysAgo(5), MetaRangeID: "mr-d2"},
+			"f1": {CreationDate: daysAgo(10), MetaRangeID: "mr-f1", Parents: graveler.CommitParents{"c1"}},
+			"f2": {CreationDate: daysAgo(2), MetaRangeID: "mr-f2", Parents: graveler.CommitParents{"f1"}},
+			"f3": {CreationDate: daysAgo(50), MetaRangeID: "mr-f1", Parents: graveler.CommitParents{"f1"}},
+			"t1": {CreationDate: daysAgo(100), MetaRangeID: "mr-t1"},
+		},
+		MetaRanges: map[graveler.MetaRangeID][]FakeRange{
+			"mr-c1": {{ID: "r-ab", Values: append(records("a", "b"), imported)}},
+			"mr-c2": {{ID: "r-ac", Values: records("a", "c")}},
+			"mr-c3": {{ID: "r-c", Values: records("c")}, {ID: "r-d", Values: records("d")}},
+			"mr-d1": {{ID: "r-gs", Values: records("g", "s")}},
+			"mr-d2": {{ID: "r-k", Values: records("k")}},
+			"mr-f1": {{ID: "r-ab", Values: append(records("a", "b"), imported)}, {ID: "r-e", Values: records("e")}},
+			"mr-f2": {{ID: "r-bf", Values: records("b", "f")}},
+			"mr-t1": {{ID: "r-c", Values: records("c")}, {ID: "r-h", Values: records("h")}},
+		},
+		StagedValues: map[graveler.BranchID][]*graveler.ValueRecord{
+			"main": records("s"),
+		},
+	}
+	c := &Catalog{
+		Store: gravelerMock,
+	}
+
+	ctx := context.Background()
+	params := ExpiryParams{
+		DefaultRetentionDays: 30,
+		Rules:                []RetentionRule{{BranchPattern: "feature/*", RetentionDays: 7}},
+	}
+	rows, err := c.ListExpired(ctx, "repo", params)
+	if err != nil {
+		t.Fatalf("ListExpired() error = %v", err)
+	}
+	defer rows.Close()
+	var got []*ExpireResult
+	for rows.Next() {
+		res, err := rows.Read()
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		got = append(got, res)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("ListExpired() rows error = %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool {
+		return got[i].PhysicalAddress < got[j].PhysicalAddress
+	})
+	// unreachable d2 is newer than the default retention, staged s is live
+	want := []*ExpireResult{
+		{Repository: "repo", Branch: "feature/x", PhysicalAddress: "addr-a", InternalReference: "path/a"},
+		{Repository: "repo", Branch: "feature/x", PhysicalAddress: "addr-e", InternalReference: "path/e"},
+		{Repository: "repo", Branch: "", PhysicalAddress: "addr-g", InternalReference: "path/g"},
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Error("ListExpired() diff found", diff)
+	}
+	for rangeID, reads := range gravelerMock.RangeReads {
+		if reads > 1 {
+			t.Errorf("range %s read %d times, expected once", rangeID, reads)
+		}
+	}
+}
+
+func TestCatalog_ListExpiredInvalidParams(t *testing.T) {
+	c := &Catalog{
+		Store: &FakeGraveler{},
+	}
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		params ExpiryParams
+	}{
+		{name: "negative default", params: ExpiryParams{DefaultRetentionDays: -2}},
+		{name: "negative rule", params: ExpiryParams{Rules: []RetentionRule{{BranchPattern: "main", RetentionDays: -2}}}},
+		{name: "bad pattern", params: ExpiryParams{Rules: []RetentionRule{{BranchPattern: "[", RetentionDays: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.ListExpired(ctx, "repo", tt.params)
+			if !errors.Is(err, ErrInvalidValue) {
+				t.Fatalf("ListExpired() error = %v, expected %s", err, ErrInvalidValue)
+			}
+		})
+	}
+}
+
+// multiRepositoryGraveler serves the listings of each repository from its own fake, and everything else
+// (e.g. Get and storage namespace references) from the embedded fake
+type multiRepositoryGraveler struct {
+	*FakeGraveler
+	repositories map[graveler.RepositoryID]*graveler.Repository
+	fakes        map[graveler.RepositoryID]*FakeGraveler
+}
+
+func (m *multiRepositoryGraveler) GetRepository(_ context.Context, repositoryID graveler.RepositoryID) (*graveler.Repository, error) {
+	repo, ok := m.repositories[repositoryID]
+	if !ok {
+		return nil, graveler.ErrRepositoryNotFound
+	}
+	return repo, nil
+}
+
+func (m *multiRepositoryGraveler) ListBranches(ctx context.Context, repositoryID graveler.RepositoryID) (graveler.BranchIterator, error) {
+	return m.fakes[repositoryID].ListBranches(ctx, repositoryID)
+}
+
+func (m *multiRepositoryGraveler) ListTags(ctx context.Context, repositoryID graveler.RepositoryID) (graveler.TagIterator, error) {
+	return m.fakes[repositoryID].ListTags(ctx, repositoryID)
+}
+
+func (m *multiRepositoryGraveler) ListCommits(ctx context.Context, repositoryID graveler.RepositoryID) (graveler.CommitIterator, error) {
+	return m.fakes[repositoryID].ListCommits(ctx, repositoryID)
+}
+
+func (m *multiRepositoryGraveler) Log(ctx context.Context, repositoryID graveler.RepositoryID, commitID graveler.CommitID, params graveler.LogParams) (graveler.CommitIterator, error) {
+	return m.fakes[repositoryID].Log(ctx, repositoryID, commitID, params)
+}
+
+func (m *multiRepositoryGraveler) GetCommit(ctx context.Context, repositoryID graveler.RepositoryID, commitID graveler.CommitID) (*graveler.Commit, error) {
+	return m.fakes[repositoryID].GetCommit(ctx, repositoryID, commitID)
+}
+
+func (m *multiRepositoryGraveler) ListMetaRange(ctx context.Context, repositoryID graveler.RepositoryID, metaRangeID graveler.MetaRangeID) (graveler.MetaRangeIterator, error) {
+	return m.fakes[repositoryID].ListMetaRange(ctx, repositoryID, metaRangeID)
+}
+
+func (m *multiRepositoryGraveler) DiffUncommitted(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID) (graveler.DiffIterator, error) {
+	return m.fakes[repositoryID].DiffUncommitted(ctx, repositoryID, branchID)
+}
+
+func TestCatalog_ListExpiredReferencedByFork(t *testing.T) {
+	ctx := context.Background()
+	adapter, err := local.NewAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("local adapter: %s", err)
+	}
+	const (
+		sourceNamespace = "local://source"
+		forkNamespace   = "local://fork"
+	)
+	for _, name := range []string{"a", "b", "c"} {
+		err := adapter.Put(ctx, block.ObjectPointer{
+			StorageNamespace: sourceNamespace,
+			Identifier:       "addr-" + name,
+			IdentifierType:   block.IdentifierTypeRelative,
+		}, int64(len(name)), strings.NewReader(name), block.PutOpts{})
+		if err != nil {
+			t.Fatalf("put %s: %s", name, err)
+		}
+	}
+	relative := func(name string) *graveler.ValueRecord {
+		return &graveler.ValueRecord{
+			Key:   graveler.Key(name),
+			Value: MustEntryToValue(&Entry{Address: "addr-" + name, AddressType: Entry_RELATIVE}),
+		}
+	}
+	full := func(name string) *graveler.ValueRecord {
+		return &graveler.ValueRecord{
+			Key:   graveler.Key(name),
+			Value: MustEntryToValue(&Entry{Address: sourceNamespace + "/addr-" + name, AddressType: Entry_FULL}),
+		}
+	}
+
+	// the source deleted a and c after the fork of c1, the fork still reads a
+	now := time.Now()
+	source := &FakeGraveler{
+		BranchIteratorFactory: NewFakeBranchIteratorFactory([]*graveler.BranchRecord{
+			{BranchID: "main", Branch: &graveler.Branch{CommitID: "c2"}},
+		}),
+		TagIteratorFactory: NewFakeTagIteratorFactory(nil),
+		Commits: map[graveler.CommitID]*graveler.Commit{
+			"c1": {CreationDate: now.AddDate(0, 0, -60), MetaRangeID: "mr-c1"},
+			"c2": {CreationDate: now.AddDate(0, 0, -1), MetaRangeID: "mr-c2", Parents: graveler.CommitParents{"c1"}},
+		},
+		MetaRanges: map[graveler.MetaRangeID][]FakeRange{
+			"mr-c1": {{ID: "r-abc", Values: []*graveler.ValueRecord{relative("a"), relative("b"), relative("c")}}},
+			"mr-c2": {{ID: "r-b", Values: []*graveler.ValueRecord{relative("b")}}},
+		},
+		StagedValues: map[graveler.BranchID][]*graveler.ValueRecord{},
+	}
+	fork := &FakeGraveler{
+		BranchIteratorFactory: NewFakeBranchIteratorFactory([]*graveler.BranchRecord{
+			{BranchID: "main", Branch: &graveler.Branch{CommitID: "f1"}},
+		}),
+		TagIteratorFactory: NewFakeTagIteratorFactory(nil),
+		Commits: map[graveler.CommitID]*graveler.Commit{
+			"f1": {CreationDate: now.AddDate(0, 0, -50), MetaRangeID: "mr-f1"},
+		},
+		MetaRanges: map[graveler.MetaRangeID][]FakeRange{
+			"mr-f1": {{ID: "r-f1", Values: []*graveler.ValueRecord{full("a"), full("b")}}},
+		},
+		StagedValues: map[graveler.BranchID][]*graveler.ValueRecord{},
+	}
+	store := &multiRepositoryGraveler{
+		FakeGraveler: &FakeGraveler{
+			KeyValue: map[string]*graveler.Value{
+				fakeGravelerBuildKey("fork", "main", graveler.Key("a")): full("a").Value,
+			},
+			NamespaceReferences: map[graveler.RepositoryID][]graveler.StorageNamespace{
+				"fork": {sourceNamespace},
+			},
+		},
+		repositories: map[graveler.RepositoryID]*graveler.Repository{
+			"source": {StorageNamespace: sourceNamespace},
+			"fork":   {StorageNamespace: forkNamespace},
+		},
+		fakes: map[graveler.RepositoryID]*FakeGraveler{
+			"source": source,
+			"fork":   fork,
+		},
+	}
+	c := &Catalog{
+		BlockAdapter: adapter,
+		Store:        store,
+	}
+
+	// gc the source
+	rows, err := c.ListExpired(ctx, "source", ExpiryParams{DefaultRetentionDays: 30})
+	if err != nil {
+		t.Fatalf("ListExpired() error = %v", err)
+	}
+	defer rows.Close()
+	var removed []string
+	for rows.Next() {
+		res, err := rows.Read()
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if err := c.RemoveExpired(ctx, sourceNamespace, res); err != nil {
+			t.Fatalf("RemoveExpired(%s) error = %v", res.PhysicalAddress, err)
+		}
+		removed = append(removed, res.PhysicalAddress)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("ListExpired() rows error = %v", err)
+	}
+	if diff := deep.Equal(removed, []string{"addr-c"}); diff != nil {
+		t.Error("ListExpired() diff found", diff)
+	}
+
+	// read from the fork
+	entry, err := c.GetEntry(ctx, "fork", "main", "a", GetEntryParams{})
+	if err != nil {
+		t.Fatalf("GetEntry() error = %v", err)
+	}
+	reader, err := adapter.Get(ctx, block.ObjectPointer{
+		StorageNamespace: forkNamespace,
+		Identifier:       entry.PhysicalAddress,
+		IdentifierType:   block.IdentifierTypeFull,
+	}, entry.Size)
+	if err != nil {
+		t.Fatalf("read %s from fork: %s", entry.PhysicalAddress, err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read %s from fork: %s", entry.PhysicalAddress, err)
+	}
+	if string(data) != "a" {
+		t.Errorf("read %s from fork got %q, expected %q", entry.PhysicalAddress, data, "a")
+	}
+}
@@ -3,9 +3,12 @@ package catalog
 import (
 	"bytes"
 	"context"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/testutil"
 )
 
 type FakeGraveler struct {
@@ -16,7 +19,28 @@ type FakeGraveler struct {
 	RepositoryIteratorFactory func() graveler.RepositoryIterator
 	BranchIteratorFactory     func() graveler.BranchIterator
 	TagIteratorFactory        func() graveler.TagIterator
-	hooks                     graveler.HooksHandler
+	// MetaRanges holds the ranges ListMetaRange returns for each metarange
+	MetaRanges map[graveler.MetaRangeID][]FakeRange
+	// RangeReads counts the times the values of each range were read
+	RangeReads map[graveler.RangeID]int
+	// StagedValues, when set, holds the values DiffUncommitted returns as added for each branch
+	StagedValues   map[graveler.BranchID][]*graveler.ValueRecord
+	Commits        map[graveler.CommitID]*graveler.Commit
+	RetentionRules []*graveler.RetentionRule
+	ExpiredCommits []graveler.CommitID
+	// NamespaceReferences holds the storage namespaces each repository references
+	NamespaceReferences map[graveler.RepositoryID][]graveler.StorageNamespace
+	// BlameRecords holds the sorted records Blame pages over
+	BlameRecords []graveler.BlameRecord
+	// BlameCalls counts the calls to Blame
+	BlameCalls int
+	// MergeErr is returned by Merge
+	MergeErr error
+	// ConflictRecords holds the sorted conflicts MergeConflicts returns, and MergeConflictRefs the destination
+	// and source refs it was called with
+	ConflictRecords   []*graveler.MergeConflict
+	MergeConflictRefs []graveler.Ref
+	hooks             graveler.HooksHandler
 }
 
 func (g *FakeGraveler) CreateBareRepository(ctx context.Context, repositoryID graveler.RepositoryID, storageNamespace graveler.StorageNamespace, branchID graveler.BranchID) (*graveler.Repository, error) {
@@ -39,6 +63,10 @@ func (g *FakeGraveler) DumpCommits(ctx context.Context, repositoryID graveler.Re
 	panic("implement me")
 }
 
+func (g *FakeGraveler) DumpCommitsSince(ctx context.Context, repositoryID graveler.RepositoryID, since time.Time) (*graveler.MetaRangeID, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) DumpBranches(ctx context.Context, repositoryID graveler.RepositoryID) (*graveler.MetaRangeID, error) {
 	panic("implement me")
 }
@@ -68,6 +96,11 @@ func (g *FakeGraveler) Get(_ context.Context, repositoryID graveler.RepositoryID
 	if v == nil {
 		return nil, graveler.ErrNotFound
 	}
+	for _, commitID := range g.ExpiredCommits {
+		if ref == graveler.Ref(commitID) {
+			return v, graveler.ErrCommitExpired
+		}
+	}
 	return v, nil
 }
 
@@ -84,15 +117,33 @@ func (g *FakeGraveler) Delete(ctx context.Context, repositoryID graveler.Reposit
 	panic("implement me")
 }
 
-func (g *FakeGraveler) List(_ context.Context, _ graveler.RepositoryID, _ graveler.Ref) (graveler.ValueIterator, error) {
+func (g *FakeGraveler) Apply(_ context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID, ops []graveler.StagingOperation) error {
+	if g.Err != nil {
+		return g.Err
+	}
+	for _, op := range ops {
+		k := fakeGravelerBuildKey(repositoryID, graveler.Ref(branchID.String()), op.Key)
+		if op.Value == nil {
+			delete(g.KeyValue, k)
+		} else {
+			g.KeyValue[k] = op.Value
+		}
+	}
+	return nil
+}
+
+func (g *FakeGraveler) List(_ context.Context, _ graveler.RepositoryID, ref graveler.Ref) (graveler.ValueIterator, error) {
 	if g.Err != nil {
 		return nil, g.Err
 	}
 	return g.ListIteratorFactory(), nil
 }
 
-func (g *FakeGraveler) GetRepository(ctx context.Context, repositoryID graveler.RepositoryID) (*graveler.Repository, error) {
-	panic("implement me")
+func (g *FakeGraveler) GetRepository(_ context.Context, _ graveler.RepositoryID) (*graveler.Repository, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	return &graveler.Repository{}, nil
 }
 
 func (g *FakeGraveler) CreateRepository(ctx context.Context, repositoryID graveler.RepositoryID, storageNamespace graveler.StorageNamespace, branchID graveler.BranchID) (*graveler.Repository, error) {
@@ -110,11 +161,15 @@ func (g *FakeGraveler) DeleteRepository(ctx context.Context, repositoryID gravel
 	panic("implement me")
 }
 
+func (g *FakeGraveler) UpdateRepository(ctx context.Context, repositoryID graveler.RepositoryID, update graveler.RepositoryUpdate) (*graveler.Repository, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) CreateBranch(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID, ref graveler.Ref) (*graveler.Branch, error) {
 	panic("implement me")
 }
 
-func (g *FakeGraveler) UpdateBranch(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID, ref graveler.Ref) (*graveler.Branch, error) {
+func (g *FakeGraveler) UpdateBranch(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID, ref graveler.Ref, expectedHead graveler.CommitID) (*graveler.Branch, error) {
 	panic("implement me")
 }
 
@@ -142,7 +197,11 @@ func (g *FakeGraveler) GetTag(ctx context.Context, repositoryID graveler.Reposit
 	panic("implement me")
 }
 
-func (g *FakeGraveler) CreateTag(ctx context.Context, repositoryID graveler.RepositoryID, tagID graveler.TagID, commitID graveler.CommitID) error {
+func (g *FakeGraveler) GetTagRecord(_ context.Context, _ graveler.RepositoryID, _ graveler.TagID) (*graveler.TagRecord, error) {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) CreateTag(_ context.Context, _ graveler.RepositoryID, _ graveler.TagID, _ graveler.CommitID, _ *graveler.TagAnnotation) error {
 	panic("implement me")
 }
 
@@ -157,10 +216,113 @@ func (g *FakeGraveler) ListTags(ctx context.Context, repositoryID graveler.Repos
 	return g.TagIteratorFactory(), nil
 }
 
-func (g *FakeGraveler) Log(ctx context.Context, repositoryID graveler.RepositoryID, commitID graveler.CommitID) (graveler.CommitIterator, error) {
+func (g *FakeGraveler) GetBranchProtectionRules(_ context.Context, _ graveler.RepositoryID) ([]*graveler.BranchProtectionRule, error) {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) CreateBranchProtectionRule(_ context.Context, _ graveler.RepositoryID, _ graveler.BranchProtectionRule) error {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) DeleteBranchProtectionRule(_ context.Context, _ graveler.RepositoryID, _ string) error {
 	panic("implement me")
 }
 
+func (g *FakeGraveler) GetRetentionRules(_ context.Context, _ graveler.RepositoryID) ([]*graveler.RetentionRule, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	return g.RetentionRules, nil
+}
+
+func (g *FakeGraveler) SetRetentionRules(_ context.Context, _ graveler.RepositoryID, rules []*graveler.RetentionRule) error {
+	if g.Err != nil {
+		return g.Err
+	}
+	g.RetentionRules = rules
+	return nil
+}
+
+func (g *FakeGraveler) SetExpiredCommits(_ context.Context, _ graveler.RepositoryID, commitIDs []graveler.CommitID) error {
+	if g.Err != nil {
+		return g.Err
+	}
+	g.ExpiredCommits = commitIDs
+	return nil
+}
+
+func (g *FakeGraveler) AddStorageNamespaceReferences(_ context.Context, repositoryID graveler.RepositoryID, storageNamespaces []graveler.StorageNamespace) error {
+	if g.Err != nil {
+		return g.Err
+	}
+	if g.NamespaceReferences == nil {
+		g.NamespaceReferences = make(map[graveler.RepositoryID][]graveler.StorageNamespace)
+	}
+	g.NamespaceReferences[repositoryID] = append(g.NamespaceReferences[repositoryID], storageNamespaces...)
+	return nil
+}
+
+func (g *FakeGraveler) ListStorageNamespaceReferences(_ context.Context, repositoryID graveler.RepositoryID) ([]graveler.StorageNamespace, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	return g.NamespaceReferences[repositoryID], nil
+}
+
+func (g *FakeGraveler) ListReferencingRepositories(_ context.Context, storageNamespace graveler.StorageNamespace) ([]graveler.RepositoryID, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	var repositories []graveler.RepositoryID
+	for repositoryID, namespaces := range g.NamespaceReferences {
+		for _, ns := range namespaces {
+			if ns == storageNamespace {
+				repositories = append(repositories, repositoryID)
+				break
+			}
+		}
+	}
+	return repositories, nil
+}
+
+func (g *FakeGraveler) Log(_ context.Context, _ graveler.RepositoryID, commitID graveler.CommitID, _ graveler.LogParams) (graveler.CommitIterator, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	var records []graveler.CommitRecord
+	seen := make(map[graveler.CommitID]bool)
+	queue := []graveler.CommitID{commitID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		commit, ok := g.Commits[id]
+		if !ok {
+			return nil, graveler.ErrNotFound
+		}
+		records = append(records, graveler.CommitRecord{CommitID: id, Commit: commit})
+		queue = append(queue, commit.Parents...)
+	}
+	return testutil.NewCommitIteratorFake(records), nil
+}
+
+func (g *FakeGraveler) ListCommits(_ context.Context, _ graveler.RepositoryID) (graveler.CommitIterator, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	records := make([]graveler.CommitRecord, 0, len(g.Commits))
+	for id, commit := range g.Commits {
+		records = append(records, graveler.CommitRecord{CommitID: id, Commit: commit})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CommitID < records[j].CommitID
+	})
+	return testutil.NewCommitIteratorFake(records), nil
+}
+
 func (g *FakeGraveler) ListBranches(_ context.Context, _ graveler.RepositoryID) (graveler.BranchIterator, error) {
 	if g.Err != nil {
 		return nil, g.Err
@@ -176,8 +338,15 @@ func (g *FakeGraveler) Commit(ctx context.Context, repositoryID graveler.Reposit
 	panic("implement me")
 }
 
-func (g *FakeGraveler) GetCommit(ctx context.Context, repositoryID graveler.RepositoryID, commitID graveler.CommitID) (*graveler.Commit, error) {
-	panic("implement me")
+func (g *FakeGraveler) GetCommit(_ context.Context, _ graveler.RepositoryID, commitID graveler.CommitID) (*graveler.Commit, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	commit, ok := g.Commits[commitID]
+	if !ok {
+		return nil, graveler.ErrNotFound
+	}
+	return commit, nil
 }
 
 func (g *FakeGraveler) Dereference(ctx context.Context, repositoryID graveler.RepositoryID, ref graveler.Ref) (graveler.CommitID, error) {
@@ -200,7 +369,14 @@ func (g *FakeGraveler) Revert(_ context.Context, _ graveler.RepositoryID, _ grav
 	panic("implement me")
 }
 
-func (g *FakeGraveler) Merge(ctx context.Context, repositoryID graveler.RepositoryID, destination graveler.BranchID, source graveler.Ref, _ graveler.CommitParams) (graveler.CommitID, graveler.DiffSummary, error) {
+func (g *FakeGraveler) CherryPick(_ context.Context, _ graveler.RepositoryID, _ graveler.BranchID, _ graveler.Ref, _ int, _ graveler.CommitParams) (graveler.CommitID, graveler.DiffSummary, error) {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) Merge(ctx context.Context, repositoryID graveler.RepositoryID, destination graveler.BranchID, source graveler.Ref, _ graveler.CommitParams, _ graveler.MergeParams) (graveler.CommitID, graveler.DiffSummary, error) {
+	if g.MergeErr != nil {
+		return "", graveler.DiffSummary{}, g.MergeErr
+	}
 	panic("implement me")
 }
 
@@ -208,27 +384,98 @@ func (g *FakeGraveler) DiffUncommitted(ctx context.Context, repositoryID gravele
 	if g.Err != nil {
 		return nil, g.Err
 	}
+	if g.StagedValues != nil {
+		var diffs []*graveler.Diff
+		for _, record := range g.StagedValues[branchID] {
+			diffs = append(diffs, &graveler.Diff{Type: graveler.DiffTypeAdded, Key: record.Key, Value: record.Value})
+		}
+		return NewFakeDiffIterator(diffs), nil
+	}
 	return g.DiffIteratorFactory(), nil
 }
 
-func (g *FakeGraveler) Diff(_ context.Context, _ graveler.RepositoryID, _, _ graveler.Ref) (graveler.DiffIterator, error) {
+func (g *FakeGraveler) Diff(_ context.Context, _ graveler.RepositoryID, _, _ graveler.Ref, _ graveler.DiffParams) (graveler.DiffIterator, error) {
 	if g.Err != nil {
 		return nil, g.Err
 	}
 	return g.DiffIteratorFactory(), nil
 }
 
-func (g *FakeGraveler) Compare(_ context.Context, _ graveler.RepositoryID, _, _ graveler.Ref) (graveler.DiffIterator, error) {
+func (g *FakeGraveler) Compare(_ context.Context, _ graveler.RepositoryID, _, _ graveler.Ref, _ graveler.DiffParams) (graveler.DiffIterator, error) {
 	if g.Err != nil {
 		return nil, g.Err
 	}
 	return g.DiffIteratorFactory(), nil
 }
 
+func (g *FakeGraveler) AheadBehind(_ context.Context, _ graveler.RepositoryID, _, _ graveler.Ref) (int, int, error) {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) DiffSummary(_ context.Context, _ graveler.RepositoryID, _, _ graveler.Ref, _ graveler.Key) (graveler.DiffSummary, error) {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) Rebase(_ context.Context, _ graveler.RepositoryID, _ graveler.BranchID, _ graveler.Ref) (graveler.CommitID, error) {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) MergeConflicts(_ context.Context, _ graveler.RepositoryID, destination, source graveler.Ref) (graveler.MergeConflictIterator, error) {
+	g.MergeConflictRefs = []graveler.Ref{destination, source}
+	return &fakeMergeConflictIterator{conflicts: g.ConflictRecords, idx: -1}, nil
+}
+
+type fakeMergeConflictIterator struct {
+	conflicts []*graveler.MergeConflict
+	idx       int
+}
+
+func (m *fakeMergeConflictIterator) Next() bool {
+	m.idx++
+	return m.idx < len(m.conflicts)
+}
+
+func (m *fakeMergeConflictIterator) SeekGE(id graveler.Key) {
+	m.idx = sort.Search(len(m.conflicts), func(i int) bool {
+		return bytes.Compare(m.conflicts[i].Key, id) >= 0
+	}) - 1
+}
+
+func (m *fakeMergeConflictIterator) Value() *graveler.MergeConflict {
+	if m.idx < 0 || m.idx >= len(m.conflicts) {
+		return nil
+	}
+	return m.conflicts[m.idx]
+}
+
+func (m *fakeMergeConflictIterator) Err() error {
+	return nil
+}
+
+func (m *fakeMergeConflictIterator) Close() {}
+
+func (g *FakeGraveler) Blame(_ context.Context, _ graveler.RepositoryID, _ graveler.Ref, prefix, after graveler.Key, amount int) ([]graveler.BlameRecord, error) {
+	g.BlameCalls++
+	var records []graveler.BlameRecord
+	for _, record := range g.BlameRecords {
+		if len(records) == amount {
+			break
+		}
+		if bytes.HasPrefix(record.Key, prefix) && bytes.Compare(record.Key, after) > 0 {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
 func (g *FakeGraveler) SetHooksHandler(handler graveler.HooksHandler) {
 	g.hooks = handler
 }
 
+func (g *FakeGraveler) SetBranchRunChecker(_ graveler.BranchRunChecker) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) AddCommitToBranchHead(ctx context.Context, repositoryID graveler.RepositoryID, branchID graveler.BranchID, commit graveler.Commit) (graveler.CommitID, error) {
 	panic("implement me")
 }
@@ -245,10 +492,73 @@ func (g *FakeGraveler) WriteMetaRange(_ context.Context, _ graveler.RepositoryID
 	panic("implement me")
 }
 
+func (g *FakeGraveler) ListMetaRange(_ context.Context, _ graveler.RepositoryID, metaRangeID graveler.MetaRangeID) (graveler.MetaRangeIterator, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	ranges, ok := g.MetaRanges[metaRangeID]
+	if !ok {
+		return nil, graveler.ErrNotFound
+	}
+	if g.RangeReads == nil {
+		g.RangeReads = make(map[graveler.RangeID]int)
+	}
+	return &FakeMetaRangeIterator{Ranges: ranges, RangeIndex: -1, reads: g.RangeReads}, nil
+}
+
 func (g *FakeGraveler) GetStagingToken(_ context.Context, _ graveler.RepositoryID, _ graveler.BranchID) (*graveler.StagingToken, error) {
 	panic("implement me")
 }
 
+// FakeRange is a range of a metarange listed by FakeMetaRangeIterator
+type FakeRange struct {
+	ID     graveler.RangeID
+	Values []*graveler.ValueRecord
+}
+
+// FakeMetaRangeIterator lists ranges, returning a nil value for the header of each range
+type FakeMetaRangeIterator struct {
+	Ranges     []FakeRange
+	RangeIndex int
+	// ValueIndex is -1 on the header of the current range
+	ValueIndex int
+	reads      map[graveler.RangeID]int
+}
+
+func (m *FakeMetaRangeIterator) Next() bool {
+	if m.RangeIndex >= 0 && m.RangeIndex < len(m.Ranges) && m.ValueIndex+1 < len(m.Ranges[m.RangeIndex].Values) {
+		if m.ValueIndex == -1 && m.reads != nil {
+			m.reads[m.Ranges[m.RangeIndex].ID]++
+		}
+		m.ValueIndex++
+		return true
+	}
+	return m.NextRange()
+}
+
+func (m *FakeMetaRangeIterator) NextRange() bool {
+	if m.RangeIndex >= len(m.Ranges) {
+		return false
+	}
+	m.RangeIndex++
+	m.ValueIndex = -1
+	return m.RangeIndex < len(m.Ranges)
+}
+
+func (m *FakeMetaRangeIterator) Value() (graveler.RangeID, *graveler.ValueRecord) {
+	rng := m.Ranges[m.RangeIndex]
+	if m.ValueIndex < 0 {
+		return rng.ID, nil
+	}
+	return rng.ID, rng.Values[m.ValueIndex]
+}
+
+func (m *FakeMetaRangeIterator) Err() error {
+	return nil
+}
+
+func (m *FakeMetaRangeIterator) Close() {}
+
 type FakeValueIterator struct {
 	Data  []*graveler.ValueRecord
 	Index int
@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"fmt"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"google.golang.org/protobuf/proto"
+)
+
+// fullAddressIterator rewrites the entries of values read from a repository on storageNamespace to
+// use full physical addresses, so they remain valid when read from any other repository.
+type fullAddressIterator struct {
+	it               graveler.ValueIterator
+	storageNamespace string
+	value            *graveler.ValueRecord
+	err              error
+}
+
+func NewFullAddressIterator(it graveler.ValueIterator, storageNamespace string) graveler.ValueIterator {
+	return &fullAddressIterator{
+		it:               it,
+		storageNamespace: storageNamespace,
+	}
+}
+
+func (f *fullAddressIterator) Next() bool {
+	if f.err != nil {
+		return false
+	}
+	if !f.it.Next() {
+		f.value = nil
+		f.err = f.it.Err()
+		return false
+	}
+	v := f.it.Value()
+	value, err := f.fullAddressValue(v.Value)
+	if err != nil {
+		f.value = nil
+		f.err = fmt.Errorf("%s: %w", v.Key, err)
+		return false
+	}
+	f.value = &graveler.ValueRecord{
+		Key:   v.Key,
+		Value: value,
+	}
+	return true
+}
+
+func (f *fullAddressIterator) fullAddressValue(value *graveler.Value) (*graveler.Value, error) {
+	ent, err := ValueToEntry(value)
+	if err != nil {
+		return nil, err
+	}
+	if ent.AddressType == Entry_FULL {
+		return value, nil
+	}
+	qk, err := block.ResolveNamespace(f.storageNamespace, ent.Address, block.IdentifierType(ent.AddressType))
+	if err != nil {
+		return nil, err
+	}
+	ent.Address = qk.Format()
+	ent.AddressType = Entry_FULL
+	data, err := proto.Marshal(ent)
+	if err != nil {
+		return nil, err
+	}
+	// the address is not part of the identity, which stays the same
+	return &graveler.Value{
+		Identity: value.Identity,
+		Data:     data,
+	}, nil
+}
+
+func (f *fullAddressIterator) SeekGE(id graveler.Key) {
+	f.value = nil
+	f.err = nil
+	f.it.SeekGE(id)
+}
+
+func (f *fullAddressIterator) Value() *graveler.ValueRecord {
+	return f.value
+}
+
+func (f *fullAddressIterator) Err() error {
+	return f.err
+}
+
+func (f *fullAddressIterator) Close() {
+	f.it.Close()
+}
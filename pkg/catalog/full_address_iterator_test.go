@@ -0,0 +1,50 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func TestFullAddressIterator(t *testing.T) {
+	entries := []*EntryRecord{
+		{Path: "full", Entry: &Entry{Address: "s3://other-bucket/path/to/object", AddressType: Entry_FULL, Size: 1}},
+		{Path: "relative", Entry: &Entry{Address: "data/object", AddressType: Entry_RELATIVE, Size: 2}},
+		{Path: "unknown", Entry: &Entry{Address: "object", AddressType: Entry_BY_PREFIX_DEPRECATED, Size: 3}},
+	}
+	expected := []*EntryRecord{
+		{Path: "full", Entry: &Entry{Address: "s3://other-bucket/path/to/object", AddressType: Entry_FULL, Size: 1}},
+		{Path: "relative", Entry: &Entry{Address: "s3://bucket/repo/data/object", AddressType: Entry_FULL, Size: 2}},
+		{Path: "unknown", Entry: &Entry{Address: "s3://bucket/repo/object", AddressType: Entry_FULL, Size: 3}},
+	}
+	var valueRecords []*graveler.ValueRecord
+	for _, ent := range entries {
+		valueRecords = append(valueRecords, &graveler.ValueRecord{
+			Key:   graveler.Key(ent.Path),
+			Value: MustEntryToValue(ent.Entry),
+		})
+	}
+
+	it := NewValueToEntryIterator(NewFullAddressIterator(NewFakeValueIterator(valueRecords), "s3://bucket/repo"))
+	defer it.Close()
+	var got []*EntryRecord
+	for it.Next() {
+		got = append(got, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := deep.Equal(got, expected); diff != nil {
+		t.Fatal("full address iterator found diff:", diff)
+	}
+
+	// identities are kept, so the rewritten entries do not show up as changes
+	valueIt := NewFullAddressIterator(NewFakeValueIterator(valueRecords), "s3://bucket/repo")
+	defer valueIt.Close()
+	for i := 0; valueIt.Next(); i++ {
+		if diff := deep.Equal(valueIt.Value().Identity, valueRecords[i].Identity); diff != nil {
+			t.Errorf("identity of %s changed: %s", valueIt.Value().Key, diff)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package catalog
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 )
@@ -15,6 +16,15 @@ type DiffParams struct {
 	Limit            int
 	After            string
 	AdditionalFields []string // db fields names that will be load in additional to Path on Difference's Entry
+	Prefix           string   // return only differences on paths starting with Prefix
+	Delimiter        string   // group differences on paths sharing the part up to the next Delimiter after Prefix
+}
+
+func (p DiffParams) gravelerParams() graveler.DiffParams {
+	return graveler.DiffParams{
+		Prefix:    graveler.Key(p.Prefix),
+		Delimiter: graveler.Key(p.Delimiter),
+	}
 }
 
 type RevertParams struct {
@@ -23,6 +33,97 @@ type RevertParams struct {
 	Committer    string
 }
 
+type CherryPickParams struct {
+	Reference    string // the commit to cherry-pick
+	ParentNumber int    // if cherry-picking a merge commit, the change will be taken relative to this parent number (1-based).
+	Committer    string
+	Message      string // optional, defaults to a message referring to the cherry-picked commit
+}
+
+type MergeParams struct {
+	Committer string
+	Message   string // optional, defaults to a message referring to the source and destination
+	Metadata  Metadata
+	Strategy  string // conflict resolution: "source-wins", "dest-wins" or empty to fail the merge on conflict
+	Squash    bool   // record the merge as a single-parent commit summarizing the squashed commits
+	Mode      string // "ff-only" to move the destination to the source commit, or "no-ff" (default) to record a merge commit
+	// ExpectedHead, if set, fails the merge with graveler.ErrBranchHeadMismatch unless it is the destination head
+	ExpectedHead string
+}
+
+// CommitOption sets optional parameters of Commit
+type CommitOption func(params *graveler.CommitParams)
+
+// WithExpectedHead fails the commit with graveler.ErrBranchHeadMismatch unless commitID is the branch head
+func WithExpectedHead(commitID string) CommitOption {
+	return func(params *graveler.CommitParams) {
+		params.ExpectedHead = graveler.CommitID(commitID)
+	}
+}
+
+// LogOption sets optional filters of ListCommits
+type LogOption func(params *graveler.LogParams)
+
+// WithPathPrefix lists only commits that change a path starting with prefix relative to their first parent
+func WithPathPrefix(prefix string) LogOption {
+	return func(params *graveler.LogParams) {
+		params.Prefix = graveler.Key(prefix)
+	}
+}
+
+// WithFirstParent follows only the first parent of merge commits
+func WithFirstParent() LogOption {
+	return func(params *graveler.LogParams) {
+		params.FirstParent = true
+	}
+}
+
+// WithCommitter lists only commits by committer
+func WithCommitter(committer string) LogOption {
+	return func(params *graveler.LogParams) {
+		params.Committer = committer
+	}
+}
+
+// WithSince lists only commits created at or after since
+func WithSince(since time.Time) LogOption {
+	return func(params *graveler.LogParams) {
+		params.Since = &since
+	}
+}
+
+// WithUntil lists only commits created at or before until
+func WithUntil(until time.Time) LogOption {
+	return func(params *graveler.LogParams) {
+		params.Until = &until
+	}
+}
+
+// WithMetadata lists only commits whose metadata holds all the key/values of metadata
+func WithMetadata(metadata Metadata) LogOption {
+	return func(params *graveler.LogParams) {
+		params.Metadata = graveler.Metadata(metadata)
+	}
+}
+
+// RetainForever as retention days retains all commits of the matching branches
+const RetainForever = -1
+
+// RetentionRule keeps commits on branches matching BranchPattern for RetentionDays
+type RetentionRule struct {
+	BranchPattern string // branch ID glob, in path.Match syntax
+	RetentionDays int
+}
+
+// ExpiryParams configures which commits ListExpired retains.  Branch heads, tagged commits and
+// staged objects are always retained.
+type ExpiryParams struct {
+	// DefaultRetentionDays applies to branches that match none of Rules, and to commits no branch reaches.
+	DefaultRetentionDays int
+	// Rules are matched in order, the first rule matching a branch sets its retention.
+	Rules []RetentionRule
+}
+
 type ExpireResult struct {
 	Repository        string
 	Branch            string
@@ -55,12 +156,19 @@ type Interface interface {
 	// defaultBranchID will point to a non-existent branch on creation, it is up to the caller to eventually create it.
 	CreateBareRepository(ctx context.Context, repository string, storageNamespace string, defaultBranchID string) (*Repository, error)
 
+	// CreateRepositoryFrom creates a new repository pointing to 'storageNamespace' with default branch name 'branch'
+	// starting at 'sourceReference' of 'sourceRepository', whose objects it references without copying them
+	CreateRepositoryFrom(ctx context.Context, repository string, storageNamespace string, branch string, sourceRepository string, sourceReference string, committer string) (*Repository, error)
+
 	// GetRepository get repository information
 	GetRepository(ctx context.Context, repository string) (*Repository, error)
 
 	// DeleteRepository delete a repository
 	DeleteRepository(ctx context.Context, repository string) error
 
+	// UpdateRepository changes the name, default branch, description or metadata of a repository, as set on params
+	UpdateRepository(ctx context.Context, repository string, params UpdateRepositoryParams) (*Repository, error)
+
 	// ListRepositories list repositories information, the bool returned is true when more repositories can be listed.
 	// In this case pass the last repository name as 'after' on the next call to ListRepositories
 	ListRepositories(ctx context.Context, limit int, prefix, after string) ([]*Repository, bool, error)
@@ -74,36 +182,93 @@ type Interface interface {
 	GetBranchReference(ctx context.Context, repository, branch string) (string, error)
 	ResetBranch(ctx context.Context, repository, branch string) error
 
-	CreateTag(ctx context.Context, repository, tagID string, ref string) (string, error)
+	// CreateTag creates a tag on the commit of ref and returns the commit ID.  The tag is annotated if
+	// annotation is not nil, its creation date defaults to now.
+	CreateTag(ctx context.Context, repository, tagID string, ref string, annotation *TagAnnotation) (string, error)
 	DeleteTag(ctx context.Context, repository, tagID string) error
 	ListTags(ctx context.Context, repository string, limit int, after string) ([]*Tag, bool, error)
-	GetTag(ctx context.Context, repository, tagID string) (string, error)
+	GetTag(ctx context.Context, repository, tagID string) (*Tag, error)
+
+	// ListBranchProtectionRules lists the rules protecting repository branches from direct writes and commits
+	ListBranchProtectionRules(ctx context.Context, repository string) ([]*BranchProtectionRule, error)
+	CreateBranchProtectionRule(ctx context.Context, repository string, rule BranchProtectionRule) error
+	DeleteBranchProtectionRule(ctx context.Context, repository string, pattern string) error
+	// IsBranchProtected returns true if branch matches any of the repository branch protection rules
+	IsBranchProtected(ctx context.Context, repository, branch string) (bool, error)
 
 	// GetEntry returns the current entry for path in repository branch reference.  Returns
 	// the entry with ExpiredError if it has expired from underlying storage.
 	GetEntry(ctx context.Context, repository, reference string, path string, params GetEntryParams) (*DBEntry, error)
 	CreateEntry(ctx context.Context, repository, branch string, entry DBEntry, writeConditions ...graveler.WriteConditionOption) error
+	// IfMatchChecksum returns a CreateEntry write condition that succeeds only while the entry at path on
+	// branch has checksum, returns graveler.ErrPreconditionFailed if it does not
+	IfMatchChecksum(ctx context.Context, repository, branch, path, checksum string) (graveler.WriteConditionOption, error)
 	CreateEntries(ctx context.Context, repository, branch string, entries []DBEntry) error
 	DeleteEntry(ctx context.Context, repository, branch string, path string) error
+	// ApplyTransaction stages all operations on branch atomically: either all of them are staged or none is
+	ApplyTransaction(ctx context.Context, repository, branch string, ops []TransactionOperation) error
 	ListEntries(ctx context.Context, repository, reference string, prefix, after string, delimiter string, limit int) ([]*DBEntry, bool, error)
 	ResetEntry(ctx context.Context, repository, branch string, path string) error
 	ResetEntries(ctx context.Context, repository, branch string, prefix string) error
 
-	Commit(ctx context.Context, repository, branch string, message string, committer string, metadata Metadata) (*CommitLog, error)
+	Commit(ctx context.Context, repository, branch string, message string, committer string, metadata Metadata, opts ...CommitOption) (*CommitLog, error)
 	GetCommit(ctx context.Context, repository, reference string) (*CommitLog, error)
-	ListCommits(ctx context.Context, repository, branch string, fromReference string, limit int) ([]*CommitLog, bool, error)
+	// Dereference returns the ID of the commit that reference points to
+	Dereference(ctx context.Context, repository, reference string) (string, error)
+	ListCommits(ctx context.Context, repository, branch string, fromReference string, limit int, opts ...LogOption) ([]*CommitLog, bool, error)
+
+	// Blame returns, for each object under prefix at reference, the commit that last changed it
+	Blame(ctx context.Context, repository, reference string, prefix, after string, limit int) ([]*BlameEntry, bool, error)
 
 	// Revert creates a reverse patch to the given commit, and applies it as a new commit on the given branch.
 	Revert(ctx context.Context, repository, branch string, params RevertParams) error
 
+	// CherryPick applies the changes introduced by the given commit on top of the given branch, as a new commit.
+	CherryPick(ctx context.Context, repository, branch string, params CherryPickParams) (*CommitLog, error)
+
+	// Rebase replays the commits of branch since its merge base with onto on top of onto, and returns the new head commit.
+	Rebase(ctx context.Context, repository, branch, onto string) (*CommitLog, error)
+
 	Diff(ctx context.Context, repository, leftReference string, rightReference string, params DiffParams) (Differences, bool, error)
 	Compare(ctx context.Context, repository, leftReference string, rightReference string, params DiffParams) (Differences, bool, error)
-	DiffUncommitted(ctx context.Context, repository, branch string, limit int, after string) (Differences, bool, error)
+	DiffUncommitted(ctx context.Context, repository, branch string, params DiffParams) (Differences, bool, error)
+
+	// AheadBehind returns the number of commits reachable from reference but not from otherReference, and the
+	// number reachable from otherReference but not from reference
+	AheadBehind(ctx context.Context, repository, reference, otherReference string) (ahead, behind int, err error)
+
+	// DiffSummary counts the differences between leftReference and rightReference on paths starting with prefix,
+	// without listing them
+	DiffSummary(ctx context.Context, repository, leftReference, rightReference string, prefix string) (*DiffSummary, error)
+
+	// Merge merges sourceRef into destinationBranch, conflicts are resolved according to params.Strategy.
+	Merge(ctx context.Context, repository, destinationBranch, sourceRef string, params MergeParams) (*MergeResult, error)
+
+	// ListMergeConflicts lists the paths that conflict when merging sourceRef into destinationBranch
+	ListMergeConflicts(ctx context.Context, repository, destinationBranch, sourceRef string, limit int, after string) ([]*MergeConflict, bool, error)
+
+	// ListExpired returns the physical addresses of repository objects that are no longer referenced by any
+	// retained commit or staging area, according to the branch retention rules in params.
+	ListExpired(ctx context.Context, repository string, params ExpiryParams) (ExpiryRows, error)
+
+	// RemoveExpired deletes the physical object of an expired entry from storageNamespace.  It does not check
+	// the entry again, so writes to the repository should stop between ListExpired and RemoveExpired.
+	RemoveExpired(ctx context.Context, storageNamespace string, expired *ExpireResult) error
+
+	// GetRetentionRules returns the repository retention rules, in the order they are matched.
+	GetRetentionRules(ctx context.Context, repository string) ([]RetentionRule, error)
+
+	// SetRetentionRules replaces the repository retention rules.  Branches matching no rule are retained forever.
+	SetRetentionRules(ctx context.Context, repository string, rules []RetentionRule) error
 
-	Merge(ctx context.Context, repository, destinationBranch, sourceRef, committer, message string, metadata Metadata) (*MergeResult, error)
+	// EnforceRetention marks the commits expired by the repository retention rules, and returns the number of
+	// expired commits.  GetEntry fails reading lakeFS-owned entries of marked commits with ErrExpired, unless
+	// ReturnExpired is set.
+	EnforceRetention(ctx context.Context, repository string) (int, error)
 
 	// dump/load metadata
 	DumpCommits(ctx context.Context, repositoryID string) (string, error)
+	DumpCommitsSince(ctx context.Context, repositoryID string, since time.Time) (string, error)
 	DumpBranches(ctx context.Context, repositoryID string) (string, error)
 	DumpTags(ctx context.Context, repositoryID string) (string, error)
 	LoadCommits(ctx context.Context, repositoryID, commitsMetaRangeID string) error
@@ -21,6 +21,16 @@ type Repository struct {
 	StorageNamespace string    `db:"storage_namespace"`
 	DefaultBranch    string    `db:"default_branch"`
 	CreationDate     time.Time `db:"creation_date"`
+	Description      string    `db:"description"`
+	Metadata         Metadata  `db:"metadata"`
+}
+
+// UpdateRepositoryParams holds the repository settings to change, nil fields are left unchanged
+type UpdateRepositoryParams struct {
+	Name          *string
+	DefaultBranch *string
+	Description   *string
+	Metadata      Metadata
 }
 
 type DBEntry struct {
@@ -45,9 +55,39 @@ type CommitLog struct {
 	Parents      []string
 }
 
+// BlameEntry holds the commit that last changed the object at Path, nil when no commit in the history
+// of the blamed reference adds the object
+type BlameEntry struct {
+	Path   string
+	Commit *CommitLog
+}
+
+// DiffSummary holds the number of differences by type, and their size in bytes: the total size of added and
+// removed objects, and for changed objects how much they grew
+type DiffSummary struct {
+	Count map[DifferenceType]int
+	Bytes map[DifferenceType]int64
+}
+
 type MergeResult struct {
 	Summary   map[DifferenceType]int
 	Reference string
+	// Conflicts holds the first page of conflicting paths when the merge failed on conflicts, which are not
+	// counted in Summary
+	Conflicts        []*MergeConflict
+	HasMoreConflicts bool
+}
+
+// MergeConflict describes a path changed on both source and destination of a merge.
+// SourceType and DestinationType are the changes made on each side relative to the merge base,
+// and a nil entry means the path does not exist on that side.
+type MergeConflict struct {
+	Path            string
+	SourceType      DifferenceType
+	DestinationType DifferenceType
+	Source          *DBEntry
+	Destination     *DBEntry
+	Base            *DBEntry
 }
 
 type Branch struct {
@@ -58,6 +98,21 @@ type Branch struct {
 type Tag struct {
 	ID       string
 	CommitID string
+	// Annotation is nil for a lightweight tag
+	Annotation *TagAnnotation
+}
+
+// TagAnnotation records who created an annotated tag, when and why
+type TagAnnotation struct {
+	Message      string
+	Creator      string
+	CreationDate time.Time
+	Metadata     Metadata
+}
+
+type BranchProtectionRule struct {
+	Pattern           string
+	RequirePassingRun bool
 }
 
 // AddressType is the type of an entry address
@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func (c *Catalog) GetRetentionRules(ctx context.Context, repository string) ([]RetentionRule, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := c.Store.GetRepository(ctx, repositoryID); err != nil {
+		return nil, err
+	}
+	rules, err := c.Store.GetRetentionRules(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]RetentionRule, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, RetentionRule{
+			BranchPattern: rule.BranchPattern,
+			RetentionDays: rule.RetentionDays,
+		})
+	}
+	return res, nil
+}
+
+func (c *Catalog) SetRetentionRules(ctx context.Context, repository string, rules []RetentionRule) error {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+	}); err != nil {
+		return err
+	}
+	if err := (ExpiryParams{Rules: rules}).validate(); err != nil {
+		return err
+	}
+	if _, err := c.Store.GetRepository(ctx, repositoryID); err != nil {
+		return err
+	}
+	storeRules := make([]*graveler.RetentionRule, 0, len(rules))
+	for _, rule := range rules {
+		storeRules = append(storeRules, &graveler.RetentionRule{
+			BranchPattern: rule.BranchPattern,
+			RetentionDays: rule.RetentionDays,
+		})
+	}
+	return c.Store.SetRetentionRules(ctx, repositoryID, storeRules)
+}
+
+// EnforceRetention marks the commits not retained by the repository retention rules as expired, returning
+// their number.  Commits no rule matches are retained.  Forks read their own commits, so marking never
+// affects them; ListExpired keeps the objects forks reference.
+func (c *Catalog) EnforceRetention(ctx context.Context, repository string) (int, error) {
+	rules, err := c.GetRetentionRules(ctx, repository)
+	if err != nil {
+		return 0, err
+	}
+	repositoryID := graveler.RepositoryID(repository)
+	var expiredIDs []graveler.CommitID
+	if len(rules) > 0 {
+		_, expired, err := c.expiredCommits(ctx, repositoryID, ExpiryParams{
+			DefaultRetentionDays: RetainForever,
+			Rules:                rules,
+		}, time.Now())
+		if err != nil {
+			return 0, err
+		}
+		expiredIDs = make([]graveler.CommitID, 0, len(expired))
+		for _, commit := range expired {
+			expiredIDs = append(expiredIDs, commit.commitID)
+		}
+	}
+	if err := c.Store.SetExpiredCommits(ctx, repositoryID, expiredIDs); err != nil {
+		return 0, fmt.Errorf("mark expired commits: %w", err)
+	}
+	return len(expiredIDs), nil
+}
@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+func TestCatalog_EnforceRetention(t *testing.T) {
+	now := time.Now()
+	gravelerMock := &FakeGraveler{
+		BranchIteratorFactory: NewFakeBranchIteratorFactory([]*graveler.BranchRecord{
+			{BranchID: "feature/x", Branch: &graveler.Branch{CommitID: "f2"}},
+			{BranchID: "main", Branch: &graveler.Branch{CommitID: "c2"}},
+		}),
+		TagIteratorFactory: NewFakeTagIteratorFactory(nil),
+		Commits: map[graveler.CommitID]*graveler.Commit{
+			"c1": {CreationDate: now.AddDate(0, 0, -60)},
+			"c2": {CreationDate: now.AddDate(0, 0, -40), Parents: graveler.CommitParents{"c1"}},
+			"f1": {CreationDate: now.AddDate(0, 0, -10), Parents: graveler.CommitParents{"c1"}},
+			"f2": {CreationDate: now.AddDate(0, 0, -2), Parents: graveler.CommitParents{"f1"}},
+		},
+	}
+	c := &Catalog{
+		Store: gravelerMock,
+	}
+	ctx := context.Background()
+
+	// no rules retain everything
+	expired, err := c.EnforceRetention(ctx, "repo")
+	if err != nil {
+		t.Fatalf("EnforceRetention() error = %v", err)
+	}
+	if expired != 0 || len(gravelerMock.ExpiredCommits) != 0 {
+		t.Fatalf("EnforceRetention() without rules expired %d commits: %v", expired, gravelerMock.ExpiredCommits)
+	}
+
+	err = c.SetRetentionRules(ctx, "repo", []RetentionRule{{BranchPattern: "feature/*", RetentionDays: 7}})
+	if err != nil {
+		t.Fatalf("SetRetentionRules() error = %v", err)
+	}
+	expired, err = c.EnforceRetention(ctx, "repo")
+	if err != nil {
+		t.Fatalf("EnforceRetention() error = %v", err)
+	}
+	// c1 is also on main, which matches no rule and is retained forever
+	want := []graveler.CommitID{"f1"}
+	if diff := deep.Equal(gravelerMock.ExpiredCommits, want); diff != nil {
+		t.Error("EnforceRetention() expired commits diff found", diff)
+	}
+	if expired != len(want) {
+		t.Errorf("EnforceRetention() = %d, expected %d", expired, len(want))
+	}
+}
+
+func TestCatalog_GetEntryExpired(t *testing.T) {
+	gravelerMock := &FakeGraveler{
+		KeyValue: map[string]*graveler.Value{
+			fakeGravelerBuildKey("repo", "c1", graveler.Key("owned")):    MustEntryToValue(&Entry{Address: "addr-owned", AddressType: Entry_RELATIVE}),
+			fakeGravelerBuildKey("repo", "c1", graveler.Key("imported")): MustEntryToValue(&Entry{Address: "s3://bucket/imported", AddressType: Entry_FULL}),
+			fakeGravelerBuildKey("repo", "c2", graveler.Key("owned")):    MustEntryToValue(&Entry{Address: "addr-owned", AddressType: Entry_RELATIVE}),
+		},
+		ExpiredCommits: []graveler.CommitID{"c1"},
+	}
+	c := &Catalog{
+		Store: gravelerMock,
+	}
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		ref           string
+		path          string
+		returnExpired bool
+		expectedErr   error
+		expired       bool
+	}{
+		{name: "retained", ref: "c2", path: "owned"},
+		{name: "expired", ref: "c1", path: "owned", expectedErr: ErrExpired, expired: true},
+		{name: "return expired", ref: "c1", path: "owned", returnExpired: true, expired: true},
+		{name: "imported", ref: "c1", path: "imported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := c.GetEntry(ctx, "repo", tt.ref, tt.path, GetEntryParams{ReturnExpired: tt.returnExpired})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("GetEntry() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if entry == nil {
+				t.Fatal("GetEntry() returned no entry")
+			}
+			if entry.Expired != tt.expired {
+				t.Errorf("GetEntry() expired = %t, expected %t", entry.Expired, tt.expired)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+// MaxTransactionOperations is the maximum number of operations ApplyTransaction accepts
+const MaxTransactionOperations = 10000
+
+// TransactionOperationType is the kind of change a TransactionOperation stages
+type TransactionOperationType string
+
+const (
+	// TransactionOperationPut stages Entry on Path
+	TransactionOperationPut TransactionOperationType = "put"
+	// TransactionOperationLink stages on Path the object found on SourcePath of SourceRef
+	TransactionOperationLink TransactionOperationType = "link"
+	// TransactionOperationDelete deletes Path
+	TransactionOperationDelete TransactionOperationType = "delete"
+)
+
+var ErrInvalidTransactionOperation = fmt.Errorf("transaction operation: %w", ErrInvalid)
+
+// TransactionOperation is a single change staged by ApplyTransaction
+type TransactionOperation struct {
+	Type       TransactionOperationType
+	Path       string
+	Entry      DBEntry // put: the staged entry, its path is taken from Path
+	SourceRef  string  // link: reference to read the source object from, defaults to the branch
+	SourcePath string  // link: path of the source object
+}
+
+func (c *Catalog) ApplyTransaction(ctx context.Context, repository, branch string, ops []TransactionOperation) error {
+	repositoryID := graveler.RepositoryID(repository)
+	branchID := graveler.BranchID(branch)
+	if err := Validate([]ValidateArg{
+		{"repositoryID", repositoryID, ValidateRepositoryID},
+		{"branchID", branchID, ValidateBranchID},
+	}); err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return fmt.Errorf("no operations: %w", ErrInvalidTransactionOperation)
+	}
+	if len(ops) > MaxTransactionOperations {
+		return fmt.Errorf("%d operations exceed limit of %d: %w", len(ops), MaxTransactionOperations, ErrInvalidTransactionOperation)
+	}
+
+	stagingOps := make([]graveler.StagingOperation, 0, len(ops))
+	for i, op := range ops {
+		p := Path(op.Path)
+		if err := Validate([]ValidateArg{{"path", p, ValidatePath}}); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+		stagingOp := graveler.StagingOperation{Key: graveler.Key(p)}
+		switch op.Type {
+		case TransactionOperationPut:
+			value, err := EntryToValue(EntryFromCatalogEntry(op.Entry))
+			if err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+			stagingOp.Value = value
+		case TransactionOperationLink:
+			value, err := c.linkValue(ctx, repository, branch, op)
+			if err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+			stagingOp.Value = value
+		case TransactionOperationDelete:
+		default:
+			return fmt.Errorf("operation %d type '%s': %w", i, op.Type, ErrInvalidTransactionOperation)
+		}
+		stagingOps = append(stagingOps, stagingOp)
+	}
+	return c.Store.Apply(ctx, repositoryID, branchID, stagingOps)
+}
+
+// linkValue returns the value staged by a link operation: the source object entry, modified now
+func (c *Catalog) linkValue(ctx context.Context, repository, branch string, op TransactionOperation) (*graveler.Value, error) {
+	sourceRef := op.SourceRef
+	if sourceRef == "" {
+		sourceRef = branch
+	}
+	source, err := c.GetEntry(ctx, repository, sourceRef, op.SourcePath, GetEntryParams{})
+	if err != nil {
+		return nil, fmt.Errorf("link source %s@%s: %w", op.SourcePath, sourceRef, err)
+	}
+	source.CreationDate = time.Now()
+	return EntryToValue(EntryFromCatalogEntry(*source))
+}
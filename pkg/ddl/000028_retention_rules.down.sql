@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE graveler_commits
+    DROP COLUMN IF EXISTS expired;
+DROP TABLE IF EXISTS graveler_retention_rules;
+
+COMMIT;
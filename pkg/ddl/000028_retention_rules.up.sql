@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS graveler_retention_rules
+(
+    repository_id  text    NOT NULL REFERENCES graveler_repositories (id) ON DELETE CASCADE,
+    position       integer NOT NULL,
+
+    branch_pattern text    NOT NULL,
+    retention_days integer NOT NULL,
+
+    PRIMARY KEY (repository_id, position)
+);
+
+ALTER TABLE graveler_commits
+    ADD COLUMN IF NOT EXISTS expired boolean NOT NULL DEFAULT false;
+
+COMMIT;
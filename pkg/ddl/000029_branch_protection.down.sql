@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS graveler_branch_protection_rules;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS graveler_branch_protection_rules
+(
+    repository_id       text    NOT NULL REFERENCES graveler_repositories (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    pattern             text    NOT NULL,
+
+    require_passing_run boolean NOT NULL DEFAULT false,
+
+    PRIMARY KEY (repository_id, pattern)
+);
+
+COMMIT;
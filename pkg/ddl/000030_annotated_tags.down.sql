@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE graveler_tags
+    DROP COLUMN IF EXISTS message,
+    DROP COLUMN IF EXISTS creator,
+    DROP COLUMN IF EXISTS creation_date,
+    DROP COLUMN IF EXISTS metadata;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE graveler_tags
+    ADD COLUMN IF NOT EXISTS message       text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS creator       text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS creation_date timestamptz,
+    ADD COLUMN IF NOT EXISTS metadata      jsonb;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE graveler_retention_rules
+    DROP CONSTRAINT IF EXISTS graveler_retention_rules_repository_id_fkey,
+    ADD CONSTRAINT graveler_retention_rules_repository_id_fkey
+        FOREIGN KEY (repository_id) REFERENCES graveler_repositories (id) ON DELETE CASCADE;
+
+ALTER TABLE graveler_repositories
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS metadata;
+
+COMMIT;
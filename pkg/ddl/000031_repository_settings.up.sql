@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE graveler_repositories
+    ADD COLUMN IF NOT EXISTS description text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS metadata    jsonb;
+
+-- follow repository renames
+ALTER TABLE graveler_retention_rules
+    DROP CONSTRAINT IF EXISTS graveler_retention_rules_repository_id_fkey,
+    ADD CONSTRAINT graveler_retention_rules_repository_id_fkey
+        FOREIGN KEY (repository_id) REFERENCES graveler_repositories (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+COMMIT;
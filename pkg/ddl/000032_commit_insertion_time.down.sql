@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS graveler_commits_inserted_at_idx;
+
+ALTER TABLE graveler_commits
+    DROP COLUMN IF EXISTS inserted_at;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- commits added since a previous refs dump are found by the time they were inserted, which unlike their
+-- creation date never goes back
+ALTER TABLE graveler_commits
+    ADD COLUMN IF NOT EXISTS inserted_at timestamptz NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS graveler_commits_inserted_at_idx ON graveler_commits (repository_id, inserted_at);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS graveler_storage_namespace_references;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+-- repositories referencing objects on storage namespaces of other repositories (e.g. forks), retention of
+-- those repositories keeps the referenced objects
+CREATE TABLE IF NOT EXISTS graveler_storage_namespace_references
+(
+    repository_id     text NOT NULL REFERENCES graveler_repositories (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    storage_namespace text NOT NULL,
+
+    PRIMARY KEY (repository_id, storage_namespace)
+);
+
+CREATE INDEX IF NOT EXISTS graveler_storage_namespace_references_storage_namespace_idx
+    ON graveler_storage_namespace_references (storage_namespace);
+
+COMMIT;
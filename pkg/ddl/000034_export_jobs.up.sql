@@ -0,0 +1,25 @@
+BEGIN;
+
+-- exports started through the API, running in the background of the request that started them
+CREATE TABLE IF NOT EXISTS export_jobs
+(
+    repository_id      text        NOT NULL REFERENCES graveler_repositories (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    export_id          text        NOT NULL,
+    commit_id          text        NOT NULL,
+    destination        text        NOT NULL,
+    status             text        NOT NULL,
+    error              text        NOT NULL DEFAULT '',
+    start_time         timestamptz NOT NULL,
+    deadline           timestamptz NOT NULL,
+    end_time           timestamptz,
+    previous_commit_id text        NOT NULL DEFAULT '',
+    copied             integer     NOT NULL DEFAULT 0,
+    removed            integer     NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (repository_id, export_id)
+);
+
+-- a single export runs to a destination at a time
+CREATE UNIQUE INDEX IF NOT EXISTS export_jobs_running_destination_uidx ON export_jobs (destination) WHERE status = 'running';
+
+COMMIT;
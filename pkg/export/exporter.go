@@ -0,0 +1,353 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+const (
+	// SuccessMarker is written at the destination after all objects were exported
+	SuccessMarker = "_SUCCESS"
+	// ManifestPath holds the manifest of the last export at the destination
+	ManifestPath = "_lakefs_export_manifest.json"
+
+	DefaultParallelism = 16
+	MaxParallelism     = 256
+	// DefaultTimeout bounds an export that sets no deadline of its own
+	DefaultTimeout = time.Hour
+
+	listPageSize = 1000
+)
+
+var ErrInvalidDestination = errors.New("invalid export destination")
+
+// Catalog is the part of the catalog used to read the exported commit
+type Catalog interface {
+	GetRepository(ctx context.Context, repository string) (*catalog.Repository, error)
+	ListRepositories(ctx context.Context, limit int, prefix, after string) ([]*catalog.Repository, bool, error)
+	Dereference(ctx context.Context, repository, reference string) (string, error)
+	ListEntries(ctx context.Context, repository, reference string, prefix, after string, delimiter string, limit int) ([]*catalog.DBEntry, bool, error)
+	Diff(ctx context.Context, repository, leftReference string, rightReference string, params catalog.DiffParams) (catalog.Differences, bool, error)
+}
+
+// Params controls a single export
+type Params struct {
+	// Incremental exports only the differences from the commit found in the destination manifest.
+	// A full export is done when the destination holds no manifest of the same repository.  A full export over
+	// a previous export removes the objects of the previous export commit that the commit does not hold.
+	Incremental bool
+	// Parallelism is the number of concurrent copy operations, DefaultParallelism if not set
+	Parallelism int
+	// Timeout is the deadline of an export job from its start, Jobs.Timeout if not set
+	Timeout time.Duration
+}
+
+// Manifest describes the last export written to a destination
+type Manifest struct {
+	Repository       string    `json:"repository"`
+	CommitID         string    `json:"commit_id"`
+	PreviousCommitID string    `json:"previous_commit_id,omitempty"`
+	ExportTime       time.Time `json:"export_time"`
+	Copied           int       `json:"copied"`
+	Removed          int       `json:"removed"`
+}
+
+// Exporter copies the objects of a commit to a destination prefix, keeping their logical path
+type Exporter struct {
+	Catalog Catalog
+	Adapter block.Adapter
+}
+
+func NewExporter(c Catalog, adapter block.Adapter) *Exporter {
+	return &Exporter{
+		Catalog: c,
+		Adapter: adapter,
+	}
+}
+
+type exportOp struct {
+	path   string
+	entry  *catalog.DBEntry
+	remove bool // remove path from the destination instead of copying entry
+}
+
+// Export copies the objects of reference in repository under destination and returns the manifest written
+// for it.  The success marker is removed while the export runs, so readers can tell a partial export.
+func (e *Exporter) Export(ctx context.Context, repository, reference, destination string, params Params) (*Manifest, error) {
+	destination = strings.TrimSuffix(destination, "/")
+	if err := e.ValidateDestination(ctx, destination); err != nil {
+		return nil, err
+	}
+	repo, err := e.Catalog.GetRepository(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := e.Catalog.Dereference(ctx, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		Repository: repository,
+		CommitID:   commitID,
+	}
+	previous, err := e.readManifest(ctx, destination)
+	if err != nil {
+		return nil, fmt.Errorf("read export manifest: %w", err)
+	}
+	// previousCommitID is the commit of the previous export of repository to destination
+	var previousCommitID string
+	if previous != nil && previous.Repository == repository {
+		previousCommitID = previous.CommitID
+		if params.Incremental {
+			manifest.PreviousCommitID = previousCommitID
+		}
+	}
+
+	log := logging.FromContext(ctx).WithFields(logging.Fields{
+		"repository":      repository,
+		"commit_id":       commitID,
+		"previous_commit": manifest.PreviousCommitID,
+		"destination":     destination,
+	})
+	log.Info("Export started")
+
+	if err := e.removeIfExists(ctx, destinationPointer(destination, SuccessMarker)); err != nil {
+		return nil, fmt.Errorf("remove success marker: %w", err)
+	}
+
+	parallelism := params.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	} else if parallelism > MaxParallelism {
+		parallelism = MaxParallelism
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ops := make(chan exportOp)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		opErr    error
+		copied   int
+		removed  int
+		setError = func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if opErr == nil {
+				opErr = err
+				cancel()
+			}
+		}
+	)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for op := range ops {
+				if err := e.apply(ctx, repo.StorageNamespace, destination, op); err != nil {
+					setError(fmt.Errorf("export %s: %w", op.path, err))
+					continue
+				}
+				mu.Lock()
+				if op.remove {
+					removed++
+				} else {
+					copied++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var produceErr error
+	if manifest.PreviousCommitID != "" {
+		produceErr = e.produceDiff(ctx, repository, manifest.PreviousCommitID, commitID, false, ops)
+	} else {
+		produceErr = e.produceEntries(ctx, repository, commitID, ops)
+		if produceErr == nil && previousCommitID != "" && previousCommitID != commitID {
+			produceErr = e.produceDiff(ctx, repository, previousCommitID, commitID, true, ops)
+		}
+	}
+	close(ops)
+	wg.Wait()
+	if opErr != nil {
+		return nil, opErr
+	}
+	if produceErr != nil {
+		return nil, produceErr
+	}
+
+	manifest.Copied = copied
+	manifest.Removed = removed
+	manifest.ExportTime = time.Now().UTC()
+	if err := e.writeManifest(ctx, destination, manifest); err != nil {
+		return nil, fmt.Errorf("write export manifest: %w", err)
+	}
+	if err := e.Adapter.Put(ctx, destinationPointer(destination, SuccessMarker), 0, bytes.NewReader(nil), block.PutOpts{}); err != nil {
+		return nil, fmt.Errorf("write success marker: %w", err)
+	}
+	log.WithFields(logging.Fields{"copied": copied, "removed": removed}).Info("Export done")
+	return manifest, nil
+}
+
+func (e *Exporter) apply(ctx context.Context, storageNamespace, destination string, op exportOp) error {
+	dst := destinationPointer(destination, op.path)
+	if op.remove {
+		return e.removeIfExists(ctx, dst)
+	}
+	src := block.ObjectPointer{
+		StorageNamespace: storageNamespace,
+		Identifier:       op.entry.PhysicalAddress,
+		IdentifierType:   op.entry.AddressType.ToIdentifierType(),
+	}
+	return e.Adapter.Copy(ctx, src, dst)
+}
+
+// produceEntries sends a copy operation for every entry of commitID
+func (e *Exporter) produceEntries(ctx context.Context, repository, commitID string, ops chan<- exportOp) error {
+	var after string
+	for {
+		entries, hasMore, err := e.Catalog.ListEntries(ctx, repository, commitID, "", after, "", listPageSize)
+		if err != nil {
+			return fmt.Errorf("list entries: %w", err)
+		}
+		for _, ent := range entries {
+			if err := sendOp(ctx, ops, exportOp{path: ent.Path, entry: ent}); err != nil {
+				return err
+			}
+		}
+		if !hasMore || len(entries) == 0 {
+			return nil
+		}
+		after = entries[len(entries)-1].Path
+	}
+}
+
+// produceDiff sends a remove operation for every entry removed from previousCommitID to commitID and, unless
+// removedOnly is set, a copy operation for every entry added or changed
+func (e *Exporter) produceDiff(ctx context.Context, repository, previousCommitID, commitID string, removedOnly bool, ops chan<- exportOp) error {
+	var after string
+	for {
+		diffs, hasMore, err := e.Catalog.Diff(ctx, repository, previousCommitID, commitID, catalog.DiffParams{
+			Limit: listPageSize,
+			After: after,
+		})
+		if err != nil {
+			return fmt.Errorf("diff from %s: %w", previousCommitID, err)
+		}
+		for i := range diffs {
+			diff := &diffs[i]
+			op := exportOp{path: diff.Path}
+			switch {
+			case diff.Type == catalog.DifferenceTypeRemoved:
+				op.remove = true
+			case removedOnly:
+				continue
+			default:
+				op.entry = &diff.DBEntry
+			}
+			if err := sendOp(ctx, ops, op); err != nil {
+				return err
+			}
+		}
+		if !hasMore || len(diffs) == 0 {
+			return nil
+		}
+		after = diffs[len(diffs)-1].Path
+	}
+}
+
+func sendOp(ctx context.Context, ops chan<- exportOp, op exportOp) error {
+	select {
+	case ops <- op:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ValidateDestination returns ErrInvalidDestination unless destination is a valid storage URI outside the
+// storage namespace of every repository.  Exporting into a namespace, or around one, would overwrite and remove
+// repository objects.
+func (e *Exporter) ValidateDestination(ctx context.Context, destination string) error {
+	destination = strings.TrimSuffix(destination, "/")
+	if _, err := block.ResolveNamespacePrefix(destination, ""); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDestination, destination)
+	}
+	dst := destination + "/"
+	var after string
+	for {
+		repos, hasMore, err := e.Catalog.ListRepositories(ctx, listPageSize, "", after)
+		if err != nil {
+			return fmt.Errorf("list repositories: %w", err)
+		}
+		for _, repo := range repos {
+			ns := strings.TrimSuffix(repo.StorageNamespace, "/") + "/"
+			if strings.HasPrefix(dst, ns) || strings.HasPrefix(ns, dst) {
+				return fmt.Errorf("%w: %s overlaps the storage namespace of repository %s", ErrInvalidDestination, destination, repo.Name)
+			}
+		}
+		if !hasMore || len(repos) == 0 {
+			return nil
+		}
+		after = repos[len(repos)-1].Name
+	}
+}
+
+func (e *Exporter) removeIfExists(ctx context.Context, obj block.ObjectPointer) error {
+	exists, err := e.Adapter.Exists(ctx, obj)
+	if err != nil || !exists {
+		return err
+	}
+	return e.Adapter.Remove(ctx, obj)
+}
+
+// readManifest returns the manifest found at destination, or nil if there is none
+func (e *Exporter) readManifest(ctx context.Context, destination string) (*Manifest, error) {
+	obj := destinationPointer(destination, ManifestPath)
+	exists, err := e.Adapter.Exists(ctx, obj)
+	if err != nil || !exists {
+		return nil, err
+	}
+	reader, err := e.Adapter.Get(ctx, obj, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func (e *Exporter) writeManifest(ctx context.Context, destination string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return e.Adapter.Put(ctx, destinationPointer(destination, ManifestPath), int64(len(data)), bytes.NewReader(data), block.PutOpts{})
+}
+
+func destinationPointer(destination, path string) block.ObjectPointer {
+	return block.ObjectPointer{
+		StorageNamespace: destination,
+		Identifier:       path,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+}
@@ -0,0 +1,240 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/export"
+)
+
+const (
+	repositoryName   = "repo"
+	storageNamespace = "mem://bucket/repo"
+	destination      = "mem://bucket/export"
+)
+
+// fakeCatalog holds the entries of each commit, references are commit IDs
+type fakeCatalog struct {
+	commits map[string]map[string]string // commit ID -> path -> physical address
+}
+
+func (f *fakeCatalog) GetRepository(_ context.Context, repository string) (*catalog.Repository, error) {
+	if repository != repositoryName {
+		return nil, catalog.ErrNotFound
+	}
+	return &catalog.Repository{Name: repository, StorageNamespace: storageNamespace}, nil
+}
+
+func (f *fakeCatalog) ListRepositories(_ context.Context, _ int, _, after string) ([]*catalog.Repository, bool, error) {
+	if after >= repositoryName {
+		return nil, false, nil
+	}
+	return []*catalog.Repository{{Name: repositoryName, StorageNamespace: storageNamespace}}, false, nil
+}
+
+func (f *fakeCatalog) Dereference(_ context.Context, _, reference string) (string, error) {
+	if _, ok := f.commits[reference]; !ok {
+		return "", catalog.ErrNotFound
+	}
+	return reference, nil
+}
+
+func (f *fakeCatalog) sortedPaths(commitID string) []string {
+	var paths []string
+	for p := range f.commits[commitID] {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (f *fakeCatalog) ListEntries(_ context.Context, _, reference string, _, after string, _ string, limit int) ([]*catalog.DBEntry, bool, error) {
+	var entries []*catalog.DBEntry
+	for _, p := range f.sortedPaths(reference) {
+		if p <= after {
+			continue
+		}
+		if len(entries) == limit {
+			return entries, true, nil
+		}
+		entries = append(entries, &catalog.DBEntry{Path: p, PhysicalAddress: f.commits[reference][p], AddressType: catalog.AddressTypeRelative})
+	}
+	return entries, false, nil
+}
+
+func (f *fakeCatalog) Diff(_ context.Context, _, leftReference string, rightReference string, params catalog.DiffParams) (catalog.Differences, bool, error) {
+	left := f.commits[leftReference]
+	right := f.commits[rightReference]
+	pathSet := make(map[string]struct{})
+	for p := range left {
+		pathSet[p] = struct{}{}
+	}
+	for p := range right {
+		pathSet[p] = struct{}{}
+	}
+	var paths []string
+	for p := range pathSet {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var diffs catalog.Differences
+	for _, p := range paths {
+		if p <= params.After {
+			continue
+		}
+		leftAddr, inLeft := left[p]
+		rightAddr, inRight := right[p]
+		diff := catalog.Difference{DBEntry: catalog.DBEntry{Path: p, PhysicalAddress: rightAddr, AddressType: catalog.AddressTypeRelative}}
+		switch {
+		case !inLeft:
+			diff.Type = catalog.DifferenceTypeAdded
+		case !inRight:
+			diff.Type = catalog.DifferenceTypeRemoved
+			diff.PhysicalAddress = leftAddr
+		case leftAddr != rightAddr:
+			diff.Type = catalog.DifferenceTypeChanged
+		default:
+			continue
+		}
+		if len(diffs) == params.Limit {
+			return diffs, true, nil
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, false, nil
+}
+
+func putObject(t *testing.T, adapter block.Adapter, namespace, identifier, data string) {
+	t.Helper()
+	err := adapter.Put(context.Background(), block.ObjectPointer{
+		StorageNamespace: namespace,
+		Identifier:       identifier,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, int64(len(data)), strings.NewReader(data), block.PutOpts{})
+	if err != nil {
+		t.Fatalf("put %s: %s", identifier, err)
+	}
+}
+
+func readObject(t *testing.T, adapter block.Adapter, identifier string) (string, bool) {
+	t.Helper()
+	obj := block.ObjectPointer{StorageNamespace: destination, Identifier: identifier, IdentifierType: block.IdentifierTypeRelative}
+	exists, err := adapter.Exists(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("exists %s: %s", identifier, err)
+	}
+	if !exists {
+		return "", false
+	}
+	reader, err := adapter.Get(context.Background(), obj, -1)
+	if err != nil {
+		t.Fatalf("get %s: %s", identifier, err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read %s: %s", identifier, err)
+	}
+	return string(bytes.TrimSpace(data)), true
+}
+
+func TestExporter_Export(t *testing.T) {
+	ctx := context.Background()
+	adapter := mem.New()
+	putObject(t, adapter, storageNamespace, "data/a1", "a1")
+	putObject(t, adapter, storageNamespace, "data/a2", "a2")
+	putObject(t, adapter, storageNamespace, "data/b1", "b1")
+	putObject(t, adapter, storageNamespace, "data/c1", "c1")
+	cat := &fakeCatalog{commits: map[string]map[string]string{
+		"c1": {"a": "data/a1", "dir/b": "data/b1"},
+		"c2": {"a": "data/a2", "dir/c": "data/c1"},
+		"c3": {"dir/b": "data/b1"},
+	}}
+	exporter := export.NewExporter(cat, adapter)
+
+	// full export
+	manifest, err := exporter.Export(ctx, repositoryName, "c1", destination+"/", export.Params{Parallelism: 2})
+	if err != nil {
+		t.Fatalf("full export: %s", err)
+	}
+	if manifest.CommitID != "c1" || manifest.PreviousCommitID != "" || manifest.Copied != 2 || manifest.Removed != 0 {
+		t.Errorf("full export manifest %+v, expected 2 objects copied from c1", manifest)
+	}
+	for path, expected := range map[string]string{"a": "a1", "dir/b": "b1"} {
+		if data, ok := readObject(t, adapter, path); !ok || data != expected {
+			t.Errorf("exported %s = '%s' (found %t), expected '%s'", path, data, ok, expected)
+		}
+	}
+	if _, ok := readObject(t, adapter, export.SuccessMarker); !ok {
+		t.Error("success marker missing after full export")
+	}
+
+	// incremental export copies changes and removes deleted objects
+	manifest, err = exporter.Export(ctx, repositoryName, "c2", destination, export.Params{Incremental: true})
+	if err != nil {
+		t.Fatalf("incremental export: %s", err)
+	}
+	if manifest.CommitID != "c2" || manifest.PreviousCommitID != "c1" || manifest.Copied != 2 || manifest.Removed != 1 {
+		t.Errorf("incremental export manifest %+v, expected 2 copied and 1 removed from c1 to c2", manifest)
+	}
+	for path, expected := range map[string]string{"a": "a2", "dir/c": "c1"} {
+		if data, ok := readObject(t, adapter, path); !ok || data != expected {
+			t.Errorf("exported %s = '%s' (found %t), expected '%s'", path, data, ok, expected)
+		}
+	}
+	if _, ok := readObject(t, adapter, "dir/b"); ok {
+		t.Error("removed object dir/b still exported")
+	}
+	if _, ok := readObject(t, adapter, export.SuccessMarker); !ok {
+		t.Error("success marker missing after incremental export")
+	}
+
+	// incremental export of the exported commit copies nothing
+	manifest, err = exporter.Export(ctx, repositoryName, "c2", destination, export.Params{Incremental: true})
+	if err != nil {
+		t.Fatalf("repeated incremental export: %s", err)
+	}
+	if manifest.Copied != 0 || manifest.Removed != 0 {
+		t.Errorf("repeated incremental export manifest %+v, expected no changes", manifest)
+	}
+
+	// full export removes the objects left by the previous export, and only them
+	putObject(t, adapter, destination, "other", "other")
+	manifest, err = exporter.Export(ctx, repositoryName, "c3", destination, export.Params{})
+	if err != nil {
+		t.Fatalf("full export over previous export: %s", err)
+	}
+	if manifest.Copied != 1 || manifest.Removed != 2 {
+		t.Errorf("full export over previous export manifest %+v, expected 1 copied and 2 removed", manifest)
+	}
+	for _, path := range []string{"a", "dir/c"} {
+		if _, ok := readObject(t, adapter, path); ok {
+			t.Errorf("stale object %s still exported", path)
+		}
+	}
+	if data, ok := readObject(t, adapter, "dir/b"); !ok || data != "b1" {
+		t.Errorf("exported dir/b = '%s' (found %t), expected 'b1'", data, ok)
+	}
+	if _, ok := readObject(t, adapter, "other"); !ok {
+		t.Error("object not written by an export was removed")
+	}
+}
+
+func TestExporter_ExportInvalidDestination(t *testing.T) {
+	cat := &fakeCatalog{commits: map[string]map[string]string{"c1": {}}}
+	exporter := export.NewExporter(cat, mem.New())
+	for _, dest := range []string{"", "bucket/prefix", "unknown://bucket/prefix", storageNamespace, storageNamespace + "/export", "mem://bucket"} {
+		_, err := exporter.Export(context.Background(), repositoryName, "c1", dest, export.Params{})
+		if !errors.Is(err, export.ErrInvalidDestination) {
+			t.Errorf("export to '%s' err=%v, expected %s", dest, err, export.ErrInvalidDestination)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/treeverse/lakefs/pkg/db"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// JobStatus is the state of an export job
+type JobStatus string
+
+const (
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+
+	// jobDeadlineGrace is the time a job past its deadline has to record its failure before it is reported as
+	// stopped
+	jobDeadlineGrace = time.Minute
+
+	jobStoppedError = "export stopped before it completed"
+)
+
+var (
+	ErrJobNotFound = errors.New("export job not found")
+	ErrJobRunning  = errors.New("export to destination already running")
+)
+
+// Job is an export that runs in the background of the request that started it
+type Job struct {
+	ID               string     `db:"export_id"`
+	Repository       string     `db:"repository_id"`
+	CommitID         string     `db:"commit_id"`
+	Destination      string     `db:"destination"`
+	Status           JobStatus  `db:"status"`
+	Error            string     `db:"error"`
+	StartTime        time.Time  `db:"start_time"`
+	Deadline         time.Time  `db:"deadline"`
+	EndTime          *time.Time `db:"end_time"`
+	PreviousCommitID string     `db:"previous_commit_id"`
+	Copied           int        `db:"copied"`
+	Removed          int        `db:"removed"`
+}
+
+// Jobs runs exports in the background, keeping their status in the database
+type Jobs struct {
+	DB       db.Database
+	Exporter *Exporter
+	// Timeout is the deadline of each export from its start
+	Timeout time.Duration
+}
+
+func NewJobs(database db.Database, exporter *Exporter) *Jobs {
+	return &Jobs{
+		DB:       database,
+		Exporter: exporter,
+		Timeout:  DefaultTimeout,
+	}
+}
+
+// Start exports reference in the background and returns the running job.  The destination and the reference are
+// checked before the job starts, and it fails with ErrJobRunning while another export to destination runs.
+func (j *Jobs) Start(ctx context.Context, repository, reference, destination string, params Params) (*Job, error) {
+	destination = strings.TrimSuffix(destination, "/")
+	if err := j.Exporter.ValidateDestination(ctx, destination); err != nil {
+		return nil, err
+	}
+	commitID, err := j.Exporter.Catalog.Dereference(ctx, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = j.Timeout
+	}
+	now := time.Now().UTC()
+	job := &Job{
+		ID:          uuid.New().String(),
+		Repository:  repository,
+		CommitID:    commitID,
+		Destination: destination,
+		Status:      JobStatusRunning,
+		StartTime:   now,
+		Deadline:    now.Add(timeout),
+	}
+	_, err = j.DB.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		// a job that stopped without recording its outcome does not hold the destination
+		_, err := tx.Exec(`UPDATE export_jobs SET status = $2, error = $3, end_time = $4
+			WHERE destination = $1 AND status = $5 AND deadline < $6`,
+			job.Destination, JobStatusFailed, jobStoppedError, now, JobStatusRunning, now.Add(-jobDeadlineGrace))
+		if err != nil {
+			return nil, err
+		}
+		return tx.Exec(`INSERT INTO export_jobs (repository_id, export_id, commit_id, destination, status, start_time, deadline)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			job.Repository, job.ID, job.CommitID, job.Destination, job.Status, job.StartTime, job.Deadline)
+	})
+	if errors.Is(err, db.ErrAlreadyExists) {
+		return nil, fmt.Errorf("%w: %s", ErrJobRunning, destination)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("insert export job: %w", err)
+	}
+	log := logging.FromContext(ctx).WithField("export_id", job.ID)
+	result := *job
+	go j.run(log, &result, params)
+	return job, nil
+}
+
+// run exports until done or until the job deadline, then records the outcome
+func (j *Jobs) run(log logging.Logger, job *Job, params Params) {
+	ctx, cancel := context.WithDeadline(logging.AddFields(context.Background(), logging.Fields{"export_id": job.ID}), job.Deadline)
+	defer cancel()
+	manifest, err := j.Exporter.Export(ctx, job.Repository, job.CommitID, job.Destination, params)
+	endTime := time.Now().UTC()
+	job.EndTime = &endTime
+	if err != nil {
+		log.WithError(err).Error("Export job failed")
+		job.Status = JobStatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = JobStatusCompleted
+		job.PreviousCommitID = manifest.PreviousCommitID
+		job.Copied = manifest.Copied
+		job.Removed = manifest.Removed
+	}
+	// the job context may be past its deadline, the outcome is recorded regardless
+	_, err = j.DB.Exec(context.Background(), `UPDATE export_jobs
+		SET status = $3, error = $4, end_time = $5, previous_commit_id = $6, copied = $7, removed = $8
+		WHERE repository_id = $1 AND export_id = $2`,
+		job.Repository, job.ID, job.Status, job.Error, job.EndTime, job.PreviousCommitID, job.Copied, job.Removed)
+	if err != nil {
+		log.WithError(err).Error("Failed to record export job")
+	}
+}
+
+// FailRunning records every running job as failed.  Jobs run in the server that started them, call it on server
+// start to report the jobs stopped by the previous run.
+func (j *Jobs) FailRunning(ctx context.Context) error {
+	res, err := j.DB.Exec(ctx, `UPDATE export_jobs SET status = $1, error = $2, end_time = $3 WHERE status = $4`,
+		JobStatusFailed, jobStoppedError, time.Now().UTC(), JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("fail running export jobs: %w", err)
+	}
+	if stopped := res.RowsAffected(); stopped > 0 {
+		logging.FromContext(ctx).WithField("jobs", stopped).Warn("Export jobs stopped before they completed")
+	}
+	return nil
+}
+
+// GetJob returns the export job exportID of repository.  A job still running well past its deadline stopped
+// without recording its outcome and is reported as failed.
+func (j *Jobs) GetJob(ctx context.Context, repository, exportID string) (*Job, error) {
+	var job Job
+	err := j.DB.Get(ctx, &job, `SELECT repository_id, export_id, commit_id, destination, status, error, start_time, deadline,
+			end_time, previous_commit_id, copied, removed
+		FROM export_jobs WHERE repository_id = $1 AND export_id = $2`,
+		repository, exportID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("export %s: %w", exportID, ErrJobNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == JobStatusRunning && time.Now().After(job.Deadline.Add(jobDeadlineGrace)) {
+		job.Status = JobStatusFailed
+		job.Error = jobStoppedError
+	}
+	return &job, nil
+}
@@ -10,6 +10,7 @@ import (
 	gerrors "github.com/treeverse/lakefs/pkg/gateway/errors"
 	"github.com/treeverse/lakefs/pkg/gateway/path"
 	"github.com/treeverse/lakefs/pkg/gateway/serde"
+	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/permissions"
 )
 
@@ -68,6 +69,14 @@ func (controller *DeleteObjects) Handle(w http.ResponseWriter, req *http.Request
 			// Spark trying to delete the path "main/", which we map to branch "main" with an empty path.
 			// Spark expects it to succeed (not deleting anything is a success), instead of returning an error.
 			lg.Debug("tried to delete with an empty branch")
+		case errors.Is(err, graveler.ErrProtectedBranch):
+			lg.WithError(err).Debug("tried to delete from a protected branch")
+			errs = append(errs, serde.DeleteError{
+				Code:    "AccessDenied",
+				Key:     obj.Key,
+				Message: "Access Denied",
+			})
+			continue
 		case err != nil:
 			lg.WithError(err).Error("failed deleting object")
 			errs = append(errs, serde.DeleteError{
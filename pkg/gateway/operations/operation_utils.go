@@ -1,14 +1,71 @@
 package operations
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/treeverse/lakefs/pkg/catalog"
+	gatewayerrors "github.com/treeverse/lakefs/pkg/gateway/errors"
+	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/logging"
 )
 
-func (o *PathOperation) finishUpload(req *http.Request, checksum, physicalAddress string, size int64, relative bool) error {
+const (
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// writeConditions returns the write conditions of the request If-Match and If-None-Match headers.  The
+// conditions are checked here to fail before uploading data, and again atomically when writing the entry.
+// Returns false after encoding the error response if a condition does not hold.
+func (o *PathOperation) writeConditions(w http.ResponseWriter, req *http.Request) ([]graveler.WriteConditionOption, bool) {
+	ifMatch := req.Header.Get(IfMatchHeader)
+	ifNoneMatch := req.Header.Get(IfNoneMatchHeader)
+	switch {
+	case ifMatch != "" && ifNoneMatch != "":
+		_ = o.EncodeError(w, req, gatewayerrors.Codes.ToAPIErr(gatewayerrors.ErrNotImplemented))
+		return nil, false
+	case ifNoneMatch != "":
+		if ifNoneMatch != "*" {
+			_ = o.EncodeError(w, req, gatewayerrors.Codes.ToAPIErr(gatewayerrors.ErrNotImplemented))
+			return nil, false
+		}
+		_, err := o.Catalog.GetEntry(req.Context(), o.Repository.Name, o.Reference, o.Path, catalog.GetEntryParams{ReturnExpired: true})
+		if err == nil {
+			_ = o.EncodeError(w, req, gatewayerrors.Codes.ToAPIErr(gatewayerrors.ErrPreconditionFailed))
+			return nil, false
+		}
+		if !errors.Is(err, catalog.ErrNotFound) {
+			o.Log(req).WithError(err).Error("could not check if object exists")
+			_ = o.EncodeError(w, req, gatewayerrors.Codes.ToAPIErr(gatewayerrors.ErrInternalError))
+			return nil, false
+		}
+		return []graveler.WriteConditionOption{graveler.IfAbsent(true)}, true
+	case ifMatch != "":
+		cond, err := o.Catalog.IfMatchChecksum(req.Context(), o.Repository.Name, o.Reference, o.Path, trimQuotes(ifMatch))
+		if err != nil {
+			_ = o.EncodeError(w, req, gatewayerrors.Codes.ToAPIErr(writeErrorCode(err)))
+			return nil, false
+		}
+		return []graveler.WriteConditionOption{cond}, true
+	}
+	return nil, true
+}
+
+// writeErrorCode returns the gateway error code of a failed entry write
+func writeErrorCode(err error) gatewayerrors.APIErrorCode {
+	switch {
+	case errors.Is(err, graveler.ErrPreconditionFailed):
+		return gatewayerrors.ErrPreconditionFailed
+	case errors.Is(err, graveler.ErrProtectedBranch):
+		return gatewayerrors.ErrAccessDenied
+	default:
+		return gatewayerrors.ErrInternalError
+	}
+}
+
+func (o *PathOperation) finishUpload(req *http.Request, checksum, physicalAddress string, size int64, relative bool, writeConditions ...graveler.WriteConditionOption) error {
 	addressType := catalog.AddressTypeRelative
 	if !relative {
 		addressType = catalog.AddressTypeFull
@@ -26,7 +83,7 @@ func (o *PathOperation) finishUpload(req *http.Request, checksum, physicalAddres
 		CreationDate:    writeTime,
 	}
 
-	err := o.Catalog.CreateEntry(req.Context(), o.Repository.Name, o.Reference, entry)
+	err := o.Catalog.CreateEntry(req.Context(), o.Repository.Name, o.Reference, entry, writeConditions...)
 	if err != nil {
 		o.Log(req).WithError(err).Error("could not update metadata")
 		return err
@@ -101,7 +101,7 @@ func (controller *PostObject) HandleCompleteMultipartUpload(w http.ResponseWrite
 	checksum := strings.Split(ch, "-")[0]
 	err = o.finishUpload(req, checksum, objName, size, true)
 	if err != nil {
-		_ = o.EncodeError(w, req, errors.Codes.ToAPIErr(errors.ErrInternalError))
+		_ = o.EncodeError(w, req, errors.Codes.ToAPIErr(writeErrorCode(err)))
 		return
 	}
 	err = o.MultipartsTracker.Delete(req.Context(), uploadID)
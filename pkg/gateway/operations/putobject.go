@@ -186,6 +186,17 @@ func (controller *PutObject) Handle(w http.ResponseWriter, req *http.Request, o
 		_ = o.EncodeError(w, req, errors.Codes.ToAPIErr(errors.ErrNoSuchBucket))
 		return
 	}
+	protected, err := o.Catalog.IsBranchProtected(req.Context(), o.Repository.Name, o.Reference)
+	if err != nil {
+		o.Log(req).WithError(err).Error("could not check if branch is protected")
+		_ = o.EncodeError(w, req, errors.Codes.ToAPIErr(errors.ErrInternalError))
+		return
+	}
+	if protected {
+		o.Log(req).Debug("write to protected branch")
+		_ = o.EncodeError(w, req, errors.Codes.ToAPIErr(errors.ErrAccessDenied))
+		return
+	}
 
 	query := req.URL.Query()
 
@@ -215,6 +226,10 @@ func (controller *PutObject) Handle(w http.ResponseWriter, req *http.Request, o
 
 func handlePut(w http.ResponseWriter, req *http.Request, o *PathOperation) {
 	o.Incr("put_object")
+	writeConditions, ok := o.writeConditions(w, req)
+	if !ok {
+		return // operation already failed
+	}
 	storageClass := StorageClassFromHeader(req.Header)
 	opts := block.PutOpts{StorageClass: storageClass}
 	blob, err := upload.WriteBlob(req.Context(), o.BlockStore, o.Repository.StorageNamespace, req.Body, req.ContentLength, opts)
@@ -225,9 +240,9 @@ func handlePut(w http.ResponseWriter, req *http.Request, o *PathOperation) {
 	}
 
 	// write metadata
-	err = o.finishUpload(req, blob.Checksum, blob.PhysicalAddress, blob.Size, true)
+	err = o.finishUpload(req, blob.Checksum, blob.PhysicalAddress, blob.Size, true, writeConditions...)
 	if err != nil {
-		_ = o.EncodeError(w, req, errors.Codes.ToAPIErr(errors.ErrInternalError))
+		_ = o.EncodeError(w, req, errors.Codes.ToAPIErr(writeErrorCode(err)))
 		return
 	}
 	o.SetHeader(w, "ETag", httputil.ETag(blob.Checksum))
@@ -0,0 +1,162 @@
+package committed
+
+import (
+	"bytes"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+type delimitedDiffIterator struct {
+	it        DiffIterator
+	prefix    graveler.Key
+	delimiter graveler.Key
+	started   bool
+	// pending is true when it is positioned on a diff or range header that was read but not used yet
+	pending bool
+	value   *graveler.Diff
+}
+
+// NewDelimitedDiffIterator returns the diffs of it on keys starting with prefix, grouping those that
+// share the part up to the first delimiter after prefix into a single diff on that common prefix, as
+// graveler.NewDelimitedDiffIterator does.  Ranges added or removed as a whole and entirely inside a
+// common prefix are counted from their metadata without being read.
+func NewDelimitedDiffIterator(it DiffIterator, prefix, delimiter graveler.Key) graveler.DiffIterator {
+	return &delimitedDiffIterator{
+		it:        it,
+		prefix:    prefix,
+		delimiter: delimiter,
+	}
+}
+
+func (d *delimitedDiffIterator) commonPrefix(key graveler.Key) graveler.Key {
+	relevant := key[len(d.prefix):]
+	idx := bytes.Index(relevant, d.delimiter)
+	if idx == -1 {
+		return nil
+	}
+	return key[:len(d.prefix)+idx+len(d.delimiter)].Copy()
+}
+
+func (d *delimitedDiffIterator) Next() bool {
+	if !d.started {
+		d.started = true
+		d.it.SeekGE(d.prefix)
+	}
+	hasNext := d.pending || d.it.Next()
+	d.pending = false
+	for hasNext {
+		diff, rangeDiff := d.it.Value()
+		if diff == nil {
+			// header of a range added or removed as a whole
+			rng := rangeDiff.Range
+			if pastPrefix(graveler.Key(rng.MinKey), d.prefix) {
+				break
+			}
+			var commonPrefix graveler.Key
+			if bytes.HasPrefix(rng.MinKey, d.prefix) {
+				commonPrefix = d.commonPrefix(graveler.Key(rng.MinKey))
+			}
+			if commonPrefix == nil || !bytes.HasPrefix(rng.MaxKey, commonPrefix) {
+				hasNext = d.it.Next()
+				continue
+			}
+			return d.group(commonPrefix)
+		}
+		if pastPrefix(diff.Key, d.prefix) {
+			break
+		}
+		if !bytes.HasPrefix(diff.Key, d.prefix) {
+			hasNext = d.it.Next()
+			continue
+		}
+		commonPrefix := d.commonPrefix(diff.Key)
+		if commonPrefix == nil {
+			d.value = diff
+			return true
+		}
+		return d.group(commonPrefix)
+	}
+	d.value = nil
+	return false
+}
+
+// group summarizes the diffs under commonPrefix into a single diff, starting with the one it is
+// positioned on.  Ranges entirely inside commonPrefix are counted and skipped.
+func (d *delimitedDiffIterator) group(commonPrefix graveler.Key) bool {
+	summary := &graveler.DiffSummary{Count: make(map[graveler.DiffType]int)}
+	var typ graveler.DiffType
+	first := true
+	add := func(t graveler.DiffType, count int) {
+		summary.Count[t] += count
+		if first {
+			typ = t
+			first = false
+		} else if t != typ {
+			typ = graveler.DiffTypeChanged
+		}
+	}
+	hasNext := true
+	for hasNext {
+		diff, rangeDiff := d.it.Value()
+		if diff == nil {
+			rng := rangeDiff.Range
+			if !bytes.HasPrefix(rng.MinKey, commonPrefix) {
+				d.pending = true
+				break
+			}
+			if !bytes.HasPrefix(rng.MaxKey, commonPrefix) {
+				hasNext = d.it.Next()
+				continue
+			}
+			add(rangeDiff.Type, int(rng.Count))
+			hasNext = d.it.NextRange()
+			continue
+		}
+		if !bytes.HasPrefix(diff.Key, commonPrefix) {
+			d.pending = true
+			break
+		}
+		add(diff.Type, 1)
+		hasNext = d.it.Next()
+	}
+	if d.it.Err() != nil {
+		d.value = nil
+		return false
+	}
+	d.value = &graveler.Diff{
+		Type:         typ,
+		Key:          commonPrefix,
+		CommonPrefix: summary,
+	}
+	return true
+}
+
+// SeekGE seeks to id, or past the whole common prefix holding id: common prefixes are returned as a
+// single diff, so paging after one resumes without reading its keys again.
+func (d *delimitedDiffIterator) SeekGE(id graveler.Key) {
+	d.started = true
+	d.pending = false
+	d.value = nil
+	if bytes.Compare(id, d.prefix) < 0 {
+		id = d.prefix
+	} else if bytes.HasPrefix(id, d.prefix) {
+		if commonPrefix := d.commonPrefix(id); commonPrefix != nil {
+			if upperBound := graveler.UpperBoundForPrefix(commonPrefix); upperBound != nil {
+				id = upperBound
+			}
+		}
+	}
+	d.it.SeekGE(id)
+}
+
+func (d *delimitedDiffIterator) Value() *graveler.Diff {
+	return d.value
+}
+
+func (d *delimitedDiffIterator) Err() error {
+	return d.it.Err()
+}
+
+func (d *delimitedDiffIterator) Close() {
+	d.it.Close()
+}
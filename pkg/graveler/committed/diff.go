@@ -32,8 +32,10 @@ type diffIterator struct {
 	rightValue   iteratorValue
 	currentRange currentRangeData
 	currentDiff  *graveler.Diff
-	err          error
-	state        diffIteratorState
+	// currentLeftValue is the value on the left side of currentDiff when it is a change
+	currentLeftValue *graveler.Value
+	err              error
+	state            diffIteratorState
 }
 
 // currentRangeLeftIdentity returns the current range identity in case the current range is the left range, otherwise returns nil
@@ -70,6 +72,10 @@ const (
 )
 
 func NewDiffIterator(ctx context.Context, left Iterator, right Iterator) DiffIterator {
+	return newDiffIterator(ctx, left, right)
+}
+
+func newDiffIterator(ctx context.Context, left Iterator, right Iterator) *diffIterator {
 	return &diffIterator{
 		ctx:   ctx,
 		left:  left,
@@ -174,6 +180,7 @@ func (d *diffIterator) Next() bool {
 	if d.state == diffIteratorStateClosed || d.err != nil {
 		return false
 	}
+	d.currentLeftValue = nil
 	if d.currentRange.iter != nil {
 		// we are currently inside a range
 		d.currentRange.value.record, d.currentRange.value.rng, d.currentRange.value.err = diffIteratorNextValue(d.currentRange.iter)
@@ -230,6 +237,7 @@ func (d *diffIterator) Next() bool {
 			case diffItCompareResultSameKeys:
 				// same keys on different ranges
 				d.currentDiff = &graveler.Diff{Type: graveler.DiffTypeChanged, Key: d.rightValue.record.Key.Copy(), Value: d.rightValue.record.Value, LeftIdentity: d.leftValue.record.Identity}
+				d.currentLeftValue = d.leftValue.record.Value
 				d.leftValue.record, d.leftValue.rng, d.leftValue.err = diffIteratorNextValue(d.left)
 				d.rightValue.record, d.rightValue.rng, d.rightValue.err = diffIteratorNextValue(d.right)
 				return true
@@ -269,6 +277,7 @@ func (d *diffIterator) SeekGE(id graveler.Key) {
 	d.left.SeekGE(id)
 	d.right.SeekGE(id)
 	d.currentDiff = nil
+	d.currentLeftValue = nil
 	d.leftValue = iteratorValue{}
 	d.rightValue = iteratorValue{}
 	d.err = nil
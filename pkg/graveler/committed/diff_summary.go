@@ -0,0 +1,74 @@
+package committed
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+// summarizeDiff counts the differences of it on keys starting with prefix, and sums the size of
+// their objects using valueSize.  Ranges added or removed as a whole and entirely under prefix are
+// summarized from their Count and ObjectSize without reading their keys, unless their object size was
+// not recorded.
+func summarizeDiff(it *diffIterator, prefix graveler.Key, valueSize ValueSizeFunc) (graveler.DiffSummary, error) {
+	size := func(value *graveler.Value) (int64, error) {
+		if valueSize == nil || value == nil {
+			return 0, nil
+		}
+		return valueSize(value)
+	}
+	summary := graveler.DiffSummary{
+		Count: make(map[graveler.DiffType]int),
+		Bytes: make(map[graveler.DiffType]int64),
+	}
+	if len(prefix) > 0 {
+		it.SeekGE(prefix)
+	}
+	hasNext := it.Next()
+	for hasNext {
+		diff, rangeDiff := it.Value()
+		if diff == nil {
+			// header of a range added or removed as a whole
+			rng := rangeDiff.Range
+			if pastPrefix(graveler.Key(rng.MinKey), prefix) {
+				break
+			}
+			sizeKnown := valueSize == nil || rng.ObjectSize > 0 || rng.Count == 0
+			if sizeKnown && bytes.HasPrefix(rng.MinKey, prefix) && bytes.HasPrefix(rng.MaxKey, prefix) {
+				summary.Count[rangeDiff.Type] += int(rng.Count)
+				summary.Bytes[rangeDiff.Type] += rng.ObjectSize
+				hasNext = it.NextRange()
+			} else {
+				hasNext = it.Next()
+			}
+			continue
+		}
+		if pastPrefix(diff.Key, prefix) {
+			break
+		}
+		if bytes.HasPrefix(diff.Key, prefix) {
+			summary.Count[diff.Type]++
+			objectSize, err := size(diff.Value)
+			if err != nil {
+				return graveler.DiffSummary{}, fmt.Errorf("size of %s: %w", diff.Key, err)
+			}
+			if diff.Type == graveler.DiffTypeChanged {
+				// changed objects are summarized by how much they grew
+				leftSize, err := size(it.currentLeftValue)
+				if err != nil {
+					return graveler.DiffSummary{}, fmt.Errorf("size of %s: %w", diff.Key, err)
+				}
+				objectSize -= leftSize
+			}
+			summary.Bytes[diff.Type] += objectSize
+		}
+		hasNext = it.Next()
+	}
+	return summary, it.Err()
+}
+
+// pastPrefix returns true if key and all keys after it do not start with prefix
+func pastPrefix(key, prefix graveler.Key) bool {
+	return bytes.Compare(key, prefix) > 0 && !bytes.HasPrefix(key, prefix)
+}
@@ -13,11 +13,14 @@ import (
 
 type committedManager struct {
 	metaRangeManager MetaRangeManager
+	valueSize        ValueSizeFunc
 	logger           logging.Logger
 }
 
-func NewCommittedManager(m MetaRangeManager) graveler.CommittedManager {
-	return &committedManager{metaRangeManager: m, logger: logging.Default()}
+// NewCommittedManager returns a CommittedManager of the metaranges of m.  valueSize, if set, sizes the
+// objects of values to summarize diffs.
+func NewCommittedManager(m MetaRangeManager, valueSize ValueSizeFunc) graveler.CommittedManager {
+	return &committedManager{metaRangeManager: m, valueSize: valueSize, logger: logging.Default()}
 }
 
 func (c *committedManager) Exists(ctx context.Context, ns graveler.StorageNamespace, id graveler.MetaRangeID) (bool, error) {
@@ -56,6 +59,14 @@ func (c *committedManager) List(ctx context.Context, ns graveler.StorageNamespac
 	return NewValueIterator(it), nil
 }
 
+func (c *committedManager) ListMetaRange(ctx context.Context, ns graveler.StorageNamespace, metaRangeID graveler.MetaRangeID) (graveler.MetaRangeIterator, error) {
+	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, metaRangeID)
+	if err != nil {
+		return nil, err
+	}
+	return NewMetaRangeIterator(it), nil
+}
+
 func (c *committedManager) WriteMetaRange(ctx context.Context, ns graveler.StorageNamespace, it graveler.ValueIterator, metadata graveler.Metadata) (*graveler.MetaRangeID, error) {
 	writer := c.metaRangeManager.NewWriter(ctx, ns, metadata)
 	defer func() {
@@ -80,7 +91,7 @@ func (c *committedManager) WriteMetaRange(ctx context.Context, ns graveler.Stora
 	return id, nil
 }
 
-func (c *committedManager) Diff(ctx context.Context, ns graveler.StorageNamespace, left, right graveler.MetaRangeID) (graveler.DiffIterator, error) {
+func (c *committedManager) Diff(ctx context.Context, ns graveler.StorageNamespace, left, right graveler.MetaRangeID, params graveler.DiffParams) (graveler.DiffIterator, error) {
 	leftIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, left)
 	if err != nil {
 		return nil, err
@@ -89,7 +100,57 @@ func (c *committedManager) Diff(ctx context.Context, ns graveler.StorageNamespac
 	if err != nil {
 		return nil, err
 	}
-	return NewDiffValueIterator(ctx, leftIt, rightIt), nil
+	if len(params.Delimiter) > 0 {
+		return NewDelimitedDiffIterator(NewDiffIterator(ctx, leftIt, rightIt), params.Prefix, params.Delimiter), nil
+	}
+	return graveler.NewScopedDiffIterator(NewDiffValueIterator(ctx, leftIt, rightIt), params), nil
+}
+
+func (c *committedManager) PrefixChanged(ctx context.Context, ns graveler.StorageNamespace, left, right graveler.MetaRangeID, prefix graveler.Key) (bool, error) {
+	diffIt, err := c.diffWithRanges(ctx, ns, left, right)
+	if err != nil {
+		return false, fmt.Errorf("diff: %w", err)
+	}
+	defer diffIt.Close()
+	return hasDiffWithPrefix(diffIt, prefix)
+}
+
+// hasDiffWithPrefix returns true if diffIt holds a difference on a key starting with prefix.
+// Identical ranges are skipped by the diff, and the scan ends at the first changed range or key
+// past prefix, without reading it.
+func hasDiffWithPrefix(diffIt DiffIterator, prefix graveler.Key) (bool, error) {
+	diffIt.SeekGE(prefix)
+	for diffIt.Next() {
+		diff, rangeDiff := diffIt.Value()
+		var key graveler.Key
+		if diff != nil {
+			key = diff.Key
+		} else {
+			// header of a range added or removed as a whole
+			key = graveler.Key(rangeDiff.Range.MinKey)
+		}
+		if bytes.HasPrefix(key, prefix) {
+			return true, nil
+		}
+		if bytes.Compare(key, prefix) > 0 {
+			return false, nil
+		}
+	}
+	return false, diffIt.Err()
+}
+
+func (c *committedManager) DiffSummary(ctx context.Context, ns graveler.StorageNamespace, left, right graveler.MetaRangeID, prefix graveler.Key) (graveler.DiffSummary, error) {
+	leftIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, left)
+	if err != nil {
+		return graveler.DiffSummary{}, err
+	}
+	rightIt, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, right)
+	if err != nil {
+		return graveler.DiffSummary{}, err
+	}
+	diffIt := newDiffIterator(ctx, leftIt, rightIt)
+	defer diffIt.Close()
+	return summarizeDiff(diffIt, prefix, c.valueSize)
 }
 
 func (c *committedManager) diffWithRanges(ctx context.Context, ns graveler.StorageNamespace, left, right graveler.MetaRangeID) (DiffIterator, error) {
@@ -104,7 +165,7 @@ func (c *committedManager) diffWithRanges(ctx context.Context, ns graveler.Stora
 	return NewDiffIterator(ctx, leftIt, rightIt), nil
 }
 
-func (c *committedManager) Merge(ctx context.Context, ns graveler.StorageNamespace, destination, source, base graveler.MetaRangeID) (graveler.MetaRangeID, graveler.DiffSummary, error) {
+func (c *committedManager) Merge(ctx context.Context, ns graveler.StorageNamespace, destination, source, base graveler.MetaRangeID, strategy graveler.MergeStrategy) (graveler.MetaRangeID, graveler.DiffSummary, error) {
 	diffIt, err := c.diffWithRanges(ctx, ns, destination, source)
 	if err != nil {
 		return "", graveler.DiffSummary{}, fmt.Errorf("diff: %w", err)
@@ -115,7 +176,7 @@ func (c *committedManager) Merge(ctx context.Context, ns graveler.StorageNamespa
 		return "", graveler.DiffSummary{}, fmt.Errorf("get base iterator: %w", err)
 	}
 	defer baseIt.Close()
-	patchIterator := NewMergeIterator(ctx, diffIt, baseIt)
+	patchIterator := NewMergeIterator(ctx, diffIt, baseIt, strategy)
 	defer patchIterator.Close()
 	return c.applyOnDiffWithRanges(ctx, ns, destination, patchIterator)
 }
@@ -150,8 +211,9 @@ func (c *committedManager) Apply(ctx context.Context, ns graveler.StorageNamespa
 	return c.applyOnDiffWithRanges(ctx, ns, rangeID, NewIteratorWrapper(diffs))
 }
 
-func (c *committedManager) Compare(ctx context.Context, ns graveler.StorageNamespace, destination, source, base graveler.MetaRangeID) (graveler.DiffIterator, error) {
-	diffIt, err := c.Diff(ctx, ns, destination, source)
+func (c *committedManager) Compare(ctx context.Context, ns graveler.StorageNamespace, destination, source, base graveler.MetaRangeID, params graveler.DiffParams) (graveler.DiffIterator, error) {
+	// group by delimiter only after comparing with base: a common prefix has no single base value
+	diffIt, err := c.Diff(ctx, ns, destination, source, graveler.DiffParams{Prefix: params.Prefix})
 	if err != nil {
 		return nil, fmt.Errorf("diff: %w", err)
 	}
@@ -159,7 +221,11 @@ func (c *committedManager) Compare(ctx context.Context, ns graveler.StorageNames
 	if err != nil {
 		return nil, fmt.Errorf("get base iterator: %w", err)
 	}
-	return NewCompareValueIterator(ctx, NewDiffIteratorWrapper(diffIt), baseIt), nil
+	var compareIt graveler.DiffIterator = NewCompareValueIterator(ctx, NewDiffIteratorWrapper(diffIt), baseIt)
+	if len(params.Delimiter) > 0 {
+		compareIt = graveler.NewDelimitedDiffIterator(compareIt, params.Prefix, params.Delimiter)
+	}
+	return compareIt, nil
 }
 
 func (c *committedManager) GetMetaRange(ctx context.Context, ns graveler.StorageNamespace, id graveler.MetaRangeID) (graveler.MetaRangeInfo, error) {
@@ -0,0 +1,274 @@
+package committed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/golang/mock/gomock"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/committed"
+	"github.com/treeverse/lakefs/pkg/graveler/committed/mock"
+)
+
+func TestManager_PrefixChanged(t *testing.T) {
+	newRange := func(id, minKey, maxKey string, records ...testValueRecord) testRange {
+		return testRange{
+			rng:     committed.Range{ID: committed.ID(id), MinKey: committed.Key(minKey), MaxKey: committed.Key(maxKey), Count: int64(len(records))},
+			records: records,
+		}
+	}
+	left := []testRange{
+		newRange("r1", "a1", "a3", testValueRecord{"a1", "a1"}, testValueRecord{"a2", "a2"}, testValueRecord{"a3", "a3"}),
+		newRange("r2", "b1", "b2", testValueRecord{"b1", "b1"}, testValueRecord{"b2", "b2"}),
+		newRange("r3", "c1", "c2", testValueRecord{"c1", "c1"}, testValueRecord{"c2", "c2"}),
+	}
+	right := []testRange{
+		newRange("r1", "a1", "a3", testValueRecord{"a1", "a1"}, testValueRecord{"a2", "a2"}, testValueRecord{"a3", "a3"}),
+		newRange("r2-changed", "b1", "b2", testValueRecord{"b1", "b1"}, testValueRecord{"b2", "b2-changed"}),
+		newRange("r3", "c1", "c2", testValueRecord{"c1", "c1"}, testValueRecord{"c2", "c2"}),
+		newRange("r4", "d1", "d2", testValueRecord{"d1", "d1"}, testValueRecord{"d2", "d2"}),
+	}
+	tests := []struct {
+		prefix   string
+		expected bool
+	}{
+		{prefix: "", expected: true},
+		{prefix: "a", expected: false},
+		{prefix: "b", expected: true},
+		{prefix: "b1", expected: false},
+		{prefix: "b2", expected: true},
+		{prefix: "c", expected: false},
+		{prefix: "d", expected: true},
+		{prefix: "e", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run("prefix_"+tt.prefix, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			metaRangeManager := mock.NewMockMetaRangeManager(ctrl)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("left")).Return(createIter(left), nil)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("right")).Return(createIter(right), nil)
+			committedManager := committed.NewCommittedManager(metaRangeManager, nil)
+			changed, err := committedManager.PrefixChanged(context.Background(), "ns", "left", "right", graveler.Key(tt.prefix))
+			if err != nil {
+				t.Fatalf("PrefixChanged(%s) unexpected error: %s", tt.prefix, err)
+			}
+			if changed != tt.expected {
+				t.Errorf("PrefixChanged(%s)=%t, expected %t", tt.prefix, changed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManager_DiffPrefix(t *testing.T) {
+	newRange := func(id, minKey, maxKey string, records ...testValueRecord) testRange {
+		return testRange{
+			rng:     committed.Range{ID: committed.ID(id), MinKey: committed.Key(minKey), MaxKey: committed.Key(maxKey), Count: int64(len(records))},
+			records: records,
+		}
+	}
+	left := []testRange{
+		newRange("r1", "a1", "a2", testValueRecord{"a1", "a1"}, testValueRecord{"a2", "a2"}),
+		newRange("r2", "b1", "b2", testValueRecord{"b1", "b1"}, testValueRecord{"b2", "b2"}),
+	}
+	right := []testRange{
+		newRange("r1-changed", "a1", "a2", testValueRecord{"a1", "a1-changed"}, testValueRecord{"a2", "a2"}),
+		newRange("r2-changed", "b1", "b2", testValueRecord{"b1", "b1"}, testValueRecord{"b2", "b2-changed"}),
+		newRange("r3", "c1", "c2", testValueRecord{"c1", "c1"}, testValueRecord{"c2", "c2"}),
+	}
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{prefix: "", expected: []string{"a1", "b2", "c1", "c2"}},
+		{prefix: "b", expected: []string{"b2"}},
+		{prefix: "c2", expected: []string{"c2"}},
+		{prefix: "d", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run("prefix_"+tt.prefix, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			metaRangeManager := mock.NewMockMetaRangeManager(ctrl)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("left")).Return(createIter(left), nil)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("right")).Return(createIter(right), nil)
+			committedManager := committed.NewCommittedManager(metaRangeManager, nil)
+			it, err := committedManager.Diff(context.Background(), "ns", "left", "right", graveler.DiffParams{Prefix: graveler.Key(tt.prefix)})
+			if err != nil {
+				t.Fatalf("Diff(%s) unexpected error: %s", tt.prefix, err)
+			}
+			defer it.Close()
+			var keys []string
+			for it.Next() {
+				keys = append(keys, string(it.Value().Key))
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Diff(%s) iteration error: %s", tt.prefix, err)
+			}
+			if diff := deep.Equal(keys, tt.expected); diff != nil {
+				t.Errorf("Diff(%s) unexpected keys: %s", tt.prefix, diff)
+			}
+		})
+	}
+}
+
+func TestManager_DiffSummary(t *testing.T) {
+	newRange := func(id, minKey, maxKey string, objectSize int64, records ...testValueRecord) testRange {
+		return testRange{
+			rng:     committed.Range{ID: committed.ID(id), MinKey: committed.Key(minKey), MaxKey: committed.Key(maxKey), Count: int64(len(records)), ObjectSize: objectSize},
+			records: records,
+		}
+	}
+	// objects are 100 bytes for each identity byte
+	valueSize := func(value *graveler.Value) (int64, error) {
+		return int64(100 * len(value.Identity)), nil
+	}
+	left := []testRange{
+		newRange("r1", "a1", "a2", 100, testValueRecord{"a1", "a1"}, testValueRecord{"a2", "a2"}),
+		newRange("r2", "b1", "b2", 100, testValueRecord{"b1", "b1"}, testValueRecord{"b2", "b2"}),
+		newRange("r5", "e1", "e2", 500, testValueRecord{"e1", "e1"}, testValueRecord{"e2", "e2"}),
+	}
+	right := []testRange{
+		newRange("r1", "a1", "a2", 100, testValueRecord{"a1", "a1"}, testValueRecord{"a2", "a2"}),
+		newRange("r2-changed", "b1", "b2", 100, testValueRecord{"b1", "b1"}, testValueRecord{"b2", "b2-changed"}),
+		newRange("r3", "c1", "c2", 1000, testValueRecord{"c1", "c1"}, testValueRecord{"c2", "c2"}),
+		// r4 has no recorded object size, so its keys are read
+		newRange("r4", "c8", "d1", 0, testValueRecord{"c8", "c8"}, testValueRecord{"d1", "d1"}),
+	}
+	tests := []struct {
+		prefix   string
+		expected graveler.DiffSummary
+	}{
+		{
+			prefix: "",
+			expected: graveler.DiffSummary{
+				Count: map[graveler.DiffType]int{graveler.DiffTypeAdded: 4, graveler.DiffTypeRemoved: 2, graveler.DiffTypeChanged: 1},
+				Bytes: map[graveler.DiffType]int64{graveler.DiffTypeAdded: 1400, graveler.DiffTypeRemoved: 500, graveler.DiffTypeChanged: 800},
+			},
+		},
+		{
+			prefix: "b",
+			expected: graveler.DiffSummary{
+				Count: map[graveler.DiffType]int{graveler.DiffTypeChanged: 1},
+				Bytes: map[graveler.DiffType]int64{graveler.DiffTypeChanged: 800},
+			},
+		},
+		{
+			// r4 is only partly under the prefix, so its keys are read
+			prefix: "c",
+			expected: graveler.DiffSummary{
+				Count: map[graveler.DiffType]int{graveler.DiffTypeAdded: 3},
+				Bytes: map[graveler.DiffType]int64{graveler.DiffTypeAdded: 1200},
+			},
+		},
+		{
+			prefix: "e",
+			expected: graveler.DiffSummary{
+				Count: map[graveler.DiffType]int{graveler.DiffTypeRemoved: 2},
+				Bytes: map[graveler.DiffType]int64{graveler.DiffTypeRemoved: 500},
+			},
+		},
+		{
+			prefix: "f",
+			expected: graveler.DiffSummary{
+				Count: map[graveler.DiffType]int{},
+				Bytes: map[graveler.DiffType]int64{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("prefix_"+tt.prefix, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			metaRangeManager := mock.NewMockMetaRangeManager(ctrl)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("left")).Return(createIter(left), nil)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("right")).Return(createIter(right), nil)
+			committedManager := committed.NewCommittedManager(metaRangeManager, valueSize)
+			summary, err := committedManager.DiffSummary(context.Background(), "ns", "left", "right", graveler.Key(tt.prefix))
+			if err != nil {
+				t.Fatalf("DiffSummary(%s) unexpected error: %s", tt.prefix, err)
+			}
+			if diff := deep.Equal(summary, tt.expected); diff != nil {
+				t.Errorf("DiffSummary(%s) unexpected summary: %s", tt.prefix, diff)
+			}
+		})
+	}
+}
+
+func TestManager_DiffDelimiter(t *testing.T) {
+	newRange := func(id, minKey, maxKey string, count int64, records ...testValueRecord) testRange {
+		return testRange{
+			rng:     committed.Range{ID: committed.ID(id), MinKey: committed.Key(minKey), MaxKey: committed.Key(maxKey), Count: count},
+			records: records,
+		}
+	}
+	left := []testRange{
+		newRange("r1", "a/1", "a/2", 2, testValueRecord{"a/1", "a/1"}, testValueRecord{"a/2", "a/2"}),
+	}
+	right := []testRange{
+		newRange("r1-changed", "a/1", "a/2", 2, testValueRecord{"a/1", "a/1"}, testValueRecord{"a/2", "a/2-changed"}),
+		// r2 records more keys than it holds here: it is counted from its metadata without being read
+		newRange("r2", "b/1", "b/9", 5, testValueRecord{"b/1", "b/1"}, testValueRecord{"b/9", "b/9"}),
+		// r3 spans two common prefixes, so its keys are read
+		newRange("r3", "c/1", "d", 5, testValueRecord{"c/1", "c/1"}, testValueRecord{"d", "d"}),
+	}
+	commonPrefix := func(key string, typ graveler.DiffType, count int) graveler.Diff {
+		return graveler.Diff{
+			Key:          graveler.Key(key),
+			Type:         typ,
+			CommonPrefix: &graveler.DiffSummary{Count: map[graveler.DiffType]int{typ: count}},
+		}
+	}
+	tests := []struct {
+		name     string
+		seek     string
+		expected []graveler.Diff
+	}{
+		{
+			name: "all",
+			expected: []graveler.Diff{
+				commonPrefix("a/", graveler.DiffTypeChanged, 1),
+				commonPrefix("b/", graveler.DiffTypeAdded, 5),
+				commonPrefix("c/", graveler.DiffTypeAdded, 1),
+				{Key: graveler.Key("d"), Type: graveler.DiffTypeAdded, Value: &graveler.Value{Identity: []byte("d")}},
+			},
+		},
+		{
+			name: "seek past common prefix",
+			seek: "b/",
+			expected: []graveler.Diff{
+				commonPrefix("c/", graveler.DiffTypeAdded, 1),
+				{Key: graveler.Key("d"), Type: graveler.DiffTypeAdded, Value: &graveler.Value{Identity: []byte("d")}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			metaRangeManager := mock.NewMockMetaRangeManager(ctrl)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("left")).Return(createIter(left), nil)
+			metaRangeManager.EXPECT().NewMetaRangeIterator(gomock.Any(), gomock.Any(), graveler.MetaRangeID("right")).Return(createIter(right), nil)
+			committedManager := committed.NewCommittedManager(metaRangeManager, nil)
+			it, err := committedManager.Diff(context.Background(), "ns", "left", "right", graveler.DiffParams{Delimiter: graveler.Key("/")})
+			if err != nil {
+				t.Fatalf("Diff unexpected error: %s", err)
+			}
+			defer it.Close()
+			if tt.seek != "" {
+				it.SeekGE(graveler.Key(tt.seek))
+			}
+			var got []graveler.Diff
+			for it.Next() {
+				got = append(got, *it.Value())
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Diff iteration error: %s", err)
+			}
+			if diff := deep.Equal(got, tt.expected); diff != nil {
+				t.Errorf("Diff unexpected diffs: %s", diff)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 type compareIterator struct {
 	ctx             context.Context
 	errorOnConflict bool
+	strategy        graveler.MergeStrategy
 	diffIt          DiffIterator
 	val             *graveler.Diff
 	rng             *RangeDiff
@@ -27,14 +28,17 @@ var ErrUnsupportedRangeDiffType = errors.New("range diff type unsupported - supp
 // NewMergeIterator accepts an iterator describing a diff from the merge destination to the source.
 // It returns an Iterator with the changes to perform on the destination branch, in order to merge the source into it,
 // relative to base as the merge base.
-// When reaching a conflict, the iterator will enter an error state with the graveler.ErrConflictFound error.
-func NewMergeIterator(ctx context.Context, diffDestToSource DiffIterator, base Iterator) *mergeIterator {
+// When reaching a conflict, the iterator resolves it according to strategy: graveler.MergeStrategyDest keeps the
+// destination value, graveler.MergeStrategySrc takes the source value, and graveler.MergeStrategyNone will make the
+// iterator enter an error state with the graveler.ErrConflictFound error.
+func NewMergeIterator(ctx context.Context, diffDestToSource DiffIterator, base Iterator, strategy graveler.MergeStrategy) *mergeIterator {
 	return &mergeIterator{
 		compareIterator: &compareIterator{
 			ctx:             ctx,
 			diffIt:          diffDestToSource,
 			base:            base,
 			errorOnConflict: true,
+			strategy:        strategy,
 		},
 	}
 }
@@ -77,15 +81,27 @@ func (d *compareIterator) valueFromBase(key graveler.Key) (*graveler.ValueRecord
 	return val, nil
 }
 
-func (d *compareIterator) handleConflict() bool {
+// handleConflict resolves a conflict on the current value according to the strategy
+// returns hasMore if iterator has more, and done if the step is over (got to a value, end of iterator, or error)
+func (d *compareIterator) handleConflict() (hasNext, done bool) {
+	val, rngDiff := d.diffIt.Value()
+	switch d.strategy {
+	case graveler.MergeStrategyDest:
+		// keep the value on dest - next value
+		return d.diffIt.Next(), false
+	case graveler.MergeStrategySrc:
+		// take the value from source
+		d.val = val.Copy()
+		d.setRangeDiff(rngDiff)
+		return true, true
+	}
 	if d.errorOnConflict {
 		d.err = graveler.ErrConflictFound
-		return false
+		return false, true
 	}
-	val, _ := d.diffIt.Value()
 	d.val = val.Copy()
 	d.val.Type = graveler.DiffTypeConflict
-	return true
+	return true, true
 }
 func (d *compareIterator) setRangeDiff(r *RangeDiff) {
 	if r != nil {
@@ -148,12 +164,12 @@ func (d *compareIterator) stepValue() (hasNext, done bool) {
 		}
 		if !bytes.Equal(baseVal.Identity, val.Value.Identity) {
 			// removed on dest, but changed on source
-			return d.handleConflict(), true
+			return d.handleConflict()
 		}
 	case graveler.DiffTypeChanged:
 		if baseVal == nil {
 			// added on dest and source, with different identities
-			return d.handleConflict(), true
+			return d.handleConflict()
 		}
 		if bytes.Equal(baseVal.Identity, val.Value.Identity) {
 			// changed on dest, but not on source
@@ -161,7 +177,7 @@ func (d *compareIterator) stepValue() (hasNext, done bool) {
 		}
 		if !bytes.Equal(baseVal.Identity, val.LeftIdentity) {
 			// changed on dest and source, to different identities
-			return d.handleConflict(), true
+			return d.handleConflict()
 		}
 		// changed only on source
 		d.val = val.Copy()
@@ -177,7 +193,7 @@ func (d *compareIterator) stepValue() (hasNext, done bool) {
 				return true, true
 			}
 			// changed on dest, removed on source
-			return d.handleConflict(), true
+			return d.handleConflict()
 		}
 		// added on dest, but not on source - next value
 	}
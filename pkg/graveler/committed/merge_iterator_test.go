@@ -125,7 +125,7 @@ func TestMerge(t *testing.T) {
 			defer diffIt.Close()
 			base := makeBaseIterator(tst.baseKeys)
 			ctx := context.Background()
-			it := committed.NewMergeIterator(ctx, committed.NewDiffIteratorWrapper(diffIt), base)
+			it := committed.NewMergeIterator(ctx, committed.NewDiffIteratorWrapper(diffIt), base, graveler.MergeStrategyNone)
 			var gotValues, gotKeys []string
 			idx := 0
 			for it.Next() {
@@ -158,6 +158,59 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestMergeStrategies(t *testing.T) {
+	diffs := []graveler.Diff{
+		testMergeNewDiff(changed, "k1", "i1a", "i1"),
+		testMergeNewDiff(changed, "k2", "i2b", "i2a"),
+		testMergeNewDiff(removed, "k3", "i3a", "i3a"),
+		testMergeNewDiff(added, "k4", "i4a", ""),
+	}
+	baseKeys := []string{"k1", "k2", "k3", "k4"}
+	tests := map[string]struct {
+		strategy           graveler.MergeStrategy
+		expectedKeys       []string
+		expectedIdentities []string
+	}{
+		"dest wins": {
+			strategy:           graveler.MergeStrategyDest,
+			expectedKeys:       []string{"k1"},
+			expectedIdentities: []string{"i1a"},
+		},
+		"source wins": {
+			strategy:           graveler.MergeStrategySrc,
+			expectedKeys:       []string{"k1", "k2", "k3", "k4"},
+			expectedIdentities: []string{"i1a", "i2b", "", "i4a"},
+		},
+	}
+	for name, tst := range tests {
+		t.Run(name, func(t *testing.T) {
+			diffIt := testutil.NewDiffIter(diffs)
+			defer diffIt.Close()
+			base := makeBaseIterator(baseKeys)
+			it := committed.NewMergeIterator(context.Background(), committed.NewDiffIteratorWrapper(diffIt), base, tst.strategy)
+			var gotValues, gotKeys []string
+			for it.Next() {
+				val, _ := it.Value()
+				gotKeys = append(gotKeys, string(val.Key))
+				if val.Value == nil {
+					gotValues = append(gotValues, "")
+				} else {
+					gotValues = append(gotValues, string(val.Identity))
+				}
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			if diff := deep.Equal(tst.expectedKeys, gotKeys); diff != nil {
+				t.Fatalf("got unexpected keys from merge iterator. diff=%s", diff)
+			}
+			if diff := deep.Equal(tst.expectedIdentities, gotValues); diff != nil {
+				t.Fatalf("got unexpected values from merge iterator. diff=%s", diff)
+			}
+		})
+	}
+}
+
 func makeDV(typ graveler.DiffType, k, id, leftID string) *graveler.Diff {
 	res := &graveler.Diff{
 		Type:         typ,
@@ -412,7 +465,7 @@ func TestMergeRange(t *testing.T) {
 
 			// test merge iterator
 			ctx := context.Background()
-			it := committed.NewMergeIterator(ctx, diffIt, baseIt)
+			it := committed.NewMergeIterator(ctx, diffIt, baseIt, graveler.MergeStrategyNone)
 			gotKeys := make([]string, 0)
 			gotIDs := make([]string, 0)
 			gotRangesIDs := make([]string, 0)
@@ -469,7 +522,7 @@ func TestMergeNextRange(t *testing.T) {
 	diffIt.AddValueRecords(makeDV(added, "k4", "i4", ""), makeDV(added, "k5", "i5", ""))
 
 	ctx := context.Background()
-	it := committed.NewMergeIterator(ctx, diffIt, baseIt)
+	it := committed.NewMergeIterator(ctx, diffIt, baseIt, graveler.MergeStrategyNone)
 
 	if !it.Next() {
 		t.Fatalf("expected it.Next() to return true (error:%v)", it.Err())
@@ -519,7 +572,7 @@ func TestMergeCancelContext(t *testing.T) {
 	base := makeBaseIterator(baseKeys)
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	it := committed.NewMergeIterator(ctx, committed.NewDiffIteratorWrapper(diffIt), base)
+	it := committed.NewMergeIterator(ctx, committed.NewDiffIteratorWrapper(diffIt), base, graveler.MergeStrategyNone)
 	if it.Next() {
 		t.Fatal("Next() should return false")
 	}
@@ -544,7 +597,7 @@ func TestMergeSeek(t *testing.T) {
 	baseKeys := []string{"k2", "k3", "k4", "k6"}
 	base := makeBaseIterator(baseKeys)
 	ctx := context.Background()
-	it := committed.NewMergeIterator(ctx, committed.NewDiffIteratorWrapper(diffIt), base)
+	it := committed.NewMergeIterator(ctx, committed.NewDiffIteratorWrapper(diffIt), base, graveler.MergeStrategyNone)
 	// expected diffs, +k1, -k2, Chng:k3,+k7, Conf:k9,
 	defer it.Close()
 	tests := []struct {
@@ -809,7 +862,7 @@ func TestMergeSeekWithRange(t *testing.T) {
 		}).
 		AddValueRecords(makeV("k8", "i8"), makeV("k9", "i9"))
 	ctx := context.Background()
-	it := committed.NewMergeIterator(ctx, diffIt, baseIt)
+	it := committed.NewMergeIterator(ctx, diffIt, baseIt, graveler.MergeStrategyNone)
 	// expected diffs, +k1, -k2, Chng:k3,+k7, Conf:k9,
 	defer it.Close()
 	tests := []struct {
@@ -597,8 +597,8 @@ func Test_merge(t *testing.T) {
 			writer.EXPECT().Abort()
 			metaRangeId := graveler.MetaRangeID("merge")
 			writer.EXPECT().Close().Return(&metaRangeId, nil).AnyTimes()
-			committedManager := committed.NewCommittedManager(metaRangeManager)
-			_, summary, err := committedManager.Merge(ctx, "ns", "dest", "source", "base")
+			committedManager := committed.NewCommittedManager(metaRangeManager, nil)
+			_, summary, err := committedManager.Merge(ctx, "ns", "dest", "source", "base", graveler.MergeStrategyNone)
 			if err != tst.expectedErr {
 				t.Fatal(err)
 			}
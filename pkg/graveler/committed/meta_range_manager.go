@@ -22,8 +22,14 @@ type Params struct {
 	RangeSizeEntriesRaggedness float64
 	// MaxUploaders is the maximal number of uploaders to use in a single metarange writer.
 	MaxUploaders int
+	// ValueSize returns the size of the object a value describes.  Written ranges record the
+	// total size of their objects when it is set.
+	ValueSize ValueSizeFunc
 }
 
+// ValueSizeFunc returns the size in bytes of the object that value describes
+type ValueSizeFunc func(value *graveler.Value) (int64, error)
+
 type metaRangeManager struct {
 	params       Params
 	metaManager  RangeManager // For metaranges
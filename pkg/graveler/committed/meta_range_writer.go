@@ -20,6 +20,7 @@ type GeneralMetaRangeWriter struct {
 	metaRangeManager RangeManager
 	rangeManager     RangeManager
 	rangeWriter      RangeWriter // writer for the current range
+	rangeObjectSize  int64       // total object size of the values written to the current range
 	lastKey          Key
 	batchWriteCloser BatchWriterCloser
 	ranges           []Range
@@ -66,6 +67,14 @@ func (w *GeneralMetaRangeWriter) WriteRecord(record graveler.ValueRecord) error
 		w.rangeWriter.SetMetadata(MetadataTypeKey, MetadataRangesType)
 	}
 
+	if w.params.ValueSize != nil {
+		size, err := w.params.ValueSize(record.Value)
+		if err != nil {
+			return fmt.Errorf("size of %s: %w", record.Key, err)
+		}
+		w.rangeObjectSize += size
+	}
+
 	v, err := MarshalValue(record.Value)
 	if err != nil {
 		return err
@@ -85,13 +94,28 @@ func (w *GeneralMetaRangeWriter) closeCurrentRange() error {
 	if w.rangeWriter == nil {
 		return nil
 	}
-	if err := w.batchWriteCloser.CloseWriterAsync(w.rangeWriter); err != nil {
+	if err := w.batchWriteCloser.CloseWriterAsync(&sizedRangeCloser{RangeWriter: w.rangeWriter, objectSize: w.rangeObjectSize}); err != nil {
 		return fmt.Errorf("write range: %w", err)
 	}
 	w.rangeWriter = nil
+	w.rangeObjectSize = 0
 	return nil
 }
 
+// sizedRangeCloser closes a range writer, recording the total object size of the range in its result
+type sizedRangeCloser struct {
+	RangeWriter
+	objectSize int64
+}
+
+func (c *sizedRangeCloser) Close() (*WriteResult, error) {
+	res, err := c.RangeWriter.Close()
+	if res != nil {
+		res.ObjectSize = c.objectSize
+	}
+	return res, err
+}
+
 func (w *GeneralMetaRangeWriter) getBatchedRanges() ([]Range, error) {
 	wr, err := w.batchWriteCloser.Wait()
 	if err != nil {
@@ -105,6 +129,7 @@ func (w *GeneralMetaRangeWriter) getBatchedRanges() ([]Range, error) {
 			MaxKey:        r.Last,
 			EstimatedSize: r.EstimatedRangeSizeBytes,
 			Count:         int64(r.Count),
+			ObjectSize:    r.ObjectSize,
 		}
 	}
 	return ranges, nil
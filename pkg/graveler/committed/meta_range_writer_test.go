@@ -95,6 +95,49 @@ func TestWriter_WriteRecords(t *testing.T) {
 	}
 }
 
+func TestWriter_RecordsObjectSize(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	writeResult := committed.WriteResult{
+		RangeID: committed.ID("id"),
+		First:   committed.Key("a"),
+		Last:    committed.Key("b"),
+		Count:   2,
+	}
+	fakeWriter := NewFakeRangeWriter(&writeResult, nil)
+	fakeWriter.ExpectAnyRecord()
+	fakeWriter.ExpectAnyRecord()
+	rangeManager := mock.NewMockRangeManager(ctrl)
+	rangeManager.EXPECT().GetWriter(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeWriter, nil)
+
+	// the metarange records the total object size of the range
+	rangeData, err := committed.MarshalRange(committed.Range{MinKey: committed.Key("a"), MaxKey: committed.Key("b"), Count: 2, ObjectSize: 30})
+	testutil.Must(t, err)
+	rangeValue, err := committed.MarshalValue(&graveler.Value{Identity: []byte("id"), Data: rangeData})
+	testutil.Must(t, err)
+	fakeMetaWriter := NewFakeRangeWriter(&committed.WriteResult{RangeID: committed.ID("meta-range-id")}, nil)
+	fakeMetaWriter.ExpectWriteRecord(committed.Record{Key: committed.Key("b"), Value: rangeValue})
+	rangeManagerMeta := mock.NewMockRangeManager(ctrl)
+	rangeManagerMeta.EXPECT().GetWriter(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeMetaWriter, nil)
+
+	sizedParams := params
+	sizedParams.ValueSize = func(value *graveler.Value) (int64, error) {
+		return int64(10 * len(value.Identity)), nil
+	}
+	w := committed.NewGeneralMetaRangeWriter(ctx, rangeManager, rangeManagerMeta, &sizedParams, committed.Namespace("ns"), nil)
+	for _, record := range []graveler.ValueRecord{
+		{Key: graveler.Key("a"), Value: &graveler.Value{Identity: []byte("a")}},
+		{Key: graveler.Key("b"), Value: &graveler.Value{Identity: []byte("bb")}},
+	} {
+		testutil.MustDo(t, "write record "+record.Key.String(), w.WriteRecord(record))
+	}
+	_, err = w.Close()
+	testutil.MustDo(t, "close", err)
+	testutil.MustDo(t, "write metarange", fakeMetaWriter.Err())
+}
+
 func TestWriter_OverlappingRanges(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
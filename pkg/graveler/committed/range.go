@@ -10,6 +10,7 @@ type Range struct {
 	MaxKey        Key
 	EstimatedSize uint64 // EstimatedSize estimated Range size in bytes
 	Count         int64
+	ObjectSize    int64 // ObjectSize total size of the objects the Range values describe, 0 when not recorded
 	Tombstone     bool
 }
 
@@ -20,6 +21,7 @@ func (r Range) Copy() *Range {
 		MaxKey:        r.MaxKey.Copy(),
 		EstimatedSize: r.EstimatedSize,
 		Count:         r.Count,
+		ObjectSize:    r.ObjectSize,
 		Tombstone:     r.Tombstone,
 	}
 }
@@ -30,6 +32,7 @@ func MarshalRange(r Range) ([]byte, error) {
 		MaxKey:        r.MaxKey,
 		EstimatedSize: r.EstimatedSize,
 		Count:         r.Count,
+		ObjectSize:    r.ObjectSize,
 	})
 }
 
@@ -44,5 +47,6 @@ func UnmarshalRange(b []byte) (Range, error) {
 		MaxKey:        p.MaxKey,
 		EstimatedSize: p.EstimatedSize,
 		Count:         p.Count,
+		ObjectSize:    p.ObjectSize,
 	}, nil
 }
@@ -82,6 +82,9 @@ type WriteResult struct {
 
 	// EstimatedRangeSizeBytes is Approximate size of each Range
 	EstimatedRangeSizeBytes uint64
+
+	// ObjectSize is the total size of the objects the Range values describe, 0 when not recorded.
+	ObjectSize int64
 }
 
 // RangeWriter is an abstraction for writing Ranges.
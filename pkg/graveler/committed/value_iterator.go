@@ -37,3 +37,38 @@ func NewValueIterator(it Iterator) graveler.ValueIterator {
 		it: it,
 	}
 }
+
+// metaRangeIterator exposes the ranges of a MetaRange Iterator by their ID
+type metaRangeIterator struct {
+	it Iterator
+}
+
+func (m *metaRangeIterator) Next() bool {
+	return m.it.Next()
+}
+
+func (m *metaRangeIterator) NextRange() bool {
+	return m.it.NextRange()
+}
+
+func (m *metaRangeIterator) Value() (graveler.RangeID, *graveler.ValueRecord) {
+	rec, rng := m.it.Value()
+	if rng == nil {
+		return "", rec
+	}
+	return graveler.RangeID(rng.ID), rec
+}
+
+func (m *metaRangeIterator) Err() error {
+	return m.it.Err()
+}
+
+func (m *metaRangeIterator) Close() {
+	m.it.Close()
+}
+
+func NewMetaRangeIterator(it Iterator) graveler.MetaRangeIterator {
+	return &metaRangeIterator{
+		it: it,
+	}
+}
@@ -39,6 +39,27 @@ var (
 	ErrAddCommitNoParent      = errors.New("added commit must have a parent")
 	ErrMultipleParents        = errors.New("cannot have more than a single parent")
 	ErrRevertParentOutOfRange = errors.New("given commit does not have the given parent number")
+
+	ErrCherryPickMergeNoParent    = errors.New("must specify 1-based parent number for cherry-picking merge commit")
+	ErrCherryPickParentOutOfRange = errors.New("cherry-picked commit does not have the given parent number")
+
+	ErrInvalidMergeStrategy = fmt.Errorf("merge strategy: %w", ErrInvalidValue)
+
+	ErrInvalidMergeMode = fmt.Errorf("merge mode: %w", ErrInvalidValue)
+	ErrNotFastForward   = errors.New("destination is not an ancestor of source, cannot fast-forward")
+
+	ErrBranchProtectionRuleNotFound = fmt.Errorf("branch protection rule %w", ErrNotFound)
+	ErrBranchProtectionRuleExists   = fmt.Errorf("branch protection rule already exists: %w", ErrNotUnique)
+	ErrProtectedBranch              = errors.New("protected branch")
+	ErrWriteToProtectedBranch       = fmt.Errorf("cannot write to %w", ErrProtectedBranch)
+	ErrCommitToProtectedBranch      = fmt.Errorf("cannot commit to %w", ErrProtectedBranch)
+	ErrMergeSourceRunNotPassed      = fmt.Errorf("latest action run of merge source did not pass, cannot merge into %w", ErrProtectedBranch)
+
+	ErrBranchHeadMismatch = fmt.Errorf("branch head mismatch: %w", ErrPreconditionFailed)
+
+	ErrCommitExpired = errors.New("commit expired by retention")
+
+	ErrRepositoryInPolicy = errors.New("repository is named by auth policies, update them to rename it")
 )
 
 // wrappedError is an error for wrapping another error while ignoring its message.
@@ -75,3 +96,34 @@ func (e *HookAbortError) Error() string {
 func (e *HookAbortError) Unwrap() error {
 	return e.Err
 }
+
+// MergeConflictError is returned when merge stops on a conflict, holding the commits it merged.  Conflicts
+// listed between these commits are those of the failed merge, as their merge base is the one it used.
+type MergeConflictError struct {
+	Source      CommitID
+	Destination CommitID
+	Err         error
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("merge '%s' into '%s': %s", e.Source, e.Destination, e.Err)
+}
+
+func (e *MergeConflictError) Unwrap() error {
+	return e.Err
+}
+
+// RebaseConflictError is returned when rebase stops on a commit that cannot be applied on top of the target ref
+type RebaseConflictError struct {
+	CommitID CommitID
+	Message  string
+	Err      error
+}
+
+func (e *RebaseConflictError) Error() string {
+	return fmt.Sprintf("rebase stopped at commit '%s' (%s): %s", e.CommitID, e.Message, e.Err)
+}
+
+func (e *RebaseConflictError) Unwrap() error {
+	return e.Err
+}
@@ -1,13 +1,17 @@
 package graveler
 
 import (
+	"bytes"
+	"container/heap"
 	"context"
 	"errors"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/treeverse/lakefs/pkg/cache"
 	"github.com/treeverse/lakefs/pkg/ident"
 	"github.com/treeverse/lakefs/pkg/logging"
 	"google.golang.org/protobuf/proto"
@@ -28,6 +32,8 @@ const (
 
 type DiffSummary struct {
 	Count map[DiffType]int
+	// Bytes, when set, is the total size of the added and removed objects, and how much changed objects grew
+	Bytes map[DiffType]int64
 }
 
 // ReferenceType represents the type of the reference
@@ -55,8 +61,97 @@ type RangeInfo struct {
 	Address string
 }
 
+// MergeStrategy changes the way conflicts are resolved when merging
+type MergeStrategy int
+
+const (
+	// MergeStrategyNone fails the merge on the first conflict
+	MergeStrategyNone MergeStrategy = iota
+	// MergeStrategyDest resolves conflicts by keeping the destination value
+	MergeStrategyDest
+	// MergeStrategySrc resolves conflicts by taking the source value
+	MergeStrategySrc
+)
+
+const (
+	MergeStrategyNoneStr = "default"
+	MergeStrategyDestStr = "dest-wins"
+	MergeStrategySrcStr  = "source-wins"
+)
+
+var mergeStrategyString = map[MergeStrategy]string{
+	MergeStrategyNone: MergeStrategyNoneStr,
+	MergeStrategyDest: MergeStrategyDestStr,
+	MergeStrategySrc:  MergeStrategySrcStr,
+}
+
+func (s MergeStrategy) String() string {
+	if str, ok := mergeStrategyString[s]; ok {
+		return str
+	}
+	return fmt.Sprintf("MergeStrategy(%d)", int(s))
+}
+
+// ParseMergeStrategy returns the MergeStrategy matching the given name. An empty name
+// selects MergeStrategyNone.
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	if name == "" {
+		return MergeStrategyNone, nil
+	}
+	for strategy, str := range mergeStrategyString {
+		if str == name {
+			return strategy, nil
+		}
+	}
+	return MergeStrategyNone, fmt.Errorf("%w: '%s'", ErrInvalidMergeStrategy, name)
+}
+
+// MergeMode selects whether a merge records a new commit or moves the destination branch to the source commit
+type MergeMode int
+
+const (
+	// MergeModeNoFF always records the merge as a new commit on the destination
+	MergeModeNoFF MergeMode = iota
+	// MergeModeFFOnly moves the destination branch to the source commit, and fails when the destination
+	// is not an ancestor of the source
+	MergeModeFFOnly
+)
+
+const (
+	MergeModeNoFFStr   = "no-ff"
+	MergeModeFFOnlyStr = "ff-only"
+)
+
+var mergeModeString = map[MergeMode]string{
+	MergeModeNoFF:   MergeModeNoFFStr,
+	MergeModeFFOnly: MergeModeFFOnlyStr,
+}
+
+func (m MergeMode) String() string {
+	if str, ok := mergeModeString[m]; ok {
+		return str
+	}
+	return fmt.Sprintf("MergeMode(%d)", int(m))
+}
+
+// ParseMergeMode returns the MergeMode matching the given name. An empty name
+// selects MergeModeNoFF.
+func ParseMergeMode(name string) (MergeMode, error) {
+	if name == "" {
+		return MergeModeNoFF, nil
+	}
+	for mode, str := range mergeModeString {
+		if str == name {
+			return mode, nil
+		}
+	}
+	return MergeModeNoFF, fmt.Errorf("%w: '%s'", ErrInvalidMergeMode, name)
+}
+
 type WriteCondition struct {
 	IfAbsent bool
+	// IfMatch, if set, is the identity the current value must have for the write to succeed
+	IfMatch []byte
 }
 
 type WriteConditionOption func(condition *WriteCondition)
@@ -67,6 +162,21 @@ func IfAbsent(v bool) WriteConditionOption {
 	}
 }
 
+func IfMatch(identity []byte) WriteConditionOption {
+	return func(condition *WriteCondition) {
+		condition.IfMatch = identity
+	}
+}
+
+// StagingOperation is a single write in a batch applied atomically to a branch staging area.
+// A nil Value deletes Key.  Drop is set by graveler when a deleted key needs no tombstone, and
+// instructs the staging manager to remove the key instead of staging a nil value.
+type StagingOperation struct {
+	Key   Key
+	Value *Value
+	Drop  bool
+}
+
 // function/methods receiving the following basic types could assume they passed validation
 
 // StorageNamespace is the URI to the storage location
@@ -109,6 +219,17 @@ type Repository struct {
 	StorageNamespace StorageNamespace `db:"storage_namespace"`
 	CreationDate     time.Time        `db:"creation_date"`
 	DefaultBranchID  BranchID         `db:"default_branch"`
+	Description      string           `db:"description"`
+	Metadata         Metadata         `db:"metadata"`
+}
+
+// RepositoryUpdate holds the settings UpdateRepository changes on a repository, nil fields are left unchanged
+type RepositoryUpdate struct {
+	// RepositoryID renames the repository
+	RepositoryID    *RepositoryID
+	DefaultBranchID *BranchID
+	Description     *string
+	Metadata        Metadata
 }
 
 type RepositoryRecord struct {
@@ -162,6 +283,11 @@ func (cp CommitParents) AsStringSlice() []string {
 // FirstCommitMsg is the message of the first (zero) commit of a lakeFS repository
 const FirstCommitMsg = "Repository created"
 
+// DumpCommitsSinceOverlap is how long before its start time DumpCommitsSince starts dumping commits.  It covers
+// commits whose insertion was still in progress when the previous dump read the commits, and clock differences
+// between lakeFS and its database.  Loading a commit twice has no effect, so the overlap only costs its size.
+const DumpCommitsSinceOverlap = time.Minute
+
 // CommitVersion used to track changes in Commit schema. Each version is change that a constant describes.
 type CommitVersion int
 
@@ -184,6 +310,8 @@ type Commit struct {
 	Parents      CommitParents `db:"parents"`
 	Metadata     Metadata      `db:"metadata"`
 	Generation   int           `db:"generation"`
+	// Expired is set on commits expired by retention, it is not part of the commit identity
+	Expired bool `db:"expired"`
 }
 
 func NewCommit() Commit {
@@ -223,10 +351,34 @@ type BranchRecord struct {
 	*Branch
 }
 
+// RetentionRule keeps commits on branches matching BranchPattern for RetentionDays
+type RetentionRule struct {
+	BranchPattern string `db:"branch_pattern"`
+	RetentionDays int    `db:"retention_days"`
+}
+
+// BranchProtectionRule protects the branches matching Pattern from direct writes and commits, they change only by merge
+type BranchProtectionRule struct {
+	// Pattern is a branch ID glob, in path.Match syntax
+	Pattern string `db:"pattern"`
+	// RequirePassingRun accepts merges only from branches whose latest action run passed
+	RequirePassingRun bool `db:"require_passing_run"`
+}
+
+// TagAnnotation holds the message, creator, creation date and metadata recorded by an annotated tag
+type TagAnnotation struct {
+	Message      string
+	Creator      string
+	CreationDate time.Time
+	Metadata     Metadata
+}
+
 // TagRecord holds TagID with the associated Tag data
 type TagRecord struct {
 	TagID    TagID
 	CommitID CommitID
+	// Annotation is nil for a lightweight tag
+	Annotation *TagAnnotation
 }
 
 // Diff represents a change in value based on key
@@ -235,6 +387,9 @@ type Diff struct {
 	Key          Key
 	Value        *Value
 	LeftIdentity []byte // the Identity of the value on the left side of the diff
+	// CommonPrefix is set when Key is a common prefix grouping all diffs of keys under it.
+	// Value and LeftIdentity are nil in that case.
+	CommonPrefix *DiffSummary
 }
 
 func (d *Diff) Copy() *Diff {
@@ -243,18 +398,77 @@ func (d *Diff) Copy() *Diff {
 		Key:          d.Key.Copy(),
 		Value:        d.Value,
 		LeftIdentity: append([]byte(nil), d.LeftIdentity...),
+		CommonPrefix: d.CommonPrefix,
 	}
 }
 
+// MergeConflict describes a key that was changed differently on both sides of a merge.
+// A nil value means the key does not exist on that side.
+type MergeConflict struct {
+	Key         Key
+	Source      *Value
+	Destination *Value
+	Base        *Value
+}
+
+// BlameRecord holds the commit that last changed a key
+type BlameRecord struct {
+	Key    Key
+	Commit *CommitRecord
+}
+
 type CommitParams struct {
 	Committer string
 	Message   string
 	Metadata  Metadata
+	// ExpectedHead, if set, fails the commit with ErrBranchHeadMismatch unless it is the branch head
+	ExpectedHead CommitID
+}
+
+// CommitFilter selects the commits returned while walking a log
+type CommitFilter struct {
+	// FirstParent follows only the first parent of merge commits
+	FirstParent bool
+	// Committer, if set, matches only commits by this committer
+	Committer string
+	// Since, if set, matches only commits created at or after it.  The log ends at the first
+	// older commit.
+	Since *time.Time
+	// Until, if set, matches only commits created at or before it
+	Until *time.Time
+	// Metadata, if set, matches only commits whose metadata holds all of its key/values
+	Metadata Metadata
+}
+
+// LogParams filter the commits returned by Log
+type LogParams struct {
+	CommitFilter
+	// Prefix, if set, returns only commits that change a key starting with Prefix relative to their first parent
+	Prefix Key
+}
+
+// DiffParams scope a diff to keys starting with Prefix.  When Delimiter is set, diffs of keys
+// sharing the part up to the first Delimiter after Prefix are grouped into a single common prefix.
+type DiffParams struct {
+	Prefix    Key
+	Delimiter Key
+}
+
+type MergeParams struct {
+	// Strategy resolves conflicts, the merge fails on conflict with MergeStrategyNone
+	Strategy MergeStrategy
+	// Squash records the merge as a single-parent commit on the destination, with a message
+	// summarizing the squashed commits of the source
+	Squash bool
+	// Mode selects between recording a merge commit and fast-forwarding the destination
+	Mode MergeMode
+	// ExpectedHead, if set, fails the merge with ErrBranchHeadMismatch unless it is the destination head
+	ExpectedHead CommitID
 }
 
 type KeyValueStore interface {
 	// Get returns value from repository / reference by key, nil value is a valid value for tombstone
-	// returns error if value does not exist
+	// returns error if value does not exist, and the value with ErrCommitExpired if read from an expired commit
 	Get(ctx context.Context, repositoryID RepositoryID, ref Ref, key Key) (*Value, error)
 
 	// Set stores value on repository / branch by key. nil value is a valid value for tombstone
@@ -263,6 +477,10 @@ type KeyValueStore interface {
 	// Delete value from repository / branch branch by key
 	Delete(ctx context.Context, repositoryID RepositoryID, branchID BranchID, key Key) error
 
+	// Apply stages all operations on repository / branch, or none of them if any fails.
+	// Operations are applied in order, a later operation on a key overrides an earlier one.
+	Apply(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ops []StagingOperation) error
+
 	// List lists values on repository / ref
 	List(ctx context.Context, repositoryID RepositoryID, ref Ref) (ValueIterator, error)
 }
@@ -283,11 +501,15 @@ type VersionController interface {
 	// DeleteRepository deletes the repository
 	DeleteRepository(ctx context.Context, repositoryID RepositoryID) error
 
+	// UpdateRepository changes the settings of the repository, returning it as updated
+	UpdateRepository(ctx context.Context, repositoryID RepositoryID, update RepositoryUpdate) (*Repository, error)
+
 	// CreateBranch creates branch on repository pointing to ref
 	CreateBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref) (*Branch, error)
 
 	// UpdateBranch updates branch on repository pointing to ref
-	UpdateBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref) (*Branch, error)
+	// expectedHead, if set, fails the update with ErrBranchHeadMismatch unless it is the branch head
+	UpdateBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref, expectedHead CommitID) (*Branch, error)
 
 	// GetBranch gets branch information by branch / repository id
 	GetBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID) (*Branch, error)
@@ -295,8 +517,11 @@ type VersionController interface {
 	// GetTag gets tag's commit id
 	GetTag(ctx context.Context, repositoryID RepositoryID, tagID TagID) (*CommitID, error)
 
-	// CreateTag creates tag on a repository pointing to a commit id
-	CreateTag(ctx context.Context, repositoryID RepositoryID, tagID TagID, commitID CommitID) error
+	// GetTagRecord gets tag's commit id and annotation
+	GetTagRecord(ctx context.Context, repositoryID RepositoryID, tagID TagID) (*TagRecord, error)
+
+	// CreateTag creates tag on a repository pointing to a commit id, annotated if annotation is not nil
+	CreateTag(ctx context.Context, repositoryID RepositoryID, tagID TagID, commitID CommitID, annotation *TagAnnotation) error
 
 	// DeleteTag remove tag from a repository
 	DeleteTag(ctx context.Context, repositoryID RepositoryID, tagID TagID) error
@@ -304,8 +529,38 @@ type VersionController interface {
 	// ListTags lists tags on a repository
 	ListTags(ctx context.Context, repositoryID RepositoryID) (TagIterator, error)
 
-	// Log returns an iterator starting at commit ID up to repository root
-	Log(ctx context.Context, repositoryID RepositoryID, commitID CommitID) (CommitIterator, error)
+	// GetBranchProtectionRules lists the branch protection rules of a repository
+	GetBranchProtectionRules(ctx context.Context, repositoryID RepositoryID) ([]*BranchProtectionRule, error)
+
+	// CreateBranchProtectionRule protects the branches matching rule.Pattern
+	CreateBranchProtectionRule(ctx context.Context, repositoryID RepositoryID, rule BranchProtectionRule) error
+
+	// DeleteBranchProtectionRule removes the protection rule matching pattern
+	DeleteBranchProtectionRule(ctx context.Context, repositoryID RepositoryID, pattern string) error
+
+	// GetRetentionRules lists the retention rules of a repository, in the order they are matched
+	GetRetentionRules(ctx context.Context, repositoryID RepositoryID) ([]*RetentionRule, error)
+
+	// SetRetentionRules replaces the retention rules of a repository
+	SetRetentionRules(ctx context.Context, repositoryID RepositoryID, rules []*RetentionRule) error
+
+	// SetExpiredCommits marks exactly commitIDs of the repository as expired
+	SetExpiredCommits(ctx context.Context, repositoryID RepositoryID, commitIDs []CommitID) error
+
+	// AddStorageNamespaceReferences records that the repository references objects on storageNamespaces
+	AddStorageNamespaceReferences(ctx context.Context, repositoryID RepositoryID, storageNamespaces []StorageNamespace) error
+
+	// ListStorageNamespaceReferences lists the storage namespaces the repository references objects on
+	ListStorageNamespaceReferences(ctx context.Context, repositoryID RepositoryID) ([]StorageNamespace, error)
+
+	// ListReferencingRepositories lists the repositories referencing objects on storageNamespace
+	ListReferencingRepositories(ctx context.Context, storageNamespace StorageNamespace) ([]RepositoryID, error)
+
+	// Log returns an iterator starting at commit ID up to repository root, over the commits matching params
+	Log(ctx context.Context, repositoryID RepositoryID, commitID CommitID, params LogParams) (CommitIterator, error)
+
+	// ListCommits returns an iterator over all known commits in the repository, ordered by their commit ID
+	ListCommits(ctx context.Context, repositoryID RepositoryID) (CommitIterator, error)
 
 	// ListBranches lists branches on repositories
 	ListBranches(ctx context.Context, repositoryID RepositoryID) (BranchIterator, error)
@@ -348,23 +603,51 @@ type VersionController interface {
 	// Revert creates a reverse patch to the commit given as 'ref', and applies it as a new commit on the given branch.
 	Revert(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref, parentNumber int, commitParams CommitParams) (CommitID, DiffSummary, error)
 
+	// CherryPick applies the changes introduced by the commit given as 'ref' on top of the given branch, and records them as a new commit.
+	CherryPick(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref, parentNumber int, commitParams CommitParams) (CommitID, DiffSummary, error)
+
+	// Rebase replays the non-merge commits of branchID since its merge base with 'onto' on top of 'onto',
+	// keeping the metadata of the replayed commits. Commits whose changes are already on 'onto' are dropped. It
+	// stops with RebaseConflictError at the first conflicting commit.
+	Rebase(ctx context.Context, repositoryID RepositoryID, branchID BranchID, onto Ref) (CommitID, error)
+
 	// Merge merges 'source' into 'destination' and returns the commit id for the created merge commit, and a summary of results.
-	Merge(ctx context.Context, repositoryID RepositoryID, destination BranchID, source Ref, commitParams CommitParams) (CommitID, DiffSummary, error)
+	// Conflicts are resolved according to mergeParams.Strategy, failing with ErrConflictFound when no resolution is requested.
+	Merge(ctx context.Context, repositoryID RepositoryID, destination BranchID, source Ref, commitParams CommitParams, mergeParams MergeParams) (CommitID, DiffSummary, error)
 
 	// DiffUncommitted returns iterator to scan the changes made on the branch
 	DiffUncommitted(ctx context.Context, repositoryID RepositoryID, branchID BranchID) (DiffIterator, error)
 
 	// Diff returns the changes between 'left' and 'right' ref.
 	// This is similar to a two-dot (left..right) diff in git.
-	Diff(ctx context.Context, repositoryID RepositoryID, left, right Ref) (DiffIterator, error)
+	Diff(ctx context.Context, repositoryID RepositoryID, left, right Ref, params DiffParams) (DiffIterator, error)
 
 	// Compare returns the difference between the commit where 'to' was last synced into 'from', and the most recent commit of `from`.
 	// This is similar to a three-dot (from...to) diff in git.
-	Compare(ctx context.Context, repositoryID RepositoryID, from, to Ref) (DiffIterator, error)
+	Compare(ctx context.Context, repositoryID RepositoryID, from, to Ref, params DiffParams) (DiffIterator, error)
+
+	// AheadBehind returns the number of commits reachable from 'ref' but not from 'other', and the number reachable from
+	// 'other' but not from 'ref'.
+	AheadBehind(ctx context.Context, repositoryID RepositoryID, ref, other Ref) (ahead, behind int, err error)
+
+	// DiffSummary counts the changes between 'left' and 'right' ref on keys starting with prefix, and sums their object size.
+	DiffSummary(ctx context.Context, repositoryID RepositoryID, left, right Ref, prefix Key) (DiffSummary, error)
+
+	// MergeConflicts returns iterator over the keys that conflict when merging 'source' into 'destination',
+	// with their values on the source, the destination and the merge base
+	MergeConflicts(ctx context.Context, repositoryID RepositoryID, destination, source Ref) (MergeConflictIterator, error)
+
+	// Blame returns, for up to amount keys starting with prefix and greater than after on ref, the
+	// commit that last changed each key.  Uncommitted changes are ignored.  The log is walked once for
+	// all the keys, until each is resolved; a key that no commit adds has a nil Commit.
+	Blame(ctx context.Context, repositoryID RepositoryID, ref Ref, prefix, after Key, amount int) ([]BlameRecord, error)
 
 	// SetHooksHandler set handler for all graveler hooks
 	SetHooksHandler(handler HooksHandler)
 
+	// SetBranchRunChecker set the checker of action runs used by protected branches requiring a passing run
+	SetBranchRunChecker(checker BranchRunChecker)
+
 	// GetStagingToken returns the token identifying current staging for branchID of
 	// repositoryID.
 	GetStagingToken(ctx context.Context, repositoryID RepositoryID, branchID BranchID) (*StagingToken, error)
@@ -377,12 +660,19 @@ type Plumbing interface {
 	GetMetaRange(ctx context.Context, repositoryID RepositoryID, metaRangeID MetaRangeID) (MetaRangeInfo, error)
 	// GetRange returns information where rangeID is stored.
 	GetRange(ctx context.Context, repositoryID RepositoryID, rangeID RangeID) (RangeInfo, error)
+	// ListMetaRange returns an iterator over the ranges of metaRangeID and their values, which can skip
+	// entire ranges.
+	ListMetaRange(ctx context.Context, repositoryID RepositoryID, metaRangeID MetaRangeID) (MetaRangeIterator, error)
 }
 
 type Dumper interface {
 	// DumpCommits iterates through all commits and dumps them in Graveler format
 	DumpCommits(ctx context.Context, repositoryID RepositoryID) (*MetaRangeID, error)
 
+	// DumpCommitsSince dumps in Graveler format the commits added to repositoryID since 'since', less
+	// DumpCommitsSinceOverlap.  Loading these commits after those of a dump started at 'since' loads all commits.
+	DumpCommitsSince(ctx context.Context, repositoryID RepositoryID, since time.Time) (*MetaRangeID, error)
+
 	// DumpBranches iterates through all branches and dumps them in Graveler format
 	DumpBranches(ctx context.Context, repositoryID RepositoryID) (*MetaRangeID, error)
 
@@ -394,10 +684,13 @@ type Loader interface {
 	// LoadCommits iterates through all commits in Graveler format and loads them into repositoryID
 	LoadCommits(ctx context.Context, repositoryID RepositoryID, metaRangeID MetaRangeID) error
 
-	// LoadBranches iterates through all branches in Graveler format and loads them into repositoryID
+	// LoadBranches iterates through all branches in Graveler format and loads them into repositoryID.  Existing
+	// branches move to their loaded commit and keep their uncommitted changes, other than the default branch
+	// those missing from the dump are deleted.
 	LoadBranches(ctx context.Context, repositoryID RepositoryID, metaRangeID MetaRangeID) error
 
-	// LoadTags iterates through all tags in Graveler format and loads them into repositoryID
+	// LoadTags iterates through all tags in Graveler format and loads them into repositoryID.  Existing tags on
+	// another commit are replaced by their loaded version, those missing from the dump are deleted.
 	LoadTags(ctx context.Context, repositoryID RepositoryID, metaRangeID MetaRangeID) error
 }
 
@@ -434,6 +727,19 @@ type ValueIterator interface {
 	Close()
 }
 
+// MetaRangeIterator iterates over the ranges of a metarange and their values, allowing to skip entire ranges
+type MetaRangeIterator interface {
+	// Next moves to the next value of the current range, or to the header of the next range when the
+	// current range is over.
+	Next() bool
+	// NextRange skips the values of the current range and moves to the header of the next range.
+	NextRange() bool
+	// Value returns the ID of the current range, with a nil value on the range header.
+	Value() (RangeID, *ValueRecord)
+	Err() error
+	Close()
+}
+
 type DiffIterator interface {
 	Next() bool
 	SeekGE(id Key)
@@ -442,6 +748,14 @@ type DiffIterator interface {
 	Close()
 }
 
+type MergeConflictIterator interface {
+	Next() bool
+	SeekGE(id Key)
+	Value() *MergeConflict
+	Err() error
+	Close()
+}
+
 type BranchIterator interface {
 	Next() bool
 	SeekGE(id BranchID)
@@ -486,6 +800,10 @@ type RefManager interface {
 	// DeleteRepository deletes the repository
 	DeleteRepository(ctx context.Context, repositoryID RepositoryID) error
 
+	// UpdateRepository changes the settings of the repository.  The default branch must exist, and a
+	// renamed repository keeps its branches, commits, tags and branch protection rules.
+	UpdateRepository(ctx context.Context, repositoryID RepositoryID, update RepositoryUpdate) error
+
 	// RevParse returns the Reference matching the given Ref
 	RevParse(ctx context.Context, repositoryID RepositoryID, ref Ref) (Reference, error)
 
@@ -504,8 +822,11 @@ type RefManager interface {
 	// GetTag returns the Tag metadata object for the given TagID
 	GetTag(ctx context.Context, repositoryID RepositoryID, tagID TagID) (*CommitID, error)
 
-	// CreateTag create a given tag pointing to a commit
-	CreateTag(ctx context.Context, repositoryID RepositoryID, tagID TagID, commitID CommitID) error
+	// GetTagRecord returns the commit and annotation of the given TagID
+	GetTagRecord(ctx context.Context, repositoryID RepositoryID, tagID TagID) (*TagRecord, error)
+
+	// CreateTag create a given tag pointing to a commit, annotated if annotation is not nil
+	CreateTag(ctx context.Context, repositoryID RepositoryID, tagID TagID, commitID CommitID, annotation *TagAnnotation) error
 
 	// DeleteTag deletes the tag
 	DeleteTag(ctx context.Context, repositoryID RepositoryID, tagID TagID) error
@@ -513,6 +834,34 @@ type RefManager interface {
 	// ListTags lists tags
 	ListTags(ctx context.Context, repositoryID RepositoryID) (TagIterator, error)
 
+	// GetBranchProtectionRules lists the branch protection rules
+	GetBranchProtectionRules(ctx context.Context, repositoryID RepositoryID) ([]*BranchProtectionRule, error)
+
+	// CreateBranchProtectionRule stores a new branch protection rule
+	CreateBranchProtectionRule(ctx context.Context, repositoryID RepositoryID, rule BranchProtectionRule) error
+
+	// DeleteBranchProtectionRule deletes the branch protection rule
+	DeleteBranchProtectionRule(ctx context.Context, repositoryID RepositoryID, pattern string) error
+
+	// GetRetentionRules lists the retention rules
+	GetRetentionRules(ctx context.Context, repositoryID RepositoryID) ([]*RetentionRule, error)
+
+	// SetRetentionRules replaces the retention rules
+	SetRetentionRules(ctx context.Context, repositoryID RepositoryID, rules []*RetentionRule) error
+
+	// SetExpiredCommits marks exactly commitIDs as expired, reading from them fails with ErrCommitExpired
+	SetExpiredCommits(ctx context.Context, repositoryID RepositoryID, commitIDs []CommitID) error
+
+	// AddStorageNamespaceReferences records that the repository references objects on storage namespaces of
+	// other repositories (e.g. it is a fork), so their retention keeps these objects
+	AddStorageNamespaceReferences(ctx context.Context, repositoryID RepositoryID, storageNamespaces []StorageNamespace) error
+
+	// ListStorageNamespaceReferences lists the storage namespaces the repository references objects on
+	ListStorageNamespaceReferences(ctx context.Context, repositoryID RepositoryID) ([]StorageNamespace, error)
+
+	// ListReferencingRepositories lists the repositories referencing objects on storageNamespace
+	ListReferencingRepositories(ctx context.Context, storageNamespace StorageNamespace) ([]RepositoryID, error)
+
 	// GetCommit returns the Commit metadata object for the given CommitID.
 	GetCommit(ctx context.Context, repositoryID RepositoryID, commitID CommitID) (*Commit, error)
 
@@ -524,12 +873,16 @@ type RefManager interface {
 	// and internally: https://github.com/treeverse/lakeFS/blob/09954804baeb36ada74fa17d8fdc13a38552394e/index/dag/commits.go
 	FindMergeBase(ctx context.Context, repositoryID RepositoryID, commitIDs ...CommitID) (*Commit, error)
 
-	// Log returns an iterator starting at commit ID up to repository root
-	Log(ctx context.Context, repositoryID RepositoryID, commitID CommitID) (CommitIterator, error)
+	// Log returns an iterator starting at commit ID up to repository root, over the commits matching filter
+	Log(ctx context.Context, repositoryID RepositoryID, commitID CommitID, filter CommitFilter) (CommitIterator, error)
 
 	// ListCommits returns an iterator over all known commits, ordered by their commit ID
 	ListCommits(ctx context.Context, repositoryID RepositoryID) (CommitIterator, error)
 
+	// ListCommitsSince returns an iterator over the commits added to the repository at or after since, ordered
+	// by their commit ID
+	ListCommitsSince(ctx context.Context, repositoryID RepositoryID, since time.Time) (CommitIterator, error)
+
 	// FillGenerations computes and updates the generation field for all commits in a repository.
 	// It should be used for restoring commits from a commit-dump which was performed before the field was introduced.
 	FillGenerations(ctx context.Context, repositoryID RepositoryID) error
@@ -550,18 +903,31 @@ type CommittedManager interface {
 	// List takes a given tree and returns an ValueIterator
 	List(ctx context.Context, ns StorageNamespace, rangeID MetaRangeID) (ValueIterator, error)
 
-	// Diff receives two metaRanges and returns a DiffIterator describing all differences between them.
-	// This is similar to a two-dot diff in git (left..right)
-	Diff(ctx context.Context, ns StorageNamespace, left, right MetaRangeID) (DiffIterator, error)
+	// ListMetaRange returns a MetaRangeIterator over the ranges of metaRangeID and their values
+	ListMetaRange(ctx context.Context, ns StorageNamespace, metaRangeID MetaRangeID) (MetaRangeIterator, error)
+
+	// Diff receives two metaRanges and returns a DiffIterator describing all differences between them
+	// that are in the scope of params.  This is similar to a two-dot diff in git (left..right)
+	Diff(ctx context.Context, ns StorageNamespace, left, right MetaRangeID, params DiffParams) (DiffIterator, error)
 
-	// Compare returns the difference between 'source' and 'destination', relative to a merge base 'base'.
-	// This is similar to a three-dot diff in git.
-	Compare(ctx context.Context, ns StorageNamespace, destination, source, base MetaRangeID) (DiffIterator, error)
+	// DiffSummary counts the differences between left and right on keys starting with prefix, and sums
+	// their object size.  Ranges added or removed as a whole are summarized from their metadata without being
+	// read when it records their object size.
+	DiffSummary(ctx context.Context, ns StorageNamespace, left, right MetaRangeID, prefix Key) (DiffSummary, error)
+
+	// PrefixChanged returns true if left and right differ on any key starting with prefix.
+	// Ranges identical on both sides or outside prefix are skipped without being read.
+	PrefixChanged(ctx context.Context, ns StorageNamespace, left, right MetaRangeID, prefix Key) (bool, error)
+
+	// Compare returns the difference between 'source' and 'destination', relative to a merge base 'base',
+	// that is in the scope of params.  This is similar to a three-dot diff in git.
+	Compare(ctx context.Context, ns StorageNamespace, destination, source, base MetaRangeID, params DiffParams) (DiffIterator, error)
 
 	// Merge applies changes from 'source' to 'destination', relative to a merge base 'base' and
 	// returns the ID of the new metarange and a summary of diffs.  This is similar to a
 	// git merge operation. The resulting tree is expected to be immediately addressable.
-	Merge(ctx context.Context, ns StorageNamespace, destination, source, base MetaRangeID) (MetaRangeID, DiffSummary, error)
+	// Conflicts are resolved according to 'strategy'.
+	Merge(ctx context.Context, ns StorageNamespace, destination, source, base MetaRangeID, strategy MergeStrategy) (MetaRangeID, DiffSummary, error)
 
 	// Apply is the act of taking an existing metaRange (snapshot) and applying a set of changes to it.
 	// A change is either an entity to write/overwrite, or a tombstone to mark a deletion
@@ -581,7 +947,9 @@ type StagingManager interface {
 	Get(ctx context.Context, st StagingToken, key Key) (*Value, error)
 
 	// Set writes a (possibly nil) value under the given staging token and key.
-	Set(ctx context.Context, st StagingToken, key Key, value *Value, overwrite bool) error
+	// Returns ErrPreconditionFailed if condition.IfAbsent is set and the key is staged, or if
+	// condition.IfMatch is set and the key is not staged with that identity.
+	Set(ctx context.Context, st StagingToken, key Key, value *Value, condition WriteCondition) error
 
 	// List returns a ValueIterator for the given staging token
 	List(ctx context.Context, st StagingToken) (ValueIterator, error)
@@ -589,6 +957,9 @@ type StagingManager interface {
 	// DropKey clears a value by staging token and key
 	DropKey(ctx context.Context, st StagingToken, key Key) error
 
+	// Apply sets or drops all keys of ops under the given staging token in a single transaction
+	Apply(ctx context.Context, st StagingToken, ops []StagingOperation) error
+
 	// Drop clears the given staging area
 	Drop(ctx context.Context, st StagingToken) error
 
@@ -646,12 +1017,22 @@ func (id TagID) String() string {
 	return string(id)
 }
 
+// Branch protection rules are read on every write, they are cached per repository.  Rule changes through this
+// instance invalidate its cache, changes through other instances show after the expiry.
+const (
+	protectionRulesCacheSize   = 1000
+	protectionRulesCacheExpiry = 10 * time.Second
+	protectionRulesCacheJitter = time.Second
+)
+
 type Graveler struct {
 	CommittedManager CommittedManager
 	StagingManager   StagingManager
 	RefManager       RefManager
 	branchLocker     BranchLocker
 	hooks            HooksHandler
+	runChecker       BranchRunChecker
+	protectionRules  cache.Cache
 	log              logging.Logger
 }
 
@@ -662,6 +1043,7 @@ func NewGraveler(branchLocker BranchLocker, committedManager CommittedManager, s
 		RefManager:       refManager,
 		branchLocker:     branchLocker,
 		hooks:            &HooksNoOp{},
+		protectionRules:  cache.NewCache(protectionRulesCacheSize, protectionRulesCacheExpiry, cache.NewJitterFn(protectionRulesCacheJitter)),
 		log:              logging.Default().WithField("service_name", "graveler_graveler"),
 	}
 }
@@ -710,9 +1092,23 @@ func (g *Graveler) WriteMetaRange(ctx context.Context, repositoryID RepositoryID
 }
 
 func (g *Graveler) DeleteRepository(ctx context.Context, repositoryID RepositoryID) error {
+	defer g.protectionRules.Invalidate(repositoryID)
 	return g.RefManager.DeleteRepository(ctx, repositoryID)
 }
 
+func (g *Graveler) UpdateRepository(ctx context.Context, repositoryID RepositoryID, update RepositoryUpdate) (*Repository, error) {
+	if err := g.RefManager.UpdateRepository(ctx, repositoryID, update); err != nil {
+		return nil, err
+	}
+	if update.RepositoryID != nil {
+		// the rules moved with the repository
+		g.protectionRules.Invalidate(repositoryID)
+		repositoryID = *update.RepositoryID
+		g.protectionRules.Invalidate(repositoryID)
+	}
+	return g.RefManager.GetRepository(ctx, repositoryID)
+}
+
 func (g *Graveler) GetCommit(ctx context.Context, repositoryID RepositoryID, commitID CommitID) (*Commit, error) {
 	return g.RefManager.GetCommit(ctx, repositoryID, commitID)
 }
@@ -751,9 +1147,12 @@ func (g *Graveler) CreateBranch(ctx context.Context, repositoryID RepositoryID,
 	return &newBranch, nil
 }
 
-func (g *Graveler) UpdateBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref) (*Branch, error) {
+func (g *Graveler) UpdateBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref, expectedHead CommitID) (*Branch, error) {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrWriteToProtectedBranch); err != nil {
+		return nil, err
+	}
 	res, err := g.branchLocker.MetadataUpdater(ctx, repositoryID, branchID, func() (interface{}, error) {
-		return g.updateBranchNoLock(ctx, repositoryID, branchID, ref)
+		return g.updateBranchNoLock(ctx, repositoryID, branchID, ref, expectedHead)
 	})
 	if err != nil {
 		return nil, err
@@ -761,7 +1160,7 @@ func (g *Graveler) UpdateBranch(ctx context.Context, repositoryID RepositoryID,
 	return res.(*Branch), nil
 }
 
-func (g *Graveler) updateBranchNoLock(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref) (*Branch, error) {
+func (g *Graveler) updateBranchNoLock(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref, expectedHead CommitID) (*Branch, error) {
 	reference, err := g.RefManager.RevParse(ctx, repositoryID, ref)
 	if err != nil {
 		return nil, err
@@ -771,6 +1170,9 @@ func (g *Graveler) updateBranchNoLock(ctx context.Context, repositoryID Reposito
 	if err != nil {
 		return nil, err
 	}
+	if err := checkExpectedHead(branchID, curBranch, expectedHead); err != nil {
+		return nil, err
+	}
 	// validate no conflict
 	// TODO(Guys) return error only on conflicts, currently returns error for any changes on staging
 	iter, err := g.StagingManager.List(ctx, curBranch.StagingToken)
@@ -793,6 +1195,15 @@ func (g *Graveler) updateBranchNoLock(ctx context.Context, repositoryID Reposito
 	return &newBranch, nil
 }
 
+// checkExpectedHead returns ErrBranchHeadMismatch if expectedHead is set and is not the head of branch.
+// Callers hold the branch metadata lock, so the head cannot move before they update the branch.
+func checkExpectedHead(branchID BranchID, branch *Branch, expectedHead CommitID) error {
+	if expectedHead == "" || expectedHead == branch.CommitID {
+		return nil
+	}
+	return fmt.Errorf("branch %s head is %s, expected %s: %w", branchID, branch.CommitID, expectedHead, ErrBranchHeadMismatch)
+}
+
 func (g *Graveler) GetBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID) (*Branch, error) {
 	return g.RefManager.GetBranch(ctx, repositoryID, branchID)
 }
@@ -801,8 +1212,12 @@ func (g *Graveler) GetTag(ctx context.Context, repositoryID RepositoryID, tagID
 	return g.RefManager.GetTag(ctx, repositoryID, tagID)
 }
 
-func (g *Graveler) CreateTag(ctx context.Context, repositoryID RepositoryID, tagID TagID, commitID CommitID) error {
-	return g.RefManager.CreateTag(ctx, repositoryID, tagID, commitID)
+func (g *Graveler) GetTagRecord(ctx context.Context, repositoryID RepositoryID, tagID TagID) (*TagRecord, error) {
+	return g.RefManager.GetTagRecord(ctx, repositoryID, tagID)
+}
+
+func (g *Graveler) CreateTag(ctx context.Context, repositoryID RepositoryID, tagID TagID, commitID CommitID, annotation *TagAnnotation) error {
+	return g.RefManager.CreateTag(ctx, repositoryID, tagID, commitID, annotation)
 }
 
 func (g *Graveler) DeleteTag(ctx context.Context, repositoryID RepositoryID, tagID TagID) error {
@@ -821,8 +1236,27 @@ func (g *Graveler) Dereference(ctx context.Context, repositoryID RepositoryID, r
 	return reference.CommitID(), nil
 }
 
-func (g *Graveler) Log(ctx context.Context, repositoryID RepositoryID, commitID CommitID) (CommitIterator, error) {
-	return g.RefManager.Log(ctx, repositoryID, commitID)
+func (g *Graveler) Log(ctx context.Context, repositoryID RepositoryID, commitID CommitID, params LogParams) (CommitIterator, error) {
+	if len(params.Prefix) == 0 {
+		return g.RefManager.Log(ctx, repositoryID, commitID, params.CommitFilter)
+	}
+	repo, err := g.RefManager.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	it, err := g.RefManager.Log(ctx, repositoryID, commitID, params.CommitFilter)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrefixCommitIterator(ctx, it, g.RefManager, g.CommittedManager, repositoryID, repo.StorageNamespace, params.Prefix), nil
+}
+
+func (g *Graveler) ListCommits(ctx context.Context, repositoryID RepositoryID) (CommitIterator, error) {
+	_, err := g.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	return g.RefManager.ListCommits(ctx, repositoryID)
 }
 
 func (g *Graveler) ListBranches(ctx context.Context, repositoryID RepositoryID) (BranchIterator, error) {
@@ -834,6 +1268,14 @@ func (g *Graveler) ListBranches(ctx context.Context, repositoryID RepositoryID)
 }
 
 func (g *Graveler) DeleteBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID) error {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrWriteToProtectedBranch); err != nil {
+		return err
+	}
+	return g.deleteBranch(ctx, repositoryID, branchID)
+}
+
+// deleteBranch deletes the branch along with its staging area
+func (g *Graveler) deleteBranch(ctx context.Context, repositoryID RepositoryID, branchID BranchID) error {
 	_, err := g.branchLocker.MetadataUpdater(ctx, repositoryID, branchID, func() (interface{}, error) {
 		branch, err := g.RefManager.GetBranch(ctx, repositoryID, branchID)
 		if err != nil {
@@ -885,10 +1327,20 @@ func (g *Graveler) Get(ctx context.Context, repositoryID RepositoryID, ref Ref,
 	if err != nil {
 		return nil, err
 	}
-	return g.CommittedManager.Get(ctx, repo.StorageNamespace, commit.MetaRangeID, key)
+	value, err := g.CommittedManager.Get(ctx, repo.StorageNamespace, commit.MetaRangeID, key)
+	if err != nil {
+		return nil, err
+	}
+	if commit.Expired {
+		return value, ErrCommitExpired
+	}
+	return value, nil
 }
 
 func (g *Graveler) Set(ctx context.Context, repositoryID RepositoryID, branchID BranchID, key Key, value Value, writeConditions ...WriteConditionOption) error {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrWriteToProtectedBranch); err != nil {
+		return err
+	}
 	_, err := g.branchLocker.Writer(ctx, repositoryID, branchID, func() (interface{}, error) {
 		branch, err := g.GetBranch(ctx, repositoryID, branchID)
 		if err != nil {
@@ -899,6 +1351,7 @@ func (g *Graveler) Set(ctx context.Context, repositoryID RepositoryID, branchID
 			cond(writeCondition)
 		}
 
+		stagingCondition := WriteCondition{IfAbsent: writeCondition.IfAbsent}
 		if writeCondition.IfAbsent {
 			// Ensure the given key doesn't exist in the underlying commit first
 			// Since we're being protected by the branch locker, we're guaranteed the commit
@@ -913,12 +1366,49 @@ func (g *Graveler) Set(ctx context.Context, repositoryID RepositoryID, branchID
 				return nil, err
 			}
 		}
-		err = g.StagingManager.Set(ctx, branch.StagingToken, key, &value, !writeCondition.IfAbsent)
+		if writeCondition.IfMatch != nil {
+			stagingCondition, err = g.ifMatchStagingCondition(ctx, repositoryID, branch, key, writeCondition.IfMatch)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = g.StagingManager.Set(ctx, branch.StagingToken, key, &value, stagingCondition)
 		return nil, err
 	})
 	return err
 }
 
+// ifMatchStagingCondition verifies the current value of key on branch has identity and returns the
+// condition under which staging applies the write atomically: a staged value must still have identity,
+// a committed value must still not be shadowed by a staged one (the commit cannot change under the
+// branch writer lock).
+func (g *Graveler) ifMatchStagingCondition(ctx context.Context, repositoryID RepositoryID, branch *Branch, key Key, identity []byte) (WriteCondition, error) {
+	staged, err := g.StagingManager.Get(ctx, branch.StagingToken, key)
+	switch {
+	case err == nil:
+		if staged == nil || !bytes.Equal(staged.Identity, identity) {
+			return WriteCondition{}, ErrPreconditionFailed
+		}
+		return WriteCondition{IfMatch: identity}, nil
+	case !errors.Is(err, ErrNotFound):
+		return WriteCondition{}, err
+	}
+	if branch.CommitID == "" {
+		return WriteCondition{}, ErrPreconditionFailed
+	}
+	committed, err := g.Get(ctx, repositoryID, Ref(branch.CommitID), key)
+	if errors.Is(err, ErrNotFound) {
+		return WriteCondition{}, ErrPreconditionFailed
+	}
+	if err != nil {
+		return WriteCondition{}, err
+	}
+	if !bytes.Equal(committed.Identity, identity) {
+		return WriteCondition{}, ErrPreconditionFailed
+	}
+	return WriteCondition{IfAbsent: true}, nil
+}
+
 // checkStaged returns true if key is staged on manager at token.  It treats staging manager
 // errors by returning "not a tombstone", and is unsafe to use if that matters!
 func isStagedTombstone(ctx context.Context, manager StagingManager, token StagingToken, key Key) bool {
@@ -930,6 +1420,9 @@ func isStagedTombstone(ctx context.Context, manager StagingManager, token Stagin
 }
 
 func (g *Graveler) Delete(ctx context.Context, repositoryID RepositoryID, branchID BranchID, key Key) error {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrWriteToProtectedBranch); err != nil {
+		return err
+	}
 	_, err := g.branchLocker.Writer(ctx, repositoryID, branchID, func() (interface{}, error) {
 		repo, err := g.RefManager.GetRepository(ctx, repositoryID)
 		if err != nil {
@@ -971,7 +1464,51 @@ func (g *Graveler) Delete(ctx context.Context, repositoryID RepositoryID, branch
 			return nil, ErrNotFound
 		}
 
-		return nil, g.StagingManager.Set(ctx, branch.StagingToken, key, nil, true)
+		return nil, g.StagingManager.Set(ctx, branch.StagingToken, key, nil, WriteCondition{})
+	})
+	return err
+}
+
+func (g *Graveler) Apply(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ops []StagingOperation) error {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrWriteToProtectedBranch); err != nil {
+		return err
+	}
+	_, err := g.branchLocker.Writer(ctx, repositoryID, branchID, func() (interface{}, error) {
+		repo, err := g.RefManager.GetRepository(ctx, repositoryID)
+		if err != nil {
+			return nil, err
+		}
+		branch, err := g.GetBranch(ctx, repositoryID, branchID)
+		if err != nil {
+			return nil, err
+		}
+		var commit *Commit
+		if branch.CommitID != "" {
+			commit, err = g.RefManager.GetCommit(ctx, repositoryID, branch.CommitID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// deleted keys need a tombstone only if they are committed, otherwise they are dropped from staging
+		stagingOps := make([]StagingOperation, len(ops))
+		for i, op := range ops {
+			stagingOps[i] = StagingOperation{Key: op.Key, Value: op.Value}
+			if op.Value != nil {
+				continue
+			}
+			if commit == nil {
+				stagingOps[i].Drop = true
+				continue
+			}
+			_, err := g.CommittedManager.Get(ctx, repo.StorageNamespace, commit.MetaRangeID, op.Key)
+			if errors.Is(err, ErrNotFound) {
+				stagingOps[i].Drop = true
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		return nil, g.StagingManager.Apply(ctx, branch.StagingToken, stagingOps)
 	})
 	return err
 }
@@ -1010,6 +1547,9 @@ func (g *Graveler) List(ctx context.Context, repositoryID RepositoryID, ref Ref)
 }
 
 func (g *Graveler) Commit(ctx context.Context, repositoryID RepositoryID, branchID BranchID, params CommitParams) (CommitID, error) {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrCommitToProtectedBranch); err != nil {
+		return "", err
+	}
 	var preRunID string
 	var commit Commit
 	var storageNamespace StorageNamespace
@@ -1024,6 +1564,9 @@ func (g *Graveler) Commit(ctx context.Context, repositoryID RepositoryID, branch
 		if err != nil {
 			return "", fmt.Errorf("get branch: %w", err)
 		}
+		if err := checkExpectedHead(branchID, branch, params.ExpectedHead); err != nil {
+			return "", err
+		}
 
 		// fill commit information - use for pre-commit and after adding the commit information used by commit
 		commit = NewCommit()
@@ -1157,6 +1700,9 @@ func (g *Graveler) isCommitExist(ctx context.Context, repositoryID RepositoryID,
 }
 
 func (g *Graveler) AddCommitToBranchHead(ctx context.Context, repositoryID RepositoryID, branchID BranchID, commit Commit) (CommitID, error) {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrCommitToProtectedBranch); err != nil {
+		return "", err
+	}
 	res, err := g.branchLocker.MetadataUpdater(ctx, repositoryID, branchID, func() (interface{}, error) {
 		// parentCommitID should always match the HEAD of the branch.
 		// Empty parentCommitID matches first commit of the branch.
@@ -1185,7 +1731,7 @@ func (g *Graveler) AddCommitToBranchHead(ctx context.Context, repositoryID Repos
 		if err != nil {
 			return nil, fmt.Errorf("adding commit: %w", err)
 		}
-		_, err = g.updateBranchNoLock(ctx, repositoryID, branchID, Ref(commitID))
+		_, err = g.updateBranchNoLock(ctx, repositoryID, branchID, Ref(commitID), "")
 		if err != nil {
 			return nil, err
 		}
@@ -1307,6 +1853,9 @@ type CommitIDAndSummary struct {
 // That is, try to apply the diff from C2 to C1 on the tip of the branch.
 // If the commit is a merge commit, 'parentNumber' is the parent number (1-based) relative to which the revert is done.
 func (g *Graveler) Revert(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref, parentNumber int, commitParams CommitParams) (CommitID, DiffSummary, error) {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrCommitToProtectedBranch); err != nil {
+		return "", DiffSummary{}, err
+	}
 	commitRecord, err := g.getCommitRecordFromRef(ctx, repositoryID, ref)
 	if err != nil {
 		return "", DiffSummary{}, fmt.Errorf("get commit from ref %s: %w", ref, err)
@@ -1349,7 +1898,7 @@ func (g *Graveler) Revert(ctx context.Context, repositoryID RepositoryID, branch
 			return "", fmt.Errorf("get commit from ref %s: %w", branch.CommitID, err)
 		}
 		// merge from the parent to the top of the branch, with the given ref as the merge base:
-		metaRangeID, summary, err := g.CommittedManager.Merge(ctx, repo.StorageNamespace, branchCommit.MetaRangeID, parentMetaRangeID, commitRecord.MetaRangeID)
+		metaRangeID, summary, err := g.CommittedManager.Merge(ctx, repo.StorageNamespace, branchCommit.MetaRangeID, parentMetaRangeID, commitRecord.MetaRangeID, MergeStrategyNone)
 		if err != nil {
 			if !errors.Is(err, ErrUserVisible) {
 				err = fmt.Errorf("merge: %w", err)
@@ -1383,50 +1932,442 @@ func (g *Graveler) Revert(ctx context.Context, repositoryID RepositoryID, branch
 	return c.ID, c.Summary, nil
 }
 
-func (g *Graveler) Merge(ctx context.Context, repositoryID RepositoryID, destination BranchID, source Ref, commitParams CommitParams) (CommitID, DiffSummary, error) {
-	var preRunID string
-	var storageNamespace StorageNamespace
-	var commit Commit
-	res, err := g.branchLocker.MetadataUpdater(ctx, repositoryID, destination, func() (interface{}, error) {
-		repo, err := g.RefManager.GetRepository(ctx, repositoryID)
-		if err != nil {
-			return "", err
+// CherryPick applies the changes introduced by the commit given as 'ref' on top of the given branch, and records them as a new commit.
+// This is implemented by merging 'ref' into the branch, with the parent of 'ref' as the merge base.
+// Example: consider the following tree: C1 -> C2 -> C3 on branch 'dev', and C4 on branch 'main'.
+// To cherry-pick C2 onto 'main', we merge C2 into 'main', with C1 as the merge base.
+// That is, try to apply the diff from C1 to C2 on the tip of the branch. Conflicting changes fail with ErrConflictFound, as in Merge.
+// If the commit is a merge commit, 'parentNumber' is the parent number (1-based) relative to which the diff is taken.
+func (g *Graveler) CherryPick(ctx context.Context, repositoryID RepositoryID, branchID BranchID, ref Ref, parentNumber int, commitParams CommitParams) (CommitID, DiffSummary, error) {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrCommitToProtectedBranch); err != nil {
+		return "", DiffSummary{}, err
+	}
+	commitRecord, err := g.getCommitRecordFromRef(ctx, repositoryID, ref)
+	if err != nil {
+		return "", DiffSummary{}, fmt.Errorf("get commit from ref %s: %w", ref, err)
+	}
+	if len(commitRecord.Parents) > 1 && parentNumber <= 0 {
+		// if commit has more than one parent, must explicitly specify parent number
+		return "", DiffSummary{}, ErrCherryPickMergeNoParent
+	}
+	if parentNumber > 0 {
+		// validate parent is in range:
+		if parentNumber > len(commitRecord.Parents) { // parent number is 1-based
+			return "", DiffSummary{}, fmt.Errorf("%w: parent %d", ErrCherryPickParentOutOfRange, parentNumber)
 		}
-		storageNamespace = repo.StorageNamespace
-
-		branch, err := g.GetBranch(ctx, repositoryID, destination)
+		parentNumber--
+	}
+	res, err := g.branchLocker.MetadataUpdater(ctx, repositoryID, branchID, func() (interface{}, error) {
+		repo, err := g.RefManager.GetRepository(ctx, repositoryID)
 		if err != nil {
-			return "", fmt.Errorf("get branch: %w", err)
+			return nil, fmt.Errorf("get repo %s: %w", repositoryID, err)
 		}
-		empty, err := g.stagingEmpty(ctx, branch)
+		branch, err := g.RefManager.GetBranch(ctx, repositoryID, branchID)
 		if err != nil {
-			return "", fmt.Errorf("check if staging empty: %w", err)
+			return "", fmt.Errorf("get branch %s: %w", branchID, err)
 		}
-		if !empty {
+		if empty, err := g.stagingEmpty(ctx, branch); err != nil {
+			return "", err
+		} else if !empty {
 			return "", ErrDirtyBranch
 		}
-		fromCommit, toCommit, baseCommit, err := g.getCommitsForMerge(ctx, repositoryID, source, Ref(destination))
+		var parentMetaRangeID MetaRangeID
+		if len(commitRecord.Parents) > 0 {
+			parentCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, commitRecord.Parents[parentNumber].Ref())
+			if err != nil {
+				return "", fmt.Errorf("get commit from ref %s: %w", commitRecord.Parents[parentNumber], err)
+			}
+			parentMetaRangeID = parentCommit.MetaRangeID
+		}
+		branchCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, branch.CommitID.Ref())
 		if err != nil {
-			return "", err
+			return "", fmt.Errorf("get commit from ref %s: %w", branch.CommitID, err)
 		}
-		metaRangeID, summary, err := g.CommittedManager.Merge(ctx, storageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, baseCommit.MetaRangeID)
+		// merge from the given ref to the top of the branch, with its parent as the merge base:
+		metaRangeID, summary, err := g.CommittedManager.Merge(ctx, repo.StorageNamespace, branchCommit.MetaRangeID, commitRecord.MetaRangeID, parentMetaRangeID, MergeStrategyNone)
 		if err != nil {
 			if !errors.Is(err, ErrUserVisible) {
-				err = fmt.Errorf("merge in CommitManager: %w", err)
+				err = fmt.Errorf("merge: %w", err)
 			}
 			return "", err
 		}
-		commit = NewCommit()
+		commit := NewCommit()
 		commit.Committer = commitParams.Committer
 		commit.Message = commitParams.Message
 		commit.MetaRangeID = metaRangeID
-		commit.Parents = []CommitID{toCommit.CommitID, fromCommit.CommitID}
-		if toCommit.Generation > fromCommit.Generation {
-			commit.Generation = toCommit.Generation + 1
-		} else {
-			commit.Generation = fromCommit.Generation + 1
-		}
+		commit.Parents = []CommitID{branch.CommitID}
 		commit.Metadata = commitParams.Metadata
+		commit.Generation = branchCommit.Generation + 1
+		commitID, err := g.RefManager.AddCommit(ctx, repositoryID, commit)
+		if err != nil {
+			return "", fmt.Errorf("add commit: %w", err)
+		}
+		err = g.RefManager.SetBranch(ctx, repositoryID, branchID, Branch{
+			CommitID:     commitID,
+			StagingToken: branch.StagingToken,
+		})
+		if err != nil {
+			return "", fmt.Errorf("set branch: %w", err)
+		}
+		return &CommitIDAndSummary{commitID, summary}, nil
+	})
+	if err != nil {
+		return "", DiffSummary{}, err
+	}
+	c := res.(*CommitIDAndSummary)
+	return c.ID, c.Summary, nil
+}
+
+func (g *Graveler) Rebase(ctx context.Context, repositoryID RepositoryID, branchID BranchID, onto Ref) (CommitID, error) {
+	if err := g.checkBranchNotProtected(ctx, repositoryID, branchID, ErrCommitToProtectedBranch); err != nil {
+		return "", err
+	}
+	res, err := g.branchLocker.MetadataUpdater(ctx, repositoryID, branchID, func() (interface{}, error) {
+		repo, err := g.RefManager.GetRepository(ctx, repositoryID)
+		if err != nil {
+			return nil, fmt.Errorf("get repo %s: %w", repositoryID, err)
+		}
+		branch, err := g.RefManager.GetBranch(ctx, repositoryID, branchID)
+		if err != nil {
+			return nil, fmt.Errorf("get branch %s: %w", branchID, err)
+		}
+		if empty, err := g.stagingEmpty(ctx, branch); err != nil {
+			return nil, err
+		} else if !empty {
+			return nil, ErrDirtyBranch
+		}
+		branchCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, branch.CommitID.Ref())
+		if err != nil {
+			return nil, fmt.Errorf("get commit from ref %s: %w", branch.CommitID, err)
+		}
+		ontoCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, onto)
+		if err != nil {
+			return nil, fmt.Errorf("get commit from ref %s: %w", onto, err)
+		}
+		baseCommit, err := g.RefManager.FindMergeBase(ctx, repositoryID, branchCommit.CommitID, ontoCommit.CommitID)
+		if err != nil {
+			return nil, fmt.Errorf("find merge base: %w", err)
+		}
+		if baseCommit == nil {
+			return nil, ErrNoMergeBase
+		}
+		baseCommitRecord := &CommitRecord{
+			CommitID: CommitID(ident.NewHexAddressProvider().ContentAddress(baseCommit)),
+			Commit:   baseCommit,
+		}
+		commits, err := g.commitsToRebase(ctx, repositoryID, branchCommit, baseCommitRecord)
+		if err != nil {
+			return nil, err
+		}
+		head := ontoCommit
+		for _, commitRecord := range commits {
+			head, err = g.replayCommit(ctx, repositoryID, repo.StorageNamespace, head, commitRecord)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = g.RefManager.SetBranch(ctx, repositoryID, branchID, Branch{
+			CommitID:     head.CommitID,
+			StagingToken: branch.StagingToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("set branch: %w", err)
+		}
+		return head.CommitID, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.(CommitID), nil
+}
+
+// replayCommit applies the changes introduced by commitRecord relative to its parent on top of head, and
+// returns the new commit, which keeps the committer, message and metadata of commitRecord.  If head already
+// holds these changes it returns head, dropping commitRecord as git rebase drops already applied patches.
+func (g *Graveler) replayCommit(ctx context.Context, repositoryID RepositoryID, storageNamespace StorageNamespace, head, commitRecord *CommitRecord) (*CommitRecord, error) {
+	var parentMetaRangeID MetaRangeID
+	if len(commitRecord.Parents) > 0 {
+		parentCommit, err := g.RefManager.GetCommit(ctx, repositoryID, commitRecord.Parents[0])
+		if err != nil {
+			return nil, fmt.Errorf("get commit %s: %w", commitRecord.Parents[0], err)
+		}
+		parentMetaRangeID = parentCommit.MetaRangeID
+	}
+	metaRangeID, _, err := g.CommittedManager.Merge(ctx, storageNamespace, head.MetaRangeID, commitRecord.MetaRangeID, parentMetaRangeID, MergeStrategyNone)
+	if errors.Is(err, ErrNoChanges) {
+		return head, nil
+	}
+	if errors.Is(err, ErrConflictFound) {
+		return nil, &RebaseConflictError{CommitID: commitRecord.CommitID, Message: commitRecord.Message, Err: err}
+	}
+	if err != nil {
+		if !errors.Is(err, ErrUserVisible) {
+			err = fmt.Errorf("replay commit %s: %w", commitRecord.CommitID, err)
+		}
+		return nil, err
+	}
+	commit := NewCommit()
+	commit.Committer = commitRecord.Committer
+	commit.Message = commitRecord.Message
+	commit.Metadata = commitRecord.Metadata
+	commit.MetaRangeID = metaRangeID
+	commit.Parents = []CommitID{head.CommitID}
+	commit.Generation = head.Generation + 1
+	commitID, err := g.RefManager.AddCommit(ctx, repositoryID, commit)
+	if err != nil {
+		return nil, fmt.Errorf("add commit: %w", err)
+	}
+	return &CommitRecord{CommitID: commitID, Commit: &commit}, nil
+}
+
+// uniqueCommits returns the commits reachable from head but not from other, by descending generation.
+// The reachability of each commit is known once it is visited, as its descendants have higher generations.
+// The walk stops once all the remaining commits are reachable from other.
+func (g *Graveler) uniqueCommits(ctx context.Context, repositoryID RepositoryID, head, other *CommitRecord) ([]*CommitRecord, error) {
+	const (
+		fromHead = 1 << iota
+		fromOther
+	)
+	reached := map[CommitID]int{head.CommitID: fromHead}
+	reached[other.CommitID] |= fromOther
+	queue := &generationQueue{head}
+	if other.CommitID != head.CommitID {
+		heap.Push(queue, other)
+	}
+	// headOnly counts the queued commits not reachable from other
+	headOnly := 1
+	if other.CommitID == head.CommitID {
+		headOnly = 0
+	}
+	var commits []*CommitRecord
+	for headOnly > 0 {
+		commitRecord := heap.Pop(queue).(*CommitRecord)
+		flags := reached[commitRecord.CommitID]
+		if flags == fromHead {
+			commits = append(commits, commitRecord)
+			headOnly--
+		}
+		for _, parent := range commitRecord.Parents {
+			parentFlags, ok := reached[parent]
+			if !ok {
+				parentCommit, err := g.RefManager.GetCommit(ctx, repositoryID, parent)
+				if err != nil {
+					return nil, fmt.Errorf("get commit %s: %w", parent, err)
+				}
+				heap.Push(queue, &CommitRecord{CommitID: parent, Commit: parentCommit})
+			}
+			reached[parent] = parentFlags | flags
+			switch {
+			case !ok && flags == fromHead:
+				headOnly++
+			case ok && parentFlags == fromHead && flags&fromOther != 0:
+				headOnly--
+			}
+		}
+	}
+	return commits, nil
+}
+
+// generationQueue is a heap of commits that pops the highest generation first
+type generationQueue []*CommitRecord
+
+func (q generationQueue) Len() int {
+	return len(q)
+}
+
+func (q generationQueue) Less(i, j int) bool {
+	return q[i].Generation > q[j].Generation
+}
+
+func (q generationQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *generationQueue) Push(x interface{}) {
+	rec := x.(*CommitRecord)
+	*q = append(*q, rec)
+}
+
+func (q *generationQueue) Pop() interface{} {
+	qq := *q
+	n := len(qq) - 1
+	item := qq[n]
+	*q = qq[:n]
+	return item
+}
+
+func (g *Graveler) AheadBehind(ctx context.Context, repositoryID RepositoryID, ref, other Ref) (int, int, error) {
+	refCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, ref)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get commit from ref %s: %w", ref, err)
+	}
+	otherCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, other)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get commit from ref %s: %w", other, err)
+	}
+	if refCommit.CommitID == otherCommit.CommitID {
+		return 0, 0, nil
+	}
+	baseCommit, err := g.RefManager.FindMergeBase(ctx, repositoryID, refCommit.CommitID, otherCommit.CommitID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("find merge base: %w", err)
+	}
+	var baseCommitID CommitID
+	if baseCommit != nil {
+		baseCommitID = CommitID(ident.NewHexAddressProvider().ContentAddress(baseCommit))
+	}
+	// a side that is the merge base has no commits the other side lacks, so skip walking it
+	var ahead, behind []*CommitRecord
+	if baseCommitID != refCommit.CommitID {
+		ahead, err = g.uniqueCommits(ctx, repositoryID, refCommit, otherCommit)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if baseCommitID != otherCommit.CommitID {
+		behind, err = g.uniqueCommits(ctx, repositoryID, otherCommit, refCommit)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return len(ahead), len(behind), nil
+}
+
+// commitsToRebase returns the non-merge commits reachable from head but not from base, the merge base of head
+// and the rebase target, oldest first
+func (g *Graveler) commitsToRebase(ctx context.Context, repositoryID RepositoryID, head, base *CommitRecord) ([]*CommitRecord, error) {
+	unique, err := g.uniqueCommits(ctx, repositoryID, head, base)
+	if err != nil {
+		return nil, err
+	}
+	var commits []*CommitRecord
+	for i := len(unique) - 1; i >= 0; i-- {
+		if len(unique[i].Parents) <= 1 {
+			commits = append(commits, unique[i])
+		}
+	}
+	return commits, nil
+}
+
+// squashedCommitsMessage returns a summary of the commits of source that are squashed when merged into destination,
+// listed in log order
+func (g *Graveler) squashedCommitsMessage(ctx context.Context, repositoryID RepositoryID, source, destination *CommitRecord) (string, error) {
+	unique, err := g.uniqueCommits(ctx, repositoryID, source, destination)
+	if err != nil {
+		return "", err
+	}
+	if len(unique) == 0 {
+		return "", nil
+	}
+	squashed := make(map[CommitID]struct{}, len(unique))
+	for _, commitRecord := range unique {
+		squashed[commitRecord.CommitID] = struct{}{}
+	}
+	it, err := g.RefManager.Log(ctx, repositoryID, source.CommitID, CommitFilter{})
+	if err != nil {
+		return "", err
+	}
+	defer it.Close()
+	var b strings.Builder
+	b.WriteString("\n\nSquashed commits:\n")
+	for len(squashed) > 0 && it.Next() {
+		commitRecord := it.Value()
+		if _, ok := squashed[commitRecord.CommitID]; !ok {
+			continue
+		}
+		delete(squashed, commitRecord.CommitID)
+		message := commitRecord.Message
+		if idx := strings.IndexByte(message, '\n'); idx >= 0 {
+			message = message[:idx]
+		}
+		fmt.Fprintf(&b, "* %s %s\n", commitRecord.CommitID, message)
+	}
+	if err := it.Err(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (g *Graveler) Merge(ctx context.Context, repositoryID RepositoryID, destination BranchID, source Ref, commitParams CommitParams, mergeParams MergeParams) (CommitID, DiffSummary, error) {
+	if mergeParams.Mode == MergeModeFFOnly && mergeParams.Squash {
+		return "", DiffSummary{}, fmt.Errorf("squash with %s: %w", mergeParams.Mode, ErrInvalidMergeMode)
+	}
+	if err := g.checkMergeIntoProtected(ctx, repositoryID, destination, source); err != nil {
+		return "", DiffSummary{}, err
+	}
+	var preRunID string
+	var storageNamespace StorageNamespace
+	var commit Commit
+	res, err := g.branchLocker.MetadataUpdater(ctx, repositoryID, destination, func() (interface{}, error) {
+		repo, err := g.RefManager.GetRepository(ctx, repositoryID)
+		if err != nil {
+			return "", err
+		}
+		storageNamespace = repo.StorageNamespace
+
+		branch, err := g.GetBranch(ctx, repositoryID, destination)
+		if err != nil {
+			return "", fmt.Errorf("get branch: %w", err)
+		}
+		if err := checkExpectedHead(destination, branch, mergeParams.ExpectedHead); err != nil {
+			return "", err
+		}
+		empty, err := g.stagingEmpty(ctx, branch)
+		if err != nil {
+			return "", fmt.Errorf("check if staging empty: %w", err)
+		}
+		if !empty {
+			return "", ErrDirtyBranch
+		}
+		fromCommit, toCommit, baseCommit, err := g.getCommitsForMerge(ctx, repositoryID, source, Ref(destination))
+		if err != nil {
+			return "", err
+		}
+		var summary DiffSummary
+		if mergeParams.Mode == MergeModeFFOnly {
+			// fast-forward is possible only when the destination is the merge base
+			if CommitID(ident.NewHexAddressProvider().ContentAddress(baseCommit)) != toCommit.CommitID {
+				return "", ErrNotFastForward
+			}
+			summary, err = g.CommittedManager.DiffSummary(ctx, storageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, nil)
+			if err != nil {
+				return "", err
+			}
+			commit = *fromCommit.Commit
+		} else {
+			var metaRangeID MetaRangeID
+			metaRangeID, summary, err = g.CommittedManager.Merge(ctx, storageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, baseCommit.MetaRangeID, mergeParams.Strategy)
+			if errors.Is(err, ErrConflictFound) {
+				return "", &MergeConflictError{Source: fromCommit.CommitID, Destination: toCommit.CommitID, Err: err}
+			}
+			if err != nil {
+				if !errors.Is(err, ErrUserVisible) {
+					err = fmt.Errorf("merge in CommitManager: %w", err)
+				}
+				return "", err
+			}
+			commit = NewCommit()
+			commit.Committer = commitParams.Committer
+			commit.Message = commitParams.Message
+			commit.MetaRangeID = metaRangeID
+			if mergeParams.Squash {
+				squashed, err := g.squashedCommitsMessage(ctx, repositoryID, fromCommit, toCommit)
+				if err != nil {
+					return "", err
+				}
+				commit.Message += squashed
+				commit.Parents = []CommitID{toCommit.CommitID}
+				commit.Generation = toCommit.Generation + 1
+			} else {
+				commit.Parents = []CommitID{toCommit.CommitID, fromCommit.CommitID}
+				if toCommit.Generation > fromCommit.Generation {
+					commit.Generation = toCommit.Generation + 1
+				} else {
+					commit.Generation = fromCommit.Generation + 1
+				}
+			}
+			commit.Metadata = commitParams.Metadata
+		}
 		preRunID = NewRunID()
 		err = g.hooks.PreMergeHook(ctx, HookRecord{
 			EventType:        EventTypePreMerge,
@@ -1444,9 +2385,12 @@ func (g *Graveler) Merge(ctx context.Context, repositoryID RepositoryID, destina
 				Err:       err,
 			}
 		}
-		commitID, err := g.RefManager.AddCommit(ctx, repositoryID, commit)
-		if err != nil {
-			return "", fmt.Errorf("add commit: %w", err)
+		commitID := fromCommit.CommitID
+		if mergeParams.Mode != MergeModeFFOnly {
+			commitID, err = g.RefManager.AddCommit(ctx, repositoryID, commit)
+			if err != nil {
+				return "", fmt.Errorf("add commit: %w", err)
+			}
 		}
 		branch.CommitID = commitID
 		err = g.RefManager.SetBranch(ctx, repositoryID, destination, *branch)
@@ -1528,7 +2472,7 @@ func (g *Graveler) getCommitRecordFromRef(ctx context.Context, repositoryID Repo
 	}, nil
 }
 
-func (g *Graveler) Diff(ctx context.Context, repositoryID RepositoryID, left, right Ref) (DiffIterator, error) {
+func (g *Graveler) Diff(ctx context.Context, repositoryID RepositoryID, left, right Ref, params DiffParams) (DiffIterator, error) {
 	repo, err := g.RefManager.GetRepository(ctx, repositoryID)
 	if err != nil {
 		return nil, err
@@ -1542,10 +2486,162 @@ func (g *Graveler) Diff(ctx context.Context, repositoryID RepositoryID, left, ri
 		return nil, err
 	}
 
-	return g.CommittedManager.Diff(ctx, repo.StorageNamespace, leftCommit.MetaRangeID, rightCommit.MetaRangeID)
+	return g.CommittedManager.Diff(ctx, repo.StorageNamespace, leftCommit.MetaRangeID, rightCommit.MetaRangeID, params)
 }
 
-func (g *Graveler) Compare(ctx context.Context, repositoryID RepositoryID, from, to Ref) (DiffIterator, error) {
+func (g *Graveler) DiffSummary(ctx context.Context, repositoryID RepositoryID, left, right Ref, prefix Key) (DiffSummary, error) {
+	repo, err := g.RefManager.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return DiffSummary{}, err
+	}
+	leftCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, left)
+	if err != nil {
+		return DiffSummary{}, err
+	}
+	rightCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, right)
+	if err != nil {
+		return DiffSummary{}, err
+	}
+	return g.CommittedManager.DiffSummary(ctx, repo.StorageNamespace, leftCommit.MetaRangeID, rightCommit.MetaRangeID, prefix)
+}
+
+func (g *Graveler) Blame(ctx context.Context, repositoryID RepositoryID, ref Ref, prefix, after Key, amount int) ([]BlameRecord, error) {
+	repo, err := g.RefManager.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	reference, err := g.RefManager.RevParse(ctx, repositoryID, ref)
+	if err != nil {
+		return nil, err
+	}
+	commitID := reference.CommitID()
+	if commitID == "" {
+		// nothing committed yet
+		return nil, nil
+	}
+	commit, err := g.RefManager.GetCommit(ctx, repositoryID, commitID)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the keys to blame together with their identity on ref
+	it, err := g.CommittedManager.List(ctx, repo.StorageNamespace, commit.MetaRangeID)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	from := prefix
+	if bytes.Compare(after, prefix) > 0 {
+		from = after
+	}
+	it.SeekGE(from)
+	var records []BlameRecord
+	identities := make(map[string][]byte)
+	for len(records) < amount && it.Next() {
+		value := it.Value()
+		if !bytes.HasPrefix(value.Key, prefix) {
+			break
+		}
+		if bytes.Equal(value.Key, after) {
+			continue
+		}
+		records = append(records, BlameRecord{Key: value.Key.Copy()})
+		identities[string(value.Key)] = value.Identity
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return records, nil
+	}
+
+	// walk the log from ref, resolving keys to the first commit that introduces their identity
+	logIt, err := g.RefManager.Log(ctx, repositoryID, commitID, CommitFilter{})
+	if err != nil {
+		return nil, err
+	}
+	defer logIt.Close()
+	resolved := make(map[string]*CommitRecord)
+	for len(resolved) < len(records) && logIt.Next() {
+		c := *logIt.Value()
+		if err := g.blameCommit(ctx, repositoryID, repo.StorageNamespace, &c, records, identities, resolved); err != nil {
+			return nil, err
+		}
+	}
+	if err := logIt.Err(); err != nil {
+		return nil, err
+	}
+	for i := range records {
+		records[i].Commit = resolved[string(records[i].Key)]
+	}
+	return records, nil
+}
+
+// blameCommit resolves to commit the keys of records not yet in resolved that commit changes to
+// their identity on the blamed ref.  A merge commit is not blamed for a change that it takes as
+// is from one of its other parents.
+func (g *Graveler) blameCommit(ctx context.Context, repositoryID RepositoryID, ns StorageNamespace, commit *CommitRecord, records []BlameRecord, identities map[string][]byte, resolved map[string]*CommitRecord) error {
+	// limit the diff to the range of keys still unresolved
+	var minKey, maxKey Key
+	for _, record := range records {
+		if _, ok := resolved[string(record.Key)]; ok {
+			continue
+		}
+		if minKey == nil {
+			minKey = record.Key
+		}
+		maxKey = record.Key
+	}
+	parents := make([]*Commit, 0, len(commit.Parents))
+	for _, parentID := range commit.Parents {
+		parent, err := g.RefManager.GetCommit(ctx, repositoryID, parentID)
+		if err != nil {
+			return err
+		}
+		parents = append(parents, parent)
+	}
+	var (
+		parentMetaRangeID MetaRangeID
+		otherParents      []*Commit
+	)
+	if len(parents) > 0 {
+		parentMetaRangeID = parents[0].MetaRangeID
+		otherParents = parents[1:]
+	}
+	diffIt, err := g.CommittedManager.Diff(ctx, ns, parentMetaRangeID, commit.MetaRangeID, DiffParams{})
+	if err != nil {
+		return err
+	}
+	defer diffIt.Close()
+	diffIt.SeekGE(minKey)
+	for diffIt.Next() {
+		diff := diffIt.Value()
+		if bytes.Compare(diff.Key, maxKey) > 0 {
+			break
+		}
+		identity, ok := identities[string(diff.Key)]
+		if !ok || resolved[string(diff.Key)] != nil || diff.Type == DiffTypeRemoved || !bytes.Equal(diff.Value.Identity, identity) {
+			continue
+		}
+		fromOtherParent := false
+		for _, parent := range otherParents {
+			value, err := g.CommittedManager.Get(ctx, ns, parent.MetaRangeID, diff.Key)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			if value != nil && bytes.Equal(value.Identity, identity) {
+				fromOtherParent = true
+				break
+			}
+		}
+		if !fromOtherParent {
+			resolved[string(diff.Key)] = commit
+		}
+	}
+	return diffIt.Err()
+}
+
+func (g *Graveler) Compare(ctx context.Context, repositoryID RepositoryID, from, to Ref, params DiffParams) (DiffIterator, error) {
 	repo, err := g.RefManager.GetRepository(ctx, repositoryID)
 	if err != nil {
 		return nil, err
@@ -1554,7 +2650,24 @@ func (g *Graveler) Compare(ctx context.Context, repositoryID RepositoryID, from,
 	if err != nil {
 		return nil, err
 	}
-	return g.CommittedManager.Compare(ctx, repo.StorageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, baseCommit.MetaRangeID)
+	return g.CommittedManager.Compare(ctx, repo.StorageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, baseCommit.MetaRangeID, params)
+}
+
+func (g *Graveler) MergeConflicts(ctx context.Context, repositoryID RepositoryID, destination, source Ref) (MergeConflictIterator, error) {
+	repo, err := g.RefManager.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	fromCommit, toCommit, baseCommit, err := g.getCommitsForMerge(ctx, repositoryID, source, destination)
+	if err != nil {
+		return nil, err
+	}
+	diffIt, err := g.CommittedManager.Compare(ctx, repo.StorageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, baseCommit.MetaRangeID, DiffParams{})
+	if err != nil {
+		return nil, err
+	}
+	return NewMergeConflictIterator(ctx, diffIt, g.CommittedManager, repo.StorageNamespace,
+		fromCommit.MetaRangeID, toCommit.MetaRangeID, baseCommit.MetaRangeID), nil
 }
 
 func (g *Graveler) SetHooksHandler(handler HooksHandler) {
@@ -1565,6 +2678,121 @@ func (g *Graveler) SetHooksHandler(handler HooksHandler) {
 	}
 }
 
+func (g *Graveler) SetBranchRunChecker(checker BranchRunChecker) {
+	g.runChecker = checker
+}
+
+func (g *Graveler) GetBranchProtectionRules(ctx context.Context, repositoryID RepositoryID) ([]*BranchProtectionRule, error) {
+	rules, err := g.protectionRules.GetOrSet(repositoryID, func() (interface{}, error) {
+		return g.RefManager.GetBranchProtectionRules(ctx, repositoryID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules.([]*BranchProtectionRule), nil
+}
+
+func (g *Graveler) CreateBranchProtectionRule(ctx context.Context, repositoryID RepositoryID, rule BranchProtectionRule) error {
+	if _, err := path.Match(rule.Pattern, ""); err != nil {
+		return fmt.Errorf("branch pattern %s: %w", rule.Pattern, ErrInvalidValue)
+	}
+	defer g.protectionRules.Invalidate(repositoryID)
+	return g.RefManager.CreateBranchProtectionRule(ctx, repositoryID, rule)
+}
+
+func (g *Graveler) DeleteBranchProtectionRule(ctx context.Context, repositoryID RepositoryID, pattern string) error {
+	defer g.protectionRules.Invalidate(repositoryID)
+	return g.RefManager.DeleteBranchProtectionRule(ctx, repositoryID, pattern)
+}
+
+func (g *Graveler) GetRetentionRules(ctx context.Context, repositoryID RepositoryID) ([]*RetentionRule, error) {
+	return g.RefManager.GetRetentionRules(ctx, repositoryID)
+}
+
+func (g *Graveler) SetRetentionRules(ctx context.Context, repositoryID RepositoryID, rules []*RetentionRule) error {
+	return g.RefManager.SetRetentionRules(ctx, repositoryID, rules)
+}
+
+func (g *Graveler) SetExpiredCommits(ctx context.Context, repositoryID RepositoryID, commitIDs []CommitID) error {
+	return g.RefManager.SetExpiredCommits(ctx, repositoryID, commitIDs)
+}
+
+func (g *Graveler) AddStorageNamespaceReferences(ctx context.Context, repositoryID RepositoryID, storageNamespaces []StorageNamespace) error {
+	return g.RefManager.AddStorageNamespaceReferences(ctx, repositoryID, storageNamespaces)
+}
+
+func (g *Graveler) ListStorageNamespaceReferences(ctx context.Context, repositoryID RepositoryID) ([]StorageNamespace, error) {
+	return g.RefManager.ListStorageNamespaceReferences(ctx, repositoryID)
+}
+
+func (g *Graveler) ListReferencingRepositories(ctx context.Context, storageNamespace StorageNamespace) ([]RepositoryID, error) {
+	return g.RefManager.ListReferencingRepositories(ctx, storageNamespace)
+}
+
+// MatchBranchProtection returns the protection of branchID combined from all matching rules, or nil if it is not protected
+func MatchBranchProtection(rules []*BranchProtectionRule, branchID BranchID) *BranchProtectionRule {
+	var protection *BranchProtectionRule
+	for _, rule := range rules {
+		if matched, _ := path.Match(rule.Pattern, branchID.String()); !matched {
+			continue
+		}
+		if protection == nil {
+			protection = &BranchProtectionRule{Pattern: rule.Pattern}
+		}
+		protection.RequirePassingRun = protection.RequirePassingRun || rule.RequirePassingRun
+	}
+	return protection
+}
+
+func (g *Graveler) branchProtection(ctx context.Context, repositoryID RepositoryID, branchID BranchID) (*BranchProtectionRule, error) {
+	rules, err := g.GetBranchProtectionRules(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	return MatchBranchProtection(rules, branchID), nil
+}
+
+// checkBranchNotProtected returns protectedErr if branchID is protected
+func (g *Graveler) checkBranchNotProtected(ctx context.Context, repositoryID RepositoryID, branchID BranchID, protectedErr error) error {
+	protection, err := g.branchProtection(ctx, repositoryID, branchID)
+	if err != nil {
+		return err
+	}
+	if protection != nil {
+		return fmt.Errorf("branch %s: %w", branchID, protectedErr)
+	}
+	return nil
+}
+
+// checkMergeIntoProtected verifies source may be merged into destination, if destination requires a passing action run
+func (g *Graveler) checkMergeIntoProtected(ctx context.Context, repositoryID RepositoryID, destination BranchID, source Ref) error {
+	protection, err := g.branchProtection(ctx, repositoryID, destination)
+	if err != nil {
+		return err
+	}
+	if protection == nil || !protection.RequirePassingRun {
+		return nil
+	}
+	sourceBranch := BranchID(source)
+	if _, err := g.RefManager.GetBranch(ctx, repositoryID, sourceBranch); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("source %s is not a branch: %w", source, ErrMergeSourceRunNotPassed)
+		}
+		return err
+	}
+	passed := false
+	if g.runChecker != nil {
+		passed, err = g.runChecker.LatestRunPassed(ctx, repositoryID, sourceBranch)
+		if err != nil {
+			return err
+		}
+	}
+	if !passed {
+		return fmt.Errorf("source %s: %w", source, ErrMergeSourceRunNotPassed)
+	}
+	return nil
+}
+
 func (g *Graveler) getCommitsForMerge(ctx context.Context, repositoryID RepositoryID, from Ref, to Ref) (*CommitRecord, *CommitRecord, *Commit, error) {
 	fromCommit, err := g.getCommitRecordFromRef(ctx, repositoryID, from)
 	if err != nil {
@@ -1646,6 +2874,7 @@ func (g *Graveler) LoadBranches(ctx context.Context, repositoryID RepositoryID,
 		return err
 	}
 	defer iter.Close()
+	loaded := make(map[BranchID]struct{})
 	for iter.Next() {
 		rawValue := iter.Value()
 		branch := &BranchData{}
@@ -1654,9 +2883,18 @@ func (g *Graveler) LoadBranches(ctx context.Context, repositoryID RepositoryID,
 			return err
 		}
 		branchID := BranchID(branch.Id)
+		loaded[branchID] = struct{}{}
+		stagingToken := generateStagingToken(repositoryID, branchID)
+		existing, err := g.RefManager.GetBranch(ctx, repositoryID, branchID)
+		switch {
+		case err == nil:
+			stagingToken = existing.StagingToken
+		case !errors.Is(err, ErrBranchNotFound):
+			return err
+		}
 		err = g.RefManager.SetBranch(ctx, repositoryID, branchID, Branch{
 			CommitID:     CommitID(branch.CommitId),
-			StagingToken: generateStagingToken(repositoryID, branchID),
+			StagingToken: stagingToken,
 		})
 		if err != nil {
 			return err
@@ -1665,6 +2903,29 @@ func (g *Graveler) LoadBranches(ctx context.Context, repositoryID RepositoryID,
 	if iter.Err() != nil {
 		return iter.Err()
 	}
+	// branches missing from the dump were deleted after a previous dump was loaded, except for the
+	// default branch that the repository keeps
+	branchIt, err := g.RefManager.ListBranches(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	var deleted []BranchID
+	for branchIt.Next() {
+		branchID := branchIt.Value().BranchID
+		if _, ok := loaded[branchID]; !ok && branchID != repo.DefaultBranchID {
+			deleted = append(deleted, branchID)
+		}
+	}
+	err = branchIt.Err()
+	branchIt.Close()
+	if err != nil {
+		return err
+	}
+	for _, branchID := range deleted {
+		if err := g.deleteBranch(ctx, repositoryID, branchID); err != nil {
+			return fmt.Errorf("delete branch %s: %w", branchID, err)
+		}
+	}
 	return nil
 }
 
@@ -1678,6 +2939,7 @@ func (g *Graveler) LoadTags(ctx context.Context, repositoryID RepositoryID, meta
 		return err
 	}
 	defer iter.Close()
+	loaded := make(map[TagID]struct{})
 	for iter.Next() {
 		rawValue := iter.Value()
 		tag := &TagData{}
@@ -1686,7 +2948,29 @@ func (g *Graveler) LoadTags(ctx context.Context, repositoryID RepositoryID, meta
 			return err
 		}
 		tagID := TagID(tag.Id)
-		err = g.RefManager.CreateTag(ctx, repositoryID, tagID, CommitID(tag.CommitId))
+		loaded[tagID] = struct{}{}
+		var annotation *TagAnnotation
+		if tag.CreationDate != nil {
+			annotation = &TagAnnotation{
+				Message:      tag.Message,
+				Creator:      tag.Creator,
+				CreationDate: tag.CreationDate.AsTime(),
+				Metadata:     tag.Metadata,
+			}
+		}
+		existing, err := g.RefManager.GetTagRecord(ctx, repositoryID, tagID)
+		switch {
+		case err == nil:
+			if existing.CommitID == CommitID(tag.CommitId) {
+				continue
+			}
+			if err := g.RefManager.DeleteTag(ctx, repositoryID, tagID); err != nil {
+				return err
+			}
+		case !errors.Is(err, ErrTagNotFound):
+			return err
+		}
+		err = g.RefManager.CreateTag(ctx, repositoryID, tagID, CommitID(tag.CommitId), annotation)
 		if err != nil {
 			return err
 		}
@@ -1694,6 +2978,28 @@ func (g *Graveler) LoadTags(ctx context.Context, repositoryID RepositoryID, meta
 	if iter.Err() != nil {
 		return iter.Err()
 	}
+	// tags missing from the dump were deleted after a previous dump was loaded
+	tagIt, err := g.RefManager.ListTags(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	var deleted []TagID
+	for tagIt.Next() {
+		tagID := tagIt.Value().TagID
+		if _, ok := loaded[tagID]; !ok {
+			deleted = append(deleted, tagID)
+		}
+	}
+	err = tagIt.Err()
+	tagIt.Close()
+	if err != nil {
+		return err
+	}
+	for _, tagID := range deleted {
+		if err := g.RefManager.DeleteTag(ctx, repositoryID, tagID); err != nil {
+			return fmt.Errorf("delete tag %s: %w", tagID, err)
+		}
+	}
 	return nil
 }
 
@@ -1713,16 +3019,37 @@ func (g *Graveler) GetRange(ctx context.Context, repositoryID RepositoryID, rang
 	return g.CommittedManager.GetRange(ctx, repo.StorageNamespace, rangeID)
 }
 
-func (g *Graveler) DumpCommits(ctx context.Context, repositoryID RepositoryID) (*MetaRangeID, error) {
-	repo, err := g.GetRepository(ctx, repositoryID)
+func (g *Graveler) ListMetaRange(ctx context.Context, repositoryID RepositoryID, metaRangeID MetaRangeID) (MetaRangeIterator, error) {
+	repo, err := g.RefManager.GetRepository(ctx, repositoryID)
 	if err != nil {
 		return nil, err
 	}
+	return g.CommittedManager.ListMetaRange(ctx, repo.StorageNamespace, metaRangeID)
+}
+
+func (g *Graveler) DumpCommits(ctx context.Context, repositoryID RepositoryID) (*MetaRangeID, error) {
 	iter, err := g.RefManager.ListCommits(ctx, repositoryID)
 	if err != nil {
 		return nil, err
 	}
 	defer iter.Close()
+	return g.dumpCommits(ctx, repositoryID, iter)
+}
+
+func (g *Graveler) DumpCommitsSince(ctx context.Context, repositoryID RepositoryID, since time.Time) (*MetaRangeID, error) {
+	iter, err := g.RefManager.ListCommitsSince(ctx, repositoryID, since.Add(-DumpCommitsSinceOverlap))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	return g.dumpCommits(ctx, repositoryID, iter)
+}
+
+func (g *Graveler) dumpCommits(ctx context.Context, repositoryID RepositoryID, iter CommitIterator) (*MetaRangeID, error) {
+	repo, err := g.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
 	schema, err := serializeSchemaDefinition(&CommitData{})
 	if err != nil {
 		return nil, err
@@ -1809,10 +3136,17 @@ func (t *tagValueIterator) setValue() bool {
 		return false
 	}
 	tag := t.src.Value()
-	data, err := proto.Marshal(&TagData{
+	tagData := &TagData{
 		Id:       string(tag.TagID),
 		CommitId: string(tag.CommitID),
-	})
+	}
+	if tag.Annotation != nil {
+		tagData.Message = tag.Annotation.Message
+		tagData.Creator = tag.Annotation.Creator
+		tagData.CreationDate = timestamppb.New(tag.Annotation.CreationDate)
+		tagData.Metadata = tag.Annotation.Metadata
+	}
+	data, err := proto.Marshal(tagData)
 	if err != nil {
 		t.err = err
 		return false
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"sort"
 	"testing"
 
 	"github.com/go-test/deep"
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/ref"
 	"github.com/treeverse/lakefs/pkg/graveler/testutil"
+	"github.com/treeverse/lakefs/pkg/ident"
 	tu "github.com/treeverse/lakefs/pkg/testutil"
 )
 
@@ -149,6 +151,14 @@ func TestGraveler_Get(t *testing.T) {
 				&testutil.RefsFake{RefType: graveler.ReferenceTypeCommit, Commits: map[graveler.CommitID]*graveler.Commit{"": {}}},
 			), expectedErr: errTest,
 		},
+		{
+			name: "commit - expired",
+			r: graveler.NewGraveler(branchLocker, &testutil.CommittedFake{ValuesByKey: map[string]*graveler.Value{"key": {Identity: []byte("committed")}}}, nil,
+				&testutil.RefsFake{RefType: graveler.ReferenceTypeCommit, Commits: map[graveler.CommitID]*graveler.Commit{"": {Expired: true}}},
+			),
+			expectedValueResult: graveler.Value{Identity: []byte("committed")},
+			expectedErr:         graveler.ErrCommitExpired,
+		},
 		{
 			name: "branch - only staged",
 			r: graveler.NewGraveler(branchLocker, &testutil.CommittedFake{Err: graveler.ErrNotFound}, &testutil.StagingFake{Value: &graveler.Value{Identity: []byte("staged")}},
@@ -191,7 +201,7 @@ func TestGraveler_Get(t *testing.T) {
 			if err != tt.expectedErr {
 				t.Fatalf("wrong error, expected:%s got:%s", tt.expectedErr, err)
 			}
-			if err != nil {
+			if err != nil && err != graveler.ErrCommitExpired {
 				return // err == tt.expected error
 			}
 			if string(tt.expectedValueResult.Identity) != string(Value.Identity) {
@@ -201,6 +211,88 @@ func TestGraveler_Get(t *testing.T) {
 	}
 }
 
+func TestGraveler_SetIfMatch(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	const commitID = graveler.CommitID("c1")
+	committed := &testutil.CommittedFake{ValuesByKey: map[string]*graveler.Value{"key": {Identity: []byte("committed")}}}
+	tests := []struct {
+		name              string
+		committedManager  *testutil.CommittedFake
+		stagingManager    *testutil.StagingFake
+		ifMatch           string
+		expectedErr       error
+		expectedCondition graveler.WriteCondition
+	}{
+		{
+			name:              "staged match",
+			committedManager:  committed,
+			stagingManager:    &testutil.StagingFake{Value: &graveler.Value{Identity: []byte("staged")}},
+			ifMatch:           "staged",
+			expectedCondition: graveler.WriteCondition{IfMatch: []byte("staged")},
+		},
+		{
+			name:             "staged mismatch",
+			committedManager: committed,
+			stagingManager:   &testutil.StagingFake{Value: &graveler.Value{Identity: []byte("staged")}},
+			ifMatch:          "committed",
+			expectedErr:      graveler.ErrPreconditionFailed,
+		},
+		{
+			name:             "staged tombstone",
+			committedManager: committed,
+			stagingManager:   &testutil.StagingFake{Value: nil},
+			ifMatch:          "committed",
+			expectedErr:      graveler.ErrPreconditionFailed,
+		},
+		{
+			name:              "committed match",
+			committedManager:  committed,
+			stagingManager:    &testutil.StagingFake{Err: graveler.ErrNotFound},
+			ifMatch:           "committed",
+			expectedCondition: graveler.WriteCondition{IfAbsent: true},
+		},
+		{
+			name:             "committed mismatch",
+			committedManager: committed,
+			stagingManager:   &testutil.StagingFake{Err: graveler.ErrNotFound},
+			ifMatch:          "other",
+			expectedErr:      graveler.ErrPreconditionFailed,
+		},
+		{
+			name:             "not found",
+			committedManager: &testutil.CommittedFake{Err: graveler.ErrNotFound},
+			stagingManager:   &testutil.StagingFake{Err: graveler.ErrNotFound},
+			ifMatch:          "committed",
+			expectedErr:      graveler.ErrPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refManager := &testutil.RefsFake{
+				RefType:  graveler.ReferenceTypeCommit,
+				CommitID: commitID,
+				Branch:   &graveler.Branch{CommitID: commitID},
+				Commits:  map[graveler.CommitID]*graveler.Commit{commitID: {}},
+			}
+			g := graveler.NewGraveler(branchLocker, tt.committedManager, tt.stagingManager, refManager)
+			err := g.Set(context.Background(), "repo", "branch", graveler.Key("key"), graveler.Value{Identity: []byte("new")}, graveler.IfMatch([]byte(tt.ifMatch)))
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Set err=%v, expected=%v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				if tt.stagingManager.LastSetValueRecord != nil {
+					t.Errorf("Set staged %+v on failed condition", tt.stagingManager.LastSetValueRecord)
+				}
+				return
+			}
+			if diff := deep.Equal(tt.stagingManager.LastSetCondition, tt.expectedCondition); diff != nil {
+				t.Errorf("Set staging condition diff: %s", diff)
+			}
+		})
+	}
+}
+
 func TestGraveler_DiffUncommitted(t *testing.T) {
 	conn, _ := tu.GetDB(t, databaseURI)
 	branchLocker := ref.NewBranchLocker(conn)
@@ -322,7 +414,7 @@ func TestGraveler_UpdateBranch(t *testing.T) {
 		&testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake([]graveler.ValueRecord{{Key: graveler.Key("foo/one"), Value: &graveler.Value{}}})},
 		&testutil.RefsFake{Branch: &graveler.Branch{}},
 	)
-	_, err := gravel.UpdateBranch(context.Background(), "", "", "")
+	_, err := gravel.UpdateBranch(context.Background(), "", "", "", "")
 	if !errors.Is(err, graveler.ErrConflictFound) {
 		t.Fatal("expected update to fail on conflict")
 	}
@@ -330,10 +422,14 @@ func TestGraveler_UpdateBranch(t *testing.T) {
 		&testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake([]graveler.ValueRecord{})},
 		&testutil.RefsFake{Branch: &graveler.Branch{}},
 	)
-	_, err = gravel.UpdateBranch(context.Background(), "", "", "")
+	_, err = gravel.UpdateBranch(context.Background(), "", "", "", "")
 	if err != nil {
 		t.Fatal("did not expect to get error")
 	}
+	_, err = gravel.UpdateBranch(context.Background(), "", "", "", "movedCommitID")
+	if !errors.Is(err, graveler.ErrBranchHeadMismatch) {
+		t.Fatalf("expected update to fail on head mismatch, got %v", err)
+	}
 }
 
 func TestGraveler_Commit(t *testing.T) {
@@ -354,6 +450,7 @@ func TestGraveler_Commit(t *testing.T) {
 		committer    string
 		message      string
 		metadata     graveler.Metadata
+		expectedHead graveler.CommitID
 	}
 	tests := []struct {
 		name        string
@@ -382,6 +479,48 @@ func TestGraveler_Commit(t *testing.T) {
 			want:        expectedCommitID,
 			expectedErr: nil,
 		},
+		{
+			name: "valid commit with expected head",
+			fields: fields{
+				CommittedManager: &testutil.CommittedFake{MetaRangeID: expectedRangeID},
+				StagingManager:   &testutil.StagingFake{ValueIterator: values},
+				RefManager: &testutil.RefsFake{CommitID: expectedCommitID,
+					Branch:  &graveler.Branch{CommitID: expectedCommitID},
+					Commits: map[graveler.CommitID]*graveler.Commit{expectedCommitID: {MetaRangeID: expectedRangeID}}},
+			},
+			args: args{
+				ctx:          nil,
+				repositoryID: "repo",
+				branchID:     "branch",
+				committer:    "committer",
+				message:      "a message",
+				metadata:     graveler.Metadata{},
+				expectedHead: expectedCommitID,
+			},
+			want:        expectedCommitID,
+			expectedErr: nil,
+		},
+		{
+			name: "fail on branch head mismatch",
+			fields: fields{
+				CommittedManager: &testutil.CommittedFake{MetaRangeID: expectedRangeID},
+				StagingManager:   &testutil.StagingFake{ValueIterator: values},
+				RefManager: &testutil.RefsFake{CommitID: expectedCommitID,
+					Branch:  &graveler.Branch{CommitID: expectedCommitID},
+					Commits: map[graveler.CommitID]*graveler.Commit{expectedCommitID: {MetaRangeID: expectedRangeID}}},
+			},
+			args: args{
+				ctx:          nil,
+				repositoryID: "repo",
+				branchID:     "branch",
+				committer:    "committer",
+				message:      "a message",
+				metadata:     nil,
+				expectedHead: "movedCommitID",
+			},
+			want:        expectedCommitID,
+			expectedErr: graveler.ErrBranchHeadMismatch,
+		},
 		{
 			name: "fail on staging",
 			fields: fields{
@@ -472,9 +611,10 @@ func TestGraveler_Commit(t *testing.T) {
 			g := graveler.NewGraveler(branchLocker, tt.fields.CommittedManager, tt.fields.StagingManager, tt.fields.RefManager)
 
 			got, err := g.Commit(context.Background(), "", "", graveler.CommitParams{
-				Committer: tt.args.committer,
-				Message:   tt.args.message,
-				Metadata:  tt.args.metadata,
+				Committer:    tt.args.committer,
+				Message:      tt.args.message,
+				Metadata:     tt.args.metadata,
+				ExpectedHead: tt.args.expectedHead,
 			})
 			if !errors.Is(err, tt.expectedErr) {
 				t.Fatalf("unexpected err got = %v, wanted = %v", err, tt.expectedErr)
@@ -595,6 +735,310 @@ func TestGraveler_PreCommitHook(t *testing.T) {
 	}
 }
 
+func TestGraveler_MergeSquash(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	const (
+		expectedRangeID  = graveler.MetaRangeID("expectedRangeID")
+		expectedCommitID = graveler.CommitID("expectedCommitID")
+		mergeDestination = graveler.BranchID("destinationID")
+	)
+	// history: c0 <- d1 (destination), c0 <- s1 <- s2 (source)
+	commits := map[graveler.CommitID]*graveler.Commit{
+		"c0": {MetaRangeID: "c0RangeID", Generation: 1, Message: "c0"},
+		"d1": {MetaRangeID: "d1RangeID", Generation: 2, Message: "d1", Parents: graveler.CommitParents{"c0"}},
+		"s1": {MetaRangeID: "s1RangeID", Generation: 2, Message: "s1\ndetails", Parents: graveler.CommitParents{"c0"}},
+		"s2": {MetaRangeID: "s2RangeID", Generation: 3, Message: "s2", Parents: graveler.CommitParents{"s1"}},
+	}
+	var log []graveler.CommitRecord
+	for _, id := range []graveler.CommitID{"s2", "s1", "c0"} {
+		log = append(log, graveler.CommitRecord{CommitID: id, Commit: commits[id]})
+	}
+	committedManager := &testutil.CommittedFake{MetaRangeID: expectedRangeID}
+	stagingManager := &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake(nil)}
+	refManager := &testutil.RefsFake{
+		CommitID: expectedCommitID,
+		Branch:   &graveler.Branch{CommitID: "d1"},
+		RevParseRes: map[graveler.Ref]graveler.Reference{
+			graveler.Ref(mergeDestination): testutil.NewFakeReference(graveler.ReferenceTypeBranch, mergeDestination, "d1"),
+			"s2":                           testutil.NewFakeReference(graveler.ReferenceTypeCommit, "", "s2"),
+		},
+		Commits:    commits,
+		CommitIter: testutil.NewCommitIteratorFake(log),
+	}
+	g := graveler.NewGraveler(branchLocker, committedManager, stagingManager, refManager)
+	commitID, _, err := g.Merge(context.Background(), "repoID", mergeDestination, "s2", graveler.CommitParams{
+		Committer: "committer",
+		Message:   "message",
+	}, graveler.MergeParams{Squash: true})
+	tu.MustDo(t, "merge", err)
+	if commitID != expectedCommitID {
+		t.Errorf("Merge commit ID '%s', expected '%s'", commitID, expectedCommitID)
+	}
+	if refManager.AddedCommit.MetaRangeID != expectedRangeID {
+		t.Errorf("Added commit MetaRangeID '%s', expected '%s'", refManager.AddedCommit.MetaRangeID, expectedRangeID)
+	}
+	if diff := deep.Equal(refManager.AddedCommit.Parents, graveler.CommitParents{"d1"}); diff != nil {
+		t.Errorf("Squash commit parents diff: %s", diff)
+	}
+	const expectedMessage = "message\n\nSquashed commits:\n* s2 s2\n* s1 s1\n"
+	if refManager.AddedCommit.Message != expectedMessage {
+		t.Errorf("Squash commit message '%s', expected '%s'", refManager.AddedCommit.Message, expectedMessage)
+	}
+}
+
+func TestGraveler_MergeConflict(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	const mergeDestination = graveler.BranchID("destinationID")
+	commits := map[graveler.CommitID]*graveler.Commit{
+		"d1": {MetaRangeID: "d1RangeID", Generation: 2, Message: "d1"},
+		"s1": {MetaRangeID: "s1RangeID", Generation: 2, Message: "s1"},
+	}
+	committedManager := &testutil.CommittedFake{Err: graveler.ErrConflictFound}
+	stagingManager := &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake(nil)}
+	refManager := &testutil.RefsFake{
+		Branch: &graveler.Branch{CommitID: "d1"},
+		RevParseRes: map[graveler.Ref]graveler.Reference{
+			graveler.Ref(mergeDestination): testutil.NewFakeReference(graveler.ReferenceTypeBranch, mergeDestination, "d1"),
+			"source":                       testutil.NewFakeReference(graveler.ReferenceTypeBranch, "source", "s1"),
+		},
+		Commits: commits,
+	}
+	g := graveler.NewGraveler(branchLocker, committedManager, stagingManager, refManager)
+	_, _, err := g.Merge(context.Background(), "repoID", mergeDestination, "source", graveler.CommitParams{
+		Committer: "committer",
+		Message:   "message",
+	}, graveler.MergeParams{})
+	// the conflicts of the merge are listed between the commits it merged, as the branches may move
+	var conflictErr *graveler.MergeConflictError
+	if !errors.As(err, &conflictErr) || !errors.Is(err, graveler.ErrConflictFound) {
+		t.Fatalf("Merge err=%v, expected MergeConflictError", err)
+	}
+	if conflictErr.Source != "s1" || conflictErr.Destination != "d1" {
+		t.Errorf("Merge conflict between source '%s' and destination '%s', expected 's1' and 'd1'", conflictErr.Source, conflictErr.Destination)
+	}
+}
+
+func TestGraveler_MergeFastForward(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	const mergeDestination = graveler.BranchID("destinationID")
+	addressProvider := ident.NewHexAddressProvider()
+	baseCommit := &graveler.Commit{MetaRangeID: "baseRangeID", Message: "base"}
+	baseCommitID := graveler.CommitID(addressProvider.ContentAddress(baseCommit))
+	destinationCommit := &graveler.Commit{MetaRangeID: "destinationRangeID", Message: "destination", Parents: graveler.CommitParents{baseCommitID}}
+	destinationCommitID := graveler.CommitID(addressProvider.ContentAddress(destinationCommit))
+	sourceCommit := &graveler.Commit{MetaRangeID: "sourceRangeID", Message: "source", Parents: graveler.CommitParents{destinationCommitID}}
+	sourceCommitID := graveler.CommitID(addressProvider.ContentAddress(sourceCommit))
+
+	tests := []struct {
+		name         string
+		mergeBase    *graveler.Commit
+		squash       bool
+		expectedHead graveler.CommitID
+		expectedErr  error
+	}{
+		{
+			name:      "fast-forward",
+			mergeBase: destinationCommit,
+		},
+		{
+			name:         "destination head matches",
+			mergeBase:    destinationCommit,
+			expectedHead: destinationCommitID,
+		},
+		{
+			name:         "destination moved",
+			mergeBase:    destinationCommit,
+			expectedHead: baseCommitID,
+			expectedErr:  graveler.ErrBranchHeadMismatch,
+		},
+		{
+			name:        "destination not ancestor",
+			mergeBase:   baseCommit,
+			expectedErr: graveler.ErrNotFastForward,
+		},
+		{
+			name:        "squash",
+			mergeBase:   destinationCommit,
+			squash:      true,
+			expectedErr: graveler.ErrInvalidMergeMode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			committedManager := &testutil.CommittedFake{
+				DiffIterator: testutil.NewDiffIter([]graveler.Diff{
+					{Type: graveler.DiffTypeAdded, Key: graveler.Key("a")},
+					{Type: graveler.DiffTypeChanged, Key: graveler.Key("b")},
+					{Type: graveler.DiffTypeAdded, Key: graveler.Key("c")},
+				}),
+			}
+			stagingManager := &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake(nil)}
+			refManager := &testutil.RefsFake{
+				Branch: &graveler.Branch{CommitID: destinationCommitID},
+				RevParseRes: map[graveler.Ref]graveler.Reference{
+					graveler.Ref(mergeDestination): testutil.NewFakeReference(graveler.ReferenceTypeBranch, mergeDestination, destinationCommitID),
+					sourceCommitID.Ref():           testutil.NewFakeReference(graveler.ReferenceTypeCommit, "", sourceCommitID),
+				},
+				Commits: map[graveler.CommitID]*graveler.Commit{
+					baseCommitID:        baseCommit,
+					destinationCommitID: destinationCommit,
+					sourceCommitID:      sourceCommit,
+				},
+				MergeBase: tt.mergeBase,
+			}
+			g := graveler.NewGraveler(branchLocker, committedManager, stagingManager, refManager)
+			commitID, summary, err := g.Merge(context.Background(), "repoID", mergeDestination, sourceCommitID.Ref(), graveler.CommitParams{
+				Committer: "committer",
+				Message:   "message",
+			}, graveler.MergeParams{Mode: graveler.MergeModeFFOnly, Squash: tt.squash, ExpectedHead: tt.expectedHead})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Merge err=%v, expected=%v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			if commitID != sourceCommitID {
+				t.Errorf("Merge commit ID '%s', expected source commit '%s'", commitID, sourceCommitID)
+			}
+			if refManager.AddedCommit.MetaRangeID != "" {
+				t.Errorf("Fast-forward merge added commit %+v", refManager.AddedCommit)
+			}
+			expectedCount := map[graveler.DiffType]int{graveler.DiffTypeAdded: 2, graveler.DiffTypeChanged: 1}
+			if diff := deep.Equal(summary.Count, expectedCount); diff != nil {
+				t.Errorf("Fast-forward summary diff: %s", diff)
+			}
+		})
+	}
+}
+
+type fakeRunChecker struct {
+	passed bool
+}
+
+func (f fakeRunChecker) LatestRunPassed(context.Context, graveler.RepositoryID, graveler.BranchID) (bool, error) {
+	return f.passed, nil
+}
+
+func TestGraveler_ProtectedBranch(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	const (
+		protectedBranch = graveler.BranchID("main")
+		sourceBranch    = graveler.BranchID("feature")
+	)
+	addressProvider := ident.NewHexAddressProvider()
+	destinationCommit := &graveler.Commit{MetaRangeID: "destinationRangeID", Message: "destination"}
+	destinationCommitID := graveler.CommitID(addressProvider.ContentAddress(destinationCommit))
+	sourceCommit := &graveler.Commit{MetaRangeID: "sourceRangeID", Message: "source", Parents: graveler.CommitParents{destinationCommitID}}
+	sourceCommitID := graveler.CommitID(addressProvider.ContentAddress(sourceCommit))
+	newGraveler := func(runPassed bool) (*graveler.Graveler, *testutil.StagingFake) {
+		stagingManager := &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake(nil)}
+		refManager := &testutil.RefsFake{
+			Branch: &graveler.Branch{CommitID: destinationCommitID},
+			RevParseRes: map[graveler.Ref]graveler.Reference{
+				graveler.Ref(protectedBranch): testutil.NewFakeReference(graveler.ReferenceTypeBranch, protectedBranch, destinationCommitID),
+				graveler.Ref(sourceBranch):    testutil.NewFakeReference(graveler.ReferenceTypeBranch, sourceBranch, sourceCommitID),
+			},
+			Commits: map[graveler.CommitID]*graveler.Commit{
+				destinationCommitID: destinationCommit,
+				sourceCommitID:      sourceCommit,
+			},
+			MergeBase:       destinationCommit,
+			ProtectionRules: []*graveler.BranchProtectionRule{{Pattern: "ma*", RequirePassingRun: true}},
+		}
+		committedManager := &testutil.CommittedFake{DiffIterator: testutil.NewDiffIter(nil)}
+		g := graveler.NewGraveler(branchLocker, committedManager, stagingManager, refManager)
+		g.SetBranchRunChecker(fakeRunChecker{passed: runPassed})
+		return g, stagingManager
+	}
+	ctx := context.Background()
+
+	t.Run("set", func(t *testing.T) {
+		g, stagingManager := newGraveler(true)
+		err := g.Set(ctx, "repo", protectedBranch, graveler.Key("key"), graveler.Value{Identity: []byte("id")})
+		if !errors.Is(err, graveler.ErrWriteToProtectedBranch) {
+			t.Fatalf("Set err=%v, expected=%v", err, graveler.ErrWriteToProtectedBranch)
+		}
+		if stagingManager.LastSetValueRecord != nil {
+			t.Errorf("Set staged %+v on protected branch", stagingManager.LastSetValueRecord)
+		}
+		if err := g.Set(ctx, "repo", sourceBranch, graveler.Key("key"), graveler.Value{Identity: []byte("id")}); err != nil {
+			t.Errorf("Set on unprotected branch err=%v", err)
+		}
+	})
+	t.Run("delete", func(t *testing.T) {
+		g, _ := newGraveler(true)
+		err := g.Delete(ctx, "repo", protectedBranch, graveler.Key("key"))
+		if !errors.Is(err, graveler.ErrWriteToProtectedBranch) {
+			t.Fatalf("Delete err=%v, expected=%v", err, graveler.ErrWriteToProtectedBranch)
+		}
+	})
+	t.Run("commit", func(t *testing.T) {
+		g, _ := newGraveler(true)
+		_, err := g.Commit(ctx, "repo", protectedBranch, graveler.CommitParams{Committer: "committer", Message: "message"})
+		if !errors.Is(err, graveler.ErrCommitToProtectedBranch) {
+			t.Fatalf("Commit err=%v, expected=%v", err, graveler.ErrCommitToProtectedBranch)
+		}
+	})
+	t.Run("merge run not passed", func(t *testing.T) {
+		g, _ := newGraveler(false)
+		_, _, err := g.Merge(ctx, "repo", protectedBranch, graveler.Ref(sourceBranch), graveler.CommitParams{Committer: "committer", Message: "message"},
+			graveler.MergeParams{Mode: graveler.MergeModeFFOnly})
+		if !errors.Is(err, graveler.ErrMergeSourceRunNotPassed) {
+			t.Fatalf("Merge err=%v, expected=%v", err, graveler.ErrMergeSourceRunNotPassed)
+		}
+	})
+	t.Run("merge run passed", func(t *testing.T) {
+		g, _ := newGraveler(true)
+		commitID, _, err := g.Merge(ctx, "repo", protectedBranch, graveler.Ref(sourceBranch), graveler.CommitParams{Committer: "committer", Message: "message"},
+			graveler.MergeParams{Mode: graveler.MergeModeFFOnly})
+		tu.MustDo(t, "merge", err)
+		if commitID != sourceCommitID {
+			t.Errorf("Merge commit ID '%s', expected source commit '%s'", commitID, sourceCommitID)
+		}
+	})
+}
+
+func TestGraveler_BranchProtectionRulesCache(t *testing.T) {
+	refManager := &testutil.RefsFake{}
+	g := graveler.NewGraveler(nil, &testutil.CommittedFake{}, &testutil.StagingFake{}, refManager)
+	ctx := context.Background()
+
+	rules, err := g.GetBranchProtectionRules(ctx, "repo")
+	if err != nil {
+		t.Fatalf("GetBranchProtectionRules err=%v", err)
+	}
+	if len(rules) != 0 {
+		t.Fatalf("GetBranchProtectionRules=%v, expected no rules", rules)
+	}
+	// rules changed elsewhere are served from the cache until it expires
+	refManager.ProtectionRules = []*graveler.BranchProtectionRule{{Pattern: "main"}}
+	rules, err = g.GetBranchProtectionRules(ctx, "repo")
+	if err != nil {
+		t.Fatalf("GetBranchProtectionRules err=%v", err)
+	}
+	if len(rules) != 0 {
+		t.Fatalf("GetBranchProtectionRules=%v, expected the cached rules", rules)
+	}
+	// creating a rule invalidates the cache
+	if err := g.CreateBranchProtectionRule(ctx, "repo", graveler.BranchProtectionRule{Pattern: "release/*"}); err != nil {
+		t.Fatalf("CreateBranchProtectionRule err=%v", err)
+	}
+	if err := g.Set(ctx, "repo", "release/1", graveler.Key("key"), graveler.Value{Identity: []byte("id")}); !errors.Is(err, graveler.ErrWriteToProtectedBranch) {
+		t.Fatalf("Set err=%v, expected=%v", err, graveler.ErrWriteToProtectedBranch)
+	}
+	rules, err = g.GetBranchProtectionRules(ctx, "repo")
+	if err != nil {
+		t.Fatalf("GetBranchProtectionRules err=%v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("GetBranchProtectionRules=%v, expected 2 rules", rules)
+	}
+}
+
 func TestGraveler_PreMergeHook(t *testing.T) {
 	// prepare graveler
 	conn, _ := tu.GetDB(t, databaseURI)
@@ -657,7 +1101,7 @@ func TestGraveler_PreMergeHook(t *testing.T) {
 				Committer: commitCommitter,
 				Message:   mergeMessage,
 				Metadata:  mergeMetadata,
-			})
+			}, graveler.MergeParams{})
 			// verify we got an error
 			if !errors.Is(err, tt.err) {
 				t.Fatalf("Merge err=%v, pre-merge error expected=%v", err, tt.err)
@@ -702,6 +1146,249 @@ func TestGraveler_PreMergeHook(t *testing.T) {
 	}
 }
 
+func TestGraveler_CherryPick(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	const (
+		expectedRangeID = graveler.MetaRangeID("expectedRangeID")
+		branchCommitID  = graveler.CommitID("branchCommitID")
+		parentCommitID  = graveler.CommitID("parentCommitID")
+		pickedCommitID  = graveler.CommitID("pickedCommitID")
+		mergeCommitID   = graveler.CommitID("mergeCommitID")
+		newCommitID     = graveler.CommitID("newCommitID")
+	)
+	commits := map[graveler.CommitID]*graveler.Commit{
+		branchCommitID: {MetaRangeID: "branchRangeID", Generation: 3},
+		parentCommitID: {MetaRangeID: "parentRangeID", Generation: 1},
+		pickedCommitID: {MetaRangeID: "pickedRangeID", Generation: 2, Parents: graveler.CommitParents{parentCommitID}},
+		mergeCommitID:  {MetaRangeID: "mergeRangeID", Generation: 3, Parents: graveler.CommitParents{parentCommitID, pickedCommitID}},
+	}
+	revParseRes := make(map[graveler.Ref]graveler.Reference)
+	for id := range commits {
+		revParseRes[id.Ref()] = testutil.NewFakeReference(graveler.ReferenceTypeCommit, "", id)
+	}
+	tests := []struct {
+		name         string
+		ref          graveler.Ref
+		parentNumber int
+		staged       []graveler.ValueRecord
+		committedErr error
+		expectedErr  error
+	}{
+		{
+			name: "success",
+			ref:  pickedCommitID.Ref(),
+		},
+		{
+			name:         "merge commit with parent",
+			ref:          mergeCommitID.Ref(),
+			parentNumber: 2,
+		},
+		{
+			name:        "merge commit without parent",
+			ref:         mergeCommitID.Ref(),
+			expectedErr: graveler.ErrCherryPickMergeNoParent,
+		},
+		{
+			name:         "parent out of range",
+			ref:          pickedCommitID.Ref(),
+			parentNumber: 2,
+			expectedErr:  graveler.ErrCherryPickParentOutOfRange,
+		},
+		{
+			name:        "dirty branch",
+			ref:         pickedCommitID.Ref(),
+			staged:      []graveler.ValueRecord{{Key: graveler.Key("foo/one"), Value: &graveler.Value{}}},
+			expectedErr: graveler.ErrDirtyBranch,
+		},
+		{
+			name:         "conflict",
+			ref:          pickedCommitID.Ref(),
+			committedErr: graveler.ErrConflictFound,
+			expectedErr:  graveler.ErrConflictFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			committedManager := &testutil.CommittedFake{MetaRangeID: expectedRangeID, Err: tt.committedErr}
+			stagingManager := &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake(tt.staged)}
+			refManager := &testutil.RefsFake{
+				CommitID:    newCommitID,
+				Branch:      &graveler.Branch{CommitID: branchCommitID},
+				RevParseRes: revParseRes,
+				Commits:     commits,
+			}
+			g := graveler.NewGraveler(branchLocker, committedManager, stagingManager, refManager)
+			commitID, _, err := g.CherryPick(ctx, "repoID", "branchID", tt.ref, tt.parentNumber, graveler.CommitParams{
+				Committer: "committer",
+				Message:   "message",
+			})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CherryPick err=%v, expected=%v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			if commitID != newCommitID {
+				t.Errorf("CherryPick commit ID '%s', expected '%s'", commitID, newCommitID)
+			}
+			if refManager.AddedCommit.MetaRangeID != expectedRangeID {
+				t.Errorf("Added commit MetaRangeID '%s', expected '%s'", refManager.AddedCommit.MetaRangeID, expectedRangeID)
+			}
+			parents := refManager.AddedCommit.Parents
+			if len(parents) != 1 || parents[0] != branchCommitID {
+				t.Errorf("Added commit parents %v, expected single parent '%s'", parents, branchCommitID)
+			}
+		})
+	}
+}
+
+// refsRecordCommits records all the commits added to the fake refs manager
+type refsRecordCommits struct {
+	*testutil.RefsFake
+	added []graveler.Commit
+}
+
+func (m *refsRecordCommits) AddCommit(ctx context.Context, repositoryID graveler.RepositoryID, commit graveler.Commit) (graveler.CommitID, error) {
+	m.added = append(m.added, commit)
+	return m.RefsFake.AddCommit(ctx, repositoryID, commit)
+}
+
+func TestGraveler_Rebase(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	const (
+		expectedRangeID = graveler.MetaRangeID("expectedRangeID")
+		newCommitID     = graveler.CommitID("newCommitID")
+	)
+	// history: c0 <- c1 <- c2 (onto), c0 <- b1 <- m1 (merge of c1) <- b2 (branch head)
+	// commits are identified by their content address, as the merge base is
+	commits := make(map[graveler.CommitID]*graveler.Commit)
+	ids := make(map[string]graveler.CommitID)
+	addCommit := func(name string, commit *graveler.Commit, parents ...string) {
+		commit.Message = name
+		for _, parent := range parents {
+			commit.Parents = append(commit.Parents, ids[parent])
+		}
+		id := graveler.CommitID(ident.NewHexAddressProvider().ContentAddress(commit))
+		ids[name] = id
+		commits[id] = commit
+	}
+	addCommit("c0", &graveler.Commit{MetaRangeID: "c0RangeID", Generation: 1})
+	addCommit("c1", &graveler.Commit{MetaRangeID: "c1RangeID", Generation: 2}, "c0")
+	addCommit("c2", &graveler.Commit{MetaRangeID: "c2RangeID", Generation: 3}, "c1")
+	addCommit("b1", &graveler.Commit{MetaRangeID: "b1RangeID", Generation: 2, Committer: "b1-committer"}, "c0")
+	addCommit("m1", &graveler.Commit{MetaRangeID: "m1RangeID", Generation: 3}, "b1", "c1")
+	addCommit("b2", &graveler.Commit{MetaRangeID: "b2RangeID", Generation: 4, Committer: "b2-committer", Metadata: graveler.Metadata{"key": "value"}}, "m1")
+	revParseRes := make(map[graveler.Ref]graveler.Reference)
+	for name, id := range ids {
+		revParseRes[graveler.Ref(name)] = testutil.NewFakeReference(graveler.ReferenceTypeCommit, "", id)
+		revParseRes[id.Ref()] = testutil.NewFakeReference(graveler.ReferenceTypeCommit, "", id)
+	}
+	tests := []struct {
+		name             string
+		onto             graveler.Ref
+		mergeBase        string
+		staged           []graveler.ValueRecord
+		committedErr     error
+		expectedErr      error
+		expectedCommitID graveler.CommitID
+		expectedMessages []string
+		expectedParents  []graveler.CommitID
+	}{
+		{
+			name:             "success",
+			onto:             "c2",
+			mergeBase:        "c1",
+			expectedMessages: []string{"b1", "b2"},
+			expectedParents:  []graveler.CommitID{ids["c2"], newCommitID},
+		},
+		{
+			name:             "onto merged ref",
+			onto:             "c1",
+			mergeBase:        "c1",
+			expectedMessages: []string{"b1", "b2"},
+			expectedParents:  []graveler.CommitID{ids["c1"], newCommitID},
+		},
+		{
+			name:        "dirty branch",
+			onto:        "c2",
+			mergeBase:   "c1",
+			staged:      []graveler.ValueRecord{{Key: graveler.Key("foo/one"), Value: &graveler.Value{}}},
+			expectedErr: graveler.ErrDirtyBranch,
+		},
+		{
+			name:         "conflict",
+			onto:         "c2",
+			mergeBase:    "c1",
+			committedErr: graveler.ErrConflictFound,
+			expectedErr:  graveler.ErrConflictFound,
+		},
+		{
+			// git rebase drops commits whose changes are already applied
+			name:             "already applied",
+			onto:             "c2",
+			mergeBase:        "c1",
+			committedErr:     graveler.ErrNoChanges,
+			expectedCommitID: ids["c2"],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			committedManager := &testutil.CommittedFake{MetaRangeID: expectedRangeID, Err: tt.committedErr}
+			stagingManager := &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake(tt.staged)}
+			refManager := &refsRecordCommits{
+				RefsFake: &testutil.RefsFake{
+					CommitID:    newCommitID,
+					Branch:      &graveler.Branch{CommitID: ids["b2"]},
+					RevParseRes: revParseRes,
+					Commits:     commits,
+					MergeBase:   commits[ids[tt.mergeBase]],
+				},
+			}
+			g := graveler.NewGraveler(branchLocker, committedManager, stagingManager, refManager)
+			commitID, err := g.Rebase(ctx, "repoID", "branchID", tt.onto)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Rebase err=%v, expected=%v", err, tt.expectedErr)
+			}
+			if errors.Is(err, graveler.ErrConflictFound) {
+				var conflictErr *graveler.RebaseConflictError
+				if !errors.As(err, &conflictErr) || conflictErr.CommitID != ids["b1"] {
+					t.Fatalf("Rebase err=%v, expected conflict on commit b1", err)
+				}
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			expectedCommitID := tt.expectedCommitID
+			if expectedCommitID == "" {
+				expectedCommitID = newCommitID
+			}
+			if commitID != expectedCommitID {
+				t.Errorf("Rebase commit ID '%s', expected '%s'", commitID, expectedCommitID)
+			}
+			var messages []string
+			var parents []graveler.CommitID
+			for _, commit := range refManager.added {
+				messages = append(messages, commit.Message)
+				parents = append(parents, commit.Parents...)
+				original := commits[ids[commit.Message]]
+				if commit.Committer != original.Committer || deep.Equal(commit.Metadata, original.Metadata) != nil {
+					t.Errorf("Replayed commit %s committer '%s' metadata %v, expected '%s' %v", commit.Message, commit.Committer, commit.Metadata, original.Committer, original.Metadata)
+				}
+			}
+			if diff := deep.Equal(messages, tt.expectedMessages); diff != nil {
+				t.Errorf("Replayed commits diff: %s", diff)
+			}
+			if diff := deep.Equal(parents, tt.expectedParents); diff != nil {
+				t.Errorf("Replayed commits parents diff: %s", diff)
+			}
+		})
+	}
+}
+
 func TestGraveler_AddCommitToBranchHead(t *testing.T) {
 	conn, _ := tu.GetDB(t, databaseURI)
 	branchLocker := ref.NewBranchLocker(conn)
@@ -1164,3 +1851,288 @@ func TestGraveler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestGraveler_Apply(t *testing.T) {
+	conn, _ := tu.GetDB(t, databaseURI)
+	branchLocker := ref.NewBranchLocker(conn)
+	value := &graveler.Value{Identity: []byte("id"), Data: []byte("data")}
+	tests := []struct {
+		name             string
+		committedManager graveler.CommittedManager
+		stagingManager   *testutil.StagingFake
+		refManager       graveler.RefManager
+		ops              []graveler.StagingOperation
+		expectedOps      []graveler.StagingOperation
+		expectedErr      error
+	}{
+		{
+			name:             "set and delete committed",
+			committedManager: &testutil.CommittedFake{ValuesByKey: map[string]*graveler.Value{"b": {}}},
+			stagingManager:   &testutil.StagingFake{},
+			refManager: &testutil.RefsFake{
+				Branch:  &graveler.Branch{CommitID: "c1"},
+				Commits: map[graveler.CommitID]*graveler.Commit{"c1": {}},
+			},
+			ops: []graveler.StagingOperation{
+				{Key: []byte("a"), Value: value},
+				{Key: []byte("b")},
+			},
+			expectedOps: []graveler.StagingOperation{
+				{Key: []byte("a"), Value: value},
+				{Key: []byte("b")},
+			},
+		},
+		{
+			name:             "delete uncommitted",
+			committedManager: &testutil.CommittedFake{Err: graveler.ErrNotFound},
+			stagingManager:   &testutil.StagingFake{},
+			refManager: &testutil.RefsFake{
+				Branch:  &graveler.Branch{CommitID: "c1"},
+				Commits: map[graveler.CommitID]*graveler.Commit{"c1": {}},
+			},
+			ops:         []graveler.StagingOperation{{Key: []byte("b")}},
+			expectedOps: []graveler.StagingOperation{{Key: []byte("b"), Drop: true}},
+		},
+		{
+			name:             "delete no commits",
+			committedManager: &testutil.CommittedFake{},
+			stagingManager:   &testutil.StagingFake{},
+			refManager: &testutil.RefsFake{
+				Branch:  &graveler.Branch{},
+				Commits: map[graveler.CommitID]*graveler.Commit{},
+			},
+			ops:         []graveler.StagingOperation{{Key: []byte("b")}},
+			expectedOps: []graveler.StagingOperation{{Key: []byte("b"), Drop: true}},
+		},
+		{
+			name:             "staging failure",
+			committedManager: &testutil.CommittedFake{},
+			stagingManager:   &testutil.StagingFake{SetErr: graveler.ErrInvalidValue},
+			refManager: &testutil.RefsFake{
+				Branch:  &graveler.Branch{CommitID: "c1"},
+				Commits: map[graveler.CommitID]*graveler.Commit{"c1": {}},
+			},
+			ops:         []graveler.StagingOperation{{Key: []byte("a"), Value: value}},
+			expectedErr: graveler.ErrInvalidValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			g := graveler.NewGraveler(branchLocker, tt.committedManager, tt.stagingManager, tt.refManager)
+			if err := g.Apply(ctx, "repo", "branch", tt.ops); !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Apply() returned unexpected error. got = %v, expected %v", err, tt.expectedErr)
+			}
+			if diff := deep.Equal(tt.stagingManager.LastAppliedOps, tt.expectedOps); diff != nil {
+				t.Errorf("unexpected applied operations %s", diff)
+			}
+		})
+	}
+}
+
+// committedByMetaRangeValues lists, diffs and gets the keys and identities set for each meta range
+type committedByMetaRangeValues struct {
+	testutil.CommittedFake
+	values map[graveler.MetaRangeID]map[string]string
+	// diffs counts the calls to Diff
+	diffs int
+}
+
+func (c *committedByMetaRangeValues) records(metaRangeID graveler.MetaRangeID) []graveler.ValueRecord {
+	values := c.values[metaRangeID]
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	records := make([]graveler.ValueRecord, 0, len(keys))
+	for _, k := range keys {
+		records = append(records, graveler.ValueRecord{Key: graveler.Key(k), Value: &graveler.Value{Identity: []byte(values[k])}})
+	}
+	return records
+}
+
+func (c *committedByMetaRangeValues) List(_ context.Context, _ graveler.StorageNamespace, metaRangeID graveler.MetaRangeID) (graveler.ValueIterator, error) {
+	return testutil.NewValueIteratorFake(c.records(metaRangeID)), nil
+}
+
+func (c *committedByMetaRangeValues) Get(_ context.Context, _ graveler.StorageNamespace, metaRangeID graveler.MetaRangeID, key graveler.Key) (*graveler.Value, error) {
+	identity, ok := c.values[metaRangeID][string(key)]
+	if !ok {
+		return nil, graveler.ErrNotFound
+	}
+	return &graveler.Value{Identity: []byte(identity)}, nil
+}
+
+func (c *committedByMetaRangeValues) Diff(_ context.Context, _ graveler.StorageNamespace, left, right graveler.MetaRangeID, _ graveler.DiffParams) (graveler.DiffIterator, error) {
+	c.diffs++
+	var diffs []graveler.Diff
+	for _, record := range c.records(right) {
+		identity, ok := c.values[left][string(record.Key)]
+		switch {
+		case !ok:
+			diffs = append(diffs, graveler.Diff{Type: graveler.DiffTypeAdded, Key: record.Key, Value: record.Value})
+		case identity != string(record.Identity):
+			diffs = append(diffs, graveler.Diff{Type: graveler.DiffTypeChanged, Key: record.Key, Value: record.Value})
+		}
+	}
+	for _, record := range c.records(left) {
+		if _, ok := c.values[right][string(record.Key)]; !ok {
+			diffs = append(diffs, graveler.Diff{Type: graveler.DiffTypeRemoved, Key: record.Key, Value: record.Value})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return bytes.Compare(diffs[i].Key, diffs[j].Key) < 0
+	})
+	return testutil.NewDiffIter(diffs), nil
+}
+
+func TestGraveler_AheadBehind(t *testing.T) {
+	// history: c0 <- c1 <- c2 (main), c0 <- b1 <- m1 (merge of c1) <- b2 (branch)
+	commits := map[graveler.CommitID]*graveler.Commit{
+		"c0": {MetaRangeID: "c0RangeID", Generation: 1},
+		"c1": {MetaRangeID: "c1RangeID", Generation: 2, Parents: graveler.CommitParents{"c0"}},
+		"c2": {MetaRangeID: "c2RangeID", Generation: 3, Parents: graveler.CommitParents{"c1"}},
+		"b1": {MetaRangeID: "b1RangeID", Generation: 2, Parents: graveler.CommitParents{"c0"}},
+		"m1": {MetaRangeID: "m1RangeID", Generation: 3, Parents: graveler.CommitParents{"b1", "c1"}},
+		"b2": {MetaRangeID: "b2RangeID", Generation: 4, Parents: graveler.CommitParents{"m1"}},
+	}
+	revParseRes := make(map[graveler.Ref]graveler.Reference)
+	for id := range commits {
+		revParseRes[id.Ref()] = testutil.NewFakeReference(graveler.ReferenceTypeCommit, "", id)
+	}
+	tests := []struct {
+		name           string
+		ref            graveler.Ref
+		other          graveler.Ref
+		mergeBase      graveler.CommitID
+		expectedAhead  int
+		expectedBehind int
+	}{
+		{name: "diverged", ref: "b2", other: "c2", mergeBase: "c1", expectedAhead: 3, expectedBehind: 1},
+		{name: "diverged reversed", ref: "c2", other: "b2", mergeBase: "c1", expectedAhead: 1, expectedBehind: 3},
+		{name: "ancestor", ref: "c2", other: "c0", mergeBase: "c0", expectedAhead: 2},
+		{name: "descendant", ref: "c0", other: "b2", mergeBase: "c0", expectedBehind: 4},
+		{name: "same", ref: "m1", other: "m1", mergeBase: "m1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refManager := &testutil.RefsFake{
+				Commits:     commits,
+				RevParseRes: revParseRes,
+				MergeBase:   commits[tt.mergeBase],
+			}
+			g := graveler.NewGraveler(nil, &testutil.CommittedFake{}, &testutil.StagingFake{}, refManager)
+			ahead, behind, err := g.AheadBehind(context.Background(), "repo", tt.ref, tt.other)
+			if err != nil {
+				t.Fatalf("AheadBehind(%s, %s) unexpected error: %s", tt.ref, tt.other, err)
+			}
+			if ahead != tt.expectedAhead || behind != tt.expectedBehind {
+				t.Errorf("AheadBehind(%s, %s) = %d, %d, expected %d, %d", tt.ref, tt.other, ahead, behind, tt.expectedAhead, tt.expectedBehind)
+			}
+		})
+	}
+}
+
+// logCountingRefs counts the calls to Log
+type logCountingRefs struct {
+	*testutil.RefsFake
+	logs int
+}
+
+func (m *logCountingRefs) Log(ctx context.Context, repositoryID graveler.RepositoryID, commitID graveler.CommitID, filter graveler.CommitFilter) (graveler.CommitIterator, error) {
+	m.logs++
+	return m.RefsFake.Log(ctx, repositoryID, commitID, filter)
+}
+
+func TestGraveler_Blame(t *testing.T) {
+	commits := map[graveler.CommitID]*graveler.Commit{
+		"c1": {MetaRangeID: "m1"},
+		"c2": {MetaRangeID: "m2", Parents: graveler.CommitParents{"c1"}},
+		"x1": {MetaRangeID: "mx", Parents: graveler.CommitParents{"c1"}},
+		"c3": {MetaRangeID: "m3", Parents: graveler.CommitParents{"c2", "x1"}},
+		"c4": {MetaRangeID: "m4", Parents: graveler.CommitParents{"c3"}},
+	}
+	values := map[graveler.MetaRangeID]map[string]string{
+		"m1": {"a": "a1", "b": "b1", "e": "e1", "f": "f1"},
+		"m2": {"a": "a2", "b": "b1", "e": "e1", "f": "f1"},
+		"mx": {"a": "a1", "b": "b2", "e": "e1", "f": "f1"},
+		// the merge takes b from x1 and resolves a new c
+		"m3": {"a": "a2", "b": "b2", "c": "c1", "e": "e1", "f": "f1"},
+		"m4": {"a": "a2", "b": "b2", "c": "c1", "d": "d1", "f": "f1", "other": "o1"},
+	}
+	var log []graveler.CommitRecord
+	for _, id := range []graveler.CommitID{"c4", "c3", "x1", "c2", "c1"} {
+		log = append(log, graveler.CommitRecord{CommitID: id, Commit: commits[id]})
+	}
+	tests := []struct {
+		name     string
+		prefix   string
+		after    string
+		amount   int
+		expected map[string]graveler.CommitID
+		// the log is walked once, and each commit diffed once until all the keys are resolved
+		expectedLogs  int
+		expectedDiffs int
+	}{
+		{
+			name:          "all",
+			amount:        100,
+			expected:      map[string]graveler.CommitID{"a": "c2", "b": "x1", "c": "c3", "d": "c4", "f": "c1", "other": "c4"},
+			expectedLogs:  1,
+			expectedDiffs: 5,
+		},
+		{
+			name:          "after",
+			after:         "a",
+			amount:        2,
+			expected:      map[string]graveler.CommitID{"b": "x1", "c": "c3"},
+			expectedLogs:  1,
+			expectedDiffs: 3,
+		},
+		{
+			name:          "prefix",
+			prefix:        "o",
+			amount:        100,
+			expected:      map[string]graveler.CommitID{"other": "c4"},
+			expectedLogs:  1,
+			expectedDiffs: 1,
+		},
+		{
+			name:     "no match",
+			prefix:   "z",
+			amount:   100,
+			expected: map[string]graveler.CommitID{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refManager := &logCountingRefs{RefsFake: &testutil.RefsFake{
+				RefType:    graveler.ReferenceTypeBranch,
+				CommitID:   "c4",
+				Commits:    commits,
+				CommitIter: testutil.NewCommitIteratorFake(log),
+			}}
+			committedManager := &committedByMetaRangeValues{values: values}
+			g := graveler.NewGraveler(nil, committedManager, &testutil.StagingFake{}, refManager)
+			records, err := g.Blame(context.Background(), "repo", "main", graveler.Key(tt.prefix), graveler.Key(tt.after), tt.amount)
+			if err != nil {
+				t.Fatalf("Blame unexpected error: %s", err)
+			}
+			got := make(map[string]graveler.CommitID)
+			for _, record := range records {
+				if record.Commit == nil {
+					t.Fatalf("Blame key %s not resolved", record.Key)
+				}
+				got[string(record.Key)] = record.Commit.CommitID
+			}
+			if diff := deep.Equal(got, tt.expected); diff != nil {
+				t.Errorf("Blame unexpected result: %s", diff)
+			}
+			if refManager.logs != tt.expectedLogs || committedManager.diffs != tt.expectedDiffs {
+				t.Errorf("Blame walked the log %d times with %d diffs, expected %d times with %d diffs",
+					refManager.logs, committedManager.diffs, tt.expectedLogs, tt.expectedDiffs)
+			}
+		})
+	}
+}
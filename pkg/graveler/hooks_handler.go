@@ -35,6 +35,12 @@ type HooksHandler interface {
 	PostMergeHook(ctx context.Context, record HookRecord) error
 }
 
+// BranchRunChecker reports the results of action runs on branches
+type BranchRunChecker interface {
+	// LatestRunPassed returns true if the latest action run on branchID passed
+	LatestRunPassed(ctx context.Context, repositoryID RepositoryID, branchID BranchID) (bool, error)
+}
+
 type HooksNoOp struct{}
 
 func (h *HooksNoOp) PreCommitHook(context.Context, HookRecord) error {
@@ -0,0 +1,86 @@
+package graveler
+
+import (
+	"context"
+	"errors"
+)
+
+type mergeConflictIterator struct {
+	ctx              context.Context
+	diffIt           DiffIterator
+	committed        CommittedManager
+	storageNamespace StorageNamespace
+	source           MetaRangeID
+	destination      MetaRangeID
+	base             MetaRangeID
+	value            *MergeConflict
+	err              error
+}
+
+// NewMergeConflictIterator scans a three-way compare diff (destination to source relative to base) and returns only
+// the conflicting keys, together with their values on each of the compared meta ranges
+func NewMergeConflictIterator(ctx context.Context, diffIt DiffIterator, committed CommittedManager, sn StorageNamespace, source, destination, base MetaRangeID) MergeConflictIterator {
+	return &mergeConflictIterator{
+		ctx:              ctx,
+		diffIt:           diffIt,
+		committed:        committed,
+		storageNamespace: sn,
+		source:           source,
+		destination:      destination,
+		base:             base,
+	}
+}
+
+// getValue returns the value of key in metaRangeID, or nil in case the key does not exist
+func (m *mergeConflictIterator) getValue(metaRangeID MetaRangeID, key Key) (*Value, error) {
+	value, err := m.committed.Get(m.ctx, m.storageNamespace, metaRangeID, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (m *mergeConflictIterator) Next() bool {
+	m.value = nil
+	if m.err != nil {
+		return false
+	}
+	for m.diffIt.Next() {
+		diff := m.diffIt.Value()
+		if diff.Type != DiffTypeConflict {
+			continue
+		}
+		conflict := &MergeConflict{Key: diff.Key.Copy()}
+		if conflict.Source, m.err = m.getValue(m.source, diff.Key); m.err != nil {
+			return false
+		}
+		if conflict.Destination, m.err = m.getValue(m.destination, diff.Key); m.err != nil {
+			return false
+		}
+		if conflict.Base, m.err = m.getValue(m.base, diff.Key); m.err != nil {
+			return false
+		}
+		m.value = conflict
+		return true
+	}
+	m.err = m.diffIt.Err()
+	return false
+}
+
+func (m *mergeConflictIterator) SeekGE(id Key) {
+	m.value = nil
+	m.err = nil
+	m.diffIt.SeekGE(id)
+}
+
+func (m *mergeConflictIterator) Value() *MergeConflict {
+	return m.value
+}
+
+func (m *mergeConflictIterator) Err() error {
+	return m.err
+}
+
+func (m *mergeConflictIterator) Close() {
+	m.diffIt.Close()
+}
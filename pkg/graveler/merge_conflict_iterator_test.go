@@ -0,0 +1,68 @@
+package graveler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/testutil"
+)
+
+// committedByMetaRange returns values from a different set of keys for each meta range
+type committedByMetaRange struct {
+	testutil.CommittedFake
+	values map[graveler.MetaRangeID]map[string]string
+}
+
+func (c *committedByMetaRange) Get(_ context.Context, _ graveler.StorageNamespace, metaRangeID graveler.MetaRangeID, key graveler.Key) (*graveler.Value, error) {
+	identity, ok := c.values[metaRangeID][string(key)]
+	if !ok {
+		return nil, graveler.ErrNotFound
+	}
+	return &graveler.Value{Identity: []byte(identity)}, nil
+}
+
+func TestMergeConflictIterator(t *testing.T) {
+	committed := &committedByMetaRange{
+		values: map[graveler.MetaRangeID]map[string]string{
+			"source":      {"a": "a-src", "b": "b-src", "d": "d-src"},
+			"destination": {"a": "a-dst", "c": "c-dst", "d": "d-dst"},
+			"base":        {"a": "a-base", "b": "b-base", "c": "c-base"},
+		},
+	}
+	diffs := []graveler.Diff{
+		{Type: graveler.DiffTypeConflict, Key: graveler.Key("a")},
+		{Type: graveler.DiffTypeAdded, Key: graveler.Key("aa")},
+		{Type: graveler.DiffTypeConflict, Key: graveler.Key("b")},
+		{Type: graveler.DiffTypeConflict, Key: graveler.Key("c")},
+		{Type: graveler.DiffTypeChanged, Key: graveler.Key("cc")},
+		{Type: graveler.DiffTypeConflict, Key: graveler.Key("d")},
+	}
+	it := graveler.NewMergeConflictIterator(context.Background(), testutil.NewDiffIter(diffs), committed, "ns", "source", "destination", "base")
+	defer it.Close()
+
+	identity := func(v *graveler.Value) string {
+		if v == nil {
+			return ""
+		}
+		return string(v.Identity)
+	}
+	var got [][]string
+	for it.Next() {
+		v := it.Value()
+		got = append(got, []string{string(v.Key), identity(v.Source), identity(v.Destination), identity(v.Base)})
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := [][]string{
+		{"a", "a-src", "a-dst", "a-base"},
+		{"b", "b-src", "", "b-base"},
+		{"c", "", "c-dst", "c-base"},
+		{"d", "d-src", "d-dst", ""},
+	}
+	if diff := deep.Equal(got, expected); diff != nil {
+		t.Fatalf("unexpected merge conflicts: %s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+package graveler
+
+import (
+	"context"
+)
+
+type prefixCommitIterator struct {
+	ctx              context.Context
+	it               CommitIterator
+	refManager       RefManager
+	committed        CommittedManager
+	repositoryID     RepositoryID
+	storageNamespace StorageNamespace
+	prefix           Key
+	value            *CommitRecord
+	err              error
+}
+
+// NewPrefixCommitIterator returns only the commits of it that change a key starting with prefix
+// relative to their first parent
+func NewPrefixCommitIterator(ctx context.Context, it CommitIterator, refManager RefManager, committed CommittedManager, repositoryID RepositoryID, sn StorageNamespace, prefix Key) CommitIterator {
+	return &prefixCommitIterator{
+		ctx:              ctx,
+		it:               it,
+		refManager:       refManager,
+		committed:        committed,
+		repositoryID:     repositoryID,
+		storageNamespace: sn,
+		prefix:           prefix,
+	}
+}
+
+// changed returns true if commit changes a key starting with the iterator prefix relative to its first parent
+func (p *prefixCommitIterator) changed(commit *CommitRecord) (bool, error) {
+	var parentMetaRangeID MetaRangeID
+	if len(commit.Parents) > 0 {
+		parent, err := p.refManager.GetCommit(p.ctx, p.repositoryID, commit.Parents[0])
+		if err != nil {
+			return false, err
+		}
+		parentMetaRangeID = parent.MetaRangeID
+	}
+	return p.committed.PrefixChanged(p.ctx, p.storageNamespace, parentMetaRangeID, commit.MetaRangeID, p.prefix)
+}
+
+func (p *prefixCommitIterator) Next() bool {
+	p.value = nil
+	if p.err != nil {
+		return false
+	}
+	for p.it.Next() {
+		commit := p.it.Value()
+		changed, err := p.changed(commit)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		if changed {
+			p.value = commit
+			return true
+		}
+	}
+	p.err = p.it.Err()
+	return false
+}
+
+func (p *prefixCommitIterator) SeekGE(id CommitID) {
+	p.value = nil
+	p.err = nil
+	p.it.SeekGE(id)
+}
+
+func (p *prefixCommitIterator) Value() *CommitRecord {
+	return p.value
+}
+
+func (p *prefixCommitIterator) Err() error {
+	return p.err
+}
+
+func (p *prefixCommitIterator) Close() {
+	p.it.Close()
+}
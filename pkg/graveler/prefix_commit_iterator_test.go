@@ -0,0 +1,64 @@
+package graveler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/testutil"
+)
+
+// committedChangedByMetaRange reports a change under prefix between a metarange and those listed for it
+type committedChangedByMetaRange struct {
+	testutil.CommittedFake
+	prefix  graveler.Key
+	changed map[graveler.MetaRangeID]graveler.MetaRangeID
+}
+
+func (c *committedChangedByMetaRange) PrefixChanged(_ context.Context, _ graveler.StorageNamespace, left, right graveler.MetaRangeID, prefix graveler.Key) (bool, error) {
+	c.prefix = prefix
+	changedFrom, ok := c.changed[right]
+	return ok && changedFrom == left, nil
+}
+
+func TestPrefixCommitIterator(t *testing.T) {
+	commits := map[graveler.CommitID]*graveler.Commit{
+		"c1": {MetaRangeID: "m1"},
+		"c2": {MetaRangeID: "m2", Parents: graveler.CommitParents{"c1"}},
+		"c3": {MetaRangeID: "m3", Parents: graveler.CommitParents{"c2"}},
+		"x1": {MetaRangeID: "mx", Parents: graveler.CommitParents{"c1"}},
+		"c4": {MetaRangeID: "m4", Parents: graveler.CommitParents{"c3", "x1"}},
+	}
+	var records []graveler.CommitRecord
+	for _, id := range []graveler.CommitID{"c4", "x1", "c3", "c2", "c1"} {
+		records = append(records, graveler.CommitRecord{CommitID: id, Commit: commits[id]})
+	}
+	refManager := &testutil.RefsFake{Commits: commits}
+	committed := &committedChangedByMetaRange{
+		changed: map[graveler.MetaRangeID]graveler.MetaRangeID{
+			"m1": "",
+			"m3": "m2",
+			"mx": "m1",
+			// the merge changes the prefix only relative to its second parent
+			"m4": "mx",
+		},
+	}
+	it := graveler.NewPrefixCommitIterator(context.Background(), testutil.NewCommitIteratorFake(records), refManager, committed, "repo", "ns", graveler.Key("tables/events/"))
+	defer it.Close()
+
+	var got []graveler.CommitID
+	for it.Next() {
+		got = append(got, it.Value().CommitID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []graveler.CommitID{"x1", "c3", "c1"}
+	if diff := deep.Equal(got, expected); diff != nil {
+		t.Errorf("unexpected commits: %s", diff)
+	}
+	if string(committed.prefix) != "tables/events/" {
+		t.Errorf("PrefixChanged called with prefix %s, expected tables/events/", committed.prefix)
+	}
+}
@@ -0,0 +1,166 @@
+package graveler
+
+import (
+	"bytes"
+)
+
+// NewScopedDiffIterator returns the diffs of it in the scope of params
+func NewScopedDiffIterator(it DiffIterator, params DiffParams) DiffIterator {
+	if len(params.Prefix) > 0 {
+		it = NewPrefixDiffIterator(it, params.Prefix)
+	}
+	if len(params.Delimiter) > 0 {
+		it = NewDelimitedDiffIterator(it, params.Prefix, params.Delimiter)
+	}
+	return it
+}
+
+type prefixDiffIterator struct {
+	it      DiffIterator
+	prefix  Key
+	started bool
+	value   *Diff
+}
+
+// NewPrefixDiffIterator returns only the diffs of it on keys starting with prefix.  The first call to
+// Next seeks it to prefix, so an underlying iterator over ranges skips those before prefix without
+// reading them.
+func NewPrefixDiffIterator(it DiffIterator, prefix Key) DiffIterator {
+	return &prefixDiffIterator{
+		it:     it,
+		prefix: prefix,
+	}
+}
+
+func (p *prefixDiffIterator) Next() bool {
+	if !p.started {
+		p.started = true
+		p.it.SeekGE(p.prefix)
+	}
+	if !p.it.Next() {
+		p.value = nil
+		return false
+	}
+	v := p.it.Value()
+	if !bytes.HasPrefix(v.Key, p.prefix) {
+		p.value = nil
+		return false
+	}
+	p.value = v
+	return true
+}
+
+func (p *prefixDiffIterator) SeekGE(id Key) {
+	p.started = true
+	p.value = nil
+	if bytes.Compare(id, p.prefix) < 0 {
+		id = p.prefix
+	}
+	p.it.SeekGE(id)
+}
+
+func (p *prefixDiffIterator) Value() *Diff {
+	return p.value
+}
+
+func (p *prefixDiffIterator) Err() error {
+	return p.it.Err()
+}
+
+func (p *prefixDiffIterator) Close() {
+	p.it.Close()
+}
+
+type delimitedDiffIterator struct {
+	it        DiffIterator
+	prefix    Key
+	delimiter Key
+	// pending is true when it is positioned on a diff that was read but not returned yet
+	pending bool
+	value   *Diff
+}
+
+// NewDelimitedDiffIterator groups the diffs of it on keys that share the part up to the first
+// delimiter after prefix into a single diff on that common prefix, holding the count of grouped diffs
+// by type.  The common prefix has the type of its diffs if they all share one, and DiffTypeChanged
+// otherwise.  Keys of it must all start with prefix.
+func NewDelimitedDiffIterator(it DiffIterator, prefix, delimiter Key) DiffIterator {
+	return &delimitedDiffIterator{
+		it:        it,
+		prefix:    prefix,
+		delimiter: delimiter,
+	}
+}
+
+func (d *delimitedDiffIterator) commonPrefix(key Key) Key {
+	relevant := key[len(d.prefix):]
+	idx := bytes.Index(relevant, d.delimiter)
+	if idx == -1 {
+		return nil
+	}
+	return Key(key[:len(d.prefix)+idx+len(d.delimiter)]).Copy()
+}
+
+func (d *delimitedDiffIterator) Next() bool {
+	if !d.pending && !d.it.Next() {
+		d.value = nil
+		return false
+	}
+	d.pending = false
+	v := d.it.Value()
+	commonPrefix := d.commonPrefix(v.Key)
+	if commonPrefix == nil {
+		d.value = v
+		return true
+	}
+	summary := &DiffSummary{Count: map[DiffType]int{v.Type: 1}}
+	typ := v.Type
+	for d.it.Next() {
+		v = d.it.Value()
+		if !bytes.HasPrefix(v.Key, commonPrefix) {
+			d.pending = true
+			break
+		}
+		summary.Count[v.Type]++
+		if v.Type != typ {
+			typ = DiffTypeChanged
+		}
+	}
+	if d.it.Err() != nil {
+		d.value = nil
+		return false
+	}
+	d.value = &Diff{
+		Type:         typ,
+		Key:          commonPrefix,
+		CommonPrefix: summary,
+	}
+	return true
+}
+
+// SeekGE seeks to id, or past the whole common prefix holding id: common prefixes are returned as a
+// single diff, so paging after one resumes without reading its keys again.
+func (d *delimitedDiffIterator) SeekGE(id Key) {
+	d.pending = false
+	d.value = nil
+	if bytes.HasPrefix(id, d.prefix) {
+		if commonPrefix := d.commonPrefix(id); commonPrefix != nil {
+			if upperBound := UpperBoundForPrefix(commonPrefix); upperBound != nil {
+				id = upperBound
+			}
+		}
+	}
+	d.it.SeekGE(id)
+}
+
+func (d *delimitedDiffIterator) Value() *Diff {
+	return d.value
+}
+
+func (d *delimitedDiffIterator) Err() error {
+	return d.it.Err()
+}
+
+func (d *delimitedDiffIterator) Close() {
+	d.it.Close()
+}
@@ -0,0 +1,104 @@
+package graveler_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/testutil"
+)
+
+func TestScopedDiffIterator(t *testing.T) {
+	diffs := []graveler.Diff{
+		{Key: graveler.Key("a/1"), Type: graveler.DiffTypeAdded},
+		{Key: graveler.Key("b/1"), Type: graveler.DiffTypeAdded},
+		{Key: graveler.Key("b/c/1"), Type: graveler.DiffTypeAdded},
+		{Key: graveler.Key("b/c/2"), Type: graveler.DiffTypeRemoved},
+		{Key: graveler.Key("b/d/1"), Type: graveler.DiffTypeRemoved},
+		{Key: graveler.Key("b/d/2"), Type: graveler.DiffTypeRemoved},
+		{Key: graveler.Key("b/e"), Type: graveler.DiffTypeChanged},
+		{Key: graveler.Key("c/1"), Type: graveler.DiffTypeChanged},
+	}
+	cases := []struct {
+		name     string
+		params   graveler.DiffParams
+		seek     graveler.Key
+		expected []graveler.Diff
+	}{
+		{
+			name:     "no scope",
+			expected: diffs,
+		},
+		{
+			name:     "prefix",
+			params:   graveler.DiffParams{Prefix: graveler.Key("b/")},
+			expected: diffs[1:7],
+		},
+		{
+			name:     "prefix with seek",
+			params:   graveler.DiffParams{Prefix: graveler.Key("b/")},
+			seek:     graveler.Key("b/d"),
+			expected: diffs[4:7],
+		},
+		{
+			name:     "seek before prefix",
+			params:   graveler.DiffParams{Prefix: graveler.Key("b/")},
+			seek:     graveler.Key("a"),
+			expected: diffs[1:7],
+		},
+		{
+			name:   "delimiter",
+			params: graveler.DiffParams{Delimiter: graveler.Key("/")},
+			expected: []graveler.Diff{
+				{Key: graveler.Key("a/"), Type: graveler.DiffTypeAdded, CommonPrefix: &graveler.DiffSummary{Count: map[graveler.DiffType]int{graveler.DiffTypeAdded: 1}}},
+				{Key: graveler.Key("b/"), Type: graveler.DiffTypeChanged, CommonPrefix: &graveler.DiffSummary{Count: map[graveler.DiffType]int{
+					graveler.DiffTypeAdded:   2,
+					graveler.DiffTypeRemoved: 3,
+					graveler.DiffTypeChanged: 1,
+				}}},
+				{Key: graveler.Key("c/"), Type: graveler.DiffTypeChanged, CommonPrefix: &graveler.DiffSummary{Count: map[graveler.DiffType]int{graveler.DiffTypeChanged: 1}}},
+			},
+		},
+		{
+			name:   "prefix and delimiter",
+			params: graveler.DiffParams{Prefix: graveler.Key("b/"), Delimiter: graveler.Key("/")},
+			expected: []graveler.Diff{
+				{Key: graveler.Key("b/1"), Type: graveler.DiffTypeAdded},
+				{Key: graveler.Key("b/c/"), Type: graveler.DiffTypeChanged, CommonPrefix: &graveler.DiffSummary{Count: map[graveler.DiffType]int{
+					graveler.DiffTypeAdded:   1,
+					graveler.DiffTypeRemoved: 1,
+				}}},
+				{Key: graveler.Key("b/d/"), Type: graveler.DiffTypeRemoved, CommonPrefix: &graveler.DiffSummary{Count: map[graveler.DiffType]int{graveler.DiffTypeRemoved: 2}}},
+				{Key: graveler.Key("b/e"), Type: graveler.DiffTypeChanged},
+			},
+		},
+		{
+			name:   "seek into common prefix",
+			params: graveler.DiffParams{Prefix: graveler.Key("b/"), Delimiter: graveler.Key("/")},
+			seek:   graveler.Key("b/c/"),
+			expected: []graveler.Diff{
+				{Key: graveler.Key("b/d/"), Type: graveler.DiffTypeRemoved, CommonPrefix: &graveler.DiffSummary{Count: map[graveler.DiffType]int{graveler.DiffTypeRemoved: 2}}},
+				{Key: graveler.Key("b/e"), Type: graveler.DiffTypeChanged},
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			it := graveler.NewScopedDiffIterator(testutil.NewDiffIter(diffs), tt.params)
+			defer it.Close()
+			if tt.seek != nil {
+				it.SeekGE(tt.seek)
+			}
+			var got []graveler.Diff
+			for it.Next() {
+				got = append(got, *it.Value())
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := deep.Equal(got, tt.expected); diff != nil {
+				t.Errorf("unexpected diffs: %s", diff)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ type CommitIterator struct {
 	ctx          context.Context
 	repositoryID graveler.RepositoryID
 	start        graveler.CommitID
+	filter       graveler.CommitFilter
 	value        *graveler.CommitRecord
 	queue        commitsPriorityQueue
 	visit        map[graveler.CommitID]struct{}
@@ -58,12 +59,15 @@ func (c *commitsPriorityQueue) Pop() interface{} {
 	return item
 }
 
-func NewCommitIterator(ctx context.Context, db db.Database, repositoryID graveler.RepositoryID, start graveler.CommitID) *CommitIterator {
+// NewCommitIterator returns an iterator over the commits reachable from start that match filter,
+// newest first
+func NewCommitIterator(ctx context.Context, db db.Database, repositoryID graveler.RepositoryID, start graveler.CommitID, filter graveler.CommitFilter) *CommitIterator {
 	return &CommitIterator{
 		db:           db,
 		ctx:          ctx,
 		repositoryID: repositoryID,
 		start:        start,
+		filter:       filter,
 		queue:        make(commitsPriorityQueue, 0),
 		visit:        make(map[graveler.CommitID]struct{}),
 	}
@@ -99,29 +103,59 @@ func (ci *CommitIterator) Next() bool {
 		ci.queue.Push(rec)
 	}
 
-	// nothing in our queue - work is done
-	if ci.queue.Len() == 0 {
-		ci.value = nil
-		ci.state = commitIteratorStateDone
-		return false
-	}
+	for {
+		// nothing in our queue - work is done
+		if ci.queue.Len() == 0 {
+			ci.value = nil
+			ci.state = commitIteratorStateDone
+			return false
+		}
 
-	// as long as we have something in the queue we will
-	// set it as the current value and push the current commit's parents to the queue
-	ci.value = heap.Pop(&ci.queue).(*graveler.CommitRecord)
-	for _, p := range ci.value.Parents {
-		rec, err := ci.getCommitRecord(p)
-		if err != nil {
+		// as long as we have something in the queue we will
+		// set it as the current value and push the current commit's parents to the queue
+		ci.value = heap.Pop(&ci.queue).(*graveler.CommitRecord)
+		if ci.filter.Since != nil && ci.value.CreationDate.Before(*ci.filter.Since) {
+			// the queue pops the newest commit first, all the rest are older too
 			ci.value = nil
-			ci.err = err
+			ci.state = commitIteratorStateDone
 			return false
 		}
-		// skip commits we already visited
-		if _, visited := ci.visit[rec.CommitID]; visited {
-			continue
+		parents := ci.value.Parents
+		if ci.filter.FirstParent && len(parents) > 1 {
+			parents = parents[:1]
+		}
+		for _, p := range parents {
+			rec, err := ci.getCommitRecord(p)
+			if err != nil {
+				ci.value = nil
+				ci.err = err
+				return false
+			}
+			// skip commits we already visited
+			if _, visited := ci.visit[rec.CommitID]; visited {
+				continue
+			}
+			ci.visit[rec.CommitID] = struct{}{}
+			heap.Push(&ci.queue, rec)
+		}
+		if matchCommitFilter(ci.filter, ci.value.Commit) {
+			return true
+		}
+	}
+}
+
+// matchCommitFilter returns true if commit matches the committer, creation date and metadata of filter
+func matchCommitFilter(filter graveler.CommitFilter, commit *graveler.Commit) bool {
+	if filter.Committer != "" && commit.Committer != filter.Committer {
+		return false
+	}
+	if filter.Until != nil && commit.CreationDate.After(*filter.Until) {
+		return false
+	}
+	for k, v := range filter.Metadata {
+		if value, ok := commit.Metadata[k]; !ok || value != v {
+			return false
 		}
-		ci.visit[rec.CommitID] = struct{}{}
-		heap.Push(&ci.queue, rec)
 	}
 	return true
 }
@@ -3,6 +3,7 @@ package ref
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/db"
 	"github.com/treeverse/lakefs/pkg/graveler"
@@ -11,10 +12,17 @@ import (
 // NewOrderedCommitIterator returns an iterator over all commits in the given repository.
 // Ordering is based on the Commit ID value.
 func NewOrderedCommitIterator(ctx context.Context, database db.Database, repositoryID graveler.RepositoryID, prefetchSize int) (*OrderedCommitIterator, error) {
+	return NewOrderedCommitIteratorSince(ctx, database, repositoryID, time.Time{}, prefetchSize)
+}
+
+// NewOrderedCommitIteratorSince returns an iterator over the commits inserted into the given repository at or
+// after since.  Ordering is based on the Commit ID value.
+func NewOrderedCommitIteratorSince(ctx context.Context, database db.Database, repositoryID graveler.RepositoryID, since time.Time, prefetchSize int) (*OrderedCommitIterator, error) {
 	return &OrderedCommitIterator{
 		ctx:          ctx,
 		db:           database,
 		repositoryID: repositoryID,
+		since:        since,
 		prefetchSize: prefetchSize,
 		buf:          make([]*graveler.CommitRecord, 0, prefetchSize),
 	}, nil
@@ -24,6 +32,7 @@ type OrderedCommitIterator struct {
 	ctx          context.Context
 	db           db.Database
 	repositoryID graveler.RepositoryID
+	since        time.Time
 	prefetchSize int
 	buf          []*graveler.CommitRecord
 	err          error
@@ -70,8 +79,9 @@ func (iter *OrderedCommitIterator) maybeFetch() {
 			FROM graveler_commits
 			WHERE repository_id = $1
 			AND id `+offsetCondition+` $2
+			AND inserted_at >= $4
 			ORDER BY id ASC
-			LIMIT $3`, iter.repositoryID, iter.offset, iter.prefetchSize)
+			LIMIT $3`, iter.repositoryID, iter.offset, iter.prefetchSize, iter.since.UTC())
 	if err != nil {
 		iter.err = err
 		return
@@ -16,6 +16,7 @@ type commitRecord struct {
 	Parents      []string               `db:"parents"`
 	Metadata     map[string]string      `db:"metadata"`
 	Generation   int                    `db:"generation"`
+	Expired      bool                   `db:"expired"`
 }
 
 func (c *commitRecord) toGravelerCommit() *graveler.Commit {
@@ -32,6 +33,7 @@ func (c *commitRecord) toGravelerCommit() *graveler.Commit {
 		Parents:      parents,
 		Metadata:     c.Metadata,
 		Generation:   c.Generation,
+		Expired:      c.Expired,
 	}
 }
 
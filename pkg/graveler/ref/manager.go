@@ -10,6 +10,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/db"
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/ident"
+	"github.com/treeverse/lakefs/pkg/permissions"
 )
 
 // IteratorPrefetchSize is the amount of records to maybeFetch from PG
@@ -42,7 +43,7 @@ func (m *Manager) GetRepository(ctx context.Context, repositoryID graveler.Repos
 		return m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
 			repository := &graveler.Repository{}
 			err := tx.Get(repository,
-				`SELECT storage_namespace, creation_date, default_branch FROM graveler_repositories WHERE id = $1`,
+				`SELECT storage_namespace, creation_date, default_branch, description, metadata FROM graveler_repositories WHERE id = $1`,
 				repositoryID)
 			if err != nil {
 				return nil, err
@@ -142,6 +143,87 @@ func (m *Manager) DeleteRepository(ctx context.Context, repositoryID graveler.Re
 	return err
 }
 
+func (m *Manager) UpdateRepository(ctx context.Context, repositoryID graveler.RepositoryID, update graveler.RepositoryUpdate) error {
+	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		repository := &graveler.Repository{}
+		err := tx.Get(repository,
+			`SELECT storage_namespace, creation_date, default_branch, description, metadata FROM graveler_repositories WHERE id = $1 FOR UPDATE`,
+			repositoryID)
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, graveler.ErrRepositoryNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if update.DefaultBranchID != nil {
+			var exists bool
+			err = tx.GetPrimitive(&exists, `SELECT EXISTS(SELECT 1 FROM graveler_branches WHERE repository_id = $1 AND id = $2)`,
+				repositoryID, *update.DefaultBranchID)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, graveler.ErrBranchNotFound
+			}
+			repository.DefaultBranchID = *update.DefaultBranchID
+		}
+		if update.Description != nil {
+			repository.Description = *update.Description
+		}
+		if update.Metadata != nil {
+			repository.Metadata = update.Metadata
+		}
+		_, err = tx.Exec(`UPDATE graveler_repositories SET default_branch = $2, description = $3, metadata = $4 WHERE id = $1`,
+			repositoryID, repository.DefaultBranchID, repository.Description, repository.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if update.RepositoryID == nil || *update.RepositoryID == repositoryID {
+			return nil, nil
+		}
+		return nil, renameRepository(tx, repositoryID, *update.RepositoryID)
+	})
+	return err
+}
+
+func renameRepository(tx db.Tx, repositoryID, newRepositoryID graveler.RepositoryID) error {
+	// auth policies name repositories in their resources, renaming would leave them granting on the old name
+	repositoryArn := permissions.RepoArn(repositoryID.String())
+	var inPolicy bool
+	err := tx.GetPrimitive(&inPolicy, `SELECT EXISTS(SELECT 1 FROM auth_policies, jsonb_array_elements(statement) s
+		WHERE s->>'Resource' = $1 OR s->>'Resource' LIKE $2)`, repositoryArn, repositoryArn+"/%")
+	if err != nil {
+		return err
+	}
+	if inPolicy {
+		return graveler.ErrRepositoryInPolicy
+	}
+	_, err = tx.Exec(`UPDATE graveler_repositories SET id = $2 WHERE id = $1`, repositoryID, newRepositoryID)
+	if errors.Is(err, db.ErrAlreadyExists) {
+		return graveler.ErrNotUnique
+	}
+	if err != nil {
+		return err
+	}
+	// tables keyed by repository without a foreign key that follows the rename, including the action runs
+	// read by branch protection.  Tables are found by their repository_id column so that new ones are
+	// renamed too, updating those with a foreign key finds nothing left on the old name.
+	var tables []string
+	err = tx.Select(&tables, `SELECT table_name::text FROM information_schema.columns
+		WHERE table_schema = current_schema() AND column_name = 'repository_id' AND data_type = 'text'
+		ORDER BY table_name`)
+	if err != nil {
+		return fmt.Errorf("list repository tables: %w", err)
+	}
+	for _, table := range tables {
+		_, err = tx.Exec(`UPDATE `+table+` SET repository_id = $2 WHERE repository_id = $1`, repositoryID, newRepositoryID)
+		if err != nil {
+			return fmt.Errorf("rename repository on %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
 func (m *Manager) RevParse(ctx context.Context, repositoryID graveler.RepositoryID, ref graveler.Ref) (graveler.Reference, error) {
 	return ResolveRef(ctx, m, m.addressProvider, repositoryID, ref)
 }
@@ -233,11 +315,42 @@ func (m *Manager) GetTag(ctx context.Context, repositoryID graveler.RepositoryID
 	return commitID.(*graveler.CommitID), nil
 }
 
-func (m *Manager) CreateTag(ctx context.Context, repositoryID graveler.RepositoryID, tagID graveler.TagID, commitID graveler.CommitID) error {
+func (m *Manager) GetTagRecord(ctx context.Context, repositoryID graveler.RepositoryID, tagID graveler.TagID) (*graveler.TagRecord, error) {
+	tag, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		var tag tagRecord
+		err := tx.Get(&tag, `SELECT `+tagColumns+` FROM graveler_tags WHERE repository_id = $1 AND id = $2`,
+			repositoryID, tagID)
+		if err != nil {
+			return nil, err
+		}
+		return tag.toGravelerTagRecord(), nil
+	}, db.ReadOnly())
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, graveler.ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tag.(*graveler.TagRecord), nil
+}
+
+func (m *Manager) CreateTag(ctx context.Context, repositoryID graveler.RepositoryID, tagID graveler.TagID, commitID graveler.CommitID, annotation *graveler.TagAnnotation) error {
+	var (
+		message, creator string
+		creationDate     *time.Time
+		metadata         map[string]string
+	)
+	if annotation != nil {
+		message = annotation.Message
+		creator = annotation.Creator
+		creationDate = &annotation.CreationDate
+		metadata = annotation.Metadata
+	}
 	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
-		res, err := tx.Exec(`INSERT INTO graveler_tags (repository_id, id, commit_id) VALUES ($1, $2, $3)
+		res, err := tx.Exec(`INSERT INTO graveler_tags (repository_id, id, commit_id, message, creator, creation_date, metadata)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
 			ON CONFLICT DO NOTHING`,
-			repositoryID, tagID, commitID)
+			repositoryID, tagID, commitID, message, creator, creationDate, metadata)
 		if err != nil {
 			return nil, err
 		}
@@ -276,6 +389,160 @@ func (m *Manager) ListTags(ctx context.Context, repositoryID graveler.Repository
 	return NewTagIterator(ctx, m.db, repositoryID, IteratorPrefetchSize), nil
 }
 
+func (m *Manager) GetBranchProtectionRules(ctx context.Context, repositoryID graveler.RepositoryID) ([]*graveler.BranchProtectionRule, error) {
+	key := fmt.Sprintf("GetBranchProtectionRules:%s", repositoryID)
+	rules, err := m.batchExecutor.BatchFor(key, MaxBatchDelay, batch.BatchFn(func() (interface{}, error) {
+		return m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+			rules := make([]*graveler.BranchProtectionRule, 0)
+			err := tx.Select(&rules, `SELECT pattern, require_passing_run FROM graveler_branch_protection_rules
+				WHERE repository_id = $1 ORDER BY pattern`, repositoryID)
+			if err != nil {
+				return nil, err
+			}
+			return rules, nil
+		}, db.ReadOnly())
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return rules.([]*graveler.BranchProtectionRule), nil
+}
+
+func (m *Manager) CreateBranchProtectionRule(ctx context.Context, repositoryID graveler.RepositoryID, rule graveler.BranchProtectionRule) error {
+	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		res, err := tx.Exec(`INSERT INTO graveler_branch_protection_rules (repository_id, pattern, require_passing_run) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`,
+			repositoryID, rule.Pattern, rule.RequirePassingRun)
+		if err != nil {
+			return nil, err
+		}
+		if res.RowsAffected() == 0 {
+			return nil, graveler.ErrBranchProtectionRuleExists
+		}
+		return nil, nil
+	})
+	return err
+}
+
+func (m *Manager) DeleteBranchProtectionRule(ctx context.Context, repositoryID graveler.RepositoryID, pattern string) error {
+	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		r, err := tx.Exec(
+			`DELETE FROM graveler_branch_protection_rules WHERE repository_id = $1 AND pattern = $2`,
+			repositoryID, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if r.RowsAffected() == 0 {
+			return nil, graveler.ErrNotFound
+		}
+		return nil, nil
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		return graveler.ErrBranchProtectionRuleNotFound
+	}
+	return err
+}
+
+func (m *Manager) GetRetentionRules(ctx context.Context, repositoryID graveler.RepositoryID) ([]*graveler.RetentionRule, error) {
+	rules, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		rules := make([]*graveler.RetentionRule, 0)
+		err := tx.Select(&rules, `SELECT branch_pattern, retention_days FROM graveler_retention_rules
+			WHERE repository_id = $1 ORDER BY position`, repositoryID)
+		if err != nil {
+			return nil, err
+		}
+		return rules, nil
+	}, db.ReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return rules.([]*graveler.RetentionRule), nil
+}
+
+func (m *Manager) SetRetentionRules(ctx context.Context, repositoryID graveler.RepositoryID, rules []*graveler.RetentionRule) error {
+	patterns := make([]string, len(rules))
+	days := make([]int, len(rules))
+	for i, rule := range rules {
+		patterns[i] = rule.BranchPattern
+		days[i] = rule.RetentionDays
+	}
+	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		if _, err := tx.Exec(`DELETE FROM graveler_retention_rules WHERE repository_id = $1`, repositoryID); err != nil {
+			return nil, err
+		}
+		if len(rules) == 0 {
+			return nil, nil
+		}
+		// position is the (1-based) index of each rule, its order of matching
+		_, err := tx.Exec(`INSERT INTO graveler_retention_rules (repository_id, position, branch_pattern, retention_days)
+			SELECT $1, r.position, r.branch_pattern, r.retention_days
+			FROM UNNEST($2::text[], $3::integer[]) WITH ORDINALITY AS r(branch_pattern, retention_days, position)`,
+			repositoryID, patterns, days)
+		return nil, err
+	})
+	return err
+}
+
+func (m *Manager) SetExpiredCommits(ctx context.Context, repositoryID graveler.RepositoryID, commitIDs []graveler.CommitID) error {
+	ids := make([]string, len(commitIDs))
+	for i, commitID := range commitIDs {
+		ids[i] = commitID.String()
+	}
+	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		// commits reachable again (e.g. by a new branch) are no longer expired
+		return tx.Exec(`UPDATE graveler_commits SET expired = (id = ANY($2))
+			WHERE repository_id = $1 AND expired <> (id = ANY($2))`,
+			repositoryID, ids)
+	})
+	return err
+}
+
+func (m *Manager) AddStorageNamespaceReferences(ctx context.Context, repositoryID graveler.RepositoryID, storageNamespaces []graveler.StorageNamespace) error {
+	namespaces := make([]string, len(storageNamespaces))
+	for i, storageNamespace := range storageNamespaces {
+		namespaces[i] = storageNamespace.String()
+	}
+	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		return tx.Exec(`INSERT INTO graveler_storage_namespace_references (repository_id, storage_namespace)
+			SELECT $1, UNNEST($2::text[])
+			ON CONFLICT DO NOTHING`,
+			repositoryID, namespaces)
+	})
+	return err
+}
+
+func (m *Manager) ListStorageNamespaceReferences(ctx context.Context, repositoryID graveler.RepositoryID) ([]graveler.StorageNamespace, error) {
+	namespaces, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		namespaces := make([]graveler.StorageNamespace, 0)
+		err := tx.Select(&namespaces, `SELECT storage_namespace FROM graveler_storage_namespace_references
+			WHERE repository_id = $1 ORDER BY storage_namespace`, repositoryID)
+		if err != nil {
+			return nil, err
+		}
+		return namespaces, nil
+	}, db.ReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return namespaces.([]graveler.StorageNamespace), nil
+}
+
+func (m *Manager) ListReferencingRepositories(ctx context.Context, storageNamespace graveler.StorageNamespace) ([]graveler.RepositoryID, error) {
+	repositories, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		repositories := make([]graveler.RepositoryID, 0)
+		err := tx.Select(&repositories, `SELECT repository_id FROM graveler_storage_namespace_references
+			WHERE storage_namespace = $1 ORDER BY repository_id`, storageNamespace)
+		if err != nil {
+			return nil, err
+		}
+		return repositories, nil
+	}, db.ReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	return repositories.([]graveler.RepositoryID), nil
+}
+
 func (m *Manager) GetCommitByPrefix(ctx context.Context, repositoryID graveler.RepositoryID, prefix graveler.CommitID) (*graveler.Commit, error) {
 	key := fmt.Sprintf("GetCommitByPrefix:%s:%s", repositoryID, prefix)
 
@@ -285,7 +552,7 @@ func (m *Manager) GetCommitByPrefix(ctx context.Context, repositoryID graveler.R
 			// LIMIT 2 is used to test if a truncated commit ID resolves to *one* commit.
 			// if we get 2 results that start with the truncated ID, that's enough to determine this prefix is not unique
 			err := tx.Select(&records, `
-					SELECT id, committer, message, creation_date, parents, meta_range_id, metadata, version, generation
+					SELECT id, committer, message, creation_date, parents, meta_range_id, metadata, version, generation, expired
 					FROM graveler_commits
 					WHERE repository_id = $1 AND id LIKE $2 || '%'
 					LIMIT 2`,
@@ -320,7 +587,7 @@ func (m *Manager) GetCommit(ctx context.Context, repositoryID graveler.Repositor
 		return m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
 			var rec commitRecord
 			err := tx.Get(&rec, `
-					SELECT committer, message, creation_date, parents, meta_range_id, metadata, version, generation
+					SELECT committer, message, creation_date, parents, meta_range_id, metadata, version, generation, expired
 					FROM graveler_commits WHERE repository_id = $1 AND id = $2`,
 				repositoryID, commitID)
 			if err != nil {
@@ -377,12 +644,12 @@ func (m *Manager) FindMergeBase(ctx context.Context, repositoryID graveler.Repos
 	return FindMergeBase(ctx, m, repositoryID, commitIDs[0], commitIDs[1])
 }
 
-func (m *Manager) Log(ctx context.Context, repositoryID graveler.RepositoryID, from graveler.CommitID) (graveler.CommitIterator, error) {
+func (m *Manager) Log(ctx context.Context, repositoryID graveler.RepositoryID, from graveler.CommitID, filter graveler.CommitFilter) (graveler.CommitIterator, error) {
 	_, err := m.GetRepository(ctx, repositoryID)
 	if err != nil {
 		return nil, err
 	}
-	return NewCommitIterator(ctx, m.db, repositoryID, from), nil
+	return NewCommitIterator(ctx, m.db, repositoryID, from, filter), nil
 }
 
 func (m *Manager) ListCommits(ctx context.Context, repositoryID graveler.RepositoryID) (graveler.CommitIterator, error) {
@@ -393,6 +660,14 @@ func (m *Manager) ListCommits(ctx context.Context, repositoryID graveler.Reposit
 	return NewOrderedCommitIterator(ctx, m.db, repositoryID, IteratorPrefetchSize)
 }
 
+func (m *Manager) ListCommitsSince(ctx context.Context, repositoryID graveler.RepositoryID, since time.Time) (graveler.CommitIterator, error) {
+	_, err := m.GetRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	return NewOrderedCommitIteratorSince(ctx, m.db, repositoryID, since, IteratorPrefetchSize)
+}
+
 func (m *Manager) FillGenerations(ctx context.Context, repositoryID graveler.RepositoryID) error {
 	_, err := m.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
 		return tx.Exec(`WITH RECURSIVE cte AS
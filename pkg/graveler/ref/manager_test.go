@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-test/deep"
 	"github.com/stretchr/testify/assert"
+	"github.com/treeverse/lakefs/pkg/actions"
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/ident"
 	"github.com/treeverse/lakefs/pkg/testutil"
@@ -152,6 +153,137 @@ func TestManager_DeleteRepository(t *testing.T) {
 	})
 }
 
+func TestManager_UpdateRepository(t *testing.T) {
+	r := testRefManager(t)
+	ctx := context.Background()
+	testutil.Must(t, r.CreateRepository(ctx, "example-repo", graveler.Repository{
+		StorageNamespace: "s3://foo",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "master",
+	}, ""))
+	testutil.Must(t, r.SetBranch(ctx, "example-repo", "main", graveler.Branch{CommitID: "c1", StagingToken: "s1"}))
+
+	t.Run("settings", func(t *testing.T) {
+		description := "curated datasets"
+		defaultBranchID := graveler.BranchID("main")
+		err := r.UpdateRepository(ctx, "example-repo", graveler.RepositoryUpdate{
+			DefaultBranchID: &defaultBranchID,
+			Description:     &description,
+			Metadata:        graveler.Metadata{"team": "data"},
+		})
+		testutil.MustDo(t, "update repository", err)
+		repo, err := r.GetRepository(ctx, "example-repo")
+		testutil.MustDo(t, "get repository", err)
+		if repo.DefaultBranchID != defaultBranchID || repo.Description != description {
+			t.Errorf("got default branch %s, description %s, expected %s, %s", repo.DefaultBranchID, repo.Description, defaultBranchID, description)
+		}
+		if diff := deep.Equal(repo.Metadata, graveler.Metadata{"team": "data"}); diff != nil {
+			t.Errorf("unexpected metadata: %s", diff)
+		}
+	})
+
+	t.Run("missing default branch", func(t *testing.T) {
+		defaultBranchID := graveler.BranchID("no-such-branch")
+		err := r.UpdateRepository(ctx, "example-repo", graveler.RepositoryUpdate{DefaultBranchID: &defaultBranchID})
+		if !errors.Is(err, graveler.ErrBranchNotFound) {
+			t.Fatalf("expected ErrBranchNotFound, got: %v", err)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		newRepositoryID := graveler.RepositoryID("renamed-repo")
+		err := r.UpdateRepository(ctx, "example-repo", graveler.RepositoryUpdate{RepositoryID: &newRepositoryID})
+		testutil.MustDo(t, "rename repository", err)
+		_, err = r.GetRepository(ctx, "example-repo")
+		if !errors.Is(err, graveler.ErrRepositoryNotFound) {
+			t.Fatalf("expected ErrRepositoryNotFound for old name, got: %v", err)
+		}
+		repo, err := r.GetRepository(ctx, newRepositoryID)
+		testutil.MustDo(t, "get renamed repository", err)
+		if repo.Description != "curated datasets" {
+			t.Errorf("renamed repository lost its description: %s", repo.Description)
+		}
+		branch, err := r.GetBranch(ctx, newRepositoryID, "main")
+		testutil.MustDo(t, "get branch of renamed repository", err)
+		if branch.CommitID != "c1" {
+			t.Errorf("got commit %s on renamed repository branch, expected c1", branch.CommitID)
+		}
+	})
+
+	t.Run("repo_does_not_exist", func(t *testing.T) {
+		err := r.UpdateRepository(ctx, "example-repo11111", graveler.RepositoryUpdate{})
+		if !errors.Is(err, graveler.ErrRepositoryNotFound) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestManager_RenameRepositoryWithRuns(t *testing.T) {
+	r, conn := testRefManagerWithDB(t)
+	ctx := context.Background()
+	testutil.Must(t, r.CreateRepository(ctx, "repo-with-runs", graveler.Repository{
+		StorageNamespace: "s3://foo",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	}, ""))
+	now := time.Now()
+	_, err := conn.Exec(ctx, `INSERT INTO actions_runs (repository_id, run_id, event_type, start_time, end_time, branch_id, source_ref, commit_id, passed)
+		VALUES ($1, 'run1', 'pre-merge', $2, $2, 'main', 'main', 'c1', true)`, "repo-with-runs", now)
+	testutil.MustDo(t, "insert run", err)
+	_, err = conn.Exec(ctx, `INSERT INTO actions_run_hooks (repository_id, run_id, hook_run_id, action_name, hook_id, start_time, end_time, passed)
+		VALUES ($1, 'run1', 'hook1', 'action', 'hook', $2, $2, true)`, "repo-with-runs", now)
+	testutil.MustDo(t, "insert run hook", err)
+
+	newRepositoryID := graveler.RepositoryID("renamed-repo-with-runs")
+	err = r.UpdateRepository(ctx, "repo-with-runs", graveler.RepositoryUpdate{RepositoryID: &newRepositoryID})
+	testutil.MustDo(t, "rename repository", err)
+
+	passed, err := actions.NewService(conn, nil, nil).LatestRunPassed(ctx, newRepositoryID, "main")
+	testutil.MustDo(t, "latest run of renamed repository", err)
+	if !passed {
+		t.Error("latest run of renamed repository did not pass")
+	}
+	var hooks int
+	err = conn.GetPrimitive(ctx, &hooks, `SELECT COUNT(*) FROM actions_run_hooks WHERE repository_id = $1`, newRepositoryID)
+	testutil.MustDo(t, "count run hooks of renamed repository", err)
+	if hooks != 1 {
+		t.Errorf("got %d run hooks on renamed repository, expected 1", hooks)
+	}
+}
+
+func TestManager_RenameRepositoryInPolicy(t *testing.T) {
+	r, conn := testRefManagerWithDB(t)
+	ctx := context.Background()
+	for _, repositoryID := range []graveler.RepositoryID{"repo-in", "repo-in-policy"} {
+		testutil.Must(t, r.CreateRepository(ctx, repositoryID, graveler.Repository{
+			StorageNamespace: "s3://foo",
+			CreationDate:     time.Now(),
+			DefaultBranchID:  "main",
+		}, ""))
+	}
+	_, err := conn.Exec(ctx, `INSERT INTO auth_policies (created_at, display_name, statement) VALUES (NOW(), 'ReadRepoInPolicy', $1)`,
+		`[{"Action": ["fs:ReadObject"], "Effect": "allow", "Resource": "arn:lakefs:fs:::repository/repo-in-policy/object/*"}]`)
+	testutil.MustDo(t, "insert policy", err)
+
+	newRepositoryID := graveler.RepositoryID("renamed-repo-in-policy")
+	err = r.UpdateRepository(ctx, "repo-in-policy", graveler.RepositoryUpdate{RepositoryID: &newRepositoryID})
+	if !errors.Is(err, graveler.ErrRepositoryInPolicy) {
+		t.Fatalf("expected ErrRepositoryInPolicy, got: %v", err)
+	}
+	_, err = r.GetRepository(ctx, "repo-in-policy")
+	testutil.MustDo(t, "get repository named by policy", err)
+
+	// a policy naming a repository that shares the name prefix does not prevent the rename
+	renamedRepositoryID := graveler.RepositoryID("renamed-repo-in")
+	err = r.UpdateRepository(ctx, "repo-in", graveler.RepositoryUpdate{RepositoryID: &renamedRepositoryID})
+	testutil.MustDo(t, "rename repository not named by policy", err)
+
+	_, err = conn.Exec(ctx, `DELETE FROM auth_policies WHERE display_name = 'ReadRepoInPolicy'`)
+	testutil.MustDo(t, "delete policy", err)
+	err = r.UpdateRepository(ctx, "repo-in-policy", graveler.RepositoryUpdate{RepositoryID: &newRepositoryID})
+	testutil.MustDo(t, "rename repository after policy deleted", err)
+}
+
 func TestManager_GetBranch(t *testing.T) {
 	r := testRefManager(t)
 	t.Run("get_branch_exists", func(t *testing.T) {
@@ -277,7 +409,7 @@ func TestManager_GetTag(t *testing.T) {
 			DefaultBranchID:  "main",
 		}, "")
 		testutil.MustDo(t, "create repo", err)
-		err = r.CreateTag(ctx, "repo1", "v1.0", "c1")
+		err = r.CreateTag(ctx, "repo1", "v1.0", "c1", nil)
 		testutil.MustDo(t, "set tag", err)
 		commitID, err := r.GetTag(context.Background(), "repo1", "v1.0")
 		testutil.MustDo(t, "get existing tag", err)
@@ -309,7 +441,7 @@ func TestManager_CreateTag(t *testing.T) {
 		DefaultBranchID:  "main",
 	}, ""))
 
-	err := r.CreateTag(ctx, "repo1", "v2", "c2")
+	err := r.CreateTag(ctx, "repo1", "v2", "c2", nil)
 	testutil.MustDo(t, "create tag v2", err)
 
 	commit, err := r.GetTag(ctx, "repo1", "v2")
@@ -322,7 +454,7 @@ func TestManager_CreateTag(t *testing.T) {
 	}
 
 	// check we can't create existing
-	err = r.CreateTag(ctx, "repo1", "v2", "c5")
+	err = r.CreateTag(ctx, "repo1", "v2", "c5", nil)
 	if !errors.Is(err, graveler.ErrTagAlreadyExists) {
 		t.Fatalf("CreateTag() err = %s, expected already exists", err)
 	}
@@ -330,7 +462,7 @@ func TestManager_CreateTag(t *testing.T) {
 	err = r.DeleteTag(ctx, "repo1", "v2")
 	testutil.MustDo(t, "delete tag v2", err)
 
-	err = r.CreateTag(ctx, "repo1", "v2", "c3")
+	err = r.CreateTag(ctx, "repo1", "v2", "c3", nil)
 	testutil.MustDo(t, "re-create tag v2", err)
 
 	commit, err = r.GetTag(ctx, "repo1", "v2")
@@ -343,6 +475,40 @@ func TestManager_CreateTag(t *testing.T) {
 	}
 }
 
+func TestManager_CreateAnnotatedTag(t *testing.T) {
+	r := testRefManager(t)
+	ctx := context.Background()
+	testutil.Must(t, r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	}, ""))
+
+	annotation := &graveler.TagAnnotation{
+		Message:      "release",
+		Creator:      "tagger",
+		CreationDate: time.Now().UTC().Truncate(time.Second),
+		Metadata:     graveler.Metadata{"dataset": "v1"},
+	}
+	testutil.Must(t, r.CreateTag(ctx, "repo1", "v1", "c1", annotation))
+	testutil.Must(t, r.CreateTag(ctx, "repo1", "v2", "c2", nil))
+
+	tag, err := r.GetTagRecord(ctx, "repo1", "v1")
+	testutil.MustDo(t, "get tag record v1", err)
+	expected := &graveler.TagRecord{TagID: "v1", CommitID: "c1", Annotation: annotation}
+	if diff := deep.Equal(tag, expected); diff != nil {
+		t.Errorf("unexpected annotated tag record %s", diff)
+	}
+	tag, err = r.GetTagRecord(ctx, "repo1", "v2")
+	testutil.MustDo(t, "get tag record v2", err)
+	if diff := deep.Equal(tag, &graveler.TagRecord{TagID: "v2", CommitID: "c2"}); diff != nil {
+		t.Errorf("unexpected lightweight tag record %s", diff)
+	}
+	if _, err := r.GetTagRecord(ctx, "repo1", "v3"); !errors.Is(err, graveler.ErrTagNotFound) {
+		t.Errorf("GetTagRecord() err = %v, expected %s", err, graveler.ErrTagNotFound)
+	}
+}
+
 func TestManager_DeleteTag(t *testing.T) {
 	r := testRefManager(t)
 	ctx := context.Background()
@@ -352,7 +518,7 @@ func TestManager_DeleteTag(t *testing.T) {
 		DefaultBranchID:  "main",
 	}, ""))
 
-	testutil.Must(t, r.CreateTag(ctx, "repo1", "v1", "c2"))
+	testutil.Must(t, r.CreateTag(ctx, "repo1", "v1", "c2", nil))
 
 	testutil.Must(t, r.DeleteTag(ctx, "repo1", "v1"))
 
@@ -380,7 +546,7 @@ func TestManager_ListTags(t *testing.T) {
 	for i, tag := range tags {
 		commitID := graveler.CommitID(fmt.Sprintf("c%d", i))
 		commitsTagged = append(commitsTagged, commitID)
-		err := r.CreateTag(ctx, "repo1", graveler.TagID(tag), commitID)
+		err := r.CreateTag(ctx, "repo1", graveler.TagID(tag), commitID, nil)
 		testutil.MustDo(t, "set tag "+tag, err)
 	}
 
@@ -442,6 +608,47 @@ func TestManager_AddCommit(t *testing.T) {
 	}
 }
 
+func TestManager_ListCommitsSince(t *testing.T) {
+	r := testRefManager(t)
+	ctx := context.Background()
+	testutil.Must(t, r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	}, ""))
+	// the creation date of a commit does not matter, only when it was added
+	ts, _ := time.Parse(time.RFC3339, "2020-12-01T15:00:00Z")
+	cid, err := r.AddCommit(ctx, "repo1", graveler.Commit{
+		Committer:    "user1",
+		Message:      "message1",
+		MetaRangeID:  "deadbeef123",
+		CreationDate: ts,
+		Parents:      graveler.CommitParents{"deadbeef1"},
+	})
+	testutil.MustDo(t, "add commit", err)
+
+	listCommitIDs := func(since time.Time) []graveler.CommitID {
+		it, err := r.ListCommitsSince(ctx, "repo1", since)
+		testutil.MustDo(t, "list commits since", err)
+		defer it.Close()
+		var ids []graveler.CommitID
+		for it.Next() {
+			ids = append(ids, it.Value().CommitID)
+		}
+		testutil.MustDo(t, "iterate commits since", it.Err())
+		return ids
+	}
+
+	// both the first commit of the repository and the added commit
+	const expectedCommits = 2
+	if ids := listCommitIDs(time.Now().Add(-time.Hour)); len(ids) != expectedCommits {
+		t.Errorf("got commits %v since an hour ago, expected %d commits including %s", ids, expectedCommits, cid)
+	}
+	if ids := listCommitIDs(time.Now().Add(time.Hour)); len(ids) != 0 {
+		t.Errorf("got commits %v since an hour from now, expected none", ids)
+	}
+}
+
 func TestManager_Log(t *testing.T) {
 	r := testRefManager(t)
 	testutil.Must(t, r.CreateRepository(context.Background(), "repo1", graveler.Repository{
@@ -472,7 +679,7 @@ func TestManager_Log(t *testing.T) {
 		ts = ts.Add(time.Second)
 	}
 
-	iter, err := r.Log(context.Background(), "repo1", previous)
+	iter, err := r.Log(context.Background(), "repo1", previous, graveler.CommitFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -560,7 +767,7 @@ func TestManager_LogGraph(t *testing.T) {
 	}
 
 	// iterate over the commits
-	it, err := r.Log(ctx, "repo1", c8)
+	it, err := r.Log(ctx, "repo1", c8, graveler.CommitFilter{})
 	if err != nil {
 		t.Fatal("Error during create Log iterator", err)
 	}
@@ -596,6 +803,115 @@ func TestManager_LogGraph(t *testing.T) {
 	}
 }
 
+func TestManager_LogFilter(t *testing.T) {
+	r := testRefManager(t)
+	ctx := context.Background()
+	err := r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	}, "")
+	testutil.MustDo(t, "Create repository", err)
+
+	/*
+		---1----2----4----7
+		    \	           \
+			 3----5----6----8---
+	*/
+	nextCommitNumber := 0
+	nextCommitTS, _ := time.Parse(time.RFC3339, "2020-12-01T15:00:00Z")
+	addNextCommit := func(committer string, metadata graveler.Metadata, parents ...graveler.CommitID) graveler.CommitID {
+		nextCommitTS = nextCommitTS.Add(time.Minute)
+		nextCommitNumber++
+		id := "c" + strconv.Itoa(nextCommitNumber)
+		c := graveler.Commit{
+			Committer:    committer,
+			Message:      id,
+			MetaRangeID:  "fefe1221",
+			CreationDate: nextCommitTS,
+			Parents:      parents,
+			Metadata:     metadata,
+		}
+		cid, err := r.AddCommit(ctx, "repo1", c)
+		testutil.MustDo(t, "Add commit "+id, err)
+		return cid
+	}
+	c1 := addNextCommit("user1", graveler.Metadata{"job_id": "1"})
+	c2 := addNextCommit("user1", graveler.Metadata{"job_id": "2"}, c1)
+	c3 := addNextCommit("user2", graveler.Metadata{"job_id": "3", "kind": "ingest"}, c1)
+	c4 := addNextCommit("user1", graveler.Metadata{"job_id": "4"}, c2)
+	c5 := addNextCommit("user2", graveler.Metadata{"job_id": "5", "kind": "ingest"}, c3)
+	c6 := addNextCommit("user2", graveler.Metadata{"job_id": "6", "kind": "ingest"}, c5)
+	c7 := addNextCommit("user1", graveler.Metadata{"job_id": "7"}, c4)
+	c8 := addNextCommit("user1", graveler.Metadata{"job_id": "8"}, c6, c7)
+
+	timeOf := func(minutes int) *time.Time {
+		ts, _ := time.Parse(time.RFC3339, "2020-12-01T15:00:00Z")
+		ts = ts.Add(time.Duration(minutes) * time.Minute)
+		return &ts
+	}
+	tests := []struct {
+		name     string
+		filter   graveler.CommitFilter
+		expected []string
+	}{
+		{
+			name:     "first_parent",
+			filter:   graveler.CommitFilter{FirstParent: true},
+			expected: []string{"c8", "c6", "c5", "c3", "c1"},
+		},
+		{
+			name:     "committer",
+			filter:   graveler.CommitFilter{Committer: "user2"},
+			expected: []string{"c6", "c5", "c3"},
+		},
+		{
+			name:     "since",
+			filter:   graveler.CommitFilter{Since: timeOf(5)},
+			expected: []string{"c8", "c7", "c6", "c5"},
+		},
+		{
+			name:     "until",
+			filter:   graveler.CommitFilter{Until: timeOf(4)},
+			expected: []string{"c4", "c3", "c2", "c1"},
+		},
+		{
+			name:     "range",
+			filter:   graveler.CommitFilter{Since: timeOf(3), Until: timeOf(6)},
+			expected: []string{"c6", "c5", "c4", "c3"},
+		},
+		{
+			name:     "metadata",
+			filter:   graveler.CommitFilter{Metadata: graveler.Metadata{"job_id": "5"}},
+			expected: []string{"c5"},
+		},
+		{
+			name:     "metadata_and_first_parent",
+			filter:   graveler.CommitFilter{FirstParent: true, Metadata: graveler.Metadata{"kind": "ingest"}},
+			expected: []string{"c6", "c5", "c3"},
+		},
+		{
+			name:   "no_match",
+			filter: graveler.CommitFilter{Committer: "user3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it, err := r.Log(ctx, "repo1", c8, tt.filter)
+			testutil.MustDo(t, "Log", err)
+			defer it.Close()
+			var commits []string
+			for it.Next() {
+				commits = append(commits, it.Value().Message)
+			}
+			testutil.MustDo(t, "Log iteration", it.Err())
+			if diff := deep.Equal(commits, tt.expected); diff != nil {
+				t.Fatal("Found diff between expected commits:", diff)
+			}
+		})
+	}
+}
+
 type fakeAddressProvider struct {
 	identities []string
 	idx        int
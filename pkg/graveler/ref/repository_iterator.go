@@ -61,7 +61,7 @@ func (ri *RepositoryIterator) maybeFetch() {
 		offsetCondition = iteratorOffsetCondition(false)
 	}
 	ri.err = ri.db.Select(ri.ctx, &ri.buf, `
-			SELECT id, storage_namespace, creation_date, default_branch
+			SELECT id, storage_namespace, creation_date, default_branch, description, metadata
 			FROM graveler_repositories
 			WHERE id `+offsetCondition+` $1
 			ORDER BY id ASC
@@ -3,6 +3,7 @@ package ref
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/ident"
@@ -52,7 +53,7 @@ func revResolve(ctx context.Context, store Store, addressProvider ident.AddressP
 
 func ResolveRef(ctx context.Context, store Store, addressProvider ident.AddressProvider, repositoryID graveler.RepositoryID, ref graveler.Ref) (graveler.Reference, error) {
 	// first we need to parse-rev to get a list references
-	// valid revs: branch, tag, commit ID, commit ID prefix (as long as unambiguous)
+	// valid revs: branch, tag, commit ID, commit ID prefix (as long as unambiguous), any of them @{time}
 	// valid modifiers: ~N
 	parsed, err := RevParse(ref)
 	if err != nil {
@@ -63,11 +64,17 @@ func ResolveRef(ctx context.Context, store Store, addressProvider ident.AddressP
 	if err != nil {
 		return nil, err
 	}
-	// return the matched reference, when no modifiers on ref or use the commit id as base
-	if len(parsed.Modifiers) == 0 {
+	// return the matched reference, when no time or modifiers on ref or use the commit id as base
+	if parsed.AtTime == nil && len(parsed.Modifiers) == 0 {
 		return rr, nil
 	}
 	baseCommit := rr.CommitID()
+	if parsed.AtTime != nil {
+		baseCommit, err = resolveAtTime(ctx, store, repositoryID, baseCommit, *parsed.AtTime)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	for _, mod := range parsed.Modifiers {
 		// lastly, apply modifier
@@ -111,6 +118,24 @@ func ResolveRef(ctx context.Context, store Store, addressProvider ident.AddressP
 	}, nil
 }
 
+// resolveAtTime returns the last commit on the first-parent history of commitID created at or before t
+func resolveAtTime(ctx context.Context, store Store, repositoryID graveler.RepositoryID, commitID graveler.CommitID, t time.Time) (graveler.CommitID, error) {
+	for commitID != "" {
+		commit, err := store.GetCommit(ctx, repositoryID, commitID)
+		if err != nil {
+			return "", err
+		}
+		if !commit.CreationDate.After(t) {
+			return commitID, nil
+		}
+		if len(commit.Parents) == 0 {
+			break
+		}
+		commitID = commit.Parents[0]
+	}
+	return "", graveler.ErrNotFound
+}
+
 func revResolveAHash(ctx context.Context, store Store, addressProvider ident.AddressProvider, repositoryID graveler.RepositoryID, rev string) (graveler.Reference, error) {
 	if !isAHash(rev) {
 		return nil, nil
@@ -44,7 +44,7 @@ func TestResolveRef_Dereference(t *testing.T) {
 		ts.Add(time.Minute)
 	}
 
-	iter, err := r.Log(ctx, "repo1", previous)
+	iter, err := r.Log(ctx, "repo1", previous, graveler.CommitFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -99,7 +99,7 @@ func TestResolveRef_Dereference(t *testing.T) {
 	}))
 
 	tagCommitID := commitLog[9]
-	testutil.Must(t, r.CreateTag(ctx, "repo1", "v1.0", tagCommitID))
+	testutil.Must(t, r.CreateTag(ctx, "repo1", "v1.0", tagCommitID, nil))
 
 	commitCommitID := commitLog[11]
 
@@ -218,7 +218,7 @@ func TestResolveRef_SameDate(t *testing.T) {
 	c4 := addCommit("c4", c3)
 	c5 := addCommit("c5", c4, c2)
 
-	it, err := r.Log(ctx, "repo1", c5)
+	it, err := r.Log(ctx, "repo1", c5, graveler.CommitFilter{})
 	testutil.MustDo(t, "Log request", err)
 	var commitIDs []graveler.CommitID
 	for it.Next() {
@@ -333,3 +333,70 @@ func TestResolveRef_DereferenceWithGraph(t *testing.T) {
 	resolve(B, "^3^2", J)
 	resolve(A, "^^3^2", J)
 }
+
+func TestResolveRef_AtTime(t *testing.T) {
+	r := testRefManager(t)
+	ctx := context.Background()
+	testutil.Must(t, r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	}, ""))
+
+	ts, _ := time.Parse(time.RFC3339, "2020-12-01T15:00:00Z")
+	addCommit := func(creationDate time.Time, parents ...graveler.CommitID) graveler.CommitID {
+		commit := graveler.Commit{
+			Committer:    "tester",
+			MetaRangeID:  "deadbeef1",
+			CreationDate: creationDate,
+		}
+		for _, p := range parents {
+			commit.Parents = append(commit.Parents, p)
+		}
+		cid, err := r.AddCommit(ctx, "repo1", commit)
+		testutil.MustDo(t, "add commit", err)
+		return cid
+	}
+	c1 := addCommit(ts)
+	c2 := addCommit(ts.Add(time.Hour), c1)
+	side := addCommit(ts.Add(3*time.Hour), c1)
+	c3 := addCommit(ts.Add(2*time.Hour), c2, side)
+	c4 := addCommit(time.Now().Add(-time.Hour), c3)
+	testutil.Must(t, r.SetBranch(ctx, "repo1", "main", graveler.Branch{CommitID: c4, StagingToken: "st1"}))
+
+	table := []struct {
+		Name        string
+		Ref         string
+		Expected    graveler.CommitID
+		ExpectedErr error
+	}{
+		{Name: "exact", Ref: "main@{2020-12-01T16:00:00Z}", Expected: c2},
+		{Name: "between", Ref: "main@{2020-12-01T16:30:00Z}", Expected: c2},
+		{Name: "first_parent_only", Ref: "main@{2020-12-01T18:30:00Z}", Expected: c3},
+		{Name: "date", Ref: "main@{2020-12-02}", Expected: c3},
+		{Name: "relative", Ref: "main@{1.day.ago}", Expected: c3},
+		{Name: "relative_head", Ref: "main@{30.minutes.ago}", Expected: c4},
+		{Name: "with_modifier", Ref: "main@{2020-12-01T17:00:00Z}~1", Expected: c2},
+		{Name: "commit", Ref: string(c3) + "@{2020-12-01T15:30:00Z}", Expected: c1},
+		{Name: "before_history", Ref: "main@{2020-11-01}", ExpectedErr: graveler.ErrNotFound},
+		{Name: "invalid_time", Ref: "main@{yesterday}", ExpectedErr: graveler.ErrInvalidRef},
+	}
+	for _, tt := range table {
+		t.Run(tt.Name, func(t *testing.T) {
+			resolved, err := ref.ResolveRef(ctx, r, ident.NewHexAddressProvider(), "repo1", graveler.Ref(tt.Ref))
+			if tt.ExpectedErr != nil {
+				if !errors.Is(err, tt.ExpectedErr) {
+					t.Fatalf("ResolveRef(%s) err=%v, expected %v", tt.Ref, err, tt.ExpectedErr)
+				}
+				return
+			}
+			testutil.MustDo(t, "resolve ref", err)
+			if resolved.CommitID() != tt.Expected {
+				t.Fatalf("ResolveRef(%s) got %s, expected %s", tt.Ref, resolved.CommitID(), tt.Expected)
+			}
+			if resolved.Type() != graveler.ReferenceTypeCommit {
+				t.Fatalf("ResolveRef(%s) type %d, expected commit", tt.Ref, resolved.Type())
+			}
+		})
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 )
 
 var (
-	hashRegexp      = regexp.MustCompile("^[a-fA-F0-9]{1,64}$")
-	modifiersRegexp = regexp.MustCompile("(^|[~^])[^^~]*")
+	hashRegexp         = regexp.MustCompile("^[a-fA-F0-9]{1,64}$")
+	modifiersRegexp    = regexp.MustCompile("(^|[~^])[^^~]*")
+	atTimeRegexp       = regexp.MustCompile(`^(.+)@\{([^{}]+)\}$`)
+	relativeTimeRegexp = regexp.MustCompile(`^(\d+)\.(second|minute|hour|day|week|month|year)s?\.ago$`)
 )
 
+// absoluteTimeLayouts are the layouts accepted for the time of a rev@{time} expression, in UTC unless
+// the layout includes a zone
+var absoluteTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
 type RevModType uint8
 
 const (
@@ -25,7 +32,9 @@ func isAHash(part string) bool {
 }
 
 type ParsedRev struct {
-	BaseRev   string
+	BaseRev string
+	// AtTime, if set, selects the last commit on the first-parent history of BaseRev created at or before it
+	AtTime    *time.Time
 	Modifiers []RevModifier
 }
 
@@ -59,12 +68,56 @@ func parseMod(buf string) (RevModifier, error) {
 	}, nil
 }
 
+// parseAtTime parses the time of a rev@{time} expression: an RFC3339 timestamp, a date, or a
+// relative time such as 3.days.ago
+func parseAtTime(spec string, now time.Time) (time.Time, error) {
+	for _, layout := range absoluteTimeLayouts {
+		if t, err := time.Parse(layout, spec); err == nil {
+			return t, nil
+		}
+	}
+	match := relativeTimeRegexp.FindStringSubmatch(spec)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("could not parse time %s: %w", spec, graveler.ErrInvalidRef)
+	}
+	amount, err := strconv.Atoi(match[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse time %s: %w", spec, graveler.ErrInvalidRef)
+	}
+	switch match[2] {
+	case "second":
+		return now.Add(-time.Duration(amount) * time.Second), nil
+	case "minute":
+		return now.Add(-time.Duration(amount) * time.Minute), nil
+	case "hour":
+		return now.Add(-time.Duration(amount) * time.Hour), nil
+	case "day":
+		return now.AddDate(0, 0, -amount), nil
+	case "week":
+		const daysInWeek = 7
+		return now.AddDate(0, 0, -amount*daysInWeek), nil
+	case "month":
+		return now.AddDate(0, -amount, 0), nil
+	default: // year
+		return now.AddDate(-amount, 0, 0), nil
+	}
+}
+
 func RevParse(r graveler.Ref) (ParsedRev, error) {
 	parts := modifiersRegexp.FindAllString(string(r), -1)
 	if len(parts) == 0 || len(parts[0]) == 0 {
 		return ParsedRev{}, graveler.ErrInvalidRef
 	}
 	baseRev := parts[0]
+	var atTime *time.Time
+	if match := atTimeRegexp.FindStringSubmatch(baseRev); match != nil {
+		t, err := parseAtTime(match[2], time.Now())
+		if err != nil {
+			return ParsedRev{}, err
+		}
+		baseRev = match[1]
+		atTime = &t
+	}
 	mods := make([]RevModifier, 0, len(parts)-1)
 	for _, part := range parts[1:] {
 		mod, err := parseMod(part)
@@ -75,6 +128,7 @@ func RevParse(r graveler.Ref) (ParsedRev, error) {
 	}
 	return ParsedRev{
 		BaseRev:   baseRev,
+		AtTime:    atTime,
 		Modifiers: mods,
 	}, nil
 }
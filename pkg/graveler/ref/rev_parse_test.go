@@ -3,12 +3,20 @@ package ref_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/ref"
 )
 
 func TestRevParse(t *testing.T) {
+	atTime := func(value string) *time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			panic(err)
+		}
+		return &t
+	}
 	table := []struct {
 		Name        string
 		Input       string
@@ -91,6 +99,34 @@ func TestRevParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:  "branch_at_time",
+			Input: "main@{2026-01-01T00:00:00Z}",
+			Expected: ref.ParsedRev{
+				BaseRev:   "main",
+				AtTime:    atTime("2026-01-01T00:00:00Z"),
+				Modifiers: make([]ref.RevModifier, 0),
+			},
+		},
+		{
+			Name:  "branch_at_date_tilde",
+			Input: "main@{2026-01-01}~2",
+			Expected: ref.ParsedRev{
+				BaseRev: "main",
+				AtTime:  atTime("2026-01-01T00:00:00Z"),
+				Modifiers: []ref.RevModifier{
+					{
+						Type:  ref.RevModTypeTilde,
+						Value: 2,
+					},
+				},
+			},
+		},
+		{
+			Name:        "invalid_at_time",
+			Input:       "main@{last.tuesday}",
+			ExpectedErr: graveler.ErrInvalidRef,
+		},
 		{
 			Name:        "no_base",
 			Input:       "^^^3",
@@ -119,6 +155,11 @@ func TestRevParse(t *testing.T) {
 				t.Fatalf("expected base rev: %s got %s", cas.Expected.BaseRev, got.BaseRev)
 			}
 
+			if (got.AtTime == nil) != (cas.Expected.AtTime == nil) ||
+				(got.AtTime != nil && !got.AtTime.Equal(*cas.Expected.AtTime)) {
+				t.Fatalf("expected at time: %v got %v", cas.Expected.AtTime, got.AtTime)
+			}
+
 			if len(got.Modifiers) != len(cas.Expected.Modifiers) {
 				t.Fatalf("got wrong number of modifiers, expected %d got %d",
 					len(cas.Expected.Modifiers), len(got.Modifiers))
@@ -137,3 +178,36 @@ func TestRevParse(t *testing.T) {
 		})
 	}
 }
+
+func TestRevParse_RelativeTime(t *testing.T) {
+	table := []struct {
+		Input    string
+		Expected func(time.Time) time.Time
+	}{
+		{Input: "main@{10.seconds.ago}", Expected: func(now time.Time) time.Time { return now.Add(-10 * time.Second) }},
+		{Input: "main@{1.hour.ago}", Expected: func(now time.Time) time.Time { return now.Add(-time.Hour) }},
+		{Input: "main@{3.days.ago}", Expected: func(now time.Time) time.Time { return now.AddDate(0, 0, -3) }},
+		{Input: "main@{2.weeks.ago}", Expected: func(now time.Time) time.Time { return now.AddDate(0, 0, -14) }},
+		{Input: "main@{1.month.ago}", Expected: func(now time.Time) time.Time { return now.AddDate(0, -1, 0) }},
+		{Input: "main@{1.year.ago}", Expected: func(now time.Time) time.Time { return now.AddDate(-1, 0, 0) }},
+	}
+	for _, cas := range table {
+		t.Run(cas.Input, func(t *testing.T) {
+			before := time.Now()
+			got, err := ref.RevParse(graveler.Ref(cas.Input))
+			after := time.Now()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.BaseRev != "main" {
+				t.Fatalf("expected base rev: main got %s", got.BaseRev)
+			}
+			if got.AtTime == nil {
+				t.Fatal("expected at time")
+			}
+			if got.AtTime.Before(cas.Expected(before)) || got.AtTime.After(cas.Expected(after)) {
+				t.Fatalf("at time %s not between %s and %s", got.AtTime, cas.Expected(before), cas.Expected(after))
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ref
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/db"
 	"github.com/treeverse/lakefs/pkg/graveler"
@@ -21,8 +22,32 @@ type TagIterator struct {
 }
 
 type tagRecord struct {
-	TagID    graveler.TagID    `db:"id"`
-	CommitID graveler.CommitID `db:"commit_id"`
+	TagID        graveler.TagID    `db:"id"`
+	CommitID     graveler.CommitID `db:"commit_id"`
+	Message      string            `db:"message"`
+	Creator      string            `db:"creator"`
+	CreationDate *time.Time        `db:"creation_date"`
+	Metadata     map[string]string `db:"metadata"`
+}
+
+// tagColumns lists the graveler_tags columns scanned into a tagRecord
+const tagColumns = "id, commit_id, message, creator, creation_date, metadata"
+
+func (t *tagRecord) toGravelerTagRecord() *graveler.TagRecord {
+	record := &graveler.TagRecord{
+		TagID:    t.TagID,
+		CommitID: t.CommitID,
+	}
+	// only annotated tags have a creation date
+	if t.CreationDate != nil {
+		record.Annotation = &graveler.TagAnnotation{
+			Message:      t.Message,
+			Creator:      t.Creator,
+			CreationDate: *t.CreationDate,
+			Metadata:     t.Metadata,
+		}
+	}
+	return record
 }
 
 func NewTagIterator(ctx context.Context, db db.Database, repositoryID graveler.RepositoryID, fetchSize int) *TagIterator {
@@ -70,7 +95,7 @@ func (ri *TagIterator) maybeFetch() {
 
 	var buf []*tagRecord
 	err := ri.db.Select(ri.ctx, &buf, `
-			SELECT id, commit_id
+			SELECT `+tagColumns+`
 			FROM graveler_tags
 			WHERE repository_id = $1
 			AND id `+offsetCondition+` $2
@@ -84,10 +109,7 @@ func (ri *TagIterator) maybeFetch() {
 		ri.state = iteratorStateDone
 	}
 	for _, b := range buf {
-		ri.buf = append(ri.buf, &graveler.TagRecord{
-			TagID:    b.TagID,
-			CommitID: b.CommitID,
-		})
+		ri.buf = append(ri.buf, b.toGravelerTagRecord())
 	}
 }
 
@@ -23,7 +23,7 @@ func TestTagIterator(t *testing.T) {
 
 	// prepare data
 	for _, b := range tags {
-		err := r.CreateTag(ctx, "repo1", b, "c1")
+		err := r.CreateTag(ctx, "repo1", b, "c1", nil)
 		testutil.Must(t, err)
 	}
 
@@ -41,14 +41,27 @@ func (p *Manager) Get(ctx context.Context, st graveler.StagingToken, key gravele
 	return value, nil
 }
 
-func (p *Manager) Set(ctx context.Context, st graveler.StagingToken, key graveler.Key, value *graveler.Value, overwrite bool) error {
+func (p *Manager) Set(ctx context.Context, st graveler.StagingToken, key graveler.Key, value *graveler.Value, condition graveler.WriteCondition) error {
 	if value == nil {
 		value = new(graveler.Value)
 	} else if value.Identity == nil {
 		return graveler.ErrInvalidValue
 	}
 	_, err := p.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
-		if !overwrite {
+		switch {
+		case condition.IfMatch != nil:
+			res, err := tx.Exec(
+				`UPDATE graveler_staging_kv SET identity = $4, data = $5
+						WHERE staging_token = $1 AND key = $2 AND identity = $3`,
+				st, key, condition.IfMatch, value.Identity, value.Data)
+			if err != nil {
+				return nil, err
+			}
+			if res.RowsAffected() == 0 {
+				return nil, graveler.ErrPreconditionFailed
+			}
+			return res, err
+		case condition.IfAbsent:
 			res, err := tx.Exec(
 				`INSERT INTO graveler_staging_kv (staging_token, key, identity, data)
 						VALUES ($1, $2, $3, $4)
@@ -79,6 +92,38 @@ func (p *Manager) DropKey(ctx context.Context, st graveler.StagingToken, key gra
 	return err
 }
 
+func (p *Manager) Apply(ctx context.Context, st graveler.StagingToken, ops []graveler.StagingOperation) error {
+	for _, op := range ops {
+		if op.Value != nil && op.Value.Identity == nil {
+			return graveler.ErrInvalidValue
+		}
+	}
+	_, err := p.db.Transact(ctx, func(tx db.Tx) (interface{}, error) {
+		for _, op := range ops {
+			if op.Drop {
+				if _, err := tx.Exec("DELETE FROM graveler_staging_kv WHERE staging_token=$1 AND key=$2", st, op.Key); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value := op.Value
+			if value == nil {
+				value = new(graveler.Value)
+			}
+			if _, err := tx.Exec(`INSERT INTO graveler_staging_kv (staging_token, key, identity, data)
+								VALUES ($1, $2, $3, $4)
+								ON CONFLICT (staging_token, key) DO UPDATE
+									SET (staging_token, key, identity, data) =
+											(excluded.staging_token, excluded.key, excluded.identity, excluded.data)`,
+				st, op.Key, value.Identity, value.Data); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}, p.txOpts()...)
+	return err
+}
+
 func (p *Manager) List(ctx context.Context, st graveler.StagingToken) (graveler.ValueIterator, error) {
 	return NewStagingIterator(ctx, p.db, p.log, st), nil
 }
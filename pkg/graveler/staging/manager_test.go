@@ -25,7 +25,7 @@ func TestSetGet(t *testing.T) {
 		t.Fatalf("error different than expected. expected=%v, got=%v", graveler.ErrNotFound, err)
 	}
 	value := newTestValue("identity1", "value1")
-	err = s.Set(ctx, "t1", []byte("a/b/c/"), value, true)
+	err = s.Set(ctx, "t1", []byte("a/b/c/"), value, graveler.WriteCondition{})
 	testutil.Must(t, err)
 	e, err := s.Get(ctx, "t1", []byte("a/b/c/"))
 	testutil.Must(t, err)
@@ -34,14 +34,68 @@ func TestSetGet(t *testing.T) {
 	}
 
 	t.Run("test overwrites", func(t *testing.T) {
-		err = s.Set(ctx, "t2", []byte("a/b/c/d"), value, false)
+		err = s.Set(ctx, "t2", []byte("a/b/c/d"), value, graveler.WriteCondition{IfAbsent: true})
 		testutil.Must(t, err)
 
-		err = s.Set(ctx, "t2", []byte("a/b/c/d"), value, false)
+		err = s.Set(ctx, "t2", []byte("a/b/c/d"), value, graveler.WriteCondition{IfAbsent: true})
 		if err != graveler.ErrPreconditionFailed {
 			t.Fatalf("expected a precondition error when overwriting")
 		}
 	})
+
+	t.Run("test if match", func(t *testing.T) {
+		err = s.Set(ctx, "t3", []byte("a/b/c/d"), newTestValue("identity2", "value2"), graveler.WriteCondition{IfMatch: []byte("identity1")})
+		if !errors.Is(err, graveler.ErrPreconditionFailed) {
+			t.Fatalf("expected a precondition error when key is not staged, got %v", err)
+		}
+		err = s.Set(ctx, "t1", []byte("a/b/c/"), newTestValue("identity2", "value2"), graveler.WriteCondition{IfMatch: []byte("identity2")})
+		if !errors.Is(err, graveler.ErrPreconditionFailed) {
+			t.Fatalf("expected a precondition error when identity does not match, got %v", err)
+		}
+		err = s.Set(ctx, "t1", []byte("a/b/c/"), newTestValue("identity2", "value2"), graveler.WriteCondition{IfMatch: []byte("identity1")})
+		testutil.Must(t, err)
+		e, err := s.Get(ctx, "t1", []byte("a/b/c/"))
+		testutil.Must(t, err)
+		if string(e.Identity) != "identity2" {
+			t.Errorf("got wrong value. expected=%s, got=%s", "identity2", string(e.Identity))
+		}
+	})
+}
+
+func TestApply(t *testing.T) {
+	ctx, s := newTestStagingManager(t)
+	testutil.Must(t, s.Set(ctx, "t1", []byte("a"), newTestValue("identity1", "value1"), graveler.WriteCondition{}))
+	testutil.Must(t, s.Set(ctx, "t1", []byte("b"), newTestValue("identity2", "value2"), graveler.WriteCondition{}))
+
+	// an invalid operation fails the whole batch
+	err := s.Apply(ctx, "t1", []graveler.StagingOperation{
+		{Key: []byte("a"), Drop: true},
+		{Key: []byte("c"), Value: &graveler.Value{Data: []byte("value3")}},
+	})
+	if !errors.Is(err, graveler.ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue for nil identity, got %v", err)
+	}
+	if _, err := s.Get(ctx, "t1", []byte("a")); err != nil {
+		t.Fatalf("expected key 'a' to remain staged after failed batch, got %v", err)
+	}
+
+	err = s.Apply(ctx, "t1", []graveler.StagingOperation{
+		{Key: []byte("a"), Drop: true},
+		{Key: []byte("b")},
+		{Key: []byte("c"), Value: newTestValue("identity3", "value3")},
+	})
+	testutil.Must(t, err)
+	if _, err := s.Get(ctx, "t1", []byte("a")); !errors.Is(err, graveler.ErrNotFound) {
+		t.Errorf("expected key 'a' to be dropped, got %v", err)
+	}
+	if v, err := s.Get(ctx, "t1", []byte("b")); err != nil || v != nil {
+		t.Errorf("expected tombstone on key 'b', got value=%v, err=%v", v, err)
+	}
+	v, err := s.Get(ctx, "t1", []byte("c"))
+	testutil.Must(t, err)
+	if string(v.Identity) != "identity3" {
+		t.Errorf("got wrong value. expected=%s, got=%s", "identity3", string(v.Identity))
+	}
 }
 
 func TestMultiToken(t *testing.T) {
@@ -50,14 +104,14 @@ func TestMultiToken(t *testing.T) {
 	if !errors.Is(err, graveler.ErrNotFound) {
 		t.Fatalf("error different than expected. expected=%v, got=%v", graveler.ErrNotFound, err)
 	}
-	err = s.Set(ctx, "t1", []byte("a/b/c/"), newTestValue("identity1", "value1"), true)
+	err = s.Set(ctx, "t1", []byte("a/b/c/"), newTestValue("identity1", "value1"), graveler.WriteCondition{})
 	testutil.Must(t, err)
 	e, err := s.Get(ctx, "t1", []byte("a/b/c/"))
 	testutil.Must(t, err)
 	if string(e.Identity) != "identity1" {
 		t.Errorf("got wrong identity. expected=%s, got=%s", "identity1", string(e.Identity))
 	}
-	err = s.Set(ctx, "t2", []byte("a/b/c/"), newTestValue("identity2", "value2"), true)
+	err = s.Set(ctx, "t2", []byte("a/b/c/"), newTestValue("identity2", "value2"), graveler.WriteCondition{})
 	testutil.Must(t, err)
 	e, err = s.Get(ctx, "t1", []byte("a/b/c/"))
 	testutil.Must(t, err)
@@ -76,9 +130,9 @@ func TestDrop(t *testing.T) {
 	ctx, s := newTestStagingManager(t)
 	numOfValues := 1400
 	for i := 0; i < numOfValues; i++ {
-		err := s.Set(ctx, "t1", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), true)
+		err := s.Set(ctx, "t1", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), graveler.WriteCondition{})
 		testutil.Must(t, err)
-		err = s.Set(ctx, "t2", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), true)
+		err = s.Set(ctx, "t2", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), graveler.WriteCondition{})
 		testutil.Must(t, err)
 	}
 	err := s.Drop(ctx, "t1")
@@ -110,9 +164,9 @@ func TestDropByPrefix(t *testing.T) {
 	ctx, s := newTestStagingManager(t)
 	numOfValues := 2400
 	for i := 0; i < numOfValues; i++ {
-		err := s.Set(ctx, "t1", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), true)
+		err := s.Set(ctx, "t1", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), graveler.WriteCondition{})
 		testutil.Must(t, err)
-		err = s.Set(ctx, "t2", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), true)
+		err = s.Set(ctx, "t2", []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), graveler.WriteCondition{})
 		testutil.Must(t, err)
 	}
 	err := s.DropByPrefix(ctx, "t1", []byte("key1"))
@@ -225,7 +279,7 @@ func TestDropPrefixBytes(t *testing.T) {
 				err := s.Set(ctx, st, k, &graveler.Value{
 					Identity: []byte{0, 0, 0, 0, 0, 0},
 					Data:     []byte{0, 0, 0, 0, 0, 0},
-				}, true)
+				}, graveler.WriteCondition{})
 				testutil.Must(t, err)
 			}
 			err := s.DropByPrefix(ctx, st, tst.prefix)
@@ -252,7 +306,7 @@ func TestList(t *testing.T) {
 	for _, numOfValues := range []int{1, 100, 1000, 1500, 2500} {
 		token := graveler.StagingToken(fmt.Sprintf("t_%d", numOfValues))
 		for i := 0; i < numOfValues; i++ {
-			err := s.Set(ctx, token, []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), true)
+			err := s.Set(ctx, token, []byte(fmt.Sprintf("key%04d", i)), newTestValue(fmt.Sprintf("identity%d", i), fmt.Sprintf("value%d", i)), graveler.WriteCondition{})
 			testutil.Must(t, err)
 		}
 		res := make([]*graveler.ValueRecord, 0, numOfValues)
@@ -282,7 +336,7 @@ func TestSeek(t *testing.T) {
 	ctx, s := newTestStagingManager(t)
 	numOfValues := 100
 	for i := 0; i < numOfValues; i++ {
-		err := s.Set(ctx, "t1", []byte(fmt.Sprintf("key%04d", i)), newTestValue("identity1", "value1"), true)
+		err := s.Set(ctx, "t1", []byte(fmt.Sprintf("key%04d", i)), newTestValue("identity1", "value1"), graveler.WriteCondition{})
 		testutil.Must(t, err)
 	}
 	it, _ := s.List(ctx, "t1")
@@ -315,9 +369,9 @@ func TestSeek(t *testing.T) {
 
 func TestNilValue(t *testing.T) {
 	ctx, s := newTestStagingManager(t)
-	err := s.Set(ctx, "t1", []byte("key1"), nil, true)
+	err := s.Set(ctx, "t1", []byte("key1"), nil, graveler.WriteCondition{})
 	testutil.Must(t, err)
-	err = s.Set(ctx, "t1", []byte("key2"), newTestValue("identity2", "value2"), true)
+	err = s.Set(ctx, "t1", []byte("key2"), newTestValue("identity2", "value2"), graveler.WriteCondition{})
 	testutil.Must(t, err)
 	e, err := s.Get(ctx, "t1", []byte("key1"))
 	testutil.Must(t, err)
@@ -347,12 +401,12 @@ func TestNilValue(t *testing.T) {
 
 func TestNilIdentity(t *testing.T) {
 	ctx, s := newTestStagingManager(t)
-	err := s.Set(ctx, "t1", []byte("key1"), newTestValue("identity1", "value1"), true)
+	err := s.Set(ctx, "t1", []byte("key1"), newTestValue("identity1", "value1"), graveler.WriteCondition{})
 	testutil.Must(t, err)
 	err = s.Set(ctx, "t1", []byte("key1"), &graveler.Value{
 		Identity: nil,
 		Data:     []byte("value1"),
-	}, true)
+	}, graveler.WriteCondition{})
 	if !errors.Is(err, graveler.ErrInvalidValue) {
 		t.Fatalf("got unexpected error. expected=%v, got=%v", graveler.ErrInvalidValue, err)
 	}
@@ -380,7 +434,7 @@ func TestDeleteAndTombstone(t *testing.T) {
 		},
 	}
 	for _, val := range tombstoneValues {
-		err = s.Set(ctx, "t1", []byte("key1"), val, true)
+		err = s.Set(ctx, "t1", []byte("key1"), val, graveler.WriteCondition{})
 		testutil.Must(t, err)
 		e, err := s.Get(ctx, "t1", []byte("key1"))
 		testutil.Must(t, err)
@@ -403,7 +457,7 @@ func TestDeleteAndTombstone(t *testing.T) {
 		}
 		it.Close()
 	}
-	err = s.Set(ctx, "t1", []byte("key1"), newTestValue("identity3", "value3"), true)
+	err = s.Set(ctx, "t1", []byte("key1"), newTestValue("identity3", "value3"), graveler.WriteCondition{})
 	testutil.Must(t, err)
 	e, err := s.Get(ctx, "t1", []byte("key1"))
 	testutil.Must(t, err)
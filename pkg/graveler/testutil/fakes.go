@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/committed"
@@ -18,13 +19,14 @@ type AppliedData struct {
 }
 
 type CommittedFake struct {
-	ValuesByKey   map[string]*graveler.Value
-	ValueIterator graveler.ValueIterator
-	DiffIterator  graveler.DiffIterator
-	Err           error
-	MetaRangeID   graveler.MetaRangeID
-	DiffSummary   graveler.DiffSummary
-	AppliedData   AppliedData
+	ValuesByKey       map[string]*graveler.Value
+	ValueIterator     graveler.ValueIterator
+	MetaRangeIterator graveler.MetaRangeIterator
+	DiffIterator      graveler.DiffIterator
+	Err               error
+	MetaRangeID       graveler.MetaRangeID
+	Summary           graveler.DiffSummary
+	AppliedData       AppliedData
 }
 
 type MetaRangeFake struct {
@@ -60,25 +62,52 @@ func (c *CommittedFake) List(context.Context, graveler.StorageNamespace, gravele
 	return c.ValueIterator, nil
 }
 
-func (c *CommittedFake) Diff(context.Context, graveler.StorageNamespace, graveler.MetaRangeID, graveler.MetaRangeID) (graveler.DiffIterator, error) {
+func (c *CommittedFake) ListMetaRange(context.Context, graveler.StorageNamespace, graveler.MetaRangeID) (graveler.MetaRangeIterator, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	return c.MetaRangeIterator, nil
+}
+
+func (c *CommittedFake) Diff(context.Context, graveler.StorageNamespace, graveler.MetaRangeID, graveler.MetaRangeID, graveler.DiffParams) (graveler.DiffIterator, error) {
 	if c.Err != nil {
 		return nil, c.Err
 	}
 	return c.DiffIterator, nil
 }
 
-func (c *CommittedFake) Compare(context.Context, graveler.StorageNamespace, graveler.MetaRangeID, graveler.MetaRangeID, graveler.MetaRangeID) (graveler.DiffIterator, error) {
+func (c *CommittedFake) DiffSummary(_ context.Context, _ graveler.StorageNamespace, _, _ graveler.MetaRangeID, prefix graveler.Key) (graveler.DiffSummary, error) {
+	if c.Err != nil {
+		return graveler.DiffSummary{}, c.Err
+	}
+	summary := graveler.DiffSummary{Count: make(map[graveler.DiffType]int)}
+	for c.DiffIterator.Next() {
+		if diff := c.DiffIterator.Value(); bytes.HasPrefix(diff.Key, prefix) {
+			summary.Count[diff.Type]++
+		}
+	}
+	return summary, c.DiffIterator.Err()
+}
+
+func (c *CommittedFake) PrefixChanged(context.Context, graveler.StorageNamespace, graveler.MetaRangeID, graveler.MetaRangeID, graveler.Key) (bool, error) {
+	if c.Err != nil {
+		return false, c.Err
+	}
+	return false, nil
+}
+
+func (c *CommittedFake) Compare(context.Context, graveler.StorageNamespace, graveler.MetaRangeID, graveler.MetaRangeID, graveler.MetaRangeID, graveler.DiffParams) (graveler.DiffIterator, error) {
 	if c.Err != nil {
 		return nil, c.Err
 	}
 	return c.DiffIterator, nil
 }
 
-func (c *CommittedFake) Merge(_ context.Context, _ graveler.StorageNamespace, _, _, _ graveler.MetaRangeID) (graveler.MetaRangeID, graveler.DiffSummary, error) {
+func (c *CommittedFake) Merge(_ context.Context, _ graveler.StorageNamespace, _, _, _ graveler.MetaRangeID, _ graveler.MergeStrategy) (graveler.MetaRangeID, graveler.DiffSummary, error) {
 	if c.Err != nil {
 		return "", graveler.DiffSummary{}, c.Err
 	}
-	return c.MetaRangeID, c.DiffSummary, nil
+	return c.MetaRangeID, c.Summary, nil
 }
 
 func (c *CommittedFake) Apply(_ context.Context, _ graveler.StorageNamespace, metaRangeID graveler.MetaRangeID, values graveler.ValueIterator) (graveler.MetaRangeID, graveler.DiffSummary, error) {
@@ -87,7 +116,7 @@ func (c *CommittedFake) Apply(_ context.Context, _ graveler.StorageNamespace, me
 	}
 	c.AppliedData.Values = values
 	c.AppliedData.MetaRangeID = metaRangeID
-	return c.MetaRangeID, c.DiffSummary, nil
+	return c.MetaRangeID, c.Summary, nil
 }
 
 func (c *CommittedFake) WriteMetaRange(ctx context.Context, ns graveler.StorageNamespace, it graveler.ValueIterator, metadata graveler.Metadata) (*graveler.MetaRangeID, error) {
@@ -116,7 +145,9 @@ type StagingFake struct {
 	ValueIterator      graveler.ValueIterator
 	stagingToken       graveler.StagingToken
 	LastSetValueRecord *graveler.ValueRecord
+	LastSetCondition   graveler.WriteCondition
 	LastRemovedKey     graveler.Key
+	LastAppliedOps     []graveler.StagingOperation
 	DropCalled         bool
 	SetErr             error
 }
@@ -140,7 +171,7 @@ func (s *StagingFake) Get(context.Context, graveler.StagingToken, graveler.Key)
 	return s.Value, nil
 }
 
-func (s *StagingFake) Set(_ context.Context, _ graveler.StagingToken, key graveler.Key, value *graveler.Value, _ bool) error {
+func (s *StagingFake) Set(_ context.Context, _ graveler.StagingToken, key graveler.Key, value *graveler.Value, condition graveler.WriteCondition) error {
 	if s.SetErr != nil {
 		return s.SetErr
 	}
@@ -148,6 +179,7 @@ func (s *StagingFake) Set(_ context.Context, _ graveler.StagingToken, key gravel
 		Key:   key,
 		Value: value,
 	}
+	s.LastSetCondition = condition
 	return nil
 }
 
@@ -159,6 +191,14 @@ func (s *StagingFake) DropKey(_ context.Context, _ graveler.StagingToken, key gr
 	return nil
 }
 
+func (s *StagingFake) Apply(_ context.Context, _ graveler.StagingToken, ops []graveler.StagingOperation) error {
+	if s.SetErr != nil {
+		return s.SetErr
+	}
+	s.LastAppliedOps = ops
+	return nil
+}
+
 func (s *StagingFake) List(context.Context, graveler.StagingToken) (graveler.ValueIterator, error) {
 	if s.Err != nil {
 		return nil, s.Err
@@ -202,6 +242,11 @@ type RefsFake struct {
 	AddedCommit         AddedCommitData
 	CommitID            graveler.CommitID
 	Commits             map[graveler.CommitID]*graveler.Commit
+	MergeBase           *graveler.Commit
+	ProtectionRules     []*graveler.BranchProtectionRule
+	RetentionRules      []*graveler.RetentionRule
+	ExpiredCommits      []graveler.CommitID
+	NamespaceReferences map[graveler.RepositoryID][]graveler.StorageNamespace
 }
 
 func (m *RefsFake) FillGenerations(ctx context.Context, repositoryID graveler.RepositoryID) error {
@@ -216,6 +261,10 @@ func (m *RefsFake) ListCommits(ctx context.Context, repositoryID graveler.Reposi
 	return nil, nil
 }
 
+func (m *RefsFake) ListCommitsSince(context.Context, graveler.RepositoryID, time.Time) (graveler.CommitIterator, error) {
+	return nil, nil
+}
+
 func (m *RefsFake) RevParse(ctx context.Context, repoID graveler.RepositoryID, ref graveler.Ref) (graveler.Reference, error) {
 	if m.RevParseRes != nil {
 		if res, ok := m.RevParseRes[ref]; ok {
@@ -247,6 +296,10 @@ func (m *RefsFake) DeleteRepository(context.Context, graveler.RepositoryID) erro
 	return nil
 }
 
+func (m *RefsFake) UpdateRepository(context.Context, graveler.RepositoryID, graveler.RepositoryUpdate) error {
+	return nil
+}
+
 func (m *RefsFake) GetBranch(context.Context, graveler.RepositoryID, graveler.BranchID) (*graveler.Branch, error) {
 	return m.Branch, m.Err
 }
@@ -267,7 +320,17 @@ func (m *RefsFake) GetTag(context.Context, graveler.RepositoryID, graveler.TagID
 	return m.TagCommitID, m.Err
 }
 
-func (m *RefsFake) CreateTag(context.Context, graveler.RepositoryID, graveler.TagID, graveler.CommitID) error {
+func (m *RefsFake) GetTagRecord(_ context.Context, _ graveler.RepositoryID, tagID graveler.TagID) (*graveler.TagRecord, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.TagCommitID == nil {
+		return nil, graveler.ErrTagNotFound
+	}
+	return &graveler.TagRecord{TagID: tagID, CommitID: *m.TagCommitID}, nil
+}
+
+func (m *RefsFake) CreateTag(context.Context, graveler.RepositoryID, graveler.TagID, graveler.CommitID, *graveler.TagAnnotation) error {
 	return nil
 }
 
@@ -279,6 +342,58 @@ func (m *RefsFake) ListTags(context.Context, graveler.RepositoryID) (graveler.Ta
 	return m.ListTagsRes, nil
 }
 
+func (m *RefsFake) GetBranchProtectionRules(context.Context, graveler.RepositoryID) ([]*graveler.BranchProtectionRule, error) {
+	return m.ProtectionRules, nil
+}
+
+func (m *RefsFake) CreateBranchProtectionRule(_ context.Context, _ graveler.RepositoryID, rule graveler.BranchProtectionRule) error {
+	m.ProtectionRules = append(m.ProtectionRules, &rule)
+	return nil
+}
+
+func (m *RefsFake) DeleteBranchProtectionRule(context.Context, graveler.RepositoryID, string) error {
+	panic("implement me")
+}
+
+func (m *RefsFake) GetRetentionRules(context.Context, graveler.RepositoryID) ([]*graveler.RetentionRule, error) {
+	return m.RetentionRules, nil
+}
+
+func (m *RefsFake) SetRetentionRules(_ context.Context, _ graveler.RepositoryID, rules []*graveler.RetentionRule) error {
+	m.RetentionRules = rules
+	return nil
+}
+
+func (m *RefsFake) AddStorageNamespaceReferences(_ context.Context, repositoryID graveler.RepositoryID, storageNamespaces []graveler.StorageNamespace) error {
+	if m.NamespaceReferences == nil {
+		m.NamespaceReferences = make(map[graveler.RepositoryID][]graveler.StorageNamespace)
+	}
+	m.NamespaceReferences[repositoryID] = append(m.NamespaceReferences[repositoryID], storageNamespaces...)
+	return nil
+}
+
+func (m *RefsFake) ListStorageNamespaceReferences(_ context.Context, repositoryID graveler.RepositoryID) ([]graveler.StorageNamespace, error) {
+	return m.NamespaceReferences[repositoryID], nil
+}
+
+func (m *RefsFake) ListReferencingRepositories(_ context.Context, storageNamespace graveler.StorageNamespace) ([]graveler.RepositoryID, error) {
+	var repositories []graveler.RepositoryID
+	for repositoryID, namespaces := range m.NamespaceReferences {
+		for _, ns := range namespaces {
+			if ns == storageNamespace {
+				repositories = append(repositories, repositoryID)
+				break
+			}
+		}
+	}
+	return repositories, nil
+}
+
+func (m *RefsFake) SetExpiredCommits(_ context.Context, _ graveler.RepositoryID, commitIDs []graveler.CommitID) error {
+	m.ExpiredCommits = commitIDs
+	return nil
+}
+
 func (m *RefsFake) GetCommit(_ context.Context, _ graveler.RepositoryID, id graveler.CommitID) (*graveler.Commit, error) {
 	if val, ok := m.Commits[id]; ok {
 		return val, nil
@@ -302,10 +417,13 @@ func (m *RefsFake) AddCommit(_ context.Context, _ graveler.RepositoryID, commit
 }
 
 func (m *RefsFake) FindMergeBase(context.Context, graveler.RepositoryID, ...graveler.CommitID) (*graveler.Commit, error) {
+	if m.MergeBase != nil {
+		return m.MergeBase, nil
+	}
 	return &graveler.Commit{}, nil
 }
 
-func (m *RefsFake) Log(context.Context, graveler.RepositoryID, graveler.CommitID) (graveler.CommitIterator, error) {
+func (m *RefsFake) Log(context.Context, graveler.RepositoryID, graveler.CommitID, graveler.CommitFilter) (graveler.CommitIterator, error) {
 	return m.CommitIter, nil
 }
 
@@ -381,6 +499,44 @@ func (r *valueIteratorFake) Err() error {
 
 func (r *valueIteratorFake) Close() {}
 
+type commitIteratorFake struct {
+	current int
+	records []graveler.CommitRecord
+	err     error
+}
+
+func NewCommitIteratorFake(records []graveler.CommitRecord) graveler.CommitIterator {
+	return &commitIteratorFake{records: records, current: -1}
+}
+
+func (r *commitIteratorFake) Next() bool {
+	r.current++
+	return r.current < len(r.records)
+}
+
+func (r *commitIteratorFake) SeekGE(id graveler.CommitID) {
+	for i, record := range r.records {
+		if record.CommitID >= id {
+			r.current = i - 1
+			return
+		}
+	}
+	r.current = len(r.records)
+}
+
+func (r *commitIteratorFake) Value() *graveler.CommitRecord {
+	if r.current < 0 || r.current >= len(r.records) {
+		return nil
+	}
+	return &r.records[r.current]
+}
+
+func (r *commitIteratorFake) Err() error {
+	return r.err
+}
+
+func (r *commitIteratorFake) Close() {}
+
 type committedValueIteratorFake struct {
 	current int
 	records []committed.Record
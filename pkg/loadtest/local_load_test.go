@@ -21,6 +21,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/config"
 	"github.com/treeverse/lakefs/pkg/db"
 	dbparams "github.com/treeverse/lakefs/pkg/db/params"
+	"github.com/treeverse/lakefs/pkg/export"
 	"github.com/treeverse/lakefs/pkg/logging"
 	"github.com/treeverse/lakefs/pkg/stats"
 	"github.com/treeverse/lakefs/pkg/testutil"
@@ -78,6 +79,7 @@ func TestLocalLoad(t *testing.T) {
 		catalog.NewActionsOutputWriter(c.BlockAdapter),
 	)
 	c.SetHooksHandler(actionsService)
+	c.SetBranchRunChecker(actionsService)
 
 	authService := auth.NewDBAuthService(conn, crypt.NewSecretStore([]byte("some secret")), authparams.ServiceCache{})
 	meta := auth.NewDBMetadataManager("dev", conf.GetFixedInstallationID(), conn)
@@ -95,6 +97,7 @@ func TestLocalLoad(t *testing.T) {
 		&nullCollector{},
 		nil,
 		actionsService,
+		export.NewJobs(conn, export.NewExporter(c, c.BlockAdapter)),
 		logging.Default(),
 		nil,
 	)
@@ -12,26 +12,33 @@ var (
 )
 
 const (
-	ReadRepositoryAction   = "fs:ReadRepository"
-	CreateRepositoryAction = "fs:CreateRepository"
-	DeleteRepositoryAction = "fs:DeleteRepository"
-	ListRepositoriesAction = "fs:ListRepositories"
-	ReadObjectAction       = "fs:ReadObject"
-	WriteObjectAction      = "fs:WriteObject"
-	DeleteObjectAction     = "fs:DeleteObject"
-	ListObjectsAction      = "fs:ListObjects"
-	CreateCommitAction     = "fs:CreateCommit"
-	ReadCommitAction       = "fs:ReadCommit"
-	ListCommitsAction      = "fs:ListCommits"
-	CreateBranchAction     = "fs:CreateBranch"
-	DeleteBranchAction     = "fs:DeleteBranch"
-	ReadBranchAction       = "fs:ReadBranch"
-	RevertBranchAction     = "fs:RevertBranch"
-	ListBranchesAction     = "fs:ListBranches"
-	CreateTagAction        = "fs:CreateTag"
-	DeleteTagAction        = "fs:DeleteTag"
-	ReadTagAction          = "fs:ReadTag"
-	ListTagsAction         = "fs:ListTags"
+	ReadRepositoryAction         = "fs:ReadRepository"
+	CreateRepositoryAction       = "fs:CreateRepository"
+	DeleteRepositoryAction       = "fs:DeleteRepository"
+	UpdateRepositoryAction       = "fs:UpdateRepository"
+	ListRepositoriesAction       = "fs:ListRepositories"
+	ReadObjectAction             = "fs:ReadObject"
+	WriteObjectAction            = "fs:WriteObject"
+	DeleteObjectAction           = "fs:DeleteObject"
+	ListObjectsAction            = "fs:ListObjects"
+	CreateCommitAction           = "fs:CreateCommit"
+	ReadCommitAction             = "fs:ReadCommit"
+	ListCommitsAction            = "fs:ListCommits"
+	CreateBranchAction           = "fs:CreateBranch"
+	DeleteBranchAction           = "fs:DeleteBranch"
+	ReadBranchAction             = "fs:ReadBranch"
+	RevertBranchAction           = "fs:RevertBranch"
+	ListBranchesAction           = "fs:ListBranches"
+	CreateTagAction              = "fs:CreateTag"
+	DeleteTagAction              = "fs:DeleteTag"
+	ReadTagAction                = "fs:ReadTag"
+	ListTagsAction               = "fs:ListTags"
+	ReadRetentionAction          = "fs:ReadRetentionRules"
+	UpdateRetentionAction        = "fs:UpdateRetentionRules"
+	ReadBranchProtectionAction   = "fs:ReadBranchProtectionRules"
+	UpdateBranchProtectionAction = "fs:UpdateBranchProtectionRules"
+	ExportRepositoryAction       = "fs:ExportRepository"
+	WriteExportDestinationAction = "fs:WriteExportDestination"
 
 	ReadUserAction          = "auth:ReadUser"
 	CreateUserAction        = "auth:CreateUser"
@@ -28,6 +28,10 @@ func TagArn(repoID, tagID string) string {
 	return fSArnPrefix + "repository/" + repoID + "/tag/" + tagID
 }
 
+func ExportDestinationArn(destination string) string {
+	return fSArnPrefix + "export/" + destination
+}
+
 func UserArn(userID string) string {
 	return authArnPrefix + "user/" + userID
 }